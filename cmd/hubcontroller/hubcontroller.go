@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/hubcontrollers"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+	"sigs.k8s.io/work-api/pkg/webhook"
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	var metricsAddr string
+	var enableLeaderElection bool
+	var hubOpts hubcontrollers.Options
+	var statusStreamCertFile, statusStreamKeyFile, statusStreamClientCAFile string
+	var webhookSelfSignedCerts bool
+	var webhookCerts webhook.CertOptions
+	var webhookConfiguration string
+	var webhookSecretPolicy string
+	var clusterDeletionPolicy string
+	var fluxSourceClusters string
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&hubOpts.EnablePlacement, "enable-placement", false,
+		"Enable WorkSets to target the clusters decided by a Placement. Requires the Placement API on the hub.")
+	flag.BoolVar(&hubOpts.EnableWorkGC, "enable-work-gc", false,
+		"Enable deleting Works whose TTL elapsed or which failed longer than the failed work retention.")
+	flag.DurationVar(&hubOpts.FailedWorkRetention, "failed-work-retention", 0,
+		"How long a Work whose apply failed is kept when the work garbage collector is enabled, 0 keeps it forever.")
+	flag.DurationVar(&hubOpts.AbandonedWorkGracePeriod, "abandoned-work-grace-period", time.Hour,
+		"How long the agent has to clean up a Work in a deleted cluster namespace before the hub releases it.")
+	flag.StringVar(&hubOpts.AgentFinalizer, "agent-finalizer", "multicluster.x-k8s.io/work-cleanup",
+		"The finalizer the agents add to the Works, released by the work garbage collector for abandoned Works.")
+	flag.StringVar(&clusterDeletionPolicy, "cluster-deletion-policy", string(hubcontrollers.ClusterDeletionPolicyCascade),
+		"Whether the agent deletes or orphans the resources of the Works of a deleted cluster namespace: Cascade or Orphan.")
+	flag.BoolVar(&hubOpts.EnableWebhook, "enable-webhook", false,
+		"Enable the admission webhooks validating Works, such as rejecting workload changes to immutable Works.")
+	flag.BoolVar(&webhookSelfSignedCerts, "webhook-self-signed-certs", false,
+		"Provision and rotate self-signed certificates for the webhooks and patch the CA bundle of their configuration, "+
+			"rather than reading certificates issued by e.g. cert-manager from the cert dir.")
+	flag.StringVar(&webhookCerts.SecretNamespace, "webhook-namespace", "work",
+		"The namespace of the webhook service and of the secret the self-signed certificates are kept in.")
+	flag.StringVar(&webhookCerts.SecretName, "webhook-cert-secret", "webhook-server-cert",
+		"The secret the self-signed certificates of the webhooks are kept in.")
+	flag.StringVar(&webhookCerts.ServiceName, "webhook-service", "webhook-service",
+		"The service of the webhooks the self-signed serving certificate is issued for.")
+	flag.StringVar(&webhookConfiguration, "webhook-configuration", "validating-webhook-configuration",
+		"The ValidatingWebhookConfiguration whose CA bundle is patched with the self-signed CA.")
+	flag.StringVar(&webhookSecretPolicy, "webhook-secret-policy", string(webhook.SecretPolicyAllow),
+		"Whether the webhook allows, warns about or rejects Works whose manifests include Secrets with plaintext data: Allow, Warn or Reject.")
+	flag.BoolVar(&hubOpts.EnableWorkStateMetrics, "enable-work-state-metrics", false,
+		"Enable exporting the conditions of every Work as the work_status_condition and work_manifest_condition metrics of the elected replica.")
+	flag.BoolVar(&hubOpts.EnableWorkSummaries, "enable-work-summaries", false,
+		"Enable maintaining a work-summary ConfigMap counting the applied, available and degraded Works of every cluster namespace.")
+	flag.BoolVar(&hubOpts.EnableFluxSources, "enable-flux-sources", false,
+		"Enable materializing the artifacts of Flux GitRepositories and OCIRepositories as Works in the clusters listed in their source-clusters annotation.")
+	flag.StringVar(&fluxSourceClusters, "flux-source-clusters", "",
+		"The clusters the Flux sources of each namespace may materialize Works in, as namespace:cluster pairs separated by commas, the cluster * allowing any, e.g. flux-system:*,team-a:cluster1. No source is materialized if empty.")
+	flag.StringVar(&hubOpts.StatusStreamAddr, "status-stream-addr", "",
+		"The address of the gRPC endpoint the agents stream the status of their Works to. The endpoint is disabled if empty.")
+	flag.StringVar(&statusStreamCertFile, "status-stream-cert-file", "",
+		"The certificate of the status stream endpoint, the connections are not encrypted if empty.")
+	flag.StringVar(&statusStreamKeyFile, "status-stream-key-file", "",
+		"The key of the certificate of the status stream endpoint.")
+	flag.StringVar(&statusStreamClientCAFile, "status-stream-client-ca-file", "",
+		"The CA verifying the client certificates of the agents, whose common name must be the namespace of their cluster.")
+	flag.BoolVar(&hubOpts.StatusStreamInsecure, "status-stream-insecure", false,
+		"Accept the status streams of agents without a verified client certificate, letting them update any Work. For development only.")
+	zapOpts := zap.Options{Development: true}
+	zapOpts.BindFlags(flag.CommandLine)
+	flag.Parse()
+	opts := ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		LeaderElection:     enableLeaderElection,
+		LeaderElectionID:   "work-hub-controller",
+		Port:               9443,
+	}
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
+
+	if hubOpts.StatusStreamAddr != "" && statusStreamClientCAFile == "" && !hubOpts.StatusStreamInsecure {
+		setupLog.Error(nil, "the status stream endpoint requires --status-stream-client-ca-file, or --status-stream-insecure")
+		os.Exit(1)
+	}
+	if statusStreamCertFile != "" {
+		tlsConfig, err := statusstream.LoadTLSConfig(statusStreamCertFile, statusStreamKeyFile, statusStreamClientCAFile, true)
+		if err != nil {
+			setupLog.Error(err, "error loading the certificates of the status stream endpoint")
+			os.Exit(1)
+		}
+		hubOpts.StatusStreamTLS = tlsConfig
+	}
+
+	deletionPolicy, err := hubcontrollers.ParseClusterDeletionPolicy(clusterDeletionPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid cluster deletion policy")
+		os.Exit(1)
+	}
+	hubOpts.ClusterDeletionPolicy = deletionPolicy
+
+	if hubOpts.FluxSourceClusters, err = hubcontrollers.ParseFluxSourceClusters(fluxSourceClusters); err != nil {
+		setupLog.Error(err, "invalid flux source clusters")
+		os.Exit(1)
+	}
+
+	secretPolicy, err := webhook.ParseSecretPolicy(webhookSecretPolicy)
+	if err != nil {
+		setupLog.Error(err, "invalid webhook secret policy")
+		os.Exit(1)
+	}
+	hubOpts.WebhookSecretPolicy = secretPolicy
+
+	if webhookSelfSignedCerts {
+		webhookCerts.ValidatingWebhookConfigurations = []string{webhookConfiguration}
+		hubOpts.WebhookCerts = &webhookCerts
+	}
+
+	if err := hubcontrollers.Start(ctrl.SetupSignalHandler(), ctrl.GetConfigOrDie(), setupLog, opts, hubOpts); err != nil {
+		setupLog.Error(err, "problem running hub controllers")
+		os.Exit(1)
+	}
+}
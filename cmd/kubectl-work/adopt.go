@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/work-api/pkg/adopt"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// runAdopt records the resources of a brownfield cluster matching a label selector on the
+// AppliedWork of a Work, so its agent adopts them as if it had applied them.
+func runAdopt(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("adopt", "Adopt the existing resources of the manifests of a Work on its cluster, matching a label selector.", &namespace)
+	clusterKubeconfig := flags.String("cluster-kubeconfig", "", "The kubeconfig of the cluster of the Work.")
+	selector := flags.String("selector", "", "The label selector of the resources to adopt.")
+	flags.StringVar(selector, "l", "", "Shorthand for --selector.")
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	switch {
+	case *clusterKubeconfig == "":
+		return fmt.Errorf("the kubeconfig of the cluster is required")
+	case *selector == "":
+		return fmt.Errorf("the label selector of the resources is required")
+	}
+	labelSelector, err := labels.Parse(*selector)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	work := &workv1alpha1.Work{}
+	if err := c.Get(ctx, key, work); err != nil {
+		return err
+	}
+
+	clusterCfg, err := clientcmd.BuildConfigFromFlags("", *clusterKubeconfig)
+	if err != nil {
+		return err
+	}
+	restMapper, err := apiutil.NewDynamicRESTMapper(clusterCfg)
+	if err != nil {
+		return err
+	}
+	clusterClient, err := client.New(clusterCfg, client.Options{Scheme: scheme, Mapper: restMapper})
+	if err != nil {
+		return err
+	}
+	result, err := adopt.Seed(ctx, clusterClient, restMapper, work, labelSelector)
+	if err != nil {
+		return err
+	}
+	for _, resource := range result.Adopted {
+		fmt.Fprintf(out, "adopted %s\n", describeManifest(resource.ResourceIdentifier))
+	}
+	for _, identifier := range result.Unmatched {
+		fmt.Fprintf(out, "! %s is not a manifest of the work, it is not adopted\n", describeManifest(identifier))
+	}
+	fmt.Fprintf(out, "%d resources adopted by work %s\n", len(result.Adopted), work.Name)
+	return nil
+}
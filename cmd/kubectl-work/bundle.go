@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/pull"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+)
+
+// runBundle writes the signed bundle of the Works of a cluster, for its agent to pull.
+func runBundle(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := flag.NewFlagSet("bundle", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Write the signed bundle of the Works of a cluster, for its agent to pull.\n\n"+
+			"Usage:\n  kubectl work bundle -n NAMESPACE --key=PATH --output=PATH\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&namespace, "namespace", "", "The namespace of the cluster.")
+	flags.StringVar(&namespace, "n", "", "Shorthand for --namespace.")
+	key := flags.String("key", "", "The PEM ed25519 private key signing the bundle.")
+	output := flags.String("output", "", "The file the bundle is written to, its signature is written next to it with the .sig suffix.")
+	validity := flags.Duration("validity", 24*time.Hour, "How long the agent accepts the bundle, it must be written again before it expires.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch {
+	case namespace == "":
+		return fmt.Errorf("the namespace of the cluster is required")
+	case *key == "" || *output == "":
+		return fmt.Errorf("the key and the output file are required")
+	case *validity <= 0:
+		return fmt.Errorf("the validity of the bundle must be positive")
+	}
+	privateKey, err := pull.LoadPrivateKey(*key)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	works := &workv1alpha1.WorkList{}
+	if err := c.List(ctx, works, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	// the signing time orders the bundles of the cluster
+	now := time.Now()
+	bundle := pull.NewBundle(namespace, now.UnixNano(), now.Add(*validity), works.Items)
+	data, signature, err := pull.Sign(bundle, privateKey)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output+pull.SignatureSuffix, signature, 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "bundle of %d works written to %s\n", len(bundle.Works), *output)
+	return nil
+}
+
+// runImportStatus records the status report put by the agent of a cluster on its Works.
+func runImportStatus(ctx context.Context, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("import-status", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Record the status report put by the agent of a cluster on its Works.\n\n"+
+			"Usage:\n  kubectl work import-status --filename=PATH\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	var file string
+	flags.StringVar(&file, "filename", "", "The status report, - for the standard input.")
+	flags.StringVar(&file, "f", "", "Shorthand for --filename.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if file == "" {
+		return fmt.Errorf("the status report is required")
+	}
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	report := &pull.StatusReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return fmt.Errorf("failed to decode the status report: %w", err)
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for i := range report.Works {
+		update := &report.Works[i]
+		// the report of a cluster only updates the works of the cluster
+		update.Cluster = report.Cluster
+		if err := statusstream.ApplyStatus(ctx, c, update); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	fmt.Fprintf(out, "status of %d works of cluster %s imported\n", len(report.Works)-len(errs), report.Cluster)
+	return utilerrors.NewAggregate(errs)
+}
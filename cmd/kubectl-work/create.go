@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/builder"
+	"sigs.k8s.io/work-api/pkg/hubcontrollers"
+)
+
+// stringsFlag is a flag which may be repeated.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// workloadFlags are the flags building the Works of the create and generate commands.
+type workloadFlags struct {
+	files   stringsFlag
+	maxSize int
+}
+
+func addWorkloadFlags(flags *flag.FlagSet) *workloadFlags {
+	f := &workloadFlags{}
+	flags.Var(&f.files, "filename", "A YAML or JSON manifest file, a directory of them, or - for the standard input. May be repeated.")
+	flags.Var(&f.files, "f", "Shorthand for --filename.")
+	flags.IntVar(&f.maxSize, "max-size", hubcontrollers.DefaultMaxWorkSize,
+		"The size in bytes of the manifests above which the Work is split into a group of Works.")
+	return f
+}
+
+// buildWorks builds the Works of the manifests, split into a group of Works if they are too large.
+func (f *workloadFlags) buildWorks(key client.ObjectKey, in io.Reader) ([]*workv1alpha1.Work, error) {
+	if len(f.files) == 0 {
+		return nil, fmt.Errorf("at least one manifest file or directory is required")
+	}
+	b := builder.New(key.Namespace, key.Name).WithMaxSize(f.maxSize)
+	for _, file := range f.files {
+		if file == "-" {
+			b.WithReader(in)
+		} else {
+			b.WithPaths(file)
+		}
+	}
+	return b.BuildGroup()
+}
+
+// runCreate creates a Work from manifest files and directories.
+func runCreate(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("create", "Create a Work from manifest files and directories.", &namespace)
+	workload := addWorkloadFlags(flags)
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	works, err := workload.buildWorks(key, os.Stdin)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	for _, work := range works {
+		if err := c.Create(ctx, work); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "work.multicluster.x-k8s.io/%s created\n", work.Name)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// runDelete deletes a Work. An orphaned Work is annotated first so the agent leaves its resources on
+// the cluster.
+func runDelete(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("delete", "Delete a Work and, unless orphaned, its resources on the cluster.", &namespace)
+	orphan := flags.Bool("orphan", false, "Leave the resources of the Work on the cluster.")
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	work := &workv1alpha1.Work{}
+	if err := c.Get(ctx, key, work); err != nil {
+		return err
+	}
+
+	if *orphan && work.Annotations[workv1alpha1.OrphanAnnotation] != "true" {
+		original := work.DeepCopy()
+		if work.Annotations == nil {
+			work.Annotations = map[string]string{}
+		}
+		work.Annotations[workv1alpha1.OrphanAnnotation] = "true"
+		if err := c.Patch(ctx, work, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+	if err := c.Delete(ctx, work); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "work.multicluster.x-k8s.io/%s deleted\n", work.Name)
+	return nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+// runDiff compares the manifests of a Work with the state reported by its cluster: the manifests
+// not reported yet, those which failed to apply, and the reported resources no longer in the Work.
+func runDiff(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("diff", "Compare the manifests of a Work with the state reported by its cluster.", &namespace)
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	work := &workv1alpha1.Work{}
+	if err := c.Get(ctx, key, work); err != nil {
+		return err
+	}
+	if err := loadStatusDetail(ctx, c, work); err != nil {
+		return err
+	}
+
+	if !conditions.IsFresh(work.Status.Conditions, conditions.TypeApplied, work.Generation) {
+		fmt.Fprintf(out, "! generation %d of the work is not reported by the cluster yet\n", work.Generation)
+	}
+	reported := make([]bool, len(work.Status.ManifestConditions))
+	for ordinal, manifest := range work.Spec.Workload.Manifests {
+		identifier, err := identifyManifest(ordinal, manifest)
+		if err != nil {
+			fmt.Fprintf(out, "! manifest %d cannot be decoded: %v\n", ordinal, err)
+			continue
+		}
+		index := findReportedManifest(identifier, work.Status.ManifestConditions)
+		if index < 0 {
+			fmt.Fprintf(out, "+ %s is not reported by the cluster yet\n", describeManifest(identifier))
+			continue
+		}
+		reported[index] = true
+		manifestConditions := work.Status.ManifestConditions[index].Conditions
+		if message := failureMessage(manifestConditions); message != "" {
+			fmt.Fprintf(out, "~ %s: %s\n", describeManifest(identifier), message)
+		}
+	}
+	for index, manifestCondition := range work.Status.ManifestConditions {
+		if !reported[index] {
+			fmt.Fprintf(out, "- %s is reported by the cluster but no longer in the work\n", describeManifest(manifestCondition.Identifier))
+		}
+	}
+	return nil
+}
+
+// identifyManifest returns the identity of a manifest as the agent reports it, but the resource
+// which is only known to the cluster.
+func identifyManifest(ordinal int, manifest workv1alpha1.Manifest) (workv1alpha1.ResourceIdentifier, error) {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(manifest.Raw, &obj.Object); err != nil {
+		return workv1alpha1.ResourceIdentifier{}, err
+	}
+	gvk := obj.GroupVersionKind()
+	return workv1alpha1.ResourceIdentifier{
+		Ordinal:   ordinal,
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Kind:      gvk.Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+	}, nil
+}
+
+// findReportedManifest returns the index of the condition of a manifest, or -1. The resource of the
+// identifier is ignored as it is not known without the cluster.
+func findReportedManifest(identifier workv1alpha1.ResourceIdentifier, manifestConditions []workv1alpha1.ManifestCondition) int {
+	for i := range manifestConditions {
+		identifier.Resource = manifestConditions[i].Identifier.Resource
+		if workv1alpha1.FindManifestCondition(identifier, manifestConditions[i:i+1]) != nil {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/work-api/pkg/export"
+)
+
+// runExport writes the live resources applied by a Work on its cluster as YAML, for a backup before
+// destructive changes to the Work or to migrate its workload off the hub.
+func runExport(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("export", "Write the live resources applied by a Work on its cluster as YAML.", &namespace)
+	clusterKubeconfig := flags.String("cluster-kubeconfig", "", "The kubeconfig of the cluster of the Work.")
+	output := flags.String("output", "", "The file the resources are written to, the standard output if empty.")
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	if *clusterKubeconfig == "" {
+		return fmt.Errorf("the kubeconfig of the cluster is required")
+	}
+
+	clusterCfg, err := clientcmd.BuildConfigFromFlags("", *clusterKubeconfig)
+	if err != nil {
+		return err
+	}
+	restMapper, err := apiutil.NewDynamicRESTMapper(clusterCfg)
+	if err != nil {
+		return err
+	}
+	clusterClient, err := client.New(clusterCfg, client.Options{Scheme: scheme, Mapper: restMapper})
+	if err != nil {
+		return err
+	}
+	resources, err := export.Export(ctx, clusterClient, restMapper, key.Namespace, key.Name)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return export.WriteYAML(out, resources)
+	}
+	// the resources may hold secrets
+	file, err := os.OpenFile(*output, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if err := export.WriteYAML(file, resources); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%d resources of work %s exported to %s\n", len(resources), key.Name, *output)
+	return nil
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// runGenerate prints the Works of manifest files and directories, or of the output of kustomize
+// build or helm template, without creating them.
+func runGenerate(_ context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("generate", "Print the Works of manifest files and directories, or of - for the standard input.", &namespace)
+	workload := addWorkloadFlags(flags)
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	works, err := workload.buildWorks(key, os.Stdin)
+	if err != nil {
+		return err
+	}
+	for i, work := range works {
+		content, err := yaml.Marshal(work)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(out, "---")
+		}
+		if _, err := out.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
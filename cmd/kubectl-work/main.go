@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-work is a kubectl plugin managing the Works of the hub cluster, installed on the PATH it
+// runs as "kubectl work".
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+const usage = `Manage the Works of the hub cluster.
+
+Usage:
+  kubectl work [--kubeconfig=PATH] COMMAND [flags]
+
+Commands:
+  create         Create a Work from manifest files and directories
+  generate       Print the Work of manifest files and directories without creating it
+  status         Show the conditions of a Work and of each of its manifests
+  diff           Compare the manifests of a Work with the state reported by its cluster
+  adopt          Adopt the existing resources of the manifests of a Work on its cluster
+  export         Write the live resources applied by a Work on its cluster as YAML
+  plan           Preview the resources the agent would create, update and delete on the cluster of a Work
+  delete         Delete a Work, --orphan leaves its resources on the cluster
+  resync         Force the agent to apply a Work again and refresh its status right away
+  restart        Restart the Deployments, StatefulSets and DaemonSets of a Work on its cluster
+  take-over      Transfer the fields of the existing resources of a Work from kubectl or Helm to its agent
+  bundle         Write the signed bundle of the Works of a cluster, for its agent to pull
+  import-status  Record the status report put by the agent of a cluster on its Works
+
+Run "kubectl work COMMAND -h" for the flags of a command.
+`
+
+// command runs a subcommand with its arguments.
+type command func(ctx context.Context, args []string, out io.Writer) error
+
+var (
+	scheme   = runtime.NewScheme()
+	commands = map[string]command{
+		"create":        runCreate,
+		"generate":      runGenerate,
+		"status":        runStatus,
+		"diff":          runDiff,
+		"adopt":         runAdopt,
+		"export":        runExport,
+		"plan":          runPlan,
+		"delete":        runDelete,
+		"resync":        runResync,
+		"restart":       runRestart,
+		"take-over":     runTakeOver,
+		"bundle":        runBundle,
+		"import-status": runImportStatus,
+	}
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(ctrl.SetupSignalHandler(), flag.Args()[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// newClient returns a client of the hub cluster, from the --kubeconfig flag or the environment.
+func newClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: scheme})
+}
+
+// newFlagSet returns the flag set of a subcommand taking the name of a Work, with the namespace flags.
+func newFlagSet(name, description string, namespace *string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "%s\n\nUsage:\n  kubectl work %s NAME -n NAMESPACE [flags]\n\nFlags:\n", description, name)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(namespace, "namespace", "", "The namespace of the cluster of the Work.")
+	flags.StringVar(namespace, "n", "", "Shorthand for --namespace.")
+	return flags
+}
+
+// parseWorkArgs parses the flags of a subcommand and returns the namespace/name of its Work.
+func parseWorkArgs(flags *flag.FlagSet, args []string, namespace *string) (client.ObjectKey, error) {
+	// the name of the work may come before the flags, as kubectl users are used to
+	name := ""
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		name, args = args[0], args[1:]
+	}
+	if err := flags.Parse(args); err != nil {
+		return client.ObjectKey{}, err
+	}
+	if name == "" && flags.NArg() > 0 {
+		name = flags.Arg(0)
+	}
+	switch {
+	case name == "":
+		return client.ObjectKey{}, fmt.Errorf("the name of the work is required")
+	case *namespace == "":
+		return client.ObjectKey{}, fmt.Errorf("the namespace of the work is required")
+	}
+	return client.ObjectKey{Namespace: *namespace, Name: name}, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/plan"
+)
+
+// runPlan previews the changes the agent would make on the cluster of a Work: the resources to
+// create, update with the fields changed, and delete.
+func runPlan(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("plan", "Preview the resources the agent would create, update and delete on the cluster of a Work.", &namespace)
+	clusterKubeconfig := flags.String("cluster-kubeconfig", "", "The kubeconfig of the cluster of the Work.")
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	if *clusterKubeconfig == "" {
+		return fmt.Errorf("the kubeconfig of the cluster is required")
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	work := &workv1alpha1.Work{}
+	if err := c.Get(ctx, key, work); err != nil {
+		return err
+	}
+
+	clusterCfg, err := clientcmd.BuildConfigFromFlags("", *clusterKubeconfig)
+	if err != nil {
+		return err
+	}
+	restMapper, err := apiutil.NewDynamicRESTMapper(clusterCfg)
+	if err != nil {
+		return err
+	}
+	clusterClient, err := client.New(clusterCfg, client.Options{Scheme: scheme, Mapper: restMapper})
+	if err != nil {
+		return err
+	}
+	p, err := plan.Compute(ctx, clusterClient, restMapper, work)
+	if err != nil {
+		return err
+	}
+	return p.Print(out)
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// runStatus prints the conditions of a Work and of each of its manifests.
+func runStatus(ctx context.Context, args []string, out io.Writer) error {
+	var namespace string
+	flags := newFlagSet("status", "Show the conditions of a Work and of each of its manifests.", &namespace)
+	key, err := parseWorkArgs(flags, args, &namespace)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	work := &workv1alpha1.Work{}
+	if err := c.Get(ctx, key, work); err != nil {
+		return err
+	}
+	if err := loadStatusDetail(ctx, c, work); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Work %s, generation %d, %d manifests\n", key, work.Generation, len(work.Spec.Workload.Manifests))
+	if work.Status.WorkloadHash != "" {
+		fmt.Fprintf(out, "Workload hash %s applied at generation %d\n", work.Status.WorkloadHash, work.Status.ObservedGeneration)
+	}
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tSTATUS\tREASON\tGENERATION\tMESSAGE")
+	for _, condition := range work.Status.Conditions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", condition.Type, condition.Status, condition.Reason, condition.ObservedGeneration, condition.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(work.Status.ManifestConditions) == 0 {
+		fmt.Fprintln(out, "\nNo manifest is reported by the cluster yet.")
+		return nil
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(w, "ORDINAL\tRESOURCE\tCONDITIONS\tMESSAGE")
+	for _, manifestCondition := range work.Status.ManifestConditions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", manifestCondition.Identifier.Ordinal, describeManifest(manifestCondition.Identifier),
+			summarizeConditions(manifestCondition.Conditions), failureMessage(manifestCondition.Conditions))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// the errors the manifests recovered from are shown too, they explain intermittent failures
+	header := false
+	for _, manifestCondition := range work.Status.ManifestConditions {
+		for _, applyError := range manifestCondition.ApplyErrors {
+			if !header {
+				fmt.Fprintln(out, "\nRecent apply errors:")
+				fmt.Fprintln(w, "ORDINAL\tRESOURCE\tTIME\tREASON\tMESSAGE")
+				header = true
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", manifestCondition.Identifier.Ordinal, describeManifest(manifestCondition.Identifier),
+				applyError.Time.UTC().Format(time.RFC3339), applyError.Reason, applyError.Message)
+		}
+	}
+	return w.Flush()
+}
+
+// loadStatusDetail sets the manifest conditions the agent moved to the WorkStatusDetail of a Work
+// back on its status.
+func loadStatusDetail(ctx context.Context, c client.Client, work *workv1alpha1.Work) error {
+	if work.Status.StatusDetailName == "" {
+		return nil
+	}
+	detail := &workv1alpha1.WorkStatusDetail{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: work.Namespace, Name: work.Status.StatusDetailName}, detail); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	work.Status.ManifestConditions = detail.ManifestConditions
+	return nil
+}
+
+// describeManifest returns a human readable identity of a manifest.
+func describeManifest(identifier workv1alpha1.ResourceIdentifier) string {
+	kind := identifier.Kind
+	switch {
+	case kind == "":
+		return "<undecodable>"
+	case identifier.Group != "":
+		kind += "." + identifier.Group
+	}
+	if identifier.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, identifier.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, identifier.Namespace, identifier.Name)
+}
+
+// summarizeConditions returns the conditions in the form Type=Status.
+func summarizeConditions(conditions []metav1.Condition) string {
+	summary := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		summary = append(summary, fmt.Sprintf("%s=%s", condition.Type, condition.Status))
+	}
+	return strings.Join(summary, " ")
+}
+
+// failureMessage returns the message of the first false condition, the message of a true condition
+// tells nothing worth a column.
+func failureMessage(conditions []metav1.Condition) string {
+	for _, condition := range conditions {
+		if condition.Status == metav1.ConditionFalse {
+			return condition.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// work-loadgen creates many Works on a test hub and measures how the agent of their cluster copes
+// with them: how long it takes to apply them, how many requests it sends and how much memory it
+// uses.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/loadgen"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	var opts loadgen.Options
+	var qps float64
+	var burst int
+	flag.StringVar(&opts.Name, "name", "loadgen", "The name of the run, the Works are named after it and labeled with it.")
+	flag.StringVar(&opts.Namespace, "namespace", "cluster1", "The cluster namespace on the hub the Works are created in.")
+	flag.StringVar(&opts.TargetNamespace, "target-namespace", "default", "The namespace on the spoke cluster the ConfigMaps of the Works are applied to.")
+	flag.IntVar(&opts.Works, "works", 100, "The number of Works created.")
+	flag.IntVar(&opts.Manifests, "manifests", 10, "The number of ConfigMaps in each Work.")
+	flag.IntVar(&opts.ManifestSize, "manifest-size", 1024, "The bytes of data of each ConfigMap.")
+	flag.IntVar(&opts.Concurrency, "concurrency", 10, "The number of Works created concurrently.")
+	flag.DurationVar(&opts.Timeout, "timeout", 10*time.Minute, "How long the agent has to apply the Works, and then to delete them.")
+	flag.DurationVar(&opts.PollInterval, "poll-interval", time.Second, "How often the Works and the metrics of the agent are read.")
+	flag.StringVar(&opts.AgentMetricsURL, "agent-metrics-url", "",
+		"The metrics endpoint of the agent, e.g. http://localhost:8080/metrics once port-forwarded. The agent is not measured if empty.")
+	flag.BoolVar(&opts.Cleanup, "cleanup", true, "Delete the Works once applied and measure how long their deletion takes.")
+	flag.Float64Var(&qps, "qps", 100, "The rate of the requests sent to the hub.")
+	flag.IntVar(&burst, "burst", 200, "The burst of the requests sent to the hub.")
+	flag.Parse()
+
+	if opts.Works <= 0 || opts.Manifests <= 0 || opts.Concurrency <= 0 || opts.PollInterval <= 0 {
+		fmt.Fprintln(os.Stderr, "error: --works, --manifests, --concurrency and --poll-interval must be positive")
+		os.Exit(2)
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.QPS, cfg.Burst = float32(qps), burst
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := loadgen.Run(ctrl.SetupSignalHandler(), c, opts)
+	if report != nil {
+		if err := report.Print(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
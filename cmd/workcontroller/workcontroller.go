@@ -17,17 +17,26 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/work-api/pkg/apis/v1alpha1"
 	"sigs.k8s.io/work-api/pkg/controllers"
+	"sigs.k8s.io/work-api/pkg/credentials"
+	"sigs.k8s.io/work-api/pkg/debug"
+	"sigs.k8s.io/work-api/pkg/pull"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+	"sigs.k8s.io/work-api/pkg/tracing"
 )
 
 var (
@@ -45,6 +54,16 @@ func main() {
 	var enableLeaderElection bool
 	var hubkubeconfig string
 	var workNamespace string
+	var otlpEndpoint string
+	var otlpInsecure bool
+	var debugAddr string
+	var agentOpts controllers.Options
+	var spokeQPS float64
+	var statusStreamCertFile, statusStreamKeyFile, statusStreamCAFile string
+	var pullPublicKeyFile string
+	var targetKubeconfigs string
+	var hubSVIDCertFile, hubSVIDKeyFile string
+	var recreatedResourcePolicy string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
@@ -52,7 +71,73 @@ func main() {
 		"Paths to a kubeconfig connect to hub.")
 	flag.StringVar(&workNamespace, "work-namespace", "",
 		"Namespace to watch for work.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "",
+		"The OTLP gRPC endpoint to export traces to. Tracing is disabled if empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false,
+		"Disable TLS when exporting traces to the OTLP endpoint.")
+	flag.StringVar(&debugAddr, "debug-addr", "",
+		"The address the pprof and expvar debug endpoints bind to. The endpoints are disabled if empty.")
+	flag.DurationVar(&agentOpts.ResyncInterval, "resync-interval", 0,
+		"How often every Work is applied again to correct drift, with a per-Work jitter. Works are only synced on change if 0.")
+	flag.DurationVar(&agentOpts.SyncTimeout, "sync-timeout", 0,
+		"The deadline of applying the manifests of a Work, cancelling the requests to the spoke cluster still in flight. No deadline if 0.")
+	flag.IntVar(&agentOpts.ApplyConcurrency, "apply-concurrency", 1,
+		"The number of Works applied concurrently.")
+	flag.IntVar(&agentOpts.FinalizeConcurrency, "finalize-concurrency", 1,
+		"The number of deleted Works cleaned up concurrently.")
+	flag.DurationVar(&agentOpts.DiscoveryTTL, "discovery-ttl", 10*time.Minute,
+		"How long the discovery of the spoke cluster is cached, it is never expired if 0.")
+	flag.BoolVar(&agentOpts.CacheSpokeResources, "cache-spoke-resources", false,
+		"Read the applied resources from shared informers of the spoke cluster instead of one request per manifest.")
+	flag.Float64Var(&spokeQPS, "spoke-qps", 5,
+		"The maximum rate of requests sent to the spoke cluster, per second, shared by all the controllers of the agent.")
+	flag.IntVar(&agentOpts.SpokeBurst, "spoke-burst", 10,
+		"The maximum burst of requests sent to the spoke cluster.")
+	flag.BoolVar(&agentOpts.SpokeAdaptiveRate, "spoke-adaptive-rate", false,
+		"Lower the rate of requests sent to the spoke cluster while it throttles them, e.g. with API Priority and Fairness.")
+	flag.StringVar(&agentOpts.StatusStreamAddr, "status-stream-addr", "",
+		"The address of the gRPC endpoint of the hub to stream the status of the Works to, instead of writing it to the hub API.")
+	flag.StringVar(&statusStreamCertFile, "status-stream-cert-file", "",
+		"The client certificate of the agent for the status stream endpoint, its common name must be the namespace of the cluster.")
+	flag.StringVar(&statusStreamKeyFile, "status-stream-key-file", "",
+		"The key of the client certificate of the agent for the status stream endpoint.")
+	flag.StringVar(&statusStreamCAFile, "status-stream-ca-file", "",
+		"The CA verifying the certificate of the status stream endpoint, the connection is not encrypted if empty.")
+	flag.StringVar(&agentOpts.ClusterName, "cluster-name", "",
+		"The namespace of the cluster on the hub, required when the Works are pulled from a bundle.")
+	flag.StringVar(&agentOpts.Pull.BundleURL, "pull-bundle-url", "",
+		"The HTTP(S) URL of the signed bundle of the Works of the cluster. The Works are pulled from it into the work namespace "+
+			"of the spoke cluster, instead of read from the hub API, if set.")
+	flag.StringVar(&agentOpts.Pull.StatusURL, "pull-status-url", "",
+		"The HTTP(S) URL the status report of the pulled Works is put to. The status is not reported if empty.")
+	flag.StringVar(&pullPublicKeyFile, "pull-public-key-file", "",
+		"The PEM ed25519 public key verifying the signature of the bundle.")
+	flag.DurationVar(&agentOpts.Pull.Interval, "pull-interval", time.Minute,
+		"How often the bundle is pulled and the status reported.")
+	flag.StringVar(&targetKubeconfigs, "target-kubeconfigs", "",
+		"Comma separated paths to the kubeconfigs of the clusters the works are applied to, rather than the local cluster. "+
+			"The first one is the primary target reported in the status, the works are copied to the others.")
+	flag.StringVar(&hubSVIDCertFile, "hub-svid-cert-file", "",
+		"The X509-SVID the agent authenticates to the hub with, rather than the credentials of the hub kubeconfig. "+
+			"It is read again when it is rotated.")
+	flag.StringVar(&hubSVIDKeyFile, "hub-svid-key-file", "",
+		"The private key of the X509-SVID.")
+	flag.StringVar(&agentOpts.Finalizer, "finalizer", "multicluster.x-k8s.io/work-cleanup",
+		"The finalizer added to the works to clean up their resources when they are deleted. The hub must use the same name.")
+	flag.BoolVar(&agentOpts.DisableFinalizer, "disable-finalizer", false,
+		"Apply the works without the finalizer, leaving the resources of deleted works on the cluster.")
+	flag.IntVar(&agentOpts.StatusSizeBudget, "status-size-budget", 512*1024,
+		"The size in bytes of the manifest conditions of a work above which they are moved to a WorkStatusDetail on the hub, 0 keeps them in the work.")
+	flag.StringVar(&recreatedResourcePolicy, "recreated-resource-policy", string(controllers.RecreatedResourcePolicyAdopt),
+		"What to do with the resources of the works deleted and recreated by someone else: Adopt, Reapply or Report.")
+	flag.StringVar(&agentOpts.AgentConfigName, "agent-config-name", "",
+		"The name of the WorkAgentConfig on the spoke cluster which tunes the agent at runtime, none if empty.")
+	flag.StringVar(&agentOpts.WorkCacheDir, "work-cache-dir", "",
+		"The directory the last applied works are cached in, to keep correcting drift while the hub is unreachable. No cache if empty.")
+	zapOpts := zap.Options{Development: true}
+	zapOpts.BindFlags(flag.CommandLine)
 	flag.Parse()
+	agentOpts.SpokeQPS = float32(spokeQPS)
 	opts := ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
@@ -60,16 +145,94 @@ func main() {
 		Port:               9443,
 		Namespace:          workNamespace,
 	}
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
 
-	hubConfig, err := clientcmd.BuildConfigFromFlags("", hubkubeconfig)
+	policy, err := controllers.ParseRecreatedResourcePolicy(recreatedResourcePolicy)
 	if err != nil {
-		setupLog.Error(err, "error reading kubeconfig to connect to hub")
+		setupLog.Error(err, "invalid recreated resource policy")
 		os.Exit(1)
 	}
+	agentOpts.RecreatedResourcePolicy = policy
 
-	if err := controllers.Start(ctrl.SetupSignalHandler(), hubConfig, ctrl.GetConfigOrDie(), setupLog, opts); err != nil {
+	if statusStreamCAFile != "" {
+		tlsConfig, err := statusstream.LoadTLSConfig(statusStreamCertFile, statusStreamKeyFile, statusStreamCAFile, false)
+		if err != nil {
+			setupLog.Error(err, "error loading the certificates of the status stream")
+			os.Exit(1)
+		}
+		agentOpts.StatusStreamTLS = tlsConfig
+	}
+
+	var hubConfig *rest.Config
+	if agentOpts.Pull.BundleURL != "" {
+		// the works are mirrored into the spoke cluster, the hub is not reachable
+		hubConfig = ctrl.GetConfigOrDie()
+		publicKey, err := pull.LoadPublicKey(pullPublicKeyFile)
+		if err != nil {
+			setupLog.Error(err, "error reading the public key of the bundle")
+			os.Exit(1)
+		}
+		agentOpts.Pull.PublicKey = publicKey
+	} else {
+		config, err := clientcmd.BuildConfigFromFlags("", hubkubeconfig)
+		if err != nil {
+			setupLog.Error(err, "error reading kubeconfig to connect to hub")
+			os.Exit(1)
+		}
+		hubConfig = config
+		if hubSVIDCertFile != "" {
+			agentOpts.HubCredentials = credentials.NewSVIDFileProvider(hubSVIDCertFile, hubSVIDKeyFile)
+		}
+	}
+
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := tracing.Setup(ctx, "work-agent", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "error setting up tracing")
+		os.Exit(1)
+	}
+
+	if err := debug.Serve(ctx, debugAddr, ctrl.Log.WithName("debug")); err != nil {
+		setupLog.Error(err, "error serving debug endpoints")
+		os.Exit(1)
+	}
+
+	if targetKubeconfigs != "" {
+		applier, err := newTargetApplier(ctx, strings.Split(targetKubeconfigs, ","), agentOpts)
+		if err != nil {
+			setupLog.Error(err, "error connecting to the target clusters")
+			os.Exit(1)
+		}
+		agentOpts.Applier = applier
+	}
+
+	err = controllers.Start(ctx, hubConfig, ctrl.GetConfigOrDie(), setupLog, opts, agentOpts)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "problem flushing traces")
+	}
+	if err != nil {
 		setupLog.Error(err, "problem running controllers")
 		os.Exit(1)
 	}
 }
+
+// newTargetApplier returns the applier of the clusters of the kubeconfigs, fanning out from the
+// first one to the others.
+func newTargetApplier(ctx context.Context, kubeconfigs []string, agentOpts controllers.Options) (controllers.Applier, error) {
+	appliers := []controllers.Applier{}
+	for _, kubeconfig := range kubeconfigs {
+		config, err := clientcmd.BuildConfigFromFlags("", strings.TrimSpace(kubeconfig))
+		if err != nil {
+			return nil, err
+		}
+		applier, err := controllers.NewKubeApplier(ctx, config, agentOpts)
+		if err != nil {
+			return nil, err
+		}
+		appliers = append(appliers, applier)
+	}
+	if len(appliers) == 1 {
+		return appliers[0], nil
+	}
+	return controllers.NewFanOutApplier(appliers[0], appliers[1:]...), nil
+}
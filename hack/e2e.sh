@@ -0,0 +1,57 @@
+#!/usr/bin/env bash
+
+# Copyright 2021 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Runs the e2e tests against a kind cluster acting as both the hub and the spoke: the works are
+# created in the default namespace and applied by the agent to the same cluster.
+#
+# This script is sensitive to the following environment variables:
+#
+#   - KIND_CLUSTER_NAME is the name of the kind cluster, work-e2e by default. An existing cluster
+#     of this name is reused.
+#   - KEEP_CLUSTER keeps the kind cluster after the tests if true, to investigate failures.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE}")/.." && pwd)
+KIND_CLUSTER_NAME=${KIND_CLUSTER_NAME:-work-e2e}
+KEEP_CLUSTER=${KEEP_CLUSTER:-false}
+# the image the agent is deployed with by deploy/deployment.yaml
+IMG=work-api-controller:latest
+
+KUBECONFIG_DIR=$(mktemp -d)
+export KUBECONFIG="${KUBECONFIG_DIR}/kubeconfig"
+
+cleanup() {
+  if [[ "${KEEP_CLUSTER}" != "true" ]]; then
+    kind delete cluster --name "${KIND_CLUSTER_NAME}"
+  else
+    echo "keeping the kind cluster ${KIND_CLUSTER_NAME}, get its kubeconfig with: kind get kubeconfig --name ${KIND_CLUSTER_NAME}"
+  fi
+  rm -rf "${KUBECONFIG_DIR}"
+}
+trap cleanup EXIT
+
+if ! kind get clusters | grep -qx "${KIND_CLUSTER_NAME}"; then
+  kind create cluster --name "${KIND_CLUSTER_NAME}" --wait 120s
+fi
+kind get kubeconfig --name "${KIND_CLUSTER_NAME}" > "${KUBECONFIG}"
+
+docker build -t "${IMG}" "${SCRIPT_ROOT}"
+kind load docker-image "${IMG}" --name "${KIND_CLUSTER_NAME}"
+
+make -C "${SCRIPT_ROOT}" test-e2e HUB_KUBECONFIG="${KUBECONFIG}" SPOKE_KUBECONFIG="${KUBECONFIG}"
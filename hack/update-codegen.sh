@@ -20,7 +20,7 @@ set -o pipefail
 
 SCRIPT_ROOT=$(dirname "${BASH_SOURCE}")/..
 
-go install k8s.io/code-generator/cmd/{client-gen,lister-gen,informer-gen,deepcopy-gen,register-gen}
+go install k8s.io/code-generator/cmd/{applyconfiguration-gen,client-gen,lister-gen,informer-gen,deepcopy-gen,register-gen}
 
 # Go installs the above commands to get installed in $GOBIN if defined, and $GOPATH/bin otherwise:
 GOBIN="$(go env GOBIN)"
@@ -41,8 +41,47 @@ COMMON_FLAGS="${VERIFY_FLAG:-} --go-header-file ${SCRIPT_ROOT}/hack/boilerplate.
 echo "Generating deepcopy funcs"
 "${gobin}/deepcopy-gen" --input-dirs "${FQ_APIS}" -O zz_generated.deepcopy --bounding-dirs "${APIS_PKG}" ${COMMON_FLAGS}
 
+echo "Generating apply configurations at ${OUTPUT_PKG}/applyconfiguration"
+# The apply configurations are generated aside and fixed up before being compared with or copied
+# into the tree:
+# - applyconfiguration-gen names the package of the group after the API group (multicluster) while
+#   client-gen names it after the API directory (apis), so the package is moved under apis.
+# - applyconfiguration-gen v0.22 cannot be told where the apply configuration of OwnerReference
+#   lives, so the owner references of the object meta are switched to it.
+APPLYCONFIG_DIR="${SCRIPT_ROOT}/pkg/client/applyconfiguration"
+APPLYCONFIG_TMP=$(mktemp -d)
+trap 'rm -rf "${APPLYCONFIG_TMP}"' EXIT
+"${gobin}/applyconfiguration-gen" \
+         --input-dirs "${FQ_APIS}" \
+         --output-base "${APPLYCONFIG_TMP}" \
+         --output-package "${OUTPUT_PKG}/applyconfiguration" \
+         --go-header-file "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
+GENERATED_APPLYCONFIG_DIR="${APPLYCONFIG_TMP}/${OUTPUT_PKG}/applyconfiguration"
+mv "${GENERATED_APPLYCONFIG_DIR}/multicluster" "${GENERATED_APPLYCONFIG_DIR}/apis"
+sed -i.bak \
+  -e 's#applyconfiguration/multicluster/#applyconfiguration/apis/#' \
+  -e 's#multiclusterv1alpha1#apisv1alpha1#g' \
+  "${GENERATED_APPLYCONFIG_DIR}/utils.go"
+sed -i.bak \
+  -e 's#values \.\.\.metav1\.OwnerReference)#values ...*v1.OwnerReferenceApplyConfiguration)#' \
+  -e 's#append(b\.OwnerReferences, values\[i\])#append(b.OwnerReferences, *values[i])#' \
+  "${GENERATED_APPLYCONFIG_DIR}"/apis/v1alpha1/*.go
+find "${GENERATED_APPLYCONFIG_DIR}" -name '*.bak' -delete
+if [[ "${VERIFY_CODEGEN:-}" == "true" ]]; then
+  diff -Naupr "${APPLYCONFIG_DIR}" "${GENERATED_APPLYCONFIG_DIR}"
+else
+  rm -rf "${APPLYCONFIG_DIR}"
+  cp -R "${GENERATED_APPLYCONFIG_DIR}" "${APPLYCONFIG_DIR}"
+fi
+
 echo "Generating clientset at ${OUTPUT_PKG}/${CLIENTSET_PKG_NAME}"
-"${gobin}/client-gen" --clientset-name "${CLIENTSET_NAME}" --input-base "" --input "${FQ_APIS}" --output-package "${OUTPUT_PKG}/${CLIENTSET_PKG_NAME}" ${COMMON_FLAGS}
+"${gobin}/client-gen" \
+         --clientset-name "${CLIENTSET_NAME}" \
+         --input-base "" \
+         --input "${FQ_APIS}" \
+         --apply-configuration-package "${OUTPUT_PKG}/applyconfiguration" \
+         --output-package "${OUTPUT_PKG}/${CLIENTSET_PKG_NAME}" \
+         ${COMMON_FLAGS}
 
 echo "Generating listers at ${OUTPUT_PKG}/listers"
 "${gobin}/lister-gen" --input-dirs "${FQ_APIS}" --output-package "${OUTPUT_PKG}/listers" ${COMMON_FLAGS}
@@ -0,0 +1,201 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package adopt seeds the AppliedWork of a Work from the resources already on its cluster, so the
+// agent adopts the resources of a brownfield cluster as if it had applied them: it updates them
+// in place, detects when they are recreated by someone else, and deletes them when they are
+// removed from the Work.
+package adopt
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Result is what was adopted from a cluster for a Work.
+type Result struct {
+	// Adopted are the resources recorded on the AppliedWork, with the UID of the live resource.
+	Adopted []workv1alpha1.AppliedResourceMeta
+	// Unmatched are the resources matching the selector which are not manifests of the Work. They
+	// are not adopted, as the agent would delete them as no longer in the Work.
+	Unmatched []workv1alpha1.ResourceIdentifier
+}
+
+// Seed lists the resources of the kinds of the manifests of a Work matching a label selector, with
+// the client and the RESTMapper of its cluster, and records those of the manifests on the
+// AppliedWork of the Work, which is created if it does not exist. The resources already recorded
+// are kept as they are. The manifests using generateName cannot be matched and are ignored.
+func Seed(ctx context.Context, c client.Client, restMapper meta.RESTMapper, work *workv1alpha1.Work, selector labels.Selector) (*Result, error) {
+	manifests, err := identifyManifests(restMapper, work)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	listed := map[schema.GroupVersionKind]bool{}
+	for _, manifest := range manifests {
+		gvk := schema.GroupVersionKind{Group: manifest.Group, Version: manifest.Version, Kind: manifest.Kind}
+		if listed[gvk] {
+			continue
+		}
+		listed[gvk] = true
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+		for _, live := range list.Items {
+			identifier := workv1alpha1.ResourceIdentifier{
+				Group:     manifest.Group,
+				Version:   manifest.Version,
+				Kind:      manifest.Kind,
+				Resource:  manifest.Resource,
+				Namespace: live.GetNamespace(),
+				Name:      live.GetName(),
+			}
+			ordinal := findManifest(identifier, manifests)
+			if ordinal < 0 {
+				result.Unmatched = append(result.Unmatched, identifier)
+				continue
+			}
+			identifier.Ordinal = ordinal
+			result.Adopted = append(result.Adopted, workv1alpha1.AppliedResourceMeta{ResourceIdentifier: identifier, UID: live.GetUID()})
+		}
+	}
+
+	if err := recordAdopted(ctx, c, work, result.Adopted); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// identifyManifests returns the identity of the named manifests of a Work, with their ordinal. A
+// namespaced manifest without a namespace is given the default namespace of the Work, as the agent
+// does when it applies it.
+func identifyManifests(restMapper meta.RESTMapper, work *workv1alpha1.Work) ([]workv1alpha1.ResourceIdentifier, error) {
+	manifests := []workv1alpha1.ResourceIdentifier{}
+	for ordinal, manifest := range work.Spec.Workload.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest %d: %w", ordinal, err)
+		}
+		if obj.GetName() == "" {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map manifest %d: %w", ordinal, err)
+		}
+		namespace := obj.GetNamespace()
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace && namespace == "" {
+			namespace = work.Spec.DefaultNamespace
+		}
+		manifests = append(manifests, workv1alpha1.ResourceIdentifier{
+			Ordinal:   ordinal,
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Resource:  mapping.Resource.Resource,
+			Namespace: namespace,
+			Name:      obj.GetName(),
+		})
+	}
+	return manifests, nil
+}
+
+// findManifest returns the ordinal of the manifest of a resource, or -1.
+func findManifest(identifier workv1alpha1.ResourceIdentifier, manifests []workv1alpha1.ResourceIdentifier) int {
+	for _, manifest := range manifests {
+		if manifest.Group == identifier.Group && manifest.Kind == identifier.Kind &&
+			manifest.Namespace == identifier.Namespace && manifest.Name == identifier.Name {
+			return manifest.Ordinal
+		}
+	}
+	return -1
+}
+
+// recordAdopted records the adopted resources on the AppliedWork of a Work, looked up by its Work
+// and named with AppliedWorkName like the agent does, keeping the resources already recorded.
+func recordAdopted(ctx context.Context, c client.Client, work *workv1alpha1.Work, adopted []workv1alpha1.AppliedResourceMeta) error {
+	appliedWork, err := findAppliedWork(ctx, c, work)
+	if err != nil {
+		return err
+	}
+	if appliedWork == nil {
+		appliedWork = &workv1alpha1.AppliedWork{
+			ObjectMeta: metav1.ObjectMeta{Name: workv1alpha1.AppliedWorkName(work.Namespace, work.Name)},
+			Spec: workv1alpha1.AppliedWorkSpec{
+				WorkName:      work.Name,
+				WorkNamespace: work.Namespace,
+			},
+		}
+		if err := c.Create(ctx, appliedWork); err != nil {
+			return fmt.Errorf("failed to create appliedwork %s: %w", appliedWork.Name, err)
+		}
+	}
+
+	original := appliedWork.DeepCopy()
+	changed := false
+	for _, resource := range adopted {
+		if isRecorded(resource.ResourceIdentifier, appliedWork.Status.AppliedResources) {
+			continue
+		}
+		appliedWork.Status.AppliedResources = append(appliedWork.Status.AppliedResources, resource)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	// the patch replaces the applied resources whole, it fails rather than drop the ones the agent recorded meanwhile
+	return c.Status().Patch(ctx, appliedWork, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// findAppliedWork returns the AppliedWork of a Work, or nil if there is none. The AppliedWorks
+// created before they were named with AppliedWorkName are named after the Work alone.
+func findAppliedWork(ctx context.Context, c client.Reader, work *workv1alpha1.Work) (*workv1alpha1.AppliedWork, error) {
+	appliedWorks := &workv1alpha1.AppliedWorkList{}
+	if err := c.List(ctx, appliedWorks); err != nil {
+		return nil, err
+	}
+	for i := range appliedWorks.Items {
+		if appliedWorks.Items[i].Spec.WorkNamespace == work.Namespace && appliedWorks.Items[i].Spec.WorkName == work.Name {
+			return &appliedWorks.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// isRecorded returns whether a resource is already recorded, ignoring the ordinal and the version
+// as the same resource may be applied through another version.
+func isRecorded(identifier workv1alpha1.ResourceIdentifier, appliedResources []workv1alpha1.AppliedResourceMeta) bool {
+	for _, resource := range appliedResources {
+		if resource.Group == identifier.Group && resource.Resource == identifier.Resource &&
+			resource.Namespace == identifier.Namespace && resource.Name == identifier.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package adopt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func newConfigMap(name string, labels map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Labels: labels, UID: types.UID("uid-" + name)},
+	}
+}
+
+func newManifest(t *testing.T, obj runtime.Object) workv1alpha1.Manifest {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}
+}
+
+func TestSeed(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	app := map[string]string{"app": "shop"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newConfigMap("config", app),
+		newConfigMap("settings", app),
+		newConfigMap("leftover", app),
+		newConfigMap("other", map[string]string{"app": "other"}),
+	).Build()
+
+	// the namespace of the settings manifest is the default namespace of the work
+	settings := newConfigMap("settings", nil)
+	settings.Namespace = ""
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cluster1", Name: "shop"},
+		Spec: workv1alpha1.WorkSpec{
+			DefaultNamespace: "default",
+			Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+				newManifest(t, newConfigMap("config", nil)),
+				newManifest(t, settings),
+				newManifest(t, newConfigMap("other", nil)),
+			}},
+		},
+	}
+
+	result, err := Seed(context.Background(), c, restMapper, work, labels.SelectorFromSet(app))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Adopted) != 2 || result.Adopted[0].Name != "config" || result.Adopted[0].UID != "uid-config" ||
+		result.Adopted[1].Name != "settings" || result.Adopted[1].Ordinal != 1 {
+		t.Errorf("expected the resources of the manifests matching the selector to be adopted, got %+v", result.Adopted)
+	}
+	if len(result.Unmatched) != 1 || result.Unmatched[0].Name != "leftover" {
+		t.Errorf("expected the resource which is not a manifest to be unmatched, got %+v", result.Unmatched)
+	}
+
+	appliedWork := &workv1alpha1.AppliedWork{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: workv1alpha1.AppliedWorkName("cluster1", "shop")}, appliedWork); err != nil {
+		t.Fatal(err)
+	}
+	if appliedWork.Spec.WorkNamespace != "cluster1" || len(appliedWork.Status.AppliedResources) != 2 {
+		t.Errorf("expected the AppliedWork of the work to record the adopted resources, got %+v", appliedWork)
+	}
+
+	// seeding again keeps the resources recorded
+	if _, err := Seed(context.Background(), c, restMapper, work, labels.SelectorFromSet(app)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: workv1alpha1.AppliedWorkName("cluster1", "shop")}, appliedWork); err != nil {
+		t.Fatal(err)
+	}
+	if len(appliedWork.Status.AppliedResources) != 2 {
+		t.Errorf("expected the resources to be recorded once, got %+v", appliedWork.Status.AppliedResources)
+	}
+
+	// the work of the same name in another namespace gets its own AppliedWork
+	other := work.DeepCopy()
+	other.Namespace = "cluster2"
+	if _, err := Seed(context.Background(), c, restMapper, other, labels.SelectorFromSet(app)); err != nil {
+		t.Fatal(err)
+	}
+	otherAppliedWork := &workv1alpha1.AppliedWork{}
+	if err := c.Get(context.Background(), client.ObjectKey{Name: workv1alpha1.AppliedWorkName("cluster2", "shop")}, otherAppliedWork); err != nil {
+		t.Fatal(err)
+	}
+	if otherAppliedWork.Spec.WorkNamespace != "cluster2" {
+		t.Errorf("expected the AppliedWork of the other work, got %+v", otherAppliedWork)
+	}
+}
+
+func TestSeedLegacyAppliedWork(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	// the AppliedWork was named after the work alone by an older agent
+	app := map[string]string{"app": "shop"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newConfigMap("config", app),
+		&workv1alpha1.AppliedWork{
+			ObjectMeta: metav1.ObjectMeta{Name: "shop"},
+			Spec:       workv1alpha1.AppliedWorkSpec{WorkNamespace: "cluster1", WorkName: "shop"},
+		},
+	).Build()
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cluster1", Name: "shop"},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+			newManifest(t, newConfigMap("config", nil)),
+		}}},
+	}
+
+	if _, err := Seed(context.Background(), c, restMapper, work, labels.SelectorFromSet(app)); err != nil {
+		t.Fatal(err)
+	}
+	appliedWorks := &workv1alpha1.AppliedWorkList{}
+	if err := c.List(context.Background(), appliedWorks); err != nil {
+		t.Fatal(err)
+	}
+	if len(appliedWorks.Items) != 1 || len(appliedWorks.Items[0].Status.AppliedResources) != 1 {
+		t.Errorf("expected the resources to be recorded on the existing AppliedWork, got %+v", appliedWorks.Items)
+	}
+}
@@ -63,15 +63,19 @@ type AppliedResourceMeta struct {
 // +genclient
 // +genclient:nonNamespaced
 // +kubebuilder:subresource:status
-// +kubebuilder:resource:scope=Cluster,categories={fleet}
+// +kubebuilder:resource:scope=Cluster,shortName=aw,categories={fleet}
 // +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Work Namespace",type=string,JSONPath=`.spec.workNamespace`
+// +kubebuilder:printcolumn:name="Work",type=string,JSONPath=`.spec.workName`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // AppliedWork represents an applied work on managed cluster that is placed
 // on a managed cluster. An appliedwork links to a work on a hub recording resources
 // deployed in the managed cluster.
 // When the agent is removed from managed cluster, cluster-admin on managed cluster
 // can delete appliedwork to remove resources deployed by the agent.
-// The name of the appliedwork must be the same as {work name}
+// The name of the appliedwork is a hash of the namespace and the name of its work,
+// see AppliedWorkName.
 // The namespace of the appliedwork should be the same as the resource applied on
 // the managed cluster.
 type AppliedWork struct {
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"flag"
+	"math/rand"
+	"testing"
+
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/apitesting/fuzzer"
+	"k8s.io/apimachinery/pkg/api/apitesting/roundtrip"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metafuzzer "k8s.io/apimachinery/pkg/apis/meta/fuzzer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+var fuzzSeed = flag.Int64("fuzz-seed", 0, "The seed of the fuzzer, a random one if zero. The seed of a run is logged.")
+
+// fuzzerSeed returns the seed of the fuzzer of a test, and logs it so a failure can be reproduced with
+// -fuzz-seed.
+func fuzzerSeed(t *testing.T) int64 {
+	seed := *fuzzSeed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	t.Logf("fuzzer seed %d", seed)
+	return seed
+}
+
+// newFuzzer returns a fuzzer filling the types of the work API with random values. The manifests
+// are filled with random metav1 objects encoded as JSON, as raw manifests must be valid objects.
+func newFuzzer(seed int64) (*runtime.Scheme, serializer.CodecFactory, *fuzz.Fuzzer) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(AddToScheme(scheme))
+	codecs := serializer.NewCodecFactory(scheme)
+	return scheme, codecs, fuzzer.FuzzerFor(metafuzzer.Funcs, rand.NewSource(seed), codecs)
+}
+
+// TestRoundTripTypes checks every kind of every version of the work API is decoded back to the
+// same object once encoded, so changes to the serialization of the types cannot break the
+// objects already stored.
+func TestRoundTripTypes(t *testing.T) {
+	scheme, codecs, f := newFuzzer(fuzzerSeed(t))
+	roundtrip.RoundTripExternalTypesWithoutProtobuf(t, scheme, codecs, f, nil)
+}
+
+// TestDeepCopy checks the copy of every kind of the work API is equal to the original and shares
+// no memory with it.
+func TestDeepCopy(t *testing.T) {
+	scheme, codecs, f := newFuzzer(fuzzerSeed(t))
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.Group != GroupName || roundtrip.GlobalNonRoundTrippableTypes().Has(gvk.Kind) {
+			continue
+		}
+		t.Run(gvk.Kind, func(t *testing.T) {
+			for i := 0; i < *roundtrip.FuzzIters; i++ {
+				testDeepCopy(t, scheme, codecs, f, gvk)
+			}
+		})
+	}
+}
+
+func testDeepCopy(t *testing.T, scheme *runtime.Scheme, codecs serializer.CodecFactory, f *fuzz.Fuzzer, gvk schema.GroupVersionKind) {
+	obj, err := scheme.New(gvk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Fuzz(obj)
+	codec := codecs.LegacyCodec(gvk.GroupVersion())
+	original, err := runtime.Encode(codec, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copied := obj.DeepCopyObject()
+	if !apiequality.Semantic.DeepEqual(obj, copied) {
+		t.Fatalf("expected the copy of %v to be equal to the original", gvk)
+	}
+
+	// changing the copy must leave the original untouched
+	f.Fuzz(copied)
+	fuzzed, err := runtime.Encode(codec, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(original, fuzzed) {
+		t.Fatalf("expected the copy of %v to share no memory with the original, got\n%s\nthen\n%s", gvk, original, fuzzed)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+)
+
+// AppliedWorkName returns the name of the AppliedWork of a work on the spoke cluster. The
+// AppliedWorks are cluster scoped, the name is a hash of the namespace and the name of the work so
+// the works of the same name in several namespaces do not collide.
+func AppliedWorkName(workNamespace, workName string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(workNamespace+"/"+workName)))
+}
+
+// FindManifestCondition returns the condition of the manifest with the given identifier, or nil if
+// there is none. A condition matching the whole identifier is preferred. Otherwise a condition of
+// the same resource at another ordinal is returned, as the manifests of a work may be reordered;
+// an identifier holding only an ordinal, such as the one of a manifest which cannot be decoded,
+// only matches the whole identifier.
+func FindManifestCondition(identifier ResourceIdentifier, manifestConditions []ManifestCondition) *ManifestCondition {
+	for i := range manifestConditions {
+		if identifier == manifestConditions[i].Identifier {
+			return &manifestConditions[i]
+		}
+	}
+
+	if identifier == (ResourceIdentifier{Ordinal: identifier.Ordinal}) {
+		return nil
+	}
+
+	identifierCopy := identifier
+	for i := range manifestConditions {
+		identifierCopy.Ordinal = manifestConditions[i].Identifier.Ordinal
+		if identifierCopy == manifestConditions[i].Identifier {
+			return &manifestConditions[i]
+		}
+	}
+	return nil
+}
+
+// WorkloadHash returns the hex encoded sha256 hash of the workload of a Work spec. The manifests
+// are hashed in order, each normalized as JSON with sorted keys, with the default namespace, so
+// the hash does not depend on how the manifests were written but on their content only.
+func WorkloadHash(spec WorkSpec) (string, error) {
+	manifests := make([]interface{}, 0, len(spec.Workload.Manifests))
+	for i, manifest := range spec.Workload.Manifests {
+		raw, err := manifest.MarshalJSON()
+		if err != nil {
+			return "", fmt.Errorf("failed to encode manifest %d: %w", i, err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		// the numbers are kept as written rather than rounded to floats
+		decoder.UseNumber()
+		var normalized interface{}
+		if err := decoder.Decode(&normalized); err != nil {
+			return "", fmt.Errorf("failed to decode manifest %d: %w", i, err)
+		}
+		manifests = append(manifests, normalized)
+	}
+
+	data, err := json.Marshal(struct {
+		DefaultNamespace string        `json:"defaultNamespace,omitempty"`
+		Manifests        []interface{} `json:"manifests"`
+	}{spec.DefaultNamespace, manifests})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestFindManifestCondition(t *testing.T) {
+	configMap := ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "config"}
+	secret := ResourceIdentifier{Ordinal: 1, Version: "v1", Kind: "Secret", Resource: "secrets", Namespace: "default", Name: "secret"}
+	invalid := ResourceIdentifier{Ordinal: 2}
+	manifestConditions := []ManifestCondition{{Identifier: configMap}, {Identifier: secret}, {Identifier: invalid}}
+
+	moved := secret
+	moved.Ordinal = 0
+	cases := map[string]struct {
+		identifier ResourceIdentifier
+		expected   *ManifestCondition
+	}{
+		"whole identifier":        {identifier: secret, expected: &manifestConditions[1]},
+		"reordered manifest":      {identifier: moved, expected: &manifestConditions[1]},
+		"ordinal only":            {identifier: invalid, expected: &manifestConditions[2]},
+		"ordinal only, not found": {identifier: ResourceIdentifier{Ordinal: 3}},
+		"unknown resource":        {identifier: ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "Pod", Resource: "pods", Name: "pod"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := FindManifestCondition(c.identifier, manifestConditions); actual != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestWorkloadHash(t *testing.T) {
+	spec := func(defaultNamespace string, manifests ...string) WorkSpec {
+		spec := WorkSpec{DefaultNamespace: defaultNamespace}
+		for _, manifest := range manifests {
+			spec.Workload.Manifests = append(spec.Workload.Manifests, Manifest{RawExtension: runtime.RawExtension{Raw: []byte(manifest)}})
+		}
+		return spec
+	}
+	configMap := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config"},"data":{"replicas":"3"}}`
+	secret := `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"secret"}}`
+	hash, err := WorkloadHash(spec("", configMap, secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		spec     WorkSpec
+		expected bool
+	}{
+		"reformatted manifests": {
+			spec:     spec("", "{\"kind\": \"ConfigMap\",\n \"apiVersion\": \"v1\", \"data\": {\"replicas\": \"3\"}, \"metadata\": {\"name\": \"config\"}}", secret),
+			expected: true,
+		},
+		"changed manifest":          {spec: spec("", `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config"},"data":{"replicas":"4"}}`, secret)},
+		"reordered manifests":       {spec: spec("", secret, configMap)},
+		"removed manifest":          {spec: spec("", configMap)},
+		"changed default namespace": {spec: spec("default", configMap, secret)},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := WorkloadHash(c.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (actual == hash) != c.expected {
+				t.Errorf("expected the hash %q to be the same as %q: %v", actual, hash, c.expected)
+			}
+		})
+	}
+
+	if _, err := WorkloadHash(spec("", "{")); err == nil {
+		t.Error("expected an invalid manifest to fail to hash")
+	}
+}
+
+func TestAppliedWorkName(t *testing.T) {
+	name := AppliedWorkName("cluster1", "shop")
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		t.Errorf("expected a valid object name, got %q: %v", name, errs)
+	}
+	if name != AppliedWorkName("cluster1", "shop") {
+		t.Error("expected the name to be stable")
+	}
+	if name == AppliedWorkName("cluster2", "shop") {
+		t.Error("expected the works of the same name in several namespaces to get different names")
+	}
+}
@@ -24,10 +24,151 @@ import (
 const WorkKind = "Work"
 const WorkResource = "works"
 
+const (
+	// PromoteToAnnotation requests the hub to promote a Work to other cluster namespaces. Its value
+	// is a comma separated list of cluster namespaces. The Work is copied once its workload is
+	// applied on the cluster of its own namespace.
+	PromoteToAnnotation = "multicluster.x-k8s.io/promote-to"
+
+	// PromotedFromAnnotation is set on a promoted Work. Its value is the namespace/name of the
+	// Work it was promoted from.
+	PromotedFromAnnotation = "multicluster.x-k8s.io/promoted-from"
+
+	// PromotedGenerationAnnotation is set on a promoted Work. Its value is the generation of the
+	// Work it was promoted from.
+	PromotedGenerationAnnotation = "multicluster.x-k8s.io/promoted-generation"
+
+	// SkipManifestsAnnotation temporarily excludes manifests of a Work from reconciliation on
+	// the spoke cluster. The agent neither applies nor fixes the drift of a skipped manifest. Its
+	// value is a comma separated list of manifests in the form kind/name for cluster scoped
+	// resources or kind/namespace/name for namespaced resources, kinds are case insensitive.
+	SkipManifestsAnnotation = "multicluster.x-k8s.io/skip-manifests"
+
+	// OptionalManifestsAnnotation lists the manifests of a Work which are best effort, e.g. addons
+	// shipped alongside critical resources. They are applied like the others, but their
+	// unavailability does not make the Work unavailable. Its value is a comma separated list of
+	// manifests in the same form as the skip-manifests annotation.
+	OptionalManifestsAnnotation = "multicluster.x-k8s.io/optional-manifests"
+
+	// AvailabilityPolicyAnnotation sets how the availability of the required manifests of a Work
+	// makes the Work available: All of them, which is the default, Any of them, or a minimum
+	// percentage of them such as 80%.
+	AvailabilityPolicyAnnotation = "multicluster.x-k8s.io/availability-policy"
+
+	// UnmanagedAnnotation is set to "true" by spoke admins on a resource applied by a Work to
+	// take over its management locally. The agent stops updating the resource and reports it
+	// as externally managed in the status of the Work.
+	UnmanagedAnnotation = "work.k8s.io/unmanaged"
+
+	// WorkGenerationAnnotation is set by the agent on the resources it applies. Its value is the
+	// generation of the Work the resource was last applied from. Together with the spec-hash
+	// annotation, the hash of the manifest, it lets spoke auditors correlate a resource with a
+	// change on the hub.
+	WorkGenerationAnnotation = "multicluster.x-k8s.io/work-generation"
+
+	// WorkGroupLabel is set on every Work split from an oversized Work. Its value is the name of
+	// the Work it was split from, the Works of a group share it.
+	WorkGroupLabel = "multicluster.x-k8s.io/work-group"
+
+	// WorkGroupSizeAnnotation is set on every Work split from an oversized Work. Its value is the
+	// number of Works of the group.
+	WorkGroupSizeAnnotation = "multicluster.x-k8s.io/work-group-size"
+
+	// OrphanAnnotation is set to "true" on a Work before deleting it to leave its resources on the
+	// spoke cluster. The agent then releases the resources, deleting the AppliedWork without
+	// deleting them.
+	OrphanAnnotation = "multicluster.x-k8s.io/orphan"
+
+	// ClusterDeletionPolicyAnnotation is set by the hub on the cluster namespaces to the policy of
+	// the Works deleted along with their namespace, Cascade or Orphan. The agent leaves the
+	// resources of a Work of a terminating namespace annotated Orphan on the spoke cluster, like
+	// with the orphan annotation, even if the Work was not annotated before the agent saw it deleted.
+	ClusterDeletionPolicyAnnotation = "multicluster.x-k8s.io/cluster-deletion-policy"
+
+	// SourceClustersAnnotation is set on a Flux source, such as a GitRepository, for the hub to
+	// materialize the manifests of its artifact as Works in cluster namespaces. Its value is a
+	// comma separated list of cluster namespaces.
+	SourceClustersAnnotation = "multicluster.x-k8s.io/source-clusters"
+
+	// SourcePathAnnotation is set on a Flux source to select the directory of its artifact holding
+	// the manifests, the root of the artifact by default.
+	SourcePathAnnotation = "multicluster.x-k8s.io/source-path"
+
+	// SourceLabel is set on the Works materialized from a Flux source. Its value is the name of
+	// the source.
+	SourceLabel = "multicluster.x-k8s.io/source"
+
+	// SourceAnnotation is set on the Works materialized from a Flux source. Its value is the
+	// kind/namespace/name of the source.
+	SourceAnnotation = "multicluster.x-k8s.io/source"
+
+	// SourceRevisionAnnotation is set on the Works materialized from a Flux source. Its value is
+	// the revision of the artifact the manifests were read from.
+	SourceRevisionAnnotation = "multicluster.x-k8s.io/source-revision"
+
+	// AppliedTimeAnnotation is set by the agent on the resources it applies. Its value is the
+	// RFC 3339 time the resource was last created or updated by the agent.
+	AppliedTimeAnnotation = "multicluster.x-k8s.io/applied-time"
+
+	// AppliedGenerationTimeAnnotation is set by the hub on the Works with TTLSecondsAfterApplied.
+	// Its value is the generation of the Work last applied successfully and the RFC 3339 time the
+	// hub first saw it applied, separated by "@", e.g. 3@2021-10-01T12:00:00Z. The TTL is counted
+	// from that time, it starts again when a new generation is applied.
+	AppliedGenerationTimeAnnotation = "multicluster.x-k8s.io/applied-generation-time"
+
+	// ResyncAnnotation is set on a Work to force the agent to apply all its manifests again and
+	// refresh its status right away, rather than at the next resync interval. Any new value, such
+	// as the current time, triggers one resync, bypassing the backoff of failing manifests. The
+	// agent records the value it handled on the AppliedWork.
+	ResyncAnnotation = "multicluster.x-k8s.io/resync"
+
+	// RestartedAtAnnotation is set on a Work to restart the Deployments, StatefulSets and
+	// DaemonSets it manages, like kubectl rollout restart, without editing their manifests. The
+	// agent stamps its value as the kubectl.kubernetes.io/restartedAt annotation of their pod
+	// templates, so every new value rolls them out once.
+	RestartedAtAnnotation = "multicluster.x-k8s.io/restarted-at"
+
+	// FieldManagerAnnotation sets the field manager the agent applies the resources of a Work as.
+	// By default it is derived from the name of the Work and a hash of the hub, so the fields of
+	// the resources are owned by the Works applying them. It is at most 128 characters long.
+	FieldManagerAnnotation = "multicluster.x-k8s.io/field-manager"
+
+	// TakeOverFieldManagersAnnotation is set on a Work to onboard existing resources, e.g. created
+	// with kubectl apply or Helm. Its value is a comma separated list of field managers, such as
+	// kubectl-client-side-apply,helm, whose fields the agent transfers to its own field manager
+	// the next time it applies the resources.
+	TakeOverFieldManagersAnnotation = "multicluster.x-k8s.io/take-over-field-managers"
+
+	// WorkloadHashAnnotation is set by the agent on the resources it applies. Its value is the
+	// WorkloadHash of the spec of the Work the resource was last applied from.
+	WorkloadHashAnnotation = "multicluster.x-k8s.io/workload-hash"
+)
+
 // WorkSpec defines the desired state of Work
 type WorkSpec struct {
 	// Workload represents the manifest workload to be deployed on spoke cluster
 	Workload WorkloadTemplate `json:"workload,omitempty"`
+
+	// TTLSecondsAfterApplied limits the lifetime of a Work once its workload is applied. The
+	// hub deletes the Work TTLSecondsAfterApplied seconds after its current generation was
+	// applied, if the hub work garbage collector is enabled. The Work is never deleted if unset,
+	// or if it is stamped by a WorkSet.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterApplied *int64 `json:"ttlSecondsAfterApplied,omitempty"`
+
+	// Immutable forbids changes to the workload once the Work is created, a new version of the
+	// workload must be delivered by replacing the Work. It can be set but not unset after
+	// creation. It is enforced by the validating webhook on the hub.
+	// +optional
+	Immutable bool `json:"immutable,omitempty"`
+
+	// DefaultNamespace is the namespace the namespaced manifests without a namespace are applied
+	// to. The resolved namespace is reported in the resource identifiers of the manifest conditions.
+	// When it is unset, the namespaced manifests must set their namespace, they fail to apply
+	// otherwise.
+	// +optional
+	DefaultNamespace string `json:"defaultNamespace,omitempty"`
 }
 
 // WorkloadTemplate represents the manifest workload to be deployed on spoke cluster
@@ -59,6 +200,24 @@ type WorkStatus struct {
 	// spoke cluster.
 	// +optional
 	ManifestConditions []ManifestCondition `json:"manifestConditions,omitempty"`
+
+	// StatusDetailName is the name of the WorkStatusDetail holding the manifest conditions when
+	// they exceed the status size budget of the agent. The manifest conditions of the Work are
+	// empty then.
+	// +optional
+	StatusDetailName string `json:"statusDetailName,omitempty"`
+
+	// ObservedGeneration is the generation of the spec last applied successfully, with all its
+	// manifests applied. The agent does not apply a Work again while its generation is unchanged,
+	// until it resyncs the Work.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// WorkloadHash is the WorkloadHash of the spec last applied successfully, at the observed
+	// generation. It tells whether the resources on the spoke cluster match a given revision of
+	// the workload without comparing the manifests.
+	// +optional
+	WorkloadHash string `json:"workloadHash,omitempty"`
 }
 
 // ResourceIdentifier provides the identifiers needed to interact with any arbitrary object.
@@ -97,11 +256,45 @@ type ManifestCondition struct {
 	// Conditions represents the conditions of this resource on spoke cluster
 	// +required
 	Conditions []metav1.Condition `json:"conditions"`
+
+	// LastAppliedTime is the last time the resource was created or updated on the spoke cluster
+	// from this manifest.
+	// +optional
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+
+	// LastAvailableTime is the last time the resource was observed to exist on the spoke cluster.
+	// +optional
+	LastAvailableTime *metav1.Time `json:"lastAvailableTime,omitempty"`
+
+	// ApplyErrors are the last errors the manifest failed to apply with, oldest first. They are
+	// kept once the manifest applies again, so the failures which healed can still be diagnosed.
+	// +optional
+	ApplyErrors []ApplyError `json:"applyErrors,omitempty"`
+}
+
+// ApplyError is an error a manifest failed to apply with on the spoke cluster.
+type ApplyError struct {
+	// Time is when the manifest failed to apply.
+	// +required
+	Time metav1.Time `json:"time"`
+
+	// Reason is the reason of the error returned by the spoke cluster, e.g. Forbidden or
+	// Conflict, or AppliedManifestFailed if it has none.
+	// +required
+	Reason string `json:"reason"`
+
+	// Message is the message of the error.
+	// +required
+	Message string `json:"message"`
 }
 
 // +genclient
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=wk,categories={fleet}
+// +kubebuilder:printcolumn:name="Applied",type=string,JSONPath=`.status.conditions[?(@.type=="Applied")].status`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Work is the Schema for the works API
 type Work struct {
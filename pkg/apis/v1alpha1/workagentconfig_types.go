@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const WorkAgentConfigKind = "WorkAgentConfig"
+
+// Feature gates of the agent which can be toggled by a WorkAgentConfig.
+const (
+	// FeatureApplyGenerationGating skips applying the Works again while their generation and the
+	// annotations driving their apply are unchanged, until their resync. Enabled by default.
+	FeatureApplyGenerationGating = "ApplyGenerationGating"
+	// FeatureAvailabilityRecheck syncs the Works whose resources are not all available, or whose
+	// Jobs are not complete, again every 30 seconds. Enabled by default.
+	FeatureAvailabilityRecheck = "AvailabilityRecheck"
+)
+
+// WorkAgentConfigSpec is the tuning of the agent, overriding its command line flags.
+type WorkAgentConfigSpec struct {
+	// ResyncInterval is how often every Work is applied and its status synced again without any
+	// change. Works are only synced when they change if zero.
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+
+	// ApplyConcurrency is the number of Works applied concurrently. It is capped by the
+	// apply-concurrency flag the agent started with, which sets its number of workers.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ApplyConcurrency *int32 `json:"applyConcurrency,omitempty"`
+
+	// AllowedGroupKinds are the kinds of the resources the agent applies. The manifests of the
+	// other kinds fail to apply. All kinds are allowed if empty.
+	// +optional
+	AllowedGroupKinds []metav1.GroupKind `json:"allowedGroupKinds,omitempty"`
+
+	// FeatureGates enables or disables features of the agent by their name, e.g.
+	// ApplyGenerationGating or AvailabilityRecheck. The features not listed keep their default.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// WorkAgentConfigStatus is the status of the configuration as applied by the agent.
+type WorkAgentConfigStatus struct {
+	// ObservedGeneration is the generation of the spec last applied by the agent.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions contains the Accepted condition, false if the spec could not be applied whole,
+	// e.g. with an unknown feature gate.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={fleet}
+// +kubebuilder:object:root=true
+
+// WorkAgentConfig tunes the agent of a spoke cluster at runtime. It is created on the spoke
+// cluster, under the name the agent is started with, and applied by the agent as soon as it
+// changes, without restarting it.
+type WorkAgentConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the tuning of the agent.
+	// +optional
+	Spec WorkAgentConfigSpec `json:"spec,omitempty"`
+
+	// Status is the status of the configuration as applied by the agent.
+	// +optional
+	Status WorkAgentConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WorkAgentConfigList contains a list of WorkAgentConfig
+type WorkAgentConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of work agent configs.
+	// +listType=set
+	Items []WorkAgentConfig `json:"items"`
+}
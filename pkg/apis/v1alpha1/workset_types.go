@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const WorkSetKind = "WorkSet"
+
+// WorkSetLabel is set on every Work stamped from a WorkSet. Its value is the name of the WorkSet.
+const WorkSetLabel = "multicluster.x-k8s.io/workset"
+
+// WorkSetSpec defines the desired state of WorkSet
+type WorkSetSpec struct {
+	// Template is the Work stamped into every selected cluster namespace on the hub.
+	// References of the form ${name} in the manifests, labels and annotations of the
+	// template are substituted with the values of the cluster. The clusterName value is
+	// always set to the name of the cluster namespace. References to undefined values
+	// are left as is.
+	// +kubebuilder:validation:Required
+	// +required
+	Template WorkTemplateSpec `json:"template"`
+
+	// Values are the values substituted in the template for every cluster.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+
+	// ClusterValues override Values for individual clusters.
+	// +optional
+	ClusterValues []ClusterValues `json:"clusterValues,omitempty"`
+
+	// ClusterSelector selects the cluster namespaces on the hub that the Work is stamped into.
+	// A nil selector selects no cluster namespace. It is ignored when PlacementRef is set.
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// PlacementRef refers to a Placement whose PlacementDecisions determine the target
+	// clusters. The name of each decided cluster is used as its cluster namespace. The WorkSet is
+	// not synced unless the hub controller runs with placements enabled.
+	// +optional
+	PlacementRef *PlacementReference `json:"placementRef,omitempty"`
+
+	// RolloutStrategy controls how a change of the template is rolled out across the
+	// selected clusters. All clusters are updated at once when it is not set.
+	// +optional
+	RolloutStrategy *RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// ConditionSummaries defines how conditions of the stamped Works are summarized into a
+	// condition of the same type on the WorkSet. The Applied condition is summarized with
+	// the All policy when it is empty. The Synced and RolloutProgressing conditions of the
+	// WorkSet cannot be summarized, the WorkSet is not synced if they are.
+	// +optional
+	ConditionSummaries []ConditionSummaryPolicy `json:"conditionSummaries,omitempty"`
+}
+
+// SummaryPolicyType is the way a condition is summarized across clusters
+// +kubebuilder:validation:Enum=All;Any;Percentage
+type SummaryPolicyType string
+
+const (
+	// SummaryPolicyAll requires the condition to be true on all selected clusters.
+	SummaryPolicyAll SummaryPolicyType = "All"
+	// SummaryPolicyAny requires the condition to be true on at least one selected cluster.
+	SummaryPolicyAny SummaryPolicyType = "Any"
+	// SummaryPolicyPercentage requires the condition to be true on a percentage of the selected clusters.
+	SummaryPolicyPercentage SummaryPolicyType = "Percentage"
+)
+
+// ConditionSummaryPolicy describes how a condition of the stamped Works is summarized
+type ConditionSummaryPolicy struct {
+	// Type is the type of the Work condition to summarize.
+	// +kubebuilder:validation:Required
+	// +required
+	Type string `json:"type"`
+
+	// Policy is the way the condition is summarized across the selected clusters.
+	// +kubebuilder:validation:Required
+	// +required
+	Policy SummaryPolicyType `json:"policy"`
+
+	// Percentage is the percentage of selected clusters the condition must be true on when
+	// the policy is Percentage.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percentage int32 `json:"percentage,omitempty"`
+}
+
+// RolloutStrategy describes how a template change is rolled out progressively across clusters
+type RolloutStrategy struct {
+	// MaxConcurrentClusters is the maximum number of clusters whose Work is being rolled out
+	// at the same time. Zero means no limit.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentClusters int32 `json:"maxConcurrentClusters,omitempty"`
+
+	// SoakTime is the minimum time a cluster stays Progressing after its Work is updated,
+	// even if the Work is applied earlier, before the rollout moves on.
+	// +optional
+	SoakTime *metav1.Duration `json:"soakTime,omitempty"`
+
+	// FailureThreshold is the number of Failed clusters that halts the rollout. No further
+	// cluster is updated while the rollout is halted. Zero means the rollout never halts.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+// ClusterValues are the values substituted in the template for a cluster
+type ClusterValues struct {
+	// ClusterName is the name of the cluster namespace.
+	// +kubebuilder:validation:Required
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// Values are merged on top of the values of the WorkSet for the cluster.
+	// +optional
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// PlacementReference identifies a Placement on the hub
+type PlacementReference struct {
+	// Name is the name of the Placement.
+	// +kubebuilder:validation:Required
+	// +required
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Placement.
+	// +kubebuilder:validation:Required
+	// +required
+	Namespace string `json:"namespace"`
+}
+
+// WorkTemplateSpec describes the Work stamped into each selected cluster namespace
+type WorkTemplateSpec struct {
+	// Labels are added to every Work stamped from the template.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to every Work stamped from the template.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Spec is the spec of every Work stamped from the template.
+	// +optional
+	Spec WorkSpec `json:"spec,omitempty"`
+}
+
+// WorkSetStatus defines the observed state of WorkSet
+type WorkSetStatus struct {
+	// Conditions contains the different condition statuses for this workset.
+	// Valid condition types are:
+	// 1. Synced represents the Works in all selected cluster namespaces are synced without error.
+	// 2. RolloutProgressing represents the template is being rolled out to the selected clusters.
+	// 3. A condition of the type of every summarized Work condition.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Clusters is the list of cluster namespaces the Work is currently stamped into.
+	// +optional
+	Clusters []string `json:"clusters,omitempty"`
+
+	// ClusterRollouts records the rollout phase of the current template on each selected cluster.
+	// +optional
+	ClusterRollouts []ClusterRolloutStatus `json:"clusterRollouts,omitempty"`
+
+	// ConditionCounts counts the status of the Applied, Available and Degraded conditions,
+	// and of every summarized condition, of the Works stamped into the selected clusters.
+	// +optional
+	ConditionCounts []ConditionCount `json:"conditionCounts,omitempty"`
+}
+
+// ConditionCount counts the status of a Work condition across the selected clusters
+type ConditionCount struct {
+	// Type is the type of the Work condition.
+	// +required
+	Type string `json:"type"`
+
+	// True is the number of clusters on which the condition is true.
+	True int32 `json:"true"`
+
+	// False is the number of clusters on which the condition is false.
+	False int32 `json:"false"`
+
+	// Unknown is the number of clusters on which the condition is unknown, missing or not
+	// observed for the current generation of the Work.
+	Unknown int32 `json:"unknown"`
+}
+
+// RolloutPhase is the rollout phase of the template on a cluster
+type RolloutPhase string
+
+const (
+	// RolloutPhasePending means the Work of the cluster is not updated to the current template yet.
+	RolloutPhasePending RolloutPhase = "Pending"
+	// RolloutPhaseProgressing means the Work of the cluster is updated and waits to be applied and soaked.
+	RolloutPhaseProgressing RolloutPhase = "Progressing"
+	// RolloutPhaseSucceeded means the current template is applied on the cluster.
+	RolloutPhaseSucceeded RolloutPhase = "Succeeded"
+	// RolloutPhaseFailed means the current template failed to apply on the cluster.
+	RolloutPhaseFailed RolloutPhase = "Failed"
+)
+
+// ClusterRolloutStatus represents the rollout phase of the template on a cluster
+type ClusterRolloutStatus struct {
+	// ClusterName is the name of the cluster namespace.
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// Phase is the rollout phase of the current template on the cluster.
+	// +required
+	Phase RolloutPhase `json:"phase"`
+
+	// LastTransitionTime is the last time the phase changed.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// WorkSet fans a Work out to many clusters. The WorkSet controller on the hub
+// stamps a Work named after the WorkSet into every selected cluster namespace,
+// keeps them in sync with the template and deletes them once a cluster
+// namespace is no longer selected.
+type WorkSet struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec represents the desired configuration of WorkSet.
+	// +kubebuilder:validation:Required
+	// +required
+	Spec WorkSetSpec `json:"spec"`
+
+	// Status represents the current status of WorkSet.
+	// +optional
+	Status WorkSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WorkSetList contains a list of WorkSet
+type WorkSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of worksets.
+	// +listType=set
+	Items []WorkSet `json:"items"`
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const WorkStatusDetailKind = "WorkStatusDetail"
+
+// +genclient
+// +kubebuilder:object:root=true
+
+// WorkStatusDetail holds the manifest conditions of a Work when they exceed the status size
+// budget of the agent, keeping the Work small enough to be listed. It is named after the Work,
+// in the namespace of the Work, and owned by it.
+type WorkStatusDetail struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// ManifestConditions represents the conditions of each resource in work deployed on
+	// spoke cluster.
+	// +optional
+	ManifestConditions []ManifestCondition `json:"manifestConditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WorkStatusDetailList contains a list of WorkStatusDetail
+type WorkStatusDetailList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	// List of work status details.
+	// +listType=set
+	Items []WorkStatusDetail `json:"items"`
+}
@@ -61,7 +61,13 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&AppliedWork{},
 		&AppliedWorkList{},
 		&Work{},
+		&WorkAgentConfig{},
+		&WorkAgentConfigList{},
 		&WorkList{},
+		&WorkSet{},
+		&WorkSetList{},
+		&WorkStatusDetail{},
+		&WorkStatusDetailList{},
 	)
 	// AddToGroupVersion allows the serialization of client types like ListOptions.
 	v1.AddToGroupVersion(scheme, SchemeGroupVersion)
@@ -0,0 +1,294 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder builds Works from Kubernetes objects, so the consumers of the work API do not
+// have to marshal the manifests themselves.
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/hubcontrollers"
+)
+
+// Builder builds a Work, or a group of Works, from Kubernetes objects. The errors met while adding
+// objects are returned by Build and BuildGroup.
+type Builder struct {
+	name        string
+	namespace   string
+	labels      map[string]string
+	annotations map[string]string
+	scheme      *runtime.Scheme
+	maxSize     int
+	objects     []*unstructured.Unstructured
+	errs        []error
+}
+
+// New returns a Builder of the Work with the given name in the namespace of a cluster.
+func New(namespace, name string) *Builder {
+	return &Builder{
+		name:      name,
+		namespace: namespace,
+		scheme:    scheme.Scheme,
+		maxSize:   hubcontrollers.DefaultMaxWorkSize,
+	}
+}
+
+// WithLabels sets the labels of the Work.
+func (b *Builder) WithLabels(labels map[string]string) *Builder {
+	b.labels = labels
+	return b
+}
+
+// WithAnnotations sets the annotations of the Work.
+func (b *Builder) WithAnnotations(annotations map[string]string) *Builder {
+	b.annotations = annotations
+	return b
+}
+
+// WithScheme sets the scheme resolving the kinds of the typed objects, the client-go scheme by
+// default.
+func (b *Builder) WithScheme(scheme *runtime.Scheme) *Builder {
+	b.scheme = scheme
+	return b
+}
+
+// WithMaxSize sets the size in bytes of the manifests above which the Work is split by
+// BuildGroup, hubcontrollers.DefaultMaxWorkSize by default.
+func (b *Builder) WithMaxSize(maxSize int) *Builder {
+	b.maxSize = maxSize
+	return b
+}
+
+// WithObjects adds typed objects to the Work. The kind of an object is taken from the scheme if it
+// is not set on the object.
+func (b *Builder) WithObjects(objects ...runtime.Object) *Builder {
+	for _, object := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			b.errs = append(b.errs, err)
+			continue
+		}
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.GetKind() == "" {
+			kinds, _, err := b.scheme.ObjectKinds(object)
+			if err != nil {
+				b.errs = append(b.errs, err)
+				continue
+			}
+			obj.SetGroupVersionKind(kinds[0])
+		}
+		b.objects = append(b.objects, obj)
+	}
+	return b
+}
+
+// WithUnstructured adds unstructured objects to the Work.
+func (b *Builder) WithUnstructured(objects ...*unstructured.Unstructured) *Builder {
+	for _, object := range objects {
+		b.objects = append(b.objects, object.DeepCopy())
+	}
+	return b
+}
+
+// WithDirectory adds the objects of the YAML and JSON files of a directory to the Work, in the
+// order of the file names. A file may hold several YAML documents, sub directories are ignored.
+func (b *Builder) WithDirectory(dir string) *Builder {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	for _, entry := range entries {
+		switch filepath.Ext(entry.Name()) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+		b.WithFile(filepath.Join(dir, entry.Name()))
+	}
+	return b
+}
+
+// WithFile adds the objects of a YAML or JSON file to the Work. The file may hold several YAML
+// documents.
+func (b *Builder) WithFile(path string) *Builder {
+	file, err := os.Open(path)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	defer file.Close()
+	return b.withReader(path, file)
+}
+
+// WithPaths adds the objects of files and directories to the Work, see WithFile and WithDirectory.
+func (b *Builder) WithPaths(paths ...string) *Builder {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			b.errs = append(b.errs, err)
+		case info.IsDir():
+			b.WithDirectory(path)
+		default:
+			b.WithFile(path)
+		}
+	}
+	return b
+}
+
+// WithReader adds the objects of a YAML or JSON stream to the Work, such as the output of
+// kustomize build or helm template.
+func (b *Builder) WithReader(reader io.Reader) *Builder {
+	return b.withReader("stream", reader)
+}
+
+func (b *Builder) withReader(source string, reader io.Reader) *Builder {
+	objects, err := decodeObjects(reader)
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("failed to decode %s: %w", source, err))
+		return b
+	}
+	b.objects = append(b.objects, objects...)
+	return b
+}
+
+// Build returns the Work holding every object added to the builder. It fails if the objects are
+// invalid or their manifests are larger than the max size.
+func (b *Builder) Build() (*workv1alpha1.Work, error) {
+	work, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	size := 0
+	for _, manifest := range work.Spec.Workload.Manifests {
+		size += len(manifest.Raw)
+	}
+	if size > b.maxSize {
+		return nil, fmt.Errorf("manifests of work %s/%s are %d bytes, larger than %d", b.namespace, b.name, size, b.maxSize)
+	}
+	return work, nil
+}
+
+// BuildGroup returns the Works holding every object added to the builder. The Work is split into
+// a group of linked Works if its manifests are larger than the max size, see
+// hubcontrollers.SplitWork.
+func (b *Builder) BuildGroup() ([]*workv1alpha1.Work, error) {
+	work, err := b.build()
+	if err != nil {
+		return nil, err
+	}
+	return hubcontrollers.SplitWork(work, b.maxSize)
+}
+
+// build validates the objects and returns the Work holding them.
+func (b *Builder) build() (*workv1alpha1.Work, error) {
+	errs := append([]error{}, b.errs...)
+	if len(b.objects) == 0 {
+		errs = append(errs, fmt.Errorf("work %s/%s has no objects", b.namespace, b.name))
+	}
+
+	manifests := []workv1alpha1.Manifest{}
+	seen := map[string]int{}
+	for index, object := range b.objects {
+		if err := validateObject(object); err != nil {
+			errs = append(errs, fmt.Errorf("object %d: %w", index, err))
+			continue
+		}
+		// the objects using generateName get distinct names from the server
+		if object.GetName() != "" {
+			key := fmt.Sprintf("%s/%s/%s", object.GroupVersionKind().GroupKind(), object.GetNamespace(), object.GetName())
+			if previous, ok := seen[key]; ok {
+				errs = append(errs, fmt.Errorf("object %d: duplicates object %d", index, previous))
+				continue
+			}
+			seen[key] = index
+		}
+
+		raw, err := json.Marshal(object)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("object %d: %w", index, err))
+			continue
+		}
+		manifests = append(manifests, workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+	}
+	if len(errs) > 0 {
+		return nil, utilerrors.NewAggregate(errs)
+	}
+
+	return &workv1alpha1.Work{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: workv1alpha1.GroupVersion.String(),
+			Kind:       workv1alpha1.WorkKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        b.name,
+			Namespace:   b.namespace,
+			Labels:      b.labels,
+			Annotations: b.annotations,
+		},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: manifests}},
+	}, nil
+}
+
+// validateObject checks that an object can be applied by the agent.
+func validateObject(object *unstructured.Unstructured) error {
+	switch {
+	case object.GetAPIVersion() == "":
+		return fmt.Errorf("apiVersion is not set")
+	case object.GetKind() == "":
+		return fmt.Errorf("kind is not set")
+	case object.GetName() == "" && object.GetGenerateName() == "":
+		return fmt.Errorf("%s has no name nor generateName", object.GetKind())
+	case object.IsList():
+		return fmt.Errorf("%s %s is a list", object.GetKind(), object.GetName())
+	}
+	return nil
+}
+
+// decodeObjects decodes the objects of a YAML or JSON document stream, empty documents are skipped.
+func decodeObjects(reader io.Reader) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(reader, 4096)
+	for {
+		object := &unstructured.Unstructured{}
+		err := decoder.Decode(&object.Object)
+		switch {
+		case err == io.EOF:
+			return objects, nil
+		case err != nil:
+			return nil, err
+		}
+		if len(object.Object) > 0 {
+			objects = append(objects, object)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+const testManifests = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: test
+---
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: test
+data:
+  key: value
+`
+
+func decodeManifest(t *testing.T, manifest workv1alpha1.Manifest) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(manifest.Raw, &obj.Object); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "manifests.yaml"), []byte(testManifests), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a manifest"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetName("secret")
+	secret.SetNamespace("test")
+
+	work, err := New("cluster1", "work").
+		WithLabels(map[string]string{"app": "test"}).
+		WithDirectory(dir).
+		WithObjects(&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa", Namespace: "test"}}).
+		WithUnstructured(secret).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.Namespace != "cluster1" || work.Name != "work" || work.Labels["app"] != "test" {
+		t.Errorf("unexpected work metadata %v", work.ObjectMeta)
+	}
+	manifests := work.Spec.Workload.Manifests
+	if len(manifests) != 4 {
+		t.Fatalf("expected 4 manifests, got %d", len(manifests))
+	}
+	for i, kind := range []string{"Namespace", "ConfigMap", "ServiceAccount", "Secret"} {
+		if obj := decodeManifest(t, manifests[i]); obj.GetKind() != kind {
+			t.Errorf("expected manifest %d to be a %s, got %s", i, kind, obj.GetKind())
+		}
+	}
+	if obj := decodeManifest(t, manifests[2]); obj.GetAPIVersion() != "v1" {
+		t.Errorf("expected the kind of the typed object to be resolved, got %q", obj.GetAPIVersion())
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "test"}}
+	cases := map[string]*Builder{
+		"no objects": New("cluster1", "work"),
+		"no name":    New("cluster1", "work").WithObjects(&corev1.ConfigMap{}),
+		"duplicate":  New("cluster1", "work").WithObjects(configMap, configMap),
+		"too large":  New("cluster1", "work").WithObjects(configMap).WithMaxSize(10),
+		"no dir":     New("cluster1", "work").WithObjects(configMap).WithDirectory(filepath.Join(t.TempDir(), "missing")),
+	}
+	for name, builder := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := builder.Build(); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestBuildGenerateName(t *testing.T) {
+	job := &unstructured.Unstructured{}
+	job.SetAPIVersion("batch/v1")
+	job.SetKind("Job")
+	job.SetGenerateName("migrate-")
+	job.SetNamespace("test")
+
+	work, err := New("cluster1", "work").WithUnstructured(job, job.DeepCopy()).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(work.Spec.Workload.Manifests) != 2 {
+		t.Errorf("expected the objects using generateName not to be duplicates, got %d manifests", len(work.Spec.Workload.Manifests))
+	}
+}
+
+func TestBuildGroup(t *testing.T) {
+	builder := New("cluster1", "work")
+	for _, name := range []string{"a", "b", "c"} {
+		builder.WithObjects(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}})
+	}
+	work, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	works, err := builder.WithMaxSize(len(work.Spec.Workload.Manifests[0].Raw) * 2).BuildGroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 2 {
+		t.Fatalf("expected 2 works, got %d", len(works))
+	}
+	for _, work := range works {
+		if work.Labels[workv1alpha1.WorkGroupLabel] != "work" {
+			t.Errorf("expected work %s to be in the work group, got labels %v", work.Name, work.Labels)
+		}
+	}
+}
+
+func TestBuildFromPathsAndReader(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "manifests.yaml")
+	if err := os.WriteFile(file, []byte(testManifests), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stream := `{"apiVersion": "v1", "kind": "Secret", "metadata": {"name": "secret", "namespace": "test"}}`
+
+	work, err := New("cluster1", "work").WithPaths(file).WithReader(strings.NewReader(stream)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(work.Spec.Workload.Manifests) != 3 {
+		t.Errorf("expected 3 manifests, got %d", len(work.Spec.Workload.Manifests))
+	}
+
+	if _, err := New("cluster1", "work").WithPaths(filepath.Join(dir, "missing.yaml")).Build(); err == nil {
+		t.Error("expected a missing path to fail the build")
+	}
+}
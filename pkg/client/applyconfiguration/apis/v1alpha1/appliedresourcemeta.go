@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	types "k8s.io/apimachinery/pkg/types"
+)
+
+// AppliedResourceMetaApplyConfiguration represents an declarative configuration of the AppliedResourceMeta type for use
+// with apply.
+type AppliedResourceMetaApplyConfiguration struct {
+	ResourceIdentifierApplyConfiguration `json:",inline"`
+	UID                                  *types.UID `json:"uid,omitempty"`
+}
+
+// AppliedResourceMetaApplyConfiguration constructs an declarative configuration of the AppliedResourceMeta type for use with
+// apply.
+func AppliedResourceMeta() *AppliedResourceMetaApplyConfiguration {
+	return &AppliedResourceMetaApplyConfiguration{}
+}
+
+// WithOrdinal sets the Ordinal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ordinal field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithOrdinal(value int) *AppliedResourceMetaApplyConfiguration {
+	b.Ordinal = &value
+	return b
+}
+
+// WithGroup sets the Group field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Group field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithGroup(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Group = &value
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithVersion(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithKind(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
+// WithResource sets the Resource field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resource field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithResource(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Resource = &value
+	return b
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithNamespace(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithName(value string) *AppliedResourceMetaApplyConfiguration {
+	b.Name = &value
+	return b
+}
+
+// WithUID sets the UID field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the UID field is set to the value of the last call.
+func (b *AppliedResourceMetaApplyConfiguration) WithUID(value types.UID) *AppliedResourceMetaApplyConfiguration {
+	b.UID = &value
+	return b
+}
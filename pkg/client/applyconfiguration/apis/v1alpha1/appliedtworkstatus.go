@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// AppliedtWorkStatusApplyConfiguration represents an declarative configuration of the AppliedtWorkStatus type for use
+// with apply.
+type AppliedtWorkStatusApplyConfiguration struct {
+	AppliedResources []AppliedResourceMetaApplyConfiguration `json:"appliedResources,omitempty"`
+}
+
+// AppliedtWorkStatusApplyConfiguration constructs an declarative configuration of the AppliedtWorkStatus type for use with
+// apply.
+func AppliedtWorkStatus() *AppliedtWorkStatusApplyConfiguration {
+	return &AppliedtWorkStatusApplyConfiguration{}
+}
+
+// WithAppliedResources adds the given value to the AppliedResources field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AppliedResources field.
+func (b *AppliedtWorkStatusApplyConfiguration) WithAppliedResources(values ...*AppliedResourceMetaApplyConfiguration) *AppliedtWorkStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithAppliedResources")
+		}
+		b.AppliedResources = append(b.AppliedResources, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// AppliedWorkSpecApplyConfiguration represents an declarative configuration of the AppliedWorkSpec type for use
+// with apply.
+type AppliedWorkSpecApplyConfiguration struct {
+	WorkName      *string `json:"workName,omitempty"`
+	WorkNamespace *string `json:"workNamespace,omitempty"`
+}
+
+// AppliedWorkSpecApplyConfiguration constructs an declarative configuration of the AppliedWorkSpec type for use with
+// apply.
+func AppliedWorkSpec() *AppliedWorkSpecApplyConfiguration {
+	return &AppliedWorkSpecApplyConfiguration{}
+}
+
+// WithWorkName sets the WorkName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkName field is set to the value of the last call.
+func (b *AppliedWorkSpecApplyConfiguration) WithWorkName(value string) *AppliedWorkSpecApplyConfiguration {
+	b.WorkName = &value
+	return b
+}
+
+// WithWorkNamespace sets the WorkNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkNamespace field is set to the value of the last call.
+func (b *AppliedWorkSpecApplyConfiguration) WithWorkNamespace(value string) *AppliedWorkSpecApplyConfiguration {
+	b.WorkNamespace = &value
+	return b
+}
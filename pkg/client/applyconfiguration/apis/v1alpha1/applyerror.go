@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyErrorApplyConfiguration represents an declarative configuration of the ApplyError type for use
+// with apply.
+type ApplyErrorApplyConfiguration struct {
+	Time    *v1.Time `json:"time,omitempty"`
+	Reason  *string  `json:"reason,omitempty"`
+	Message *string  `json:"message,omitempty"`
+}
+
+// ApplyErrorApplyConfiguration constructs an declarative configuration of the ApplyError type for use with
+// apply.
+func ApplyError() *ApplyErrorApplyConfiguration {
+	return &ApplyErrorApplyConfiguration{}
+}
+
+// WithTime sets the Time field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Time field is set to the value of the last call.
+func (b *ApplyErrorApplyConfiguration) WithTime(value v1.Time) *ApplyErrorApplyConfiguration {
+	b.Time = &value
+	return b
+}
+
+// WithReason sets the Reason field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Reason field is set to the value of the last call.
+func (b *ApplyErrorApplyConfiguration) WithReason(value string) *ApplyErrorApplyConfiguration {
+	b.Reason = &value
+	return b
+}
+
+// WithMessage sets the Message field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Message field is set to the value of the last call.
+func (b *ApplyErrorApplyConfiguration) WithMessage(value string) *ApplyErrorApplyConfiguration {
+	b.Message = &value
+	return b
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// ClusterRolloutStatusApplyConfiguration represents an declarative configuration of the ClusterRolloutStatus type for use
+// with apply.
+type ClusterRolloutStatusApplyConfiguration struct {
+	ClusterName        *string                `json:"clusterName,omitempty"`
+	Phase              *v1alpha1.RolloutPhase `json:"phase,omitempty"`
+	LastTransitionTime *v1.Time               `json:"lastTransitionTime,omitempty"`
+}
+
+// ClusterRolloutStatusApplyConfiguration constructs an declarative configuration of the ClusterRolloutStatus type for use with
+// apply.
+func ClusterRolloutStatus() *ClusterRolloutStatusApplyConfiguration {
+	return &ClusterRolloutStatusApplyConfiguration{}
+}
+
+// WithClusterName sets the ClusterName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClusterName field is set to the value of the last call.
+func (b *ClusterRolloutStatusApplyConfiguration) WithClusterName(value string) *ClusterRolloutStatusApplyConfiguration {
+	b.ClusterName = &value
+	return b
+}
+
+// WithPhase sets the Phase field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Phase field is set to the value of the last call.
+func (b *ClusterRolloutStatusApplyConfiguration) WithPhase(value v1alpha1.RolloutPhase) *ClusterRolloutStatusApplyConfiguration {
+	b.Phase = &value
+	return b
+}
+
+// WithLastTransitionTime sets the LastTransitionTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastTransitionTime field is set to the value of the last call.
+func (b *ClusterRolloutStatusApplyConfiguration) WithLastTransitionTime(value v1.Time) *ClusterRolloutStatusApplyConfiguration {
+	b.LastTransitionTime = &value
+	return b
+}
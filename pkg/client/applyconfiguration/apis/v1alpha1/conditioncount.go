@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ConditionCountApplyConfiguration represents an declarative configuration of the ConditionCount type for use
+// with apply.
+type ConditionCountApplyConfiguration struct {
+	Type    *string `json:"type,omitempty"`
+	True    *int32  `json:"true,omitempty"`
+	False   *int32  `json:"false,omitempty"`
+	Unknown *int32  `json:"unknown,omitempty"`
+}
+
+// ConditionCountApplyConfiguration constructs an declarative configuration of the ConditionCount type for use with
+// apply.
+func ConditionCount() *ConditionCountApplyConfiguration {
+	return &ConditionCountApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *ConditionCountApplyConfiguration) WithType(value string) *ConditionCountApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithTrue sets the True field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the True field is set to the value of the last call.
+func (b *ConditionCountApplyConfiguration) WithTrue(value int32) *ConditionCountApplyConfiguration {
+	b.True = &value
+	return b
+}
+
+// WithFalse sets the False field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the False field is set to the value of the last call.
+func (b *ConditionCountApplyConfiguration) WithFalse(value int32) *ConditionCountApplyConfiguration {
+	b.False = &value
+	return b
+}
+
+// WithUnknown sets the Unknown field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Unknown field is set to the value of the last call.
+func (b *ConditionCountApplyConfiguration) WithUnknown(value int32) *ConditionCountApplyConfiguration {
+	b.Unknown = &value
+	return b
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// ConditionSummaryPolicyApplyConfiguration represents an declarative configuration of the ConditionSummaryPolicy type for use
+// with apply.
+type ConditionSummaryPolicyApplyConfiguration struct {
+	Type       *string                     `json:"type,omitempty"`
+	Policy     *v1alpha1.SummaryPolicyType `json:"policy,omitempty"`
+	Percentage *int32                      `json:"percentage,omitempty"`
+}
+
+// ConditionSummaryPolicyApplyConfiguration constructs an declarative configuration of the ConditionSummaryPolicy type for use with
+// apply.
+func ConditionSummaryPolicy() *ConditionSummaryPolicyApplyConfiguration {
+	return &ConditionSummaryPolicyApplyConfiguration{}
+}
+
+// WithType sets the Type field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Type field is set to the value of the last call.
+func (b *ConditionSummaryPolicyApplyConfiguration) WithType(value string) *ConditionSummaryPolicyApplyConfiguration {
+	b.Type = &value
+	return b
+}
+
+// WithPolicy sets the Policy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Policy field is set to the value of the last call.
+func (b *ConditionSummaryPolicyApplyConfiguration) WithPolicy(value v1alpha1.SummaryPolicyType) *ConditionSummaryPolicyApplyConfiguration {
+	b.Policy = &value
+	return b
+}
+
+// WithPercentage sets the Percentage field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Percentage field is set to the value of the last call.
+func (b *ConditionSummaryPolicyApplyConfiguration) WithPercentage(value int32) *ConditionSummaryPolicyApplyConfiguration {
+	b.Percentage = &value
+	return b
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ManifestConditionApplyConfiguration represents an declarative configuration of the ManifestCondition type for use
+// with apply.
+type ManifestConditionApplyConfiguration struct {
+	Identifier        *ResourceIdentifierApplyConfiguration `json:"identifier,omitempty"`
+	Conditions        []v1.Condition                        `json:"conditions,omitempty"`
+	LastAppliedTime   *v1.Time                              `json:"lastAppliedTime,omitempty"`
+	LastAvailableTime *v1.Time                              `json:"lastAvailableTime,omitempty"`
+	ApplyErrors       []ApplyErrorApplyConfiguration        `json:"applyErrors,omitempty"`
+}
+
+// ManifestConditionApplyConfiguration constructs an declarative configuration of the ManifestCondition type for use with
+// apply.
+func ManifestCondition() *ManifestConditionApplyConfiguration {
+	return &ManifestConditionApplyConfiguration{}
+}
+
+// WithIdentifier sets the Identifier field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Identifier field is set to the value of the last call.
+func (b *ManifestConditionApplyConfiguration) WithIdentifier(value *ResourceIdentifierApplyConfiguration) *ManifestConditionApplyConfiguration {
+	b.Identifier = value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *ManifestConditionApplyConfiguration) WithConditions(values ...v1.Condition) *ManifestConditionApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
+
+// WithLastAppliedTime sets the LastAppliedTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastAppliedTime field is set to the value of the last call.
+func (b *ManifestConditionApplyConfiguration) WithLastAppliedTime(value v1.Time) *ManifestConditionApplyConfiguration {
+	b.LastAppliedTime = &value
+	return b
+}
+
+// WithLastAvailableTime sets the LastAvailableTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the LastAvailableTime field is set to the value of the last call.
+func (b *ManifestConditionApplyConfiguration) WithLastAvailableTime(value v1.Time) *ManifestConditionApplyConfiguration {
+	b.LastAvailableTime = &value
+	return b
+}
+
+// WithApplyErrors adds the given value to the ApplyErrors field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ApplyErrors field.
+func (b *ManifestConditionApplyConfiguration) WithApplyErrors(values ...*ApplyErrorApplyConfiguration) *ManifestConditionApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithApplyErrors")
+		}
+		b.ApplyErrors = append(b.ApplyErrors, *values[i])
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// ResourceIdentifierApplyConfiguration represents an declarative configuration of the ResourceIdentifier type for use
+// with apply.
+type ResourceIdentifierApplyConfiguration struct {
+	Ordinal   *int    `json:"ordinal,omitempty"`
+	Group     *string `json:"group,omitempty"`
+	Version   *string `json:"version,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Resource  *string `json:"resource,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+	Name      *string `json:"name,omitempty"`
+}
+
+// ResourceIdentifierApplyConfiguration constructs an declarative configuration of the ResourceIdentifier type for use with
+// apply.
+func ResourceIdentifier() *ResourceIdentifierApplyConfiguration {
+	return &ResourceIdentifierApplyConfiguration{}
+}
+
+// WithOrdinal sets the Ordinal field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Ordinal field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithOrdinal(value int) *ResourceIdentifierApplyConfiguration {
+	b.Ordinal = &value
+	return b
+}
+
+// WithGroup sets the Group field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Group field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithGroup(value string) *ResourceIdentifierApplyConfiguration {
+	b.Group = &value
+	return b
+}
+
+// WithVersion sets the Version field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Version field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithVersion(value string) *ResourceIdentifierApplyConfiguration {
+	b.Version = &value
+	return b
+}
+
+// WithKind sets the Kind field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Kind field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithKind(value string) *ResourceIdentifierApplyConfiguration {
+	b.Kind = &value
+	return b
+}
+
+// WithResource sets the Resource field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Resource field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithResource(value string) *ResourceIdentifierApplyConfiguration {
+	b.Resource = &value
+	return b
+}
+
+// WithNamespace sets the Namespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Namespace field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithNamespace(value string) *ResourceIdentifierApplyConfiguration {
+	b.Namespace = &value
+	return b
+}
+
+// WithName sets the Name field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Name field is set to the value of the last call.
+func (b *ResourceIdentifierApplyConfiguration) WithName(value string) *ResourceIdentifierApplyConfiguration {
+	b.Name = &value
+	return b
+}
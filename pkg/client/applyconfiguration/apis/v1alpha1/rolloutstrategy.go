@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RolloutStrategyApplyConfiguration represents an declarative configuration of the RolloutStrategy type for use
+// with apply.
+type RolloutStrategyApplyConfiguration struct {
+	MaxConcurrentClusters *int32       `json:"maxConcurrentClusters,omitempty"`
+	SoakTime              *v1.Duration `json:"soakTime,omitempty"`
+	FailureThreshold      *int32       `json:"failureThreshold,omitempty"`
+}
+
+// RolloutStrategyApplyConfiguration constructs an declarative configuration of the RolloutStrategy type for use with
+// apply.
+func RolloutStrategy() *RolloutStrategyApplyConfiguration {
+	return &RolloutStrategyApplyConfiguration{}
+}
+
+// WithMaxConcurrentClusters sets the MaxConcurrentClusters field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxConcurrentClusters field is set to the value of the last call.
+func (b *RolloutStrategyApplyConfiguration) WithMaxConcurrentClusters(value int32) *RolloutStrategyApplyConfiguration {
+	b.MaxConcurrentClusters = &value
+	return b
+}
+
+// WithSoakTime sets the SoakTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SoakTime field is set to the value of the last call.
+func (b *RolloutStrategyApplyConfiguration) WithSoakTime(value v1.Duration) *RolloutStrategyApplyConfiguration {
+	b.SoakTime = &value
+	return b
+}
+
+// WithFailureThreshold sets the FailureThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailureThreshold field is set to the value of the last call.
+func (b *RolloutStrategyApplyConfiguration) WithFailureThreshold(value int32) *RolloutStrategyApplyConfiguration {
+	b.FailureThreshold = &value
+	return b
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkAgentConfigSpecApplyConfiguration represents an declarative configuration of the WorkAgentConfigSpec type for use
+// with apply.
+type WorkAgentConfigSpecApplyConfiguration struct {
+	ResyncInterval    *v1.Duration    `json:"resyncInterval,omitempty"`
+	ApplyConcurrency  *int32          `json:"applyConcurrency,omitempty"`
+	AllowedGroupKinds []v1.GroupKind  `json:"allowedGroupKinds,omitempty"`
+	FeatureGates      map[string]bool `json:"featureGates,omitempty"`
+}
+
+// WorkAgentConfigSpecApplyConfiguration constructs an declarative configuration of the WorkAgentConfigSpec type for use with
+// apply.
+func WorkAgentConfigSpec() *WorkAgentConfigSpecApplyConfiguration {
+	return &WorkAgentConfigSpecApplyConfiguration{}
+}
+
+// WithResyncInterval sets the ResyncInterval field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ResyncInterval field is set to the value of the last call.
+func (b *WorkAgentConfigSpecApplyConfiguration) WithResyncInterval(value v1.Duration) *WorkAgentConfigSpecApplyConfiguration {
+	b.ResyncInterval = &value
+	return b
+}
+
+// WithApplyConcurrency sets the ApplyConcurrency field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ApplyConcurrency field is set to the value of the last call.
+func (b *WorkAgentConfigSpecApplyConfiguration) WithApplyConcurrency(value int32) *WorkAgentConfigSpecApplyConfiguration {
+	b.ApplyConcurrency = &value
+	return b
+}
+
+// WithAllowedGroupKinds adds the given value to the AllowedGroupKinds field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the AllowedGroupKinds field.
+func (b *WorkAgentConfigSpecApplyConfiguration) WithAllowedGroupKinds(values ...v1.GroupKind) *WorkAgentConfigSpecApplyConfiguration {
+	for i := range values {
+		b.AllowedGroupKinds = append(b.AllowedGroupKinds, values[i])
+	}
+	return b
+}
+
+// WithFeatureGates puts the entries into the FeatureGates field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the FeatureGates field,
+// overwriting an existing map entries in FeatureGates field with the same key.
+func (b *WorkAgentConfigSpecApplyConfiguration) WithFeatureGates(entries map[string]bool) *WorkAgentConfigSpecApplyConfiguration {
+	if b.FeatureGates == nil && len(entries) > 0 {
+		b.FeatureGates = make(map[string]bool, len(entries))
+	}
+	for k, v := range entries {
+		b.FeatureGates[k] = v
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkSetSpecApplyConfiguration represents an declarative configuration of the WorkSetSpec type for use
+// with apply.
+type WorkSetSpecApplyConfiguration struct {
+	Template           *WorkTemplateSpecApplyConfiguration        `json:"template,omitempty"`
+	Values             map[string]string                          `json:"values,omitempty"`
+	ClusterValues      []ClusterValuesApplyConfiguration          `json:"clusterValues,omitempty"`
+	ClusterSelector    *v1.LabelSelector                          `json:"clusterSelector,omitempty"`
+	PlacementRef       *PlacementReferenceApplyConfiguration      `json:"placementRef,omitempty"`
+	RolloutStrategy    *RolloutStrategyApplyConfiguration         `json:"rolloutStrategy,omitempty"`
+	ConditionSummaries []ConditionSummaryPolicyApplyConfiguration `json:"conditionSummaries,omitempty"`
+}
+
+// WorkSetSpecApplyConfiguration constructs an declarative configuration of the WorkSetSpec type for use with
+// apply.
+func WorkSetSpec() *WorkSetSpecApplyConfiguration {
+	return &WorkSetSpecApplyConfiguration{}
+}
+
+// WithTemplate sets the Template field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Template field is set to the value of the last call.
+func (b *WorkSetSpecApplyConfiguration) WithTemplate(value *WorkTemplateSpecApplyConfiguration) *WorkSetSpecApplyConfiguration {
+	b.Template = value
+	return b
+}
+
+// WithValues puts the entries into the Values field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, the entries provided by each call will be put on the Values field,
+// overwriting an existing map entries in Values field with the same key.
+func (b *WorkSetSpecApplyConfiguration) WithValues(entries map[string]string) *WorkSetSpecApplyConfiguration {
+	if b.Values == nil && len(entries) > 0 {
+		b.Values = make(map[string]string, len(entries))
+	}
+	for k, v := range entries {
+		b.Values[k] = v
+	}
+	return b
+}
+
+// WithClusterValues adds the given value to the ClusterValues field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ClusterValues field.
+func (b *WorkSetSpecApplyConfiguration) WithClusterValues(values ...*ClusterValuesApplyConfiguration) *WorkSetSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithClusterValues")
+		}
+		b.ClusterValues = append(b.ClusterValues, *values[i])
+	}
+	return b
+}
+
+// WithClusterSelector sets the ClusterSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ClusterSelector field is set to the value of the last call.
+func (b *WorkSetSpecApplyConfiguration) WithClusterSelector(value v1.LabelSelector) *WorkSetSpecApplyConfiguration {
+	b.ClusterSelector = &value
+	return b
+}
+
+// WithPlacementRef sets the PlacementRef field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PlacementRef field is set to the value of the last call.
+func (b *WorkSetSpecApplyConfiguration) WithPlacementRef(value *PlacementReferenceApplyConfiguration) *WorkSetSpecApplyConfiguration {
+	b.PlacementRef = value
+	return b
+}
+
+// WithRolloutStrategy sets the RolloutStrategy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the RolloutStrategy field is set to the value of the last call.
+func (b *WorkSetSpecApplyConfiguration) WithRolloutStrategy(value *RolloutStrategyApplyConfiguration) *WorkSetSpecApplyConfiguration {
+	b.RolloutStrategy = value
+	return b
+}
+
+// WithConditionSummaries adds the given value to the ConditionSummaries field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ConditionSummaries field.
+func (b *WorkSetSpecApplyConfiguration) WithConditionSummaries(values ...*ConditionSummaryPolicyApplyConfiguration) *WorkSetSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditionSummaries")
+		}
+		b.ConditionSummaries = append(b.ConditionSummaries, *values[i])
+	}
+	return b
+}
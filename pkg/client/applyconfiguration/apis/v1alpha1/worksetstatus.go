@@ -0,0 +1,84 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkSetStatusApplyConfiguration represents an declarative configuration of the WorkSetStatus type for use
+// with apply.
+type WorkSetStatusApplyConfiguration struct {
+	Conditions      []v1.Condition                           `json:"conditions,omitempty"`
+	Clusters        []string                                 `json:"clusters,omitempty"`
+	ClusterRollouts []ClusterRolloutStatusApplyConfiguration `json:"clusterRollouts,omitempty"`
+	ConditionCounts []ConditionCountApplyConfiguration       `json:"conditionCounts,omitempty"`
+}
+
+// WorkSetStatusApplyConfiguration constructs an declarative configuration of the WorkSetStatus type for use with
+// apply.
+func WorkSetStatus() *WorkSetStatusApplyConfiguration {
+	return &WorkSetStatusApplyConfiguration{}
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *WorkSetStatusApplyConfiguration) WithConditions(values ...v1.Condition) *WorkSetStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
+
+// WithClusters adds the given value to the Clusters field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Clusters field.
+func (b *WorkSetStatusApplyConfiguration) WithClusters(values ...string) *WorkSetStatusApplyConfiguration {
+	for i := range values {
+		b.Clusters = append(b.Clusters, values[i])
+	}
+	return b
+}
+
+// WithClusterRollouts adds the given value to the ClusterRollouts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ClusterRollouts field.
+func (b *WorkSetStatusApplyConfiguration) WithClusterRollouts(values ...*ClusterRolloutStatusApplyConfiguration) *WorkSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithClusterRollouts")
+		}
+		b.ClusterRollouts = append(b.ClusterRollouts, *values[i])
+	}
+	return b
+}
+
+// WithConditionCounts adds the given value to the ConditionCounts field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ConditionCounts field.
+func (b *WorkSetStatusApplyConfiguration) WithConditionCounts(values ...*ConditionCountApplyConfiguration) *WorkSetStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditionCounts")
+		}
+		b.ConditionCounts = append(b.ConditionCounts, *values[i])
+	}
+	return b
+}
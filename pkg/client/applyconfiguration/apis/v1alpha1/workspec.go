@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+// WorkSpecApplyConfiguration represents an declarative configuration of the WorkSpec type for use
+// with apply.
+type WorkSpecApplyConfiguration struct {
+	Workload               *WorkloadTemplateApplyConfiguration `json:"workload,omitempty"`
+	TTLSecondsAfterApplied *int64                              `json:"ttlSecondsAfterApplied,omitempty"`
+	Immutable              *bool                               `json:"immutable,omitempty"`
+	DefaultNamespace       *string                             `json:"defaultNamespace,omitempty"`
+}
+
+// WorkSpecApplyConfiguration constructs an declarative configuration of the WorkSpec type for use with
+// apply.
+func WorkSpec() *WorkSpecApplyConfiguration {
+	return &WorkSpecApplyConfiguration{}
+}
+
+// WithWorkload sets the Workload field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Workload field is set to the value of the last call.
+func (b *WorkSpecApplyConfiguration) WithWorkload(value *WorkloadTemplateApplyConfiguration) *WorkSpecApplyConfiguration {
+	b.Workload = value
+	return b
+}
+
+// WithTTLSecondsAfterApplied sets the TTLSecondsAfterApplied field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TTLSecondsAfterApplied field is set to the value of the last call.
+func (b *WorkSpecApplyConfiguration) WithTTLSecondsAfterApplied(value int64) *WorkSpecApplyConfiguration {
+	b.TTLSecondsAfterApplied = &value
+	return b
+}
+
+// WithImmutable sets the Immutable field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Immutable field is set to the value of the last call.
+func (b *WorkSpecApplyConfiguration) WithImmutable(value bool) *WorkSpecApplyConfiguration {
+	b.Immutable = &value
+	return b
+}
+
+// WithDefaultNamespace sets the DefaultNamespace field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DefaultNamespace field is set to the value of the last call.
+func (b *WorkSpecApplyConfiguration) WithDefaultNamespace(value string) *WorkSpecApplyConfiguration {
+	b.DefaultNamespace = &value
+	return b
+}
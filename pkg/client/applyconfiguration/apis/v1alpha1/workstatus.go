@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkStatusApplyConfiguration represents an declarative configuration of the WorkStatus type for use
+// with apply.
+type WorkStatusApplyConfiguration struct {
+	Conditions         []v1.Condition                        `json:"conditions,omitempty"`
+	ManifestConditions []ManifestConditionApplyConfiguration `json:"manifestConditions,omitempty"`
+	StatusDetailName   *string                               `json:"statusDetailName,omitempty"`
+	ObservedGeneration *int64                                `json:"observedGeneration,omitempty"`
+	WorkloadHash       *string                               `json:"workloadHash,omitempty"`
+}
+
+// WorkStatusApplyConfiguration constructs an declarative configuration of the WorkStatus type for use with
+// apply.
+func WorkStatus() *WorkStatusApplyConfiguration {
+	return &WorkStatusApplyConfiguration{}
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *WorkStatusApplyConfiguration) WithConditions(values ...v1.Condition) *WorkStatusApplyConfiguration {
+	for i := range values {
+		b.Conditions = append(b.Conditions, values[i])
+	}
+	return b
+}
+
+// WithManifestConditions adds the given value to the ManifestConditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ManifestConditions field.
+func (b *WorkStatusApplyConfiguration) WithManifestConditions(values ...*ManifestConditionApplyConfiguration) *WorkStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithManifestConditions")
+		}
+		b.ManifestConditions = append(b.ManifestConditions, *values[i])
+	}
+	return b
+}
+
+// WithStatusDetailName sets the StatusDetailName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the StatusDetailName field is set to the value of the last call.
+func (b *WorkStatusApplyConfiguration) WithStatusDetailName(value string) *WorkStatusApplyConfiguration {
+	b.StatusDetailName = &value
+	return b
+}
+
+// WithObservedGeneration sets the ObservedGeneration field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ObservedGeneration field is set to the value of the last call.
+func (b *WorkStatusApplyConfiguration) WithObservedGeneration(value int64) *WorkStatusApplyConfiguration {
+	b.ObservedGeneration = &value
+	return b
+}
+
+// WithWorkloadHash sets the WorkloadHash field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkloadHash field is set to the value of the last call.
+func (b *WorkStatusApplyConfiguration) WithWorkloadHash(value string) *WorkStatusApplyConfiguration {
+	b.WorkloadHash = &value
+	return b
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by applyconfiguration-gen. DO NOT EDIT.
+
+package applyconfiguration
+
+import (
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+)
+
+// ForKind returns an apply configuration type for the given GroupVersionKind, or nil if no
+// apply configuration type exists for the given GroupVersionKind.
+func ForKind(kind schema.GroupVersionKind) interface{} {
+	switch kind {
+	// Group=multicluster.x-k8s.io, Version=v1alpha1
+	case v1alpha1.SchemeGroupVersion.WithKind("AppliedResourceMeta"):
+		return &apisv1alpha1.AppliedResourceMetaApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("AppliedtWorkStatus"):
+		return &apisv1alpha1.AppliedtWorkStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("AppliedWork"):
+		return &apisv1alpha1.AppliedWorkApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("AppliedWorkSpec"):
+		return &apisv1alpha1.AppliedWorkSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ApplyError"):
+		return &apisv1alpha1.ApplyErrorApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ClusterRolloutStatus"):
+		return &apisv1alpha1.ClusterRolloutStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ClusterValues"):
+		return &apisv1alpha1.ClusterValuesApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ConditionCount"):
+		return &apisv1alpha1.ConditionCountApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ConditionSummaryPolicy"):
+		return &apisv1alpha1.ConditionSummaryPolicyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Manifest"):
+		return &apisv1alpha1.ManifestApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ManifestCondition"):
+		return &apisv1alpha1.ManifestConditionApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("PlacementReference"):
+		return &apisv1alpha1.PlacementReferenceApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("ResourceIdentifier"):
+		return &apisv1alpha1.ResourceIdentifierApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("RolloutStrategy"):
+		return &apisv1alpha1.RolloutStrategyApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("Work"):
+		return &apisv1alpha1.WorkApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkAgentConfig"):
+		return &apisv1alpha1.WorkAgentConfigApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkAgentConfigSpec"):
+		return &apisv1alpha1.WorkAgentConfigSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkAgentConfigStatus"):
+		return &apisv1alpha1.WorkAgentConfigStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkloadTemplate"):
+		return &apisv1alpha1.WorkloadTemplateApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkSet"):
+		return &apisv1alpha1.WorkSetApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkSetSpec"):
+		return &apisv1alpha1.WorkSetSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkSetStatus"):
+		return &apisv1alpha1.WorkSetStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkSpec"):
+		return &apisv1alpha1.WorkSpecApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkStatus"):
+		return &apisv1alpha1.WorkStatusApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkStatusDetail"):
+		return &apisv1alpha1.WorkStatusDetailApplyConfiguration{}
+	case v1alpha1.SchemeGroupVersion.WithKind("WorkTemplateSpec"):
+		return &apisv1alpha1.WorkTemplateSpecApplyConfiguration{}
+
+	}
+	return nil
+}
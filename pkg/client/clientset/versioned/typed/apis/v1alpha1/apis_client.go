@@ -28,6 +28,9 @@ type MulticlusterV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	AppliedWorksGetter
 	WorksGetter
+	WorkAgentConfigsGetter
+	WorkSetsGetter
+	WorkStatusDetailsGetter
 }
 
 // MulticlusterV1alpha1Client is used to interact with features provided by the multicluster.x-k8s.io group.
@@ -43,6 +46,18 @@ func (c *MulticlusterV1alpha1Client) Works(namespace string) WorkInterface {
 	return newWorks(c, namespace)
 }
 
+func (c *MulticlusterV1alpha1Client) WorkAgentConfigs() WorkAgentConfigInterface {
+	return newWorkAgentConfigs(c)
+}
+
+func (c *MulticlusterV1alpha1Client) WorkSets() WorkSetInterface {
+	return newWorkSets(c)
+}
+
+func (c *MulticlusterV1alpha1Client) WorkStatusDetails(namespace string) WorkStatusDetailInterface {
+	return newWorkStatusDetails(c, namespace)
+}
+
 // NewForConfig creates a new MulticlusterV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*MulticlusterV1alpha1Client, error) {
 	config := *c
@@ -36,6 +36,18 @@ func (c *FakeMulticlusterV1alpha1) Works(namespace string) v1alpha1.WorkInterfac
 	return &FakeWorks{c, namespace}
 }
 
+func (c *FakeMulticlusterV1alpha1) WorkAgentConfigs() v1alpha1.WorkAgentConfigInterface {
+	return &FakeWorkAgentConfigs{c}
+}
+
+func (c *FakeMulticlusterV1alpha1) WorkSets() v1alpha1.WorkSetInterface {
+	return &FakeWorkSets{c}
+}
+
+func (c *FakeMulticlusterV1alpha1) WorkStatusDetails(namespace string) v1alpha1.WorkStatusDetailInterface {
+	return &FakeWorkStatusDetails{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeMulticlusterV1alpha1) RESTClient() rest.Interface {
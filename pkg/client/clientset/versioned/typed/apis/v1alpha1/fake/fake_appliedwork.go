@@ -20,6 +20,8 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
@@ -28,6 +30,7 @@ import (
 	watch "k8s.io/apimachinery/pkg/watch"
 	testing "k8s.io/client-go/testing"
 	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
 )
 
 // FakeAppliedWorks implements AppliedWorkInterface
@@ -131,3 +134,46 @@ func (c *FakeAppliedWorks) Patch(ctx context.Context, name string, pt types.Patc
 	}
 	return obj.(*v1alpha1.AppliedWork), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied appliedWork.
+func (c *FakeAppliedWorks) Apply(ctx context.Context, appliedWork *apisv1alpha1.AppliedWorkApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.AppliedWork, err error) {
+	if appliedWork == nil {
+		return nil, fmt.Errorf("appliedWork provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(appliedWork)
+	if err != nil {
+		return nil, err
+	}
+	name := appliedWork.Name
+	if name == nil {
+		return nil, fmt.Errorf("appliedWork.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(appliedworksResource, *name, types.ApplyPatchType, data), &v1alpha1.AppliedWork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.AppliedWork), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeAppliedWorks) ApplyStatus(ctx context.Context, appliedWork *apisv1alpha1.AppliedWorkApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.AppliedWork, err error) {
+	if appliedWork == nil {
+		return nil, fmt.Errorf("appliedWork provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(appliedWork)
+	if err != nil {
+		return nil, err
+	}
+	name := appliedWork.Name
+	if name == nil {
+		return nil, fmt.Errorf("appliedWork.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(appliedworksResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.AppliedWork{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.AppliedWork), err
+}
@@ -20,6 +20,8 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
@@ -28,6 +30,7 @@ import (
 	watch "k8s.io/apimachinery/pkg/watch"
 	testing "k8s.io/client-go/testing"
 	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
 )
 
 // FakeWorks implements WorkInterface
@@ -140,3 +143,48 @@ func (c *FakeWorks) Patch(ctx context.Context, name string, pt types.PatchType,
 	}
 	return obj.(*v1alpha1.Work), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied work.
+func (c *FakeWorks) Apply(ctx context.Context, work *apisv1alpha1.WorkApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Work, err error) {
+	if work == nil {
+		return nil, fmt.Errorf("work provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(work)
+	if err != nil {
+		return nil, err
+	}
+	name := work.Name
+	if name == nil {
+		return nil, fmt.Errorf("work.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(worksResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.Work{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Work), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeWorks) ApplyStatus(ctx context.Context, work *apisv1alpha1.WorkApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.Work, err error) {
+	if work == nil {
+		return nil, fmt.Errorf("work provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(work)
+	if err != nil {
+		return nil, err
+	}
+	name := work.Name
+	if name == nil {
+		return nil, fmt.Errorf("work.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(worksResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1alpha1.Work{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.Work), err
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+)
+
+// FakeWorkAgentConfigs implements WorkAgentConfigInterface
+type FakeWorkAgentConfigs struct {
+	Fake *FakeMulticlusterV1alpha1
+}
+
+var workagentconfigsResource = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "workagentconfigs"}
+
+var workagentconfigsKind = schema.GroupVersionKind{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Kind: "WorkAgentConfig"}
+
+// Get takes name of the workAgentConfig, and returns the corresponding workAgentConfig object, and an error if there is any.
+func (c *FakeWorkAgentConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(workagentconfigsResource, name), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// List takes label and field selectors, and returns the list of WorkAgentConfigs that match those selectors.
+func (c *FakeWorkAgentConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkAgentConfigList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(workagentconfigsResource, workagentconfigsKind, opts), &v1alpha1.WorkAgentConfigList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.WorkAgentConfigList{ListMeta: obj.(*v1alpha1.WorkAgentConfigList).ListMeta}
+	for _, item := range obj.(*v1alpha1.WorkAgentConfigList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested workAgentConfigs.
+func (c *FakeWorkAgentConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(workagentconfigsResource, opts))
+}
+
+// Create takes the representation of a workAgentConfig and creates it.  Returns the server's representation of the workAgentConfig, and an error, if there is any.
+func (c *FakeWorkAgentConfigs) Create(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.CreateOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(workagentconfigsResource, workAgentConfig), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// Update takes the representation of a workAgentConfig and updates it. Returns the server's representation of the workAgentConfig, and an error, if there is any.
+func (c *FakeWorkAgentConfigs) Update(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(workagentconfigsResource, workAgentConfig), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeWorkAgentConfigs) UpdateStatus(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (*v1alpha1.WorkAgentConfig, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(workagentconfigsResource, "status", workAgentConfig), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// Delete takes name of the workAgentConfig and deletes it. Returns an error if one occurs.
+func (c *FakeWorkAgentConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(workagentconfigsResource, name), &v1alpha1.WorkAgentConfig{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeWorkAgentConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(workagentconfigsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.WorkAgentConfigList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched workAgentConfig.
+func (c *FakeWorkAgentConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkAgentConfig, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(workagentconfigsResource, name, pt, data, subresources...), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workAgentConfig.
+func (c *FakeWorkAgentConfigs) Apply(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	if workAgentConfig == nil {
+		return nil, fmt.Errorf("workAgentConfig provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(workAgentConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := workAgentConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("workAgentConfig.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(workagentconfigsResource, *name, types.ApplyPatchType, data), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeWorkAgentConfigs) ApplyStatus(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	if workAgentConfig == nil {
+		return nil, fmt.Errorf("workAgentConfig provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(workAgentConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := workAgentConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("workAgentConfig.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(workagentconfigsResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.WorkAgentConfig{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkAgentConfig), err
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+)
+
+// FakeWorkSets implements WorkSetInterface
+type FakeWorkSets struct {
+	Fake *FakeMulticlusterV1alpha1
+}
+
+var worksetsResource = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "worksets"}
+
+var worksetsKind = schema.GroupVersionKind{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Kind: "WorkSet"}
+
+// Get takes name of the workSet, and returns the corresponding workSet object, and an error if there is any.
+func (c *FakeWorkSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootGetAction(worksetsResource, name), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// List takes label and field selectors, and returns the list of WorkSets that match those selectors.
+func (c *FakeWorkSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkSetList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootListAction(worksetsResource, worksetsKind, opts), &v1alpha1.WorkSetList{})
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.WorkSetList{ListMeta: obj.(*v1alpha1.WorkSetList).ListMeta}
+	for _, item := range obj.(*v1alpha1.WorkSetList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested workSets.
+func (c *FakeWorkSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewRootWatchAction(worksetsResource, opts))
+}
+
+// Create takes the representation of a workSet and creates it.  Returns the server's representation of the workSet, and an error, if there is any.
+func (c *FakeWorkSets) Create(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.CreateOptions) (result *v1alpha1.WorkSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootCreateAction(worksetsResource, workSet), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// Update takes the representation of a workSet and updates it. Returns the server's representation of the workSet, and an error, if there is any.
+func (c *FakeWorkSets) Update(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (result *v1alpha1.WorkSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateAction(worksetsResource, workSet), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeWorkSets) UpdateStatus(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (*v1alpha1.WorkSet, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootUpdateSubresourceAction(worksetsResource, "status", workSet), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// Delete takes name of the workSet and deletes it. Returns an error if one occurs.
+func (c *FakeWorkSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewRootDeleteAction(worksetsResource, name), &v1alpha1.WorkSet{})
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeWorkSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewRootDeleteCollectionAction(worksetsResource, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.WorkSetList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched workSet.
+func (c *FakeWorkSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkSet, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(worksetsResource, name, pt, data, subresources...), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workSet.
+func (c *FakeWorkSets) Apply(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error) {
+	if workSet == nil {
+		return nil, fmt.Errorf("workSet provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(workSet)
+	if err != nil {
+		return nil, err
+	}
+	name := workSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("workSet.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(worksetsResource, *name, types.ApplyPatchType, data), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeWorkSets) ApplyStatus(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error) {
+	if workSet == nil {
+		return nil, fmt.Errorf("workSet provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(workSet)
+	if err != nil {
+		return nil, err
+	}
+	name := workSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("workSet.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewRootPatchSubresourceAction(worksetsResource, *name, types.ApplyPatchType, data, "status"), &v1alpha1.WorkSet{})
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkSet), err
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+)
+
+// FakeWorkStatusDetails implements WorkStatusDetailInterface
+type FakeWorkStatusDetails struct {
+	Fake *FakeMulticlusterV1alpha1
+	ns   string
+}
+
+var workstatusdetailsResource = schema.GroupVersionResource{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Resource: "workstatusdetails"}
+
+var workstatusdetailsKind = schema.GroupVersionKind{Group: "multicluster.x-k8s.io", Version: "v1alpha1", Kind: "WorkStatusDetail"}
+
+// Get takes name of the workStatusDetail, and returns the corresponding workStatusDetail object, and an error if there is any.
+func (c *FakeWorkStatusDetails) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(workstatusdetailsResource, c.ns, name), &v1alpha1.WorkStatusDetail{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), err
+}
+
+// List takes label and field selectors, and returns the list of WorkStatusDetails that match those selectors.
+func (c *FakeWorkStatusDetails) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkStatusDetailList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(workstatusdetailsResource, workstatusdetailsKind, c.ns, opts), &v1alpha1.WorkStatusDetailList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.WorkStatusDetailList{ListMeta: obj.(*v1alpha1.WorkStatusDetailList).ListMeta}
+	for _, item := range obj.(*v1alpha1.WorkStatusDetailList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested workStatusDetails.
+func (c *FakeWorkStatusDetails) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(workstatusdetailsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a workStatusDetail and creates it.  Returns the server's representation of the workStatusDetail, and an error, if there is any.
+func (c *FakeWorkStatusDetails) Create(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.CreateOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(workstatusdetailsResource, c.ns, workStatusDetail), &v1alpha1.WorkStatusDetail{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), err
+}
+
+// Update takes the representation of a workStatusDetail and updates it. Returns the server's representation of the workStatusDetail, and an error, if there is any.
+func (c *FakeWorkStatusDetails) Update(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.UpdateOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(workstatusdetailsResource, c.ns, workStatusDetail), &v1alpha1.WorkStatusDetail{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), err
+}
+
+// Delete takes name of the workStatusDetail and deletes it. Returns an error if one occurs.
+func (c *FakeWorkStatusDetails) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(workstatusdetailsResource, c.ns, name), &v1alpha1.WorkStatusDetail{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeWorkStatusDetails) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(workstatusdetailsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.WorkStatusDetailList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched workStatusDetail.
+func (c *FakeWorkStatusDetails) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkStatusDetail, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(workstatusdetailsResource, c.ns, name, pt, data, subresources...), &v1alpha1.WorkStatusDetail{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), err
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workStatusDetail.
+func (c *FakeWorkStatusDetails) Apply(ctx context.Context, workStatusDetail *apisv1alpha1.WorkStatusDetailApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	if workStatusDetail == nil {
+		return nil, fmt.Errorf("workStatusDetail provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(workStatusDetail)
+	if err != nil {
+		return nil, err
+	}
+	name := workStatusDetail.Name
+	if name == nil {
+		return nil, fmt.Errorf("workStatusDetail.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(workstatusdetailsResource, c.ns, *name, types.ApplyPatchType, data), &v1alpha1.WorkStatusDetail{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), err
+}
@@ -21,3 +21,9 @@ package v1alpha1
 type AppliedWorkExpansion interface{}
 
 type WorkExpansion interface{}
+
+type WorkAgentConfigExpansion interface{}
+
+type WorkSetExpansion interface{}
+
+type WorkStatusDetailExpansion interface{}
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+	scheme "sigs.k8s.io/work-api/pkg/client/clientset/versioned/scheme"
+)
+
+// WorkAgentConfigsGetter has a method to return a WorkAgentConfigInterface.
+// A group's client should implement this interface.
+type WorkAgentConfigsGetter interface {
+	WorkAgentConfigs() WorkAgentConfigInterface
+}
+
+// WorkAgentConfigInterface has methods to work with WorkAgentConfig resources.
+type WorkAgentConfigInterface interface {
+	Create(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.CreateOptions) (*v1alpha1.WorkAgentConfig, error)
+	Update(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (*v1alpha1.WorkAgentConfig, error)
+	UpdateStatus(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (*v1alpha1.WorkAgentConfig, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.WorkAgentConfig, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.WorkAgentConfigList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkAgentConfig, err error)
+	Apply(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error)
+	ApplyStatus(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error)
+	WorkAgentConfigExpansion
+}
+
+// workAgentConfigs implements WorkAgentConfigInterface
+type workAgentConfigs struct {
+	client rest.Interface
+}
+
+// newWorkAgentConfigs returns a WorkAgentConfigs
+func newWorkAgentConfigs(c *MulticlusterV1alpha1Client) *workAgentConfigs {
+	return &workAgentConfigs{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the workAgentConfig, and returns the corresponding workAgentConfig object, and an error if there is any.
+func (c *workAgentConfigs) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Get().
+		Resource("workagentconfigs").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of WorkAgentConfigs that match those selectors.
+func (c *workAgentConfigs) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkAgentConfigList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.WorkAgentConfigList{}
+	err = c.client.Get().
+		Resource("workagentconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested workAgentConfigs.
+func (c *workAgentConfigs) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("workagentconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a workAgentConfig and creates it.  Returns the server's representation of the workAgentConfig, and an error, if there is any.
+func (c *workAgentConfigs) Create(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.CreateOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Post().
+		Resource("workagentconfigs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workAgentConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a workAgentConfig and updates it. Returns the server's representation of the workAgentConfig, and an error, if there is any.
+func (c *workAgentConfigs) Update(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Put().
+		Resource("workagentconfigs").
+		Name(workAgentConfig.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workAgentConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *workAgentConfigs) UpdateStatus(ctx context.Context, workAgentConfig *v1alpha1.WorkAgentConfig, opts v1.UpdateOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Put().
+		Resource("workagentconfigs").
+		Name(workAgentConfig.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workAgentConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the workAgentConfig and deletes it. Returns an error if one occurs.
+func (c *workAgentConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("workagentconfigs").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *workAgentConfigs) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("workagentconfigs").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched workAgentConfig.
+func (c *workAgentConfigs) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkAgentConfig, err error) {
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Patch(pt).
+		Resource("workagentconfigs").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workAgentConfig.
+func (c *workAgentConfigs) Apply(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	if workAgentConfig == nil {
+		return nil, fmt.Errorf("workAgentConfig provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(workAgentConfig)
+	if err != nil {
+		return nil, err
+	}
+	name := workAgentConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("workAgentConfig.Name must be provided to Apply")
+	}
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("workagentconfigs").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *workAgentConfigs) ApplyStatus(ctx context.Context, workAgentConfig *apisv1alpha1.WorkAgentConfigApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkAgentConfig, err error) {
+	if workAgentConfig == nil {
+		return nil, fmt.Errorf("workAgentConfig provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(workAgentConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	name := workAgentConfig.Name
+	if name == nil {
+		return nil, fmt.Errorf("workAgentConfig.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.WorkAgentConfig{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("workagentconfigs").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
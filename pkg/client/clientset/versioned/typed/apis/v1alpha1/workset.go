@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+	scheme "sigs.k8s.io/work-api/pkg/client/clientset/versioned/scheme"
+)
+
+// WorkSetsGetter has a method to return a WorkSetInterface.
+// A group's client should implement this interface.
+type WorkSetsGetter interface {
+	WorkSets() WorkSetInterface
+}
+
+// WorkSetInterface has methods to work with WorkSet resources.
+type WorkSetInterface interface {
+	Create(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.CreateOptions) (*v1alpha1.WorkSet, error)
+	Update(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (*v1alpha1.WorkSet, error)
+	UpdateStatus(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (*v1alpha1.WorkSet, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.WorkSet, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.WorkSetList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkSet, err error)
+	Apply(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error)
+	ApplyStatus(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error)
+	WorkSetExpansion
+}
+
+// workSets implements WorkSetInterface
+type workSets struct {
+	client rest.Interface
+}
+
+// newWorkSets returns a WorkSets
+func newWorkSets(c *MulticlusterV1alpha1Client) *workSets {
+	return &workSets{
+		client: c.RESTClient(),
+	}
+}
+
+// Get takes name of the workSet, and returns the corresponding workSet object, and an error if there is any.
+func (c *workSets) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkSet, err error) {
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Get().
+		Resource("worksets").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of WorkSets that match those selectors.
+func (c *workSets) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkSetList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.WorkSetList{}
+	err = c.client.Get().
+		Resource("worksets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested workSets.
+func (c *workSets) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Resource("worksets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a workSet and creates it.  Returns the server's representation of the workSet, and an error, if there is any.
+func (c *workSets) Create(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.CreateOptions) (result *v1alpha1.WorkSet, err error) {
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Post().
+		Resource("worksets").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a workSet and updates it. Returns the server's representation of the workSet, and an error, if there is any.
+func (c *workSets) Update(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (result *v1alpha1.WorkSet, err error) {
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Put().
+		Resource("worksets").
+		Name(workSet.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *workSets) UpdateStatus(ctx context.Context, workSet *v1alpha1.WorkSet, opts v1.UpdateOptions) (result *v1alpha1.WorkSet, err error) {
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Put().
+		Resource("worksets").
+		Name(workSet.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workSet).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the workSet and deletes it. Returns an error if one occurs.
+func (c *workSets) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Resource("worksets").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *workSets) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Resource("worksets").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched workSet.
+func (c *workSets) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkSet, err error) {
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Patch(pt).
+		Resource("worksets").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workSet.
+func (c *workSets) Apply(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error) {
+	if workSet == nil {
+		return nil, fmt.Errorf("workSet provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(workSet)
+	if err != nil {
+		return nil, err
+	}
+	name := workSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("workSet.Name must be provided to Apply")
+	}
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("worksets").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *workSets) ApplyStatus(ctx context.Context, workSet *apisv1alpha1.WorkSetApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkSet, err error) {
+	if workSet == nil {
+		return nil, fmt.Errorf("workSet provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(workSet)
+	if err != nil {
+		return nil, err
+	}
+
+	name := workSet.Name
+	if name == nil {
+		return nil, fmt.Errorf("workSet.Name must be provided to Apply")
+	}
+
+	result = &v1alpha1.WorkSet{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Resource("worksets").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	json "encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	apisv1alpha1 "sigs.k8s.io/work-api/pkg/client/applyconfiguration/apis/v1alpha1"
+	scheme "sigs.k8s.io/work-api/pkg/client/clientset/versioned/scheme"
+)
+
+// WorkStatusDetailsGetter has a method to return a WorkStatusDetailInterface.
+// A group's client should implement this interface.
+type WorkStatusDetailsGetter interface {
+	WorkStatusDetails(namespace string) WorkStatusDetailInterface
+}
+
+// WorkStatusDetailInterface has methods to work with WorkStatusDetail resources.
+type WorkStatusDetailInterface interface {
+	Create(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.CreateOptions) (*v1alpha1.WorkStatusDetail, error)
+	Update(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.UpdateOptions) (*v1alpha1.WorkStatusDetail, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.WorkStatusDetail, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.WorkStatusDetailList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkStatusDetail, err error)
+	Apply(ctx context.Context, workStatusDetail *apisv1alpha1.WorkStatusDetailApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkStatusDetail, err error)
+	WorkStatusDetailExpansion
+}
+
+// workStatusDetails implements WorkStatusDetailInterface
+type workStatusDetails struct {
+	client rest.Interface
+	ns     string
+}
+
+// newWorkStatusDetails returns a WorkStatusDetails
+func newWorkStatusDetails(c *MulticlusterV1alpha1Client, namespace string) *workStatusDetails {
+	return &workStatusDetails{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the workStatusDetail, and returns the corresponding workStatusDetail object, and an error if there is any.
+func (c *workStatusDetails) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	result = &v1alpha1.WorkStatusDetail{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of WorkStatusDetails that match those selectors.
+func (c *workStatusDetails) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.WorkStatusDetailList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.WorkStatusDetailList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested workStatusDetails.
+func (c *workStatusDetails) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a workStatusDetail and creates it.  Returns the server's representation of the workStatusDetail, and an error, if there is any.
+func (c *workStatusDetails) Create(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.CreateOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	result = &v1alpha1.WorkStatusDetail{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workStatusDetail).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a workStatusDetail and updates it. Returns the server's representation of the workStatusDetail, and an error, if there is any.
+func (c *workStatusDetails) Update(ctx context.Context, workStatusDetail *v1alpha1.WorkStatusDetail, opts v1.UpdateOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	result = &v1alpha1.WorkStatusDetail{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		Name(workStatusDetail.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(workStatusDetail).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the workStatusDetail and deletes it. Returns an error if one occurs.
+func (c *workStatusDetails) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *workStatusDetails) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched workStatusDetail.
+func (c *workStatusDetails) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.WorkStatusDetail, err error) {
+	result = &v1alpha1.WorkStatusDetail{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied workStatusDetail.
+func (c *workStatusDetails) Apply(ctx context.Context, workStatusDetail *apisv1alpha1.WorkStatusDetailApplyConfiguration, opts v1.ApplyOptions) (result *v1alpha1.WorkStatusDetail, err error) {
+	if workStatusDetail == nil {
+		return nil, fmt.Errorf("workStatusDetail provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(workStatusDetail)
+	if err != nil {
+		return nil, err
+	}
+	name := workStatusDetail.Name
+	if name == nil {
+		return nil, fmt.Errorf("workStatusDetail.Name must be provided to Apply")
+	}
+	result = &v1alpha1.WorkStatusDetail{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("workstatusdetails").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -28,6 +28,12 @@ type Interface interface {
 	AppliedWorks() AppliedWorkInformer
 	// Works returns a WorkInformer.
 	Works() WorkInformer
+	// WorkAgentConfigs returns a WorkAgentConfigInformer.
+	WorkAgentConfigs() WorkAgentConfigInformer
+	// WorkSets returns a WorkSetInformer.
+	WorkSets() WorkSetInformer
+	// WorkStatusDetails returns a WorkStatusDetailInformer.
+	WorkStatusDetails() WorkStatusDetailInformer
 }
 
 type version struct {
@@ -50,3 +56,18 @@ func (v *version) AppliedWorks() AppliedWorkInformer {
 func (v *version) Works() WorkInformer {
 	return &workInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// WorkAgentConfigs returns a WorkAgentConfigInformer.
+func (v *version) WorkAgentConfigs() WorkAgentConfigInformer {
+	return &workAgentConfigInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// WorkSets returns a WorkSetInformer.
+func (v *version) WorkSets() WorkSetInformer {
+	return &workSetInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// WorkStatusDetails returns a WorkStatusDetailInformer.
+func (v *version) WorkStatusDetails() WorkStatusDetailInformer {
+	return &workStatusDetailInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -57,6 +57,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1alpha1().AppliedWorks().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("works"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1alpha1().Works().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("workagentconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1alpha1().WorkAgentConfigs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("worksets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1alpha1().WorkSets().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("workstatusdetails"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Multicluster().V1alpha1().WorkStatusDetails().Informer()}, nil
 
 	}
 
@@ -29,3 +29,19 @@ type WorkListerExpansion interface{}
 // WorkNamespaceListerExpansion allows custom methods to be added to
 // WorkNamespaceLister.
 type WorkNamespaceListerExpansion interface{}
+
+// WorkAgentConfigListerExpansion allows custom methods to be added to
+// WorkAgentConfigLister.
+type WorkAgentConfigListerExpansion interface{}
+
+// WorkSetListerExpansion allows custom methods to be added to
+// WorkSetLister.
+type WorkSetListerExpansion interface{}
+
+// WorkStatusDetailListerExpansion allows custom methods to be added to
+// WorkStatusDetailLister.
+type WorkStatusDetailListerExpansion interface{}
+
+// WorkStatusDetailNamespaceListerExpansion allows custom methods to be added to
+// WorkStatusDetailNamespaceLister.
+type WorkStatusDetailNamespaceListerExpansion interface{}
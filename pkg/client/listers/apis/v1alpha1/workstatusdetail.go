@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	v1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// WorkStatusDetailLister helps list WorkStatusDetails.
+// All objects returned here must be treated as read-only.
+type WorkStatusDetailLister interface {
+	// List lists all WorkStatusDetails in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.WorkStatusDetail, err error)
+	// WorkStatusDetails returns an object that can list and get WorkStatusDetails.
+	WorkStatusDetails(namespace string) WorkStatusDetailNamespaceLister
+	WorkStatusDetailListerExpansion
+}
+
+// workStatusDetailLister implements the WorkStatusDetailLister interface.
+type workStatusDetailLister struct {
+	indexer cache.Indexer
+}
+
+// NewWorkStatusDetailLister returns a new WorkStatusDetailLister.
+func NewWorkStatusDetailLister(indexer cache.Indexer) WorkStatusDetailLister {
+	return &workStatusDetailLister{indexer: indexer}
+}
+
+// List lists all WorkStatusDetails in the indexer.
+func (s *workStatusDetailLister) List(selector labels.Selector) (ret []*v1alpha1.WorkStatusDetail, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.WorkStatusDetail))
+	})
+	return ret, err
+}
+
+// WorkStatusDetails returns an object that can list and get WorkStatusDetails.
+func (s *workStatusDetailLister) WorkStatusDetails(namespace string) WorkStatusDetailNamespaceLister {
+	return workStatusDetailNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// WorkStatusDetailNamespaceLister helps list and get WorkStatusDetails.
+// All objects returned here must be treated as read-only.
+type WorkStatusDetailNamespaceLister interface {
+	// List lists all WorkStatusDetails in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.WorkStatusDetail, err error)
+	// Get retrieves the WorkStatusDetail from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.WorkStatusDetail, error)
+	WorkStatusDetailNamespaceListerExpansion
+}
+
+// workStatusDetailNamespaceLister implements the WorkStatusDetailNamespaceLister
+// interface.
+type workStatusDetailNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all WorkStatusDetails in the indexer for a given namespace.
+func (s workStatusDetailNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.WorkStatusDetail, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.WorkStatusDetail))
+	})
+	return ret, err
+}
+
+// Get retrieves the WorkStatusDetail from the indexer for a given namespace and name.
+func (s workStatusDetailNamespaceLister) Get(name string) (*v1alpha1.WorkStatusDetail, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("workstatusdetail"), name)
+	}
+	return obj.(*v1alpha1.WorkStatusDetail), nil
+}
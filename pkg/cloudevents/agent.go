@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/mirror"
+)
+
+// agentSource is the source of the events published by the agent bridge.
+const agentSource = "work-agent"
+
+// AgentBridge mirrors the Works published for a cluster into a namespace of the cluster, where the
+// agent applies them, and publishes their status.
+type AgentBridge struct {
+	client    client.Client
+	transport Transport
+	log       logr.Logger
+	// cluster is the namespace of the cluster on the hub.
+	cluster string
+	// namespace is the namespace of the mirrors of the Works.
+	namespace string
+}
+
+// SetupAgentWithManager wires up the agent bridge of a cluster with a transport. The manager must
+// be the one of the cluster whose namespace holds the mirrors of the Works.
+func SetupAgentWithManager(mgr ctrl.Manager, transport Transport, cluster, namespace string) error {
+	if cluster == "" || namespace == "" {
+		return fmt.Errorf("the cluster and the namespace of the works are required")
+	}
+	r := &AgentBridge{
+		client:    mgr.GetClient(),
+		transport: transport,
+		log:       ctrl.Log.WithName("controllers").WithName("CloudEventsAgent"),
+		cluster:   cluster,
+		namespace: namespace,
+	}
+	if err := mgr.Add(manager.RunnableFunc(r.run)); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("cloudevents-agent").
+		For(&workv1alpha1.Work{}).
+		Complete(r)
+}
+
+// run subscribes to the Works of the cluster and asks the hub to publish them all, as some may have
+// changed while the agent was not running. The resync is only asked for once the subscription is
+// registered, so the Works the hub publishes in reply are not missed.
+func (r *AgentBridge) run(ctx context.Context) error {
+	if err := r.transport.Subscribe(ctx, SpecTopic(r.cluster), r.handleEvent); err != nil {
+		return err
+	}
+	evt, err := NewEvent(agentSource, ResyncEventType, r.cluster, "", nil)
+	if err != nil {
+		return err
+	}
+	if err := r.transport.Publish(ctx, StatusTopic, evt); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// Reconcile publishes the status of a mirror, or that the Work is cleaned up once the mirror is gone.
+func (r *AgentBridge) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Namespace != r.namespace {
+		return ctrl.Result{}, nil
+	}
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, req.NamespacedName, work)
+	switch {
+	case errors.IsNotFound(err):
+		evt, err := NewEvent(agentSource, DeletedEventType, r.cluster, req.Name, nil)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.transport.Publish(ctx, StatusTopic, evt)
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	hubGeneration, status, ok := mirror.HubStatus(work)
+	if !ok {
+		r.log.Info("work is not a mirror of a work of the hub", "work", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+	evt, err := NewEvent(agentSource, StatusEventType, r.cluster, req.Name, &WorkStatus{Generation: hubGeneration, Status: *status})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.transport.Publish(ctx, StatusTopic, evt)
+}
+
+// handleEvent mirrors the Works published by the hub.
+func (r *AgentBridge) handleEvent(ctx context.Context, evt *Event) error {
+	key := types.NamespacedName{Namespace: r.namespace, Name: evt.Subject}
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, key, work)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	switch evt.Type {
+	case DeleteEventType:
+		if !exists {
+			// the mirror is already gone, tell the hub again
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			return err
+		}
+		r.log.Info("deleting work", "work", key)
+		if err := r.client.Delete(ctx, work); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	case SpecEventType:
+		spec := &WorkSpec{}
+		if err := evt.DecodeData(spec); err != nil {
+			return err
+		}
+		changed, err := mirror.Apply(ctx, r.client, r.namespace, &workv1alpha1.Work{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        evt.Subject,
+				Labels:      spec.Labels,
+				Annotations: spec.Annotations,
+				Generation:  spec.Generation,
+			},
+			Spec: spec.Spec,
+		})
+		if changed {
+			r.log.V(2).Info("mirrored work", "work", key, "generation", spec.Generation)
+		}
+		return err
+	}
+	return fmt.Errorf("unexpected event type %s", evt.Type)
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/mirror"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+// recordingTransport records the published events, the tests deliver them to the handlers.
+type recordingTransport struct {
+	events map[string][]*Event
+}
+
+func (r *recordingTransport) Publish(_ context.Context, topic string, event *Event) error {
+	r.events[topic] = append(r.events[topic], event)
+	return nil
+}
+
+func (r *recordingTransport) Subscribe(context.Context, string, Handler) error {
+	return nil
+}
+
+// deliver calls the handler with the events of a topic and forgets them.
+func (r *recordingTransport) deliver(t *testing.T, topic string, handler Handler) {
+	events := r.events[topic]
+	delete(r.events, topic)
+	for _, event := range events {
+		if err := handler(context.Background(), event); err != nil {
+			t.Fatalf("failed to handle %s: %v", event.Type, err)
+		}
+	}
+}
+
+func TestBridges(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{ClusterLabel: "true"}}}
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Generation: 3, Labels: map[string]string{"app": "test"}}}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, work).Build()
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	transport := &recordingTransport{events: map[string][]*Event{}}
+	hub := &HubBridge{client: hubClient, transport: transport, log: ctrl.Log}
+	agent := &AgentBridge{client: spokeClient, transport: transport, log: ctrl.Log, cluster: "cluster1", namespace: "works"}
+	ctx := context.Background()
+	hubKey := client.ObjectKeyFromObject(work)
+	mirrorKey := types.NamespacedName{Namespace: "works", Name: "work"}
+
+	// the first reconcile adds the finalizer, the second publishes the work
+	for i := 0; i < 2; i++ {
+		if _, err := hub.Reconcile(ctx, ctrl.Request{NamespacedName: hubKey}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hubClient.Get(ctx, hubKey, work); err != nil {
+		t.Fatal(err)
+	}
+	if !controllerutil.ContainsFinalizer(work, hubFinalizer) {
+		t.Errorf("expected the finalizer to be added, got %v", work.Finalizers)
+	}
+	transport.deliver(t, SpecTopic("cluster1"), agent.handleEvent)
+
+	mirrored := &workv1alpha1.Work{}
+	if err := spokeClient.Get(ctx, mirrorKey, mirrored); err != nil {
+		t.Fatalf("expected the work to be mirrored: %v", err)
+	}
+	if mirrored.Labels["app"] != "test" || mirrored.Annotations[mirror.HubGenerationAnnotation] != "3" {
+		t.Errorf("unexpected mirrored metadata %v", mirrored.ObjectMeta)
+	}
+
+	// the status of the mirrored is reported with the generation of the hub
+	mirrored.Status.Conditions = []metav1.Condition{{
+		Type: conditions.TypeApplied, Status: metav1.ConditionTrue, Reason: "Applied",
+		ObservedGeneration: mirrored.Generation, LastTransitionTime: metav1.Now(),
+	}}
+	if err := spokeClient.Status().Update(ctx, mirrored); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agent.Reconcile(ctx, ctrl.Request{NamespacedName: mirrorKey}); err != nil {
+		t.Fatal(err)
+	}
+	transport.deliver(t, StatusTopic, hub.handleEvent)
+	if err := hubClient.Get(ctx, hubKey, work); err != nil {
+		t.Fatal(err)
+	}
+	if !conditions.IsFresh(work.Status.Conditions, conditions.TypeApplied, work.Generation) || !conditions.IsApplied(work.Status.Conditions) {
+		t.Errorf("expected the work to be reported applied, got %v", work.Status.Conditions)
+	}
+
+	// the work is deleted on the hub once the agent cleaned it up
+	if err := hubClient.Delete(ctx, work); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hub.Reconcile(ctx, ctrl.Request{NamespacedName: hubKey}); err != nil {
+		t.Fatal(err)
+	}
+	transport.deliver(t, SpecTopic("cluster1"), agent.handleEvent)
+	if err := spokeClient.Get(ctx, mirrorKey, mirrored); !errors.IsNotFound(err) {
+		t.Fatalf("expected the mirrored to be deleted, got %v", err)
+	}
+	if _, err := agent.Reconcile(ctx, ctrl.Request{NamespacedName: mirrorKey}); err != nil {
+		t.Fatal(err)
+	}
+	transport.deliver(t, StatusTopic, hub.handleEvent)
+	if err := hubClient.Get(ctx, hubKey, work); !errors.IsNotFound(err) {
+		t.Errorf("expected the work to be deleted, got %v", err)
+	}
+}
+
+func TestAgentBridgeResyncOnStart(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Labels: map[string]string{ClusterLabel: "true"}}}
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Generation: 1}}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, work).Build()
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	transport := NewMemoryTransport()
+	hub := &HubBridge{client: hubClient, transport: transport, log: ctrl.Log}
+	agent := &AgentBridge{client: spokeClient, transport: transport, log: ctrl.Log, cluster: "cluster1", namespace: "works"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := transport.Subscribe(ctx, StatusTopic, hub.handleEvent); err != nil {
+		t.Fatal(err)
+	}
+
+	// the work existed on the hub before the agent started, the resync mirrors it
+	done := make(chan error, 1)
+	go func() {
+		done <- agent.run(ctx)
+	}()
+	deadline := time.After(10 * time.Second)
+	for {
+		err := spokeClient.Get(ctx, types.NamespacedName{Namespace: "works", Name: "work"}, &workv1alpha1.Work{})
+		if err == nil {
+			break
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("the agent bridge stopped: %v", err)
+		case <-deadline:
+			t.Fatalf("expected the work to be mirrored on start, got %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestHubBridgeIgnoresOtherClusters(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cluster1"}}
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1"}}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, work).Build()
+	transport := &recordingTransport{events: map[string][]*Event{}}
+	hub := &HubBridge{client: hubClient, transport: transport, log: ctrl.Log}
+
+	if _, err := hub.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(work)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := hubClient.Get(context.Background(), client.ObjectKeyFromObject(work), work); err != nil {
+		t.Fatal(err)
+	}
+	if len(work.Finalizers) != 0 || len(transport.events) != 0 {
+		t.Errorf("expected the work of an unlabeled cluster to be left alone, got finalizers %v and events %v", work.Finalizers, transport.events)
+	}
+}
+
+func TestMemoryTransport(t *testing.T) {
+	transport := NewMemoryTransport()
+	ctx, cancel := context.WithCancel(context.Background())
+	received := make(chan *Event, 1)
+	if err := transport.Subscribe(ctx, "topic", func(_ context.Context, event *Event) error {
+		received <- event
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the subscription is registered once Subscribe returns, the first event is delivered
+	event, err := NewEvent("test", SpecEventType, "cluster1", "work", &WorkSpec{Generation: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Publish(ctx, "topic", event); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		spec := &WorkSpec{}
+		if err := got.DecodeData(spec); err != nil || spec.Generation != 1 {
+			t.Errorf("unexpected event data %s: %v", got.Data, err)
+		}
+	default:
+		t.Fatal("the event was not delivered")
+	}
+
+	// the handler is unregistered once the context is done
+	cancel()
+	deadline := time.After(10 * time.Second)
+	for {
+		transport.lock.RLock()
+		subscribed := len(transport.handlers["topic"])
+		transport.lock.RUnlock()
+		if subscribed == 0 {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("the handler was not unregistered")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
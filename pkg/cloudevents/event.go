@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudevents exchanges the Works and their status between the hub and the agents as
+// CloudEvents over a broker, for clusters whose agent cannot reach the hub API. The hub bridge
+// publishes the Works of the cluster namespaces labeled with ClusterLabel, the agent bridge
+// mirrors them into a namespace of its cluster where the agent applies them, and their status
+// flows back the same way.
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// ClusterLabel is set to "true" on the namespace of a cluster whose Works are exchanged as
+// CloudEvents rather than read by the agent from the hub API.
+const ClusterLabel = "multicluster.x-k8s.io/cloudevents"
+
+// The types of the events.
+const (
+	// SpecEventType carries a Work from the hub to the agent of its cluster.
+	SpecEventType = "io.x-k8s.multicluster.work.spec"
+	// DeleteEventType tells the agent a Work is deleted on the hub.
+	DeleteEventType = "io.x-k8s.multicluster.work.delete"
+	// StatusEventType carries the status of a Work from the agent to the hub.
+	StatusEventType = "io.x-k8s.multicluster.work.status"
+	// DeletedEventType tells the hub the agent cleaned up a deleted Work.
+	DeletedEventType = "io.x-k8s.multicluster.work.deleted"
+	// ResyncEventType asks the hub to publish every Work of the cluster again, the agent sends it
+	// when it starts.
+	ResyncEventType = "io.x-k8s.multicluster.work.resync"
+)
+
+// StatusTopic is the topic the agents publish their events to.
+const StatusTopic = "works/status"
+
+// SpecTopic returns the topic the hub publishes the Works of a cluster to.
+func SpecTopic(cluster string) string {
+	return "works/" + cluster + "/spec"
+}
+
+// Event is a CloudEvent in the JSON format of the CloudEvents 1.0 specification. The subject is
+// the name of the Work, the cluster extension the namespace of its cluster on the hub.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            *time.Time      `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+	// Cluster is the clustername extension attribute.
+	Cluster string `json:"clustername"`
+}
+
+// WorkSpec is the data of a spec event.
+type WorkSpec struct {
+	Labels      map[string]string     `json:"labels,omitempty"`
+	Annotations map[string]string     `json:"annotations,omitempty"`
+	Generation  int64                 `json:"generation"`
+	Spec        workv1alpha1.WorkSpec `json:"spec"`
+}
+
+// WorkStatus is the data of a status event. The observed generations of its conditions are those
+// of the Work on the hub.
+type WorkStatus struct {
+	Generation int64                   `json:"generation"`
+	Status     workv1alpha1.WorkStatus `json:"status"`
+}
+
+// NewEvent returns an event of the type about a Work of a cluster, data is encoded as JSON.
+func NewEvent(source, eventType, cluster, name string, data interface{}) (*Event, error) {
+	now := time.Now().UTC()
+	event := &Event{
+		SpecVersion: "1.0",
+		ID:          string(uuid.NewUUID()),
+		Source:      source,
+		Type:        eventType,
+		Subject:     name,
+		Time:        &now,
+		Cluster:     cluster,
+	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		event.DataContentType = "application/json"
+		event.Data = raw
+	}
+	return event, nil
+}
+
+// DecodeData decodes the JSON data of an event.
+func (e *Event) DecodeData(data interface{}) error {
+	if err := json.Unmarshal(e.Data, data); err != nil {
+		return fmt.Errorf("failed to decode the data of event %s of type %s: %w", e.ID, e.Type, err)
+	}
+	return nil
+}
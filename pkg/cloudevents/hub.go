@@ -0,0 +1,203 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevents
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/finalizers"
+)
+
+// hubFinalizer is added to the Works published by the hub bridge, it is removed once the agent
+// reports the Work cleaned up.
+const hubFinalizer = "multicluster.x-k8s.io/cloudevents-cleanup"
+
+// hubSource is the source of the events published by the hub bridge.
+const hubSource = "work-hub"
+
+// HubBridge publishes the Works of the cluster namespaces labeled with ClusterLabel, and records
+// the status reported by their agents.
+type HubBridge struct {
+	client    client.Client
+	transport Transport
+	log       logr.Logger
+}
+
+// SetupHubWithManager wires up the hub bridge with a transport.
+func SetupHubWithManager(mgr ctrl.Manager, transport Transport) error {
+	r := &HubBridge{
+		client:    mgr.GetClient(),
+		transport: transport,
+		log:       ctrl.Log.WithName("controllers").WithName("CloudEventsHub"),
+	}
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		if err := transport.Subscribe(ctx, StatusTopic, r.handleEvent); err != nil {
+			return err
+		}
+		<-ctx.Done()
+		return nil
+	})); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("cloudevents-hub").
+		For(&workv1alpha1.Work{}, builder.WithPredicates(specChanged)).
+		Complete(r)
+}
+
+// Reconcile publishes a Work to the agent of its cluster, or its deletion.
+func (r *HubBridge) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, req.NamespacedName, work)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+	bridged, err := r.isBridged(ctx, work.Namespace)
+	if err != nil || !bridged {
+		return ctrl.Result{}, err
+	}
+
+	if work.DeletionTimestamp.IsZero() && !controllerutil.ContainsFinalizer(work, hubFinalizer) {
+		r.log.V(2).Info("adding cloudevents finalizer", "work", req.NamespacedName)
+		return ctrl.Result{}, finalizers.Add(ctx, r.client, work, hubFinalizer)
+	}
+	return ctrl.Result{}, r.publish(ctx, work)
+}
+
+// publish sends a Work, or its deletion, to the agent of its cluster.
+func (r *HubBridge) publish(ctx context.Context, work *workv1alpha1.Work) error {
+	var evt *Event
+	var err error
+	switch {
+	case !work.DeletionTimestamp.IsZero() && controllerutil.ContainsFinalizer(work, hubFinalizer):
+		evt, err = NewEvent(hubSource, DeleteEventType, work.Namespace, work.Name, nil)
+	case work.DeletionTimestamp.IsZero():
+		evt, err = NewEvent(hubSource, SpecEventType, work.Namespace, work.Name, &WorkSpec{
+			Labels:      work.Labels,
+			Annotations: work.Annotations,
+			Generation:  work.Generation,
+			Spec:        work.Spec,
+		})
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.log.V(2).Info("publishing work", "work", client.ObjectKeyFromObject(work), "type", evt.Type)
+	return r.transport.Publish(ctx, SpecTopic(work.Namespace), evt)
+}
+
+// handleEvent records the events of the agents on the Works of the hub.
+func (r *HubBridge) handleEvent(ctx context.Context, evt *Event) error {
+	bridged, err := r.isBridged(ctx, evt.Cluster)
+	if err != nil || !bridged {
+		return err
+	}
+	if evt.Type == ResyncEventType {
+		return r.resync(ctx, evt.Cluster)
+	}
+
+	key := types.NamespacedName{Namespace: evt.Cluster, Name: evt.Subject}
+	work := &workv1alpha1.Work{}
+	err = r.client.Get(ctx, key, work)
+	switch {
+	case errors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	switch evt.Type {
+	case StatusEventType:
+		status := &WorkStatus{}
+		if err := evt.DecodeData(status); err != nil {
+			return err
+		}
+		// the status of an older generation is stale, the agent reports the new one later
+		if status.Generation != work.Generation {
+			return nil
+		}
+		work.Status = status.Status
+		return r.client.Status().Update(ctx, work)
+	case DeletedEventType:
+		if work.DeletionTimestamp.IsZero() || !controllerutil.ContainsFinalizer(work, hubFinalizer) {
+			return nil
+		}
+		r.log.V(2).Info("removing cloudevents finalizer", "work", key)
+		return finalizers.Remove(ctx, r.client, work, hubFinalizer)
+	}
+	return fmt.Errorf("unexpected event type %s", evt.Type)
+}
+
+// resync publishes every Work of a cluster again.
+func (r *HubBridge) resync(ctx context.Context, cluster string) error {
+	works := &workv1alpha1.WorkList{}
+	if err := r.client.List(ctx, works, client.InNamespace(cluster)); err != nil {
+		return err
+	}
+	r.log.Info("resyncing the works of cluster", "cluster", cluster, "works", len(works.Items))
+	errs := []error{}
+	for i := range works.Items {
+		if err := r.publish(ctx, &works.Items[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// isBridged returns true if the Works of the cluster namespace are exchanged as CloudEvents.
+func (r *HubBridge) isBridged(ctx context.Context, cluster string) (bool, error) {
+	ns := &corev1.Namespace{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: cluster}, ns)
+	switch {
+	case errors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	return ns.Labels[ClusterLabel] == "true", nil
+}
+
+// specChanged filters out the updates of the status of the Works, which the bridges make
+// themselves, so they are not published again.
+var specChanged = predicate.Or(
+	predicate.GenerationChangedPredicate{},
+	predicate.LabelChangedPredicate{},
+	predicate.AnnotationChangedPredicate{},
+	predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() ||
+			len(e.ObjectOld.GetFinalizers()) != len(e.ObjectNew.GetFinalizers())
+	}},
+)
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevents
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler handles the events of a subscription. An event whose handler fails is not acknowledged,
+// the transport delivers it again if the broker supports it.
+type Handler func(ctx context.Context, event *Event) error
+
+// Transport publishes and subscribes to events over a broker, such as MQTT, Kafka or gRPC.
+type Transport interface {
+	// Publish sends an event to the subscribers of a topic.
+	Publish(ctx context.Context, topic string, event *Event) error
+	// Subscribe calls the handler with the events of a topic until the context is done. It returns
+	// once the subscription is registered, the events published after it returns are delivered.
+	Subscribe(ctx context.Context, topic string, handler Handler) error
+}
+
+// MemoryTransport delivers the events in process, for tests and for running the hub and the agent
+// in the same process. The events published without subscribers are dropped.
+type MemoryTransport struct {
+	lock     sync.RWMutex
+	handlers map[string][]*Handler
+}
+
+// NewMemoryTransport returns a MemoryTransport without subscribers.
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{handlers: map[string][]*Handler{}}
+}
+
+// Publish calls the handlers subscribed to the topic and returns the first error.
+func (t *MemoryTransport) Publish(ctx context.Context, topic string, event *Event) error {
+	t.lock.RLock()
+	handlers := append([]*Handler{}, t.handlers[topic]...)
+	t.lock.RUnlock()
+	for _, handler := range handlers {
+		if err := (*handler)(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe registers the handler until the context is done.
+func (t *MemoryTransport) Subscribe(ctx context.Context, topic string, handler Handler) error {
+	t.lock.Lock()
+	t.handlers[topic] = append(t.handlers[topic], &handler)
+	t.lock.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		handlers := t.handlers[topic]
+		for i := range handlers {
+			if handlers[i] == &handler {
+				t.handlers[topic] = append(handlers[:i], handlers[i+1:]...)
+				break
+			}
+		}
+	}()
+	return nil
+}
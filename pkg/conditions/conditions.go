@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conditions reads, sets and compares the conditions of the works and their manifests.
+package conditions
+
+import (
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Equal returns true if both lists hold the same conditions in any order. The LastTransitionTime
+// is ignored: it only changes with the status, and it loses its sub-second precision once
+// written, so comparing it reports changes which are not there.
+func Equal(a, b []metav1.Condition) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, condition := range a {
+		other := find(b, condition.Type)
+		if other == nil || !equalCondition(condition, *other) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalCondition(a, b metav1.Condition) bool {
+	return a.Type == b.Type &&
+		a.Status == b.Status &&
+		a.ObservedGeneration == b.ObservedGeneration &&
+		a.Reason == b.Reason &&
+		a.Message == b.Message
+}
+
+func find(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// Compact drops the duplicated conditions of a type, keeping the one which transitioned last, and
+// caps the number of conditions to max. The conditions of the types set by the agent are always
+// kept, the others which transitioned last fill the rest of the cap. The conditions are returned
+// as they are if there is nothing to drop.
+func Compact(conditions []metav1.Condition, max int) []metav1.Condition {
+	if len(conditions) <= max && !hasDuplicates(conditions) {
+		return conditions
+	}
+
+	latest := map[string]metav1.Condition{}
+	for _, condition := range conditions {
+		if existing, ok := latest[condition.Type]; !ok || existing.LastTransitionTime.Before(&condition.LastTransitionTime) {
+			latest[condition.Type] = condition
+		}
+	}
+
+	compacted := make([]metav1.Condition, 0, len(latest))
+	for _, condition := range conditions {
+		if kept, ok := latest[condition.Type]; ok && equalCondition(kept, condition) && kept.LastTransitionTime.Equal(&condition.LastTransitionTime) {
+			compacted = append(compacted, condition)
+			delete(latest, condition.Type)
+		}
+	}
+	if len(compacted) > max {
+		// the agent conditions go first, then the others from the one which transitioned last
+		sort.SliceStable(compacted, func(i, j int) bool {
+			if agentTypes[compacted[i].Type] != agentTypes[compacted[j].Type] {
+				return agentTypes[compacted[i].Type]
+			}
+			return compacted[j].LastTransitionTime.Before(&compacted[i].LastTransitionTime)
+		})
+		kept := 0
+		for kept < len(compacted) && (kept < max || agentTypes[compacted[kept].Type]) {
+			kept++
+		}
+		compacted = compacted[:kept]
+	}
+	return compacted
+}
+
+func hasDuplicates(conditions []metav1.Condition) bool {
+	for i := range conditions {
+		for j := i + 1; j < len(conditions); j++ {
+			if conditions[i].Type == conditions[j].Type {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEqual(t *testing.T) {
+	now := time.Now()
+	applied := metav1.Condition{Type: "Applied", Status: metav1.ConditionTrue, Reason: "AppliedWorkComplete", LastTransitionTime: metav1.NewTime(now)}
+	available := metav1.Condition{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available"}
+
+	withTime := applied
+	withTime.LastTransitionTime = metav1.NewTime(now.Truncate(time.Second))
+	withStatus := applied
+	withStatus.Status = metav1.ConditionFalse
+	withGeneration := applied
+	withGeneration.ObservedGeneration = 2
+
+	cases := []struct {
+		name     string
+		a, b     []metav1.Condition
+		expected bool
+	}{
+		{name: "both empty", expected: true},
+		{name: "transition time differs", a: []metav1.Condition{applied}, b: []metav1.Condition{withTime}, expected: true},
+		{name: "order differs", a: []metav1.Condition{applied, available}, b: []metav1.Condition{available, applied}, expected: true},
+		{name: "status differs", a: []metav1.Condition{applied}, b: []metav1.Condition{withStatus}},
+		{name: "observed generation differs", a: []metav1.Condition{applied}, b: []metav1.Condition{withGeneration}},
+		{name: "condition added", a: []metav1.Condition{applied}, b: []metav1.Condition{applied, available}},
+		{name: "condition type differs", a: []metav1.Condition{applied}, b: []metav1.Condition{available}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if actual := Equal(c.a, c.b); actual != c.expected {
+				t.Errorf("expected %v, got %v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompact(t *testing.T) {
+	now := time.Now()
+	newCondition := func(conditionType string, status metav1.ConditionStatus, age time.Duration) metav1.Condition {
+		return metav1.Condition{Type: conditionType, Status: status, LastTransitionTime: metav1.NewTime(now.Add(-age))}
+	}
+
+	cases := []struct {
+		name     string
+		input    []metav1.Condition
+		max      int
+		expected []metav1.Condition
+	}{
+		{
+			name:     "nothing to drop",
+			input:    []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, 0), newCondition("Available", metav1.ConditionTrue, 0)},
+			max:      2,
+			expected: []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, 0), newCondition("Available", metav1.ConditionTrue, 0)},
+		},
+		{
+			name:     "duplicated type keeps the latest",
+			input:    []metav1.Condition{newCondition("Applied", metav1.ConditionFalse, time.Hour), newCondition("Applied", metav1.ConditionTrue, 0)},
+			max:      2,
+			expected: []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, 0)},
+		},
+		{
+			name: "capped keeps the latest",
+			input: []metav1.Condition{
+				newCondition("Old", metav1.ConditionTrue, time.Hour),
+				newCondition("Applied", metav1.ConditionTrue, 0),
+				newCondition("Available", metav1.ConditionTrue, time.Minute),
+			},
+			max:      2,
+			expected: []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, 0), newCondition("Available", metav1.ConditionTrue, time.Minute)},
+		},
+		{
+			name: "capped keeps the agent conditions",
+			input: []metav1.Condition{
+				newCondition("Applied", metav1.ConditionTrue, time.Hour),
+				newCondition("Available", metav1.ConditionTrue, time.Hour),
+				newCondition("Custom", metav1.ConditionTrue, 0),
+				newCondition("Other", metav1.ConditionTrue, time.Minute),
+			},
+			max:      3,
+			expected: []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, time.Hour), newCondition("Available", metav1.ConditionTrue, time.Hour), newCondition("Custom", metav1.ConditionTrue, 0)},
+		},
+		{
+			name: "agent conditions beyond the cap",
+			input: []metav1.Condition{
+				newCondition("Custom", metav1.ConditionTrue, 0),
+				newCondition("Applied", metav1.ConditionTrue, time.Hour),
+				newCondition("Available", metav1.ConditionTrue, time.Hour),
+			},
+			max:      1,
+			expected: []metav1.Condition{newCondition("Applied", metav1.ConditionTrue, time.Hour), newCondition("Available", metav1.ConditionTrue, time.Hour)},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			actual := Compact(c.input, c.max)
+			if len(actual) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, actual)
+			}
+			for i := range actual {
+				if actual[i].Type != c.expected[i].Type || actual[i].Status != c.expected[i].Status {
+					t.Errorf("expected %v, got %v", c.expected, actual)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Types of the conditions of the works and their manifests.
+const (
+	// TypeApplied is true once the workload of a work, or a manifest, is applied on the spoke cluster.
+	TypeApplied = "Applied"
+	// TypeAvailable is true once the workload of a work, or a manifest, exists on the spoke cluster.
+	TypeAvailable = "Available"
+	// TypeDegraded is true when the workload of a work does not match its desired state for a while.
+	TypeDegraded = "Degraded"
+	// TypeProgressing is true while the workload of a work transitions from one state to another.
+	TypeProgressing = "Progressing"
+	// TypeExternallyManaged is true when resources of a work are managed on the spoke cluster.
+	TypeExternallyManaged = "ExternallyManaged"
+	// TypeSkipped is true on a manifest excluded from the reconciliation by the skip-manifests annotation.
+	TypeSkipped = "Skipped"
+	// TypeResourceRecreated is true on a manifest whose resource was found deleted and recreated by
+	// someone else when it was last applied.
+	TypeResourceRecreated = "ResourceRecreated"
+	// TypeManagedByConflict is true on a manifest whose resource had fields of other field managers,
+	// e.g. Helm, Argo CD or kubectl, overwritten when it was last updated.
+	TypeManagedByConflict = "ManagedByConflict"
+	// TypeComplete is true once the one-shot workloads of a work, or of a manifest, i.e. its Jobs
+	// and CronJobs, completed on the spoke cluster. It is not set on the other works.
+	TypeComplete = "Complete"
+	// TypePruned is false on a manifest removed from a work whose resource failed to be deleted
+	// from the spoke cluster. The manifest is reported until its resource is deleted.
+	TypePruned = "Pruned"
+	// TypeDeletionBlocked is true on a deleted work whose resources are not deleted from the spoke
+	// cluster yet, listing the resources still terminating with their finalizers, or failing to
+	// be deleted.
+	TypeDeletionBlocked = "DeletionBlocked"
+)
+
+// agentTypes are the types of the conditions of the works and their manifests set by the agent.
+var agentTypes = map[string]bool{
+	TypeApplied: true, TypeAvailable: true, TypeDegraded: true, TypeProgressing: true, TypeExternallyManaged: true,
+	TypeSkipped: true, TypeResourceRecreated: true, TypeManagedByConflict: true, TypeComplete: true, TypePruned: true,
+	TypeDeletionBlocked: true,
+}
+
+// Types of the conditions of the WorkAgentConfigs.
+const (
+	// TypeAccepted is true once the spec of a WorkAgentConfig is applied whole by the agent.
+	TypeAccepted = "Accepted"
+)
+
+// IsApplied returns true if the Applied condition is true.
+func IsApplied(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, TypeApplied)
+}
+
+// IsAvailable returns true if the Available condition is true.
+func IsAvailable(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, TypeAvailable)
+}
+
+// IsComplete returns true if the Complete condition is true.
+func IsComplete(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, TypeComplete)
+}
+
+// IsDegraded returns true if the Degraded condition is true.
+func IsDegraded(conditions []metav1.Condition) bool {
+	return meta.IsStatusConditionTrue(conditions, TypeDegraded)
+}
+
+// IsFresh returns true if the condition of the given type observed the generation, a condition
+// observing an older generation describes a spec which is not current anymore.
+func IsFresh(conditions []metav1.Condition, conditionType string, generation int64) bool {
+	condition := meta.FindStatusCondition(conditions, conditionType)
+	return condition != nil && condition.ObservedGeneration >= generation
+}
+
+// Set sets a condition observing the given generation, see meta.SetStatusCondition. A condition
+// which observed a newer generation is kept, as it was written from a more recent spec, and false
+// is returned.
+func Set(conditions *[]metav1.Condition, condition metav1.Condition, generation int64) bool {
+	if existing := meta.FindStatusCondition(*conditions, condition.Type); existing != nil && existing.ObservedGeneration > generation {
+		return false
+	}
+	condition.ObservedGeneration = generation
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
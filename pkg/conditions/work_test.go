@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conditions
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPredicates(t *testing.T) {
+	conditions := []metav1.Condition{
+		{Type: TypeApplied, Status: metav1.ConditionTrue, ObservedGeneration: 2},
+		{Type: TypeAvailable, Status: metav1.ConditionFalse, ObservedGeneration: 1},
+	}
+	if !IsApplied(conditions) || IsAvailable(conditions) || IsDegraded(conditions) {
+		t.Errorf("unexpected predicates on %v", conditions)
+	}
+	if !IsFresh(conditions, TypeApplied, 2) || IsFresh(conditions, TypeAvailable, 2) || IsFresh(conditions, TypeDegraded, 0) {
+		t.Errorf("unexpected freshness of %v", conditions)
+	}
+}
+
+func TestSet(t *testing.T) {
+	conditions := []metav1.Condition{}
+	if !Set(&conditions, metav1.Condition{Type: TypeApplied, Status: metav1.ConditionTrue, Reason: "Applied"}, 2) {
+		t.Fatal("expected the condition to be set")
+	}
+	if !IsFresh(conditions, TypeApplied, 2) {
+		t.Errorf("expected the condition to observe generation 2, got %v", conditions)
+	}
+
+	if Set(&conditions, metav1.Condition{Type: TypeApplied, Status: metav1.ConditionFalse, Reason: "Failed"}, 1) {
+		t.Error("expected the condition of an older generation to be ignored")
+	}
+	if !IsApplied(conditions) {
+		t.Errorf("expected the condition of generation 2 to be kept, got %v", conditions)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+// defaultFeatureGates are the feature gates of the agent which a WorkAgentConfig can toggle, with
+// their default.
+var defaultFeatureGates = map[string]bool{
+	workv1alpha1.FeatureApplyGenerationGating: true,
+	workv1alpha1.FeatureAvailabilityRecheck:   true,
+}
+
+// agentConfig holds the tuning of the agent set by its WorkAgentConfig at runtime. The settings
+// the WorkAgentConfig does not set keep the options the agent started with. Nothing is applied
+// until the WorkAgentConfig is loaded, or known not to exist, so the agent never applies the kinds
+// it forbids after a restart. A nil agentConfig overrides nothing.
+type agentConfig struct {
+	lock sync.Mutex
+	// loaded is closed once the WorkAgentConfig is loaded or known not to exist.
+	loaded chan struct{}
+	// slots holds a token for each work being applied, nil if the concurrency is not capped. It
+	// is replaced when the concurrency changes, the works applying then release the previous one.
+	slots chan struct{}
+
+	resyncInterval *time.Duration
+	// applyConcurrency caps the number of works applied concurrently, no cap if zero.
+	applyConcurrency int
+	// allowedGroupKinds are the kinds the agent applies, all of them if nil once loaded.
+	allowedGroupKinds map[schema.GroupKind]bool
+	featureGates      map[string]bool
+}
+
+func newAgentConfig() *agentConfig {
+	return &agentConfig{loaded: make(chan struct{})}
+}
+
+// update applies the spec of a WorkAgentConfig, or resets the settings if nil. It returns an
+// error listing the unknown feature gates, the other settings are applied anyway.
+func (c *agentConfig) update(spec *workv1alpha1.WorkAgentConfigSpec) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	applyConcurrency := 0
+	if spec != nil && spec.ApplyConcurrency != nil {
+		applyConcurrency = int(*spec.ApplyConcurrency)
+	}
+	if applyConcurrency != c.applyConcurrency {
+		c.slots = nil
+		if applyConcurrency > 0 {
+			c.slots = make(chan struct{}, applyConcurrency)
+		}
+	}
+
+	if !c.isLoaded() {
+		close(c.loaded)
+	}
+	c.resyncInterval, c.applyConcurrency, c.allowedGroupKinds, c.featureGates = nil, applyConcurrency, nil, nil
+	if spec == nil {
+		return nil
+	}
+	if spec.ResyncInterval != nil {
+		resyncInterval := spec.ResyncInterval.Duration
+		c.resyncInterval = &resyncInterval
+	}
+	if len(spec.AllowedGroupKinds) != 0 {
+		c.allowedGroupKinds = make(map[schema.GroupKind]bool, len(spec.AllowedGroupKinds))
+		for _, groupKind := range spec.AllowedGroupKinds {
+			c.allowedGroupKinds[schema.GroupKind{Group: groupKind.Group, Kind: groupKind.Kind}] = true
+		}
+	}
+	var unknown []string
+	c.featureGates = map[string]bool{}
+	for name, enabled := range spec.FeatureGates {
+		if _, ok := defaultFeatureGates[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		c.featureGates[name] = enabled
+	}
+	if len(unknown) != 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown feature gates %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// isLoaded returns whether the WorkAgentConfig is loaded or known not to exist.
+func (c *agentConfig) isLoaded() bool {
+	select {
+	case <-c.loaded:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitLoaded waits until the WorkAgentConfig is loaded or known not to exist, or the context is done.
+func (c *agentConfig) waitLoaded(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	select {
+	case <-c.loaded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resyncIntervalOr returns the resync interval of the WorkAgentConfig, or defaultInterval if unset.
+func (c *agentConfig) resyncIntervalOr(defaultInterval time.Duration) time.Duration {
+	if c == nil {
+		return defaultInterval
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.resyncInterval == nil {
+		return defaultInterval
+	}
+	return *c.resyncInterval
+}
+
+// allowed returns whether the resources of a kind may be applied, none may before the
+// WorkAgentConfig is loaded.
+func (c *agentConfig) allowed(groupKind schema.GroupKind) bool {
+	if c == nil {
+		return true
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.isLoaded() && (c.allowedGroupKinds == nil || c.allowedGroupKinds[groupKind])
+}
+
+// enabled returns whether a feature gate is enabled.
+func (c *agentConfig) enabled(gate string) bool {
+	if c == nil {
+		return defaultFeatureGates[gate]
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if enabled, ok := c.featureGates[gate]; ok {
+		return enabled
+	}
+	return defaultFeatureGates[gate]
+}
+
+// acquire waits until a work may be applied under the concurrency of the WorkAgentConfig, or the
+// context is done, and returns the function to call once it is applied.
+func (c *agentConfig) acquire(ctx context.Context) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+	c.lock.Lock()
+	slots := c.slots
+	c.lock.Unlock()
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// AgentConfigReconciler applies the WorkAgentConfig of the agent on the spoke cluster to its
+// agentConfig as soon as it changes.
+type AgentConfigReconciler struct {
+	spokeClient client.Client
+	log         logr.Logger
+	// name is the name of the WorkAgentConfig of the agent.
+	name   string
+	config *agentConfig
+}
+
+// Reconcile applies the WorkAgentConfig and reports whether it was accepted in its status.
+func (r *AgentConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	agentConfig := &workv1alpha1.WorkAgentConfig{}
+	err := r.spokeClient.Get(ctx, types.NamespacedName{Name: r.name}, agentConfig)
+	switch {
+	case errors.IsNotFound(err):
+		r.log.Info("resetting the agent configuration", "workAgentConfig", r.name)
+		return ctrl.Result{}, r.config.update(nil)
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	accepted := metav1.Condition{
+		Type:               conditions.TypeAccepted,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.AgentConfigApplied),
+		Message:            "The configuration is applied",
+		ObservedGeneration: agentConfig.Generation,
+	}
+	if err := r.config.update(&agentConfig.Spec); err != nil {
+		r.log.Error(err, "the agent configuration is not applied whole", "workAgentConfig", r.name)
+		accepted.Status, accepted.Reason, accepted.Message = metav1.ConditionFalse, string(reasons.AgentConfigInvalid), err.Error()
+	} else {
+		r.log.Info("applied the agent configuration", "workAgentConfig", r.name, "generation", agentConfig.Generation)
+	}
+
+	if existing := meta.FindStatusCondition(agentConfig.Status.Conditions, accepted.Type); existing != nil &&
+		agentConfig.Status.ObservedGeneration == agentConfig.Generation && existing.Status == accepted.Status &&
+		existing.Reason == accepted.Reason && existing.Message == accepted.Message {
+		return ctrl.Result{}, nil
+	}
+	original := agentConfig.DeepCopy()
+	agentConfig.Status.ObservedGeneration = agentConfig.Generation
+	meta.SetStatusCondition(&agentConfig.Status.Conditions, accepted)
+	return ctrl.Result{}, r.spokeClient.Status().Patch(ctx, agentConfig, client.MergeFrom(original), client.FieldOwner(statusFieldManager))
+}
+
+// SetupWithManager wires up the controller, watching the WorkAgentConfig in the cache of the spoke
+// cluster. It is also reconciled once on start, so the works are applied when there is none.
+func (r *AgentConfigReconciler) SetupWithManager(mgr ctrl.Manager, spokeCache cache.Cache) error {
+	isAgentConfig := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == r.name
+	})
+	initial := make(chan event.GenericEvent, 1)
+	initial <- event.GenericEvent{Object: &workv1alpha1.WorkAgentConfig{ObjectMeta: metav1.ObjectMeta{Name: r.name}}}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("WorkAgentConfig").
+		Watches(source.NewKindWithCache(&workv1alpha1.WorkAgentConfig{}, spokeCache), &handler.EnqueueRequestForObject{}).
+		Watches(&source.Channel{Source: initial}, &handler.EnqueueRequestForObject{}).
+		WithEventFilter(isAgentConfig).
+		Complete(r)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func TestAgentConfig(t *testing.T) {
+	var unset *agentConfig
+	if unset.resyncIntervalOr(time.Minute) != time.Minute || !unset.allowed(schema.GroupKind{Kind: "Secret"}) ||
+		!unset.enabled(workv1alpha1.FeatureApplyGenerationGating) {
+		t.Error("expected an unset agent config to override nothing")
+	}
+	release, err := unset.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	config := newAgentConfig()
+	if config.allowed(schema.GroupKind{Kind: "Secret"}) {
+		t.Error("expected no kind to be allowed before the config is loaded")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := config.waitLoaded(ctx); err == nil {
+		t.Error("expected the works to wait for the config to be loaded")
+	}
+	concurrency := int32(1)
+	err = config.update(&workv1alpha1.WorkAgentConfigSpec{
+		ResyncInterval:    &metav1.Duration{Duration: 5 * time.Minute},
+		ApplyConcurrency:  &concurrency,
+		AllowedGroupKinds: []metav1.GroupKind{{Group: "apps", Kind: "Deployment"}},
+		FeatureGates:      map[string]bool{workv1alpha1.FeatureAvailabilityRecheck: false, "Unknown": true},
+	})
+	if err == nil {
+		t.Error("expected the unknown feature gate to be reported")
+	}
+	if resync := config.resyncIntervalOr(time.Minute); resync != 5*time.Minute {
+		t.Errorf("expected the resync interval of the config, got %v", resync)
+	}
+	if config.allowed(schema.GroupKind{Kind: "Secret"}) || !config.allowed(schema.GroupKind{Group: "apps", Kind: "Deployment"}) {
+		t.Error("expected only the Deployments to be allowed")
+	}
+	if config.enabled(workv1alpha1.FeatureAvailabilityRecheck) || !config.enabled(workv1alpha1.FeatureApplyGenerationGating) {
+		t.Error("expected only the availability recheck to be disabled")
+	}
+
+	release, err = config.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.waitLoaded(context.Background()); err != nil {
+		t.Errorf("expected the config to be loaded, got %v", err)
+	}
+
+	// a work waiting for the concurrency gives up when its context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := config.acquire(ctx); err == nil {
+		t.Fatal("expected the acquire to be cancelled with its context")
+	}
+	acquired := make(chan struct{})
+	go func() {
+		if release, err := config.acquire(context.Background()); err == nil {
+			release()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the concurrency of the config to be enforced")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second work to be applied once the first one is")
+	}
+
+	if err := config.update(nil); err != nil || config.resyncIntervalOr(time.Minute) != time.Minute ||
+		!config.allowed(schema.GroupKind{Kind: "Secret"}) || !config.enabled(workv1alpha1.FeatureAvailabilityRecheck) {
+		t.Error("expected the reset config to override nothing")
+	}
+}
+
+func TestAgentConfigReconcile(t *testing.T) {
+	agentConfig := &workv1alpha1.WorkAgentConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "work-agent", Generation: 2},
+		Spec:       workv1alpha1.WorkAgentConfigSpec{ResyncInterval: &metav1.Duration{Duration: time.Hour}},
+	}
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(agentConfig).Build()
+	r := &AgentConfigReconciler{spokeClient: spokeClient, log: ctrl.Log, name: "work-agent", config: newAgentConfig()}
+
+	ctx := context.Background()
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "work-agent"}}); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if resync := r.config.resyncIntervalOr(time.Minute); resync != time.Hour {
+		t.Errorf("expected the resync interval of the config to be applied, got %v", resync)
+	}
+	actual := &workv1alpha1.WorkAgentConfig{}
+	if err := spokeClient.Get(ctx, types.NamespacedName{Name: "work-agent"}, actual); err != nil {
+		t.Fatalf("failed to get the config: %v", err)
+	}
+	if actual.Status.ObservedGeneration != 2 || !meta.IsStatusConditionTrue(actual.Status.Conditions, conditions.TypeAccepted) {
+		t.Errorf("expected the config to be accepted, got %+v", actual.Status)
+	}
+
+	if err := spokeClient.Delete(ctx, actual); err != nil {
+		t.Fatalf("failed to delete the config: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "work-agent"}}); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if resync := r.config.resyncIntervalOr(time.Minute); resync != time.Minute {
+		t.Errorf("expected the deleted config to be reset, got %v", resync)
+	}
+}
+
+func TestAgentConfigReconcileNotFound(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &AgentConfigReconciler{spokeClient: spokeClient, log: ctrl.Log, name: "work-agent", config: newAgentConfig()}
+
+	// the works are applied with the options of the agent once the config is known not to exist
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "work-agent"}}); err != nil {
+		t.Fatalf("failed to reconcile: %v", err)
+	}
+	if !r.config.isLoaded() || !r.config.allowed(schema.GroupKind{Kind: "Secret"}) {
+		t.Error("expected all the kinds to be allowed without a config")
+	}
+}
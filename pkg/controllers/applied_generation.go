@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// appliedGenerations remembers the generations of the works which were applied successfully, so
+// the works are not applied again on every reconcile, e.g. after their status is patched, while
+// their spec is unchanged. A work is applied again once the resync interval elapses, which is when
+// the drift of its resources on the spoke cluster is detected and corrected.
+type appliedGenerations struct {
+	lock    sync.Mutex
+	entries map[types.NamespacedName]appliedGenerationEntry
+}
+
+type appliedGenerationEntry struct {
+	generation int64
+	// inputs are the annotations of the work which change how it is applied without changing its generation.
+	inputs    string
+	appliedAt time.Time
+}
+
+func newAppliedGenerations() *appliedGenerations {
+	return &appliedGenerations{entries: map[types.NamespacedName]appliedGenerationEntry{}}
+}
+
+// upToDate returns whether a work was applied successfully at its current generation and with its
+// current annotations, and if so how long until it must be resynced. It is never up to date
+// before its status records the generation as observed.
+func (g *appliedGenerations) upToDate(work *workv1alpha1.Work, resyncInterval time.Duration, now time.Time) (bool, time.Duration) {
+	if g == nil || work.Status.ObservedGeneration != work.Generation {
+		return false, 0
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	entry, ok := g.entries[types.NamespacedName{Namespace: work.Namespace, Name: work.Name}]
+	if !ok || entry.generation != work.Generation || entry.inputs != applyInputs(work) {
+		return false, 0
+	}
+	if resyncInterval == 0 {
+		return true, 0
+	}
+	if resyncAfter := entry.appliedAt.Add(resyncInterval).Sub(now); resyncAfter > 0 {
+		return true, resyncAfter
+	}
+	return false, 0
+}
+
+// applied records that a work was applied successfully at its current generation.
+func (g *appliedGenerations) applied(work *workv1alpha1.Work, now time.Time) {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.entries[types.NamespacedName{Namespace: work.Namespace, Name: work.Name}] = appliedGenerationEntry{
+		generation: work.Generation,
+		inputs:     applyInputs(work),
+		appliedAt:  now,
+	}
+}
+
+// forget forgets a work, it is applied on its next reconcile.
+func (g *appliedGenerations) forget(work types.NamespacedName) {
+	if g == nil {
+		return
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	delete(g.entries, work)
+}
+
+// applyInputAnnotations are the annotations of a work which change how it is applied without
+// changing its generation.
+var applyInputAnnotations = []string{
+	workv1alpha1.SkipManifestsAnnotation,
+	workv1alpha1.RestartedAtAnnotation,
+	workv1alpha1.ResyncAnnotation,
+	workv1alpha1.OptionalManifestsAnnotation,
+	workv1alpha1.AvailabilityPolicyAnnotation,
+	workv1alpha1.FieldManagerAnnotation,
+	workv1alpha1.TakeOverFieldManagersAnnotation,
+}
+
+// applyInputs returns the annotations of a work which change how it is applied.
+func applyInputs(work *workv1alpha1.Work) string {
+	inputs := make([]string, 0, len(applyInputAnnotations))
+	for _, annotation := range applyInputAnnotations {
+		inputs = append(inputs, work.Annotations[annotation])
+	}
+	return strings.Join(inputs, "\x00")
+}
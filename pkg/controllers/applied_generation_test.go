@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestAppliedGenerations(t *testing.T) {
+	now := time.Now()
+	resyncInterval := 10 * time.Minute
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "cluster1", Name: "work", Generation: 2},
+		Status:     workv1alpha1.WorkStatus{ObservedGeneration: 2},
+	}
+	generations := newAppliedGenerations()
+	if upToDate, _ := generations.upToDate(work, resyncInterval, now); upToDate {
+		t.Fatal("expected a work never applied by the agent not to be up to date")
+	}
+
+	generations.applied(work, now)
+	if upToDate, resyncAfter := generations.upToDate(work, resyncInterval, now.Add(time.Minute)); !upToDate || resyncAfter != 9*time.Minute {
+		t.Errorf("expected the work to be up to date until its resync, got %v and %s", upToDate, resyncAfter)
+	}
+	if upToDate, _ := generations.upToDate(work, resyncInterval, now.Add(resyncInterval)); upToDate {
+		t.Error("expected the work to be applied again once its resync interval elapsed")
+	}
+	if upToDate, resyncAfter := generations.upToDate(work, 0, now.Add(time.Hour)); !upToDate || resyncAfter != 0 {
+		t.Errorf("expected the work to stay up to date without resync, got %v and %s", upToDate, resyncAfter)
+	}
+
+	for name, modify := range map[string]func(*workv1alpha1.Work){
+		"new generation":        func(w *workv1alpha1.Work) { w.Generation = 3 },
+		"unobserved generation": func(w *workv1alpha1.Work) { w.Status.ObservedGeneration = 1 },
+		"skipped manifests": func(w *workv1alpha1.Work) {
+			w.Annotations = map[string]string{workv1alpha1.SkipManifestsAnnotation: "v1/ConfigMap/default/cm"}
+		},
+		"restart": func(w *workv1alpha1.Work) {
+			w.Annotations = map[string]string{workv1alpha1.RestartedAtAnnotation: now.String()}
+		},
+		"resync": func(w *workv1alpha1.Work) { w.Annotations = map[string]string{workv1alpha1.ResyncAnnotation: "1"} },
+	} {
+		modified := work.DeepCopy()
+		modify(modified)
+		if upToDate, _ := generations.upToDate(modified, resyncInterval, now); upToDate {
+			t.Errorf("expected a work with a %s to be applied again", name)
+		}
+	}
+
+	generations.forget(types.NamespacedName{Namespace: "cluster1", Name: "work"})
+	if upToDate, _ := generations.upToDate(work, resyncInterval, now); upToDate {
+		t.Error("expected a forgotten work to be applied again")
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Reasons of the events recorded on the AppliedWork on the spoke cluster.
+const (
+	eventReasonResourceApplied     = "ResourceApplied"
+	eventReasonResourceApplyFailed = "ResourceApplyFailed"
+	eventReasonResourceRecreated   = "ResourceRecreated"
+	eventReasonManagedByConflict   = "ManagedByConflict"
+	eventReasonResourcePruned      = "ResourcePruned"
+	eventReasonResourcePruneFailed = "ResourcePruneFailed"
+)
+
+// appliedWorkWorkIndex indexes the AppliedWorks in the spoke cache by the namespace/name of their work.
+const appliedWorkWorkIndex = "spec.work"
+
+// indexAppliedWorkByWork returns the index values of an AppliedWork for appliedWorkWorkIndex.
+func indexAppliedWorkByWork(obj client.Object) []string {
+	appliedWork, ok := obj.(*workv1alpha1.AppliedWork)
+	if !ok {
+		return nil
+	}
+	return []string{types.NamespacedName{Namespace: appliedWork.Spec.WorkNamespace, Name: appliedWork.Spec.WorkName}.String()}
+}
+
+// findAppliedWork returns the AppliedWork of a work from the spoke cache, or nil if there is none.
+// It is looked up by its work, since the AppliedWorks created before they were named with
+// AppliedWorkName are named after the work alone.
+func findAppliedWork(ctx context.Context, spokeClient client.Reader, work *workv1alpha1.Work) (*workv1alpha1.AppliedWork, error) {
+	appliedWorks := &workv1alpha1.AppliedWorkList{}
+	if err := spokeClient.List(ctx, appliedWorks, client.MatchingFields{appliedWorkWorkIndex: client.ObjectKeyFromObject(work).String()}); err != nil {
+		return nil, err
+	}
+	if len(appliedWorks.Items) == 0 {
+		return nil, nil
+	}
+	return &appliedWorks.Items[0], nil
+}
+
+// ensureAppliedWork returns the AppliedWork of a work on the spoke cluster, it is created if it does not exist.
+func ensureAppliedWork(ctx context.Context, spokeClient client.Client, work *workv1alpha1.Work) (*workv1alpha1.AppliedWork, error) {
+	appliedWork, err := findAppliedWork(ctx, spokeClient, work)
+	if err != nil || appliedWork != nil {
+		return appliedWork, err
+	}
+
+	appliedWork = &workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: workv1alpha1.AppliedWorkName(work.Namespace, work.Name)},
+		Spec: workv1alpha1.AppliedWorkSpec{
+			WorkName:      work.Name,
+			WorkNamespace: work.Namespace,
+		},
+	}
+	// the AppliedWork may not be in the cache yet
+	if err := spokeClient.Create(ctx, appliedWork); err != nil {
+		return nil, fmt.Errorf("failed to create appliedwork %s: %w", appliedWork.Name, err)
+	}
+	return appliedWork, nil
+}
+
+// buildAppliedResources returns the resources applied by a work. A resource which is skipped or
+// fails to apply this time is kept if it was applied before, so it is not pruned. So are the
+// resources applied before at the ordinal of a manifest which failed before its resource was
+// known, e.g. to be decoded or mapped while the discovery of the spoke cluster is unavailable.
+func buildAppliedResources(results []applyResult, previous []workv1alpha1.AppliedResourceMeta) []workv1alpha1.AppliedResourceMeta {
+	appliedResources := []workv1alpha1.AppliedResourceMeta{}
+	unknown := []workv1alpha1.AppliedResourceMeta{}
+	for _, result := range results {
+		if result.identifier.Resource == "" {
+			if result.err != nil {
+				for _, resource := range previous {
+					if resource.Ordinal == result.identifier.Ordinal {
+						unknown = append(unknown, resource)
+					}
+				}
+			}
+			continue
+		}
+		if result.uid != "" {
+			appliedResources = append(appliedResources, workv1alpha1.AppliedResourceMeta{
+				ResourceIdentifier: result.identifier,
+				UID:                result.uid,
+			})
+			continue
+		}
+		if existing := findAppliedResource(result.identifier, previous); existing != nil {
+			kept := *existing
+			kept.Ordinal = result.identifier.Ordinal
+			appliedResources = append(appliedResources, kept)
+		}
+	}
+	// the resource may have moved to another manifest applied this time
+	for _, resource := range unknown {
+		if findAppliedResource(resource.ResourceIdentifier, appliedResources) == nil {
+			appliedResources = append(appliedResources, resource)
+		}
+	}
+	return appliedResources
+}
+
+// findStaleResources returns the resources applied before which are no longer in the work.
+func findStaleResources(previous, current []workv1alpha1.AppliedResourceMeta) []workv1alpha1.AppliedResourceMeta {
+	stale := []workv1alpha1.AppliedResourceMeta{}
+	for _, resource := range previous {
+		if findAppliedResource(resource.ResourceIdentifier, current) == nil {
+			stale = append(stale, resource)
+		}
+	}
+	return stale
+}
+
+// findAppliedResource returns the applied resource with the same identity, ignoring the ordinal
+// and the version as the same resource may be applied through another version.
+func findAppliedResource(identifier workv1alpha1.ResourceIdentifier, appliedResources []workv1alpha1.AppliedResourceMeta) *workv1alpha1.AppliedResourceMeta {
+	identifier.Ordinal, identifier.Version = 0, ""
+	for i := range appliedResources {
+		candidate := appliedResources[i].ResourceIdentifier
+		candidate.Ordinal, candidate.Version = 0, ""
+		if candidate == identifier {
+			return &appliedResources[i]
+		}
+	}
+	return nil
+}
+
+// pruneAppliedResources deletes the resources applied before which are no longer in the work and
+// records the result on the AppliedWork. The resources which fail to be deleted are returned, with
+// their errors at the same index.
+func pruneAppliedResources(
+	ctx context.Context,
+	applier Applier,
+	recorder record.EventRecorder,
+	appliedWork *workv1alpha1.AppliedWork,
+	stale []workv1alpha1.AppliedResourceMeta) ([]workv1alpha1.AppliedResourceMeta, []error) {
+	remaining := []workv1alpha1.AppliedResourceMeta{}
+	errs := []error{}
+	for _, resource := range stale {
+		deleted, err := applier.Delete(ctx, resource)
+		switch {
+		case err != nil:
+			recorder.Eventf(appliedWork, corev1.EventTypeWarning, eventReasonResourcePruneFailed,
+				"Failed to delete %s: %v", describeResource(resource.ResourceIdentifier), err)
+			remaining = append(remaining, resource)
+			errs = append(errs, err)
+		case deleted:
+			recorder.Eventf(appliedWork, corev1.EventTypeNormal, eventReasonResourcePruned, "Deleted %s", describeResource(resource.ResourceIdentifier))
+		}
+	}
+	return remaining, errs
+}
+
+// resolveGeneratedName sets the name of a manifest using generateName to the name generated by
+// the server when it was first applied, found among the resources applied by the work for the same
+// ordinal. The manifest is left without a name, so it is created, if it was not applied before.
+func resolveGeneratedName(ordinal int, obj *unstructured.Unstructured, gvr schema.GroupVersionResource, appliedResources []workv1alpha1.AppliedResourceMeta) {
+	if obj.GetName() != "" || obj.GetGenerateName() == "" {
+		return
+	}
+	for _, resource := range appliedResources {
+		if resource.Ordinal == ordinal && resource.Group == gvr.Group && resource.Resource == gvr.Resource &&
+			resource.Namespace == obj.GetNamespace() && strings.HasPrefix(resource.Name, obj.GetGenerateName()) {
+			obj.SetName(resource.Name)
+			return
+		}
+	}
+}
+
+// describeResource returns a human readable identity of a resource for the events.
+func describeResource(identifier workv1alpha1.ResourceIdentifier) string {
+	resource := identifier.Resource
+	if identifier.Group != "" {
+		resource += "." + identifier.Group
+	}
+	if identifier.Namespace == "" {
+		return fmt.Sprintf("%s %s", resource, identifier.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", resource, identifier.Namespace, identifier.Name)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestResolveGeneratedName(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	appliedResources := []workv1alpha1.AppliedResourceMeta{
+		{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Ordinal: 0, Group: "batch", Resource: "jobs", Namespace: "default", Name: "migrate-abcde"}},
+		{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Ordinal: 1, Group: "batch", Resource: "jobs", Namespace: "default", Name: "migrate-fghij"}},
+		{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Ordinal: 2, Group: "batch", Resource: "jobs", Namespace: "default", Name: "backup"}},
+	}
+	cases := []struct {
+		name         string
+		ordinal      int
+		objName      string
+		generateName string
+		expected     string
+	}{
+		{name: "generated for the same ordinal", ordinal: 1, generateName: "migrate-", expected: "migrate-fghij"},
+		{name: "not applied yet", ordinal: 3, generateName: "migrate-"},
+		{name: "another prefix", ordinal: 2, generateName: "migrate-"},
+		{name: "named", ordinal: 0, objName: "job", generateName: "migrate-", expected: "job"},
+		{name: "no generateName", ordinal: 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("batch/v1")
+			obj.SetKind("Job")
+			obj.SetNamespace("default")
+			obj.SetName(c.objName)
+			obj.SetGenerateName(c.generateName)
+			resolveGeneratedName(c.ordinal, obj, gvr, appliedResources)
+			if obj.GetName() != c.expected {
+				t.Errorf("expected name %q, got %q", c.expected, obj.GetName())
+			}
+		})
+	}
+}
+
+func TestBuildAppliedResourcesKeepsUnmappedResources(t *testing.T) {
+	secret := &unstructured.Unstructured{}
+	secret.SetAPIVersion("v1")
+	secret.SetKind("Secret")
+	secret.SetNamespace("default")
+	secret.SetName("secret")
+	rawSecret, err := secret.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawConfigMap, err := newTestConfigMap(t, "cm").MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := &workv1alpha1.Work{}
+	work.Namespace, work.Name = "cluster1", "work"
+	work.Spec.Workload.Manifests = []workv1alpha1.Manifest{
+		{RawExtension: runtime.RawExtension{Raw: rawSecret}},
+		{RawExtension: runtime.RawExtension{Raw: rawConfigMap}},
+	}
+	previous := []workv1alpha1.AppliedResourceMeta{
+		{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "Secret", Resource: "secrets", Namespace: "default", Name: "secret"}, UID: "secret-uid"},
+		{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Ordinal: 1, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "cm"}, UID: "cm-uid"},
+	}
+
+	// the REST mapper of the test applier does not know the Secrets, like during a discovery outage
+	applier, _ := newTestKubeApplier(t)
+	r := &ApplyWorkReconciler{
+		applier:     applier,
+		backoff:     newManifestBackoff(),
+		decodeCache: utilcache.NewLRUExpireCache(decodeCacheSize),
+	}
+	results := r.applyManifests(context.Background(), logr.Discard(), work, previous, false)
+	if results[0].err == nil || results[0].identifier.Resource != "" {
+		t.Fatalf("expected the secret to fail to be mapped, got %+v", results[0])
+	}
+
+	appliedResources := buildAppliedResources(results, previous)
+	if stale := findStaleResources(previous, appliedResources); len(stale) != 0 {
+		t.Fatalf("expected no resource to be pruned, got %v", stale)
+	}
+	if len(appliedResources) != 2 {
+		t.Fatalf("expected both resources to be kept, got %v", appliedResources)
+	}
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Applier creates, updates and deletes the resources of the Works on the clusters they target.
+// The agent applies to its spoke cluster by default. Another Applier can be set in the Options to
+// apply to remote clusters, such as vcluster instances, or to fan out to several of them.
+type Applier interface {
+	// RESTMapper maps the kinds of the manifests to their resources.
+	RESTMapper() meta.RESTMapper
+
+	// Apply creates or updates a resource and returns it as applied, with whether it was created
+	// or updated and the other field managers whose fields the update overwrote, see
+	// overwrittenManagers. An externally managed resource is returned as is. observedGeneration is the
+	// generation of the resource when it was last applied, the resource is updated if it changed.
+	// A negative observedGeneration forces the update. If required has a UID, it is the UID of the
+	// resource when it was last applied, a live resource with another UID is returned as is with
+	// an error wrapping ErrResourceRecreated. The resource is applied as the field manager of the
+	// options, which takes over the fields of the managers to take over.
+	Apply(ctx context.Context, gvr schema.GroupVersionResource, required *unstructured.Unstructured,
+		observedGeneration int64, opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error)
+
+	// Delete deletes a resource applied before and returns whether it was deleted. A resource
+	// recreated by someone else, or externally managed, is left in place. If the UID of the
+	// resource is empty, it is deleted as long as it was applied by an agent.
+	Delete(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (bool, error)
+
+	// Get returns the live resource of a resource applied before, or nil if it is gone.
+	Get(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (*unstructured.Unstructured, error)
+}
+
+// ApplyOptions are the options a resource is applied with, from the Work it is applied for.
+type ApplyOptions struct {
+	// FieldManager is the field manager the resource is created or updated as, work-agent if empty.
+	FieldManager string
+
+	// TakeOverFieldManagers are the field managers, e.g. kubectl-client-side-apply or helm, whose
+	// fields are transferred to FieldManager, so the resource is owned by the agent alone.
+	TakeOverFieldManagers []string
+}
+
+func (o ApplyOptions) fieldManager() string {
+	if o.FieldManager == "" {
+		return applyFieldManager
+	}
+	return o.FieldManager
+}
+
+// kubeApplier applies the resources to a Kubernetes cluster.
+type kubeApplier struct {
+	client     dynamic.Interface
+	reader     *spokeReader
+	restMapper meta.RESTMapper
+}
+
+// NewKubeApplier returns an Applier of the cluster of a config, e.g. from the kubeconfig of a
+// remote cluster or of a vcluster. The discovery, caching and rate limit options apply to it.
+func NewKubeApplier(ctx context.Context, cfg *rest.Config, agentOpts Options) (Applier, error) {
+	cfg = countSpokeRequests(limitSpokeRate(cfg, agentOpts))
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	restMapper, err := newCachedRESTMapper(cfg, agentOpts.DiscoveryTTL)
+	if err != nil {
+		return nil, err
+	}
+	return newKubeApplier(dynamicClient, newSpokeReader(ctx, dynamicClient, agentOpts.CacheSpokeResources), restMapper), nil
+}
+
+func newKubeApplier(client dynamic.Interface, reader *spokeReader, restMapper meta.RESTMapper) *kubeApplier {
+	return &kubeApplier{client: client, reader: reader, restMapper: restMapper}
+}
+
+func (a *kubeApplier) RESTMapper() meta.RESTMapper {
+	return a.restMapper
+}
+
+func (a *kubeApplier) Apply(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	required *unstructured.Unstructured,
+	observedGeneration int64,
+	opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error) {
+	// the name of a manifest using generateName is generated by the server when it is created
+	if required.GetName() == "" {
+		return a.create(ctx, gvr, required, opts)
+	}
+	existing, err := a.reader.Get(ctx, gvr, required.GetNamespace(), required.GetName())
+	if errors.IsNotFound(err) {
+		return a.create(ctx, gvr, required, opts)
+	}
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	// leave the resource to the spoke admin who opted it out
+	if isExternallyManaged(existing) {
+		return existing, false, nil, nil
+	}
+	if uid := required.GetUID(); uid != "" && existing.GetUID() != uid {
+		return existing, false, nil, fmt.Errorf("%w: it was applied with the UID %s, it now has the UID %s", ErrResourceRecreated, uid, existing.GetUID())
+	}
+
+	// the fields taken over are owned by the agent before its update, they are not overwritten
+	before := existing.GetManagedFields()
+	takenOver, err := takeOverManagedFields(before, opts.TakeOverFieldManagers, opts.fieldManager())
+	if err != nil {
+		return existing, false, nil, err
+	}
+
+	// Compare and update the unstrcuctured, the applied time only changes when the resource is updated.
+	setAnnotation(required, workv1alpha1.AppliedTimeAnnotation, existing.GetAnnotations()[workv1alpha1.AppliedTimeAnnotation])
+	if isManifestModified(observedGeneration, gvr, existing, required) || takenOver != nil {
+		setAnnotation(required, workv1alpha1.AppliedTimeAnnotation, time.Now().UTC().Format(time.RFC3339))
+		required.SetResourceVersion(existing.GetResourceVersion())
+		if takenOver != nil {
+			// the managed fields of an update replace the ones of the resource
+			required.SetManagedFields(takenOver)
+			before = takenOver
+		}
+		actual, err := a.client.Resource(gvr).Namespace(required.GetNamespace()).Update(
+			ctx, required, metav1.UpdateOptions{FieldManager: opts.fieldManager()})
+		if err != nil {
+			return actual, true, nil, err
+		}
+		return actual, true, overwrittenManagers(before, actual.GetManagedFields(), opts.fieldManager()), nil
+	}
+
+	return existing, false, nil, nil
+}
+
+func (a *kubeApplier) create(ctx context.Context, gvr schema.GroupVersionResource, required *unstructured.Unstructured, opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error) {
+	required.SetUID("")
+	setAnnotation(required, workv1alpha1.AppliedTimeAnnotation, time.Now().UTC().Format(time.RFC3339))
+	actual, err := a.client.Resource(gvr).Namespace(required.GetNamespace()).Create(
+		ctx, required, metav1.CreateOptions{FieldManager: opts.fieldManager()})
+	return actual, true, nil, err
+}
+
+func (a *kubeApplier) Delete(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (bool, error) {
+	gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+	live, err := a.client.Resource(gvr).Namespace(resource.Namespace).Get(ctx, resource.Name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	if isExternallyManaged(live) || !live.GetDeletionTimestamp().IsZero() {
+		return false, nil
+	}
+	uid := resource.UID
+	if uid == "" {
+		if _, ok := live.GetAnnotations()[specHashAnnotation]; !ok {
+			return false, nil
+		}
+		uid = live.GetUID()
+	}
+	if live.GetUID() != uid {
+		return false, nil
+	}
+
+	err = a.client.Resource(gvr).Namespace(resource.Namespace).Delete(ctx, resource.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+	if errors.IsNotFound(err) || errors.IsConflict(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (a *kubeApplier) Get(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (*unstructured.Unstructured, error) {
+	gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+	live, err := a.client.Resource(gvr).Namespace(resource.Namespace).Get(ctx, resource.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return live, err
+}
+
+// fanOutApplier applies the resources to a primary target and copies them to secondary targets.
+type fanOutApplier struct {
+	primary     Applier
+	secondaries []Applier
+
+	// generations are the generations of the resources of the secondary targets when they were
+	// last applied, the generation of the primary target says nothing about them.
+	lock        sync.Mutex
+	generations map[secondaryResource]int64
+}
+
+// secondaryResource is a resource of a secondary target.
+type secondaryResource struct {
+	target    int
+	gvr       schema.GroupVersionResource
+	namespace string
+	name      string
+}
+
+// NewFanOutApplier returns an Applier which applies the resources to all the targets, so one agent
+// serves several child clusters. The resources of the primary target are the ones tracked in the
+// status and the AppliedWorks. The copies on the secondary targets are annotated with the UID of
+// the resource of the primary target, they are updated when they change and deleted along with
+// the resource of the primary target they were copied from. An error of any target fails the
+// resource.
+func NewFanOutApplier(primary Applier, secondaries ...Applier) Applier {
+	return &fanOutApplier{primary: primary, secondaries: secondaries, generations: map[secondaryResource]int64{}}
+}
+
+func (a *fanOutApplier) RESTMapper() meta.RESTMapper {
+	return a.primary.RESTMapper()
+}
+
+func (a *fanOutApplier) Apply(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	required *unstructured.Unstructured,
+	observedGeneration int64,
+	opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error) {
+	copies := make([]*unstructured.Unstructured, len(a.secondaries))
+	for i := range a.secondaries {
+		// the UID is the one of the resource of the primary target
+		copies[i] = required.DeepCopy()
+		copies[i].SetUID("")
+	}
+	actual, updated, overwritten, err := a.primary.Apply(ctx, gvr, required, observedGeneration, opts)
+	if err != nil {
+		return actual, updated, overwritten, err
+	}
+
+	managers := map[string]bool{}
+	for _, manager := range overwritten {
+		managers[manager] = true
+	}
+	errs := []error{}
+	for i, secondary := range a.secondaries {
+		// the resources of a manifest using generateName have the name generated by the primary target
+		if copies[i].GetName() == "" {
+			copies[i].SetName(actual.GetName())
+		}
+		setAnnotation(copies[i], primaryUIDAnnotation, string(actual.GetUID()))
+		key := secondaryResource{target: i, gvr: gvr, namespace: copies[i].GetNamespace(), name: copies[i].GetName()}
+		secondaryActual, secondaryUpdated, secondaryOverwritten, err := secondary.Apply(ctx, gvr, copies[i], a.generation(key), opts)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			a.setGeneration(key, secondaryActual.GetGeneration())
+		}
+		updated = updated || secondaryUpdated
+		for _, manager := range secondaryOverwritten {
+			managers[manager] = true
+		}
+	}
+	return actual, updated, sortedKeys(managers), utilerrors.NewAggregate(errs)
+}
+
+// generation returns the generation of a resource of a secondary target when it was last applied,
+// or -1 to update it if it was not applied since the agent started.
+func (a *fanOutApplier) generation(key secondaryResource) int64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if generation, ok := a.generations[key]; ok {
+		return generation
+	}
+	return -1
+}
+
+func (a *fanOutApplier) setGeneration(key secondaryResource, generation int64) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.generations[key] = generation
+}
+
+func (a *fanOutApplier) Delete(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (bool, error) {
+	deleted, err := a.primary.Delete(ctx, resource)
+	if err != nil {
+		return false, err
+	}
+
+	errs := []error{}
+	gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+	for i, secondary := range a.secondaries {
+		secondaryDeleted, err := a.deleteCopy(ctx, secondary, resource)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		a.lock.Lock()
+		delete(a.generations, secondaryResource{target: i, gvr: gvr, namespace: resource.Namespace, name: resource.Name})
+		a.lock.Unlock()
+		deleted = deleted || secondaryDeleted
+	}
+	return deleted, utilerrors.NewAggregate(errs)
+}
+
+// deleteCopy deletes the copy of a resource on a secondary target, only if it was copied from
+// the resource of the primary target, so the resources of the same name applied for another Work
+// or by someone else are left in place.
+func (a *fanOutApplier) deleteCopy(ctx context.Context, secondary Applier, resource workv1alpha1.AppliedResourceMeta) (bool, error) {
+	if resource.UID == "" {
+		return false, nil
+	}
+	byName := resource
+	byName.UID = types.UID("")
+	live, err := secondary.Get(ctx, byName)
+	if err != nil || live == nil || live.GetAnnotations()[primaryUIDAnnotation] != string(resource.UID) {
+		return false, err
+	}
+	copied := resource
+	copied.UID = live.GetUID()
+	return secondary.Delete(ctx, copied)
+}
+
+// Get returns the resource of the primary target, the one tracked in the AppliedWorks.
+func (a *fanOutApplier) Get(ctx context.Context, resource workv1alpha1.AppliedResourceMeta) (*unstructured.Unstructured, error) {
+	return a.primary.Get(ctx, resource)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func newTestKubeApplier(t *testing.T, objects ...runtime.Object) (*kubeApplier, *dynamicfake.FakeDynamicClient) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := worktest.NewFakeSpokeDynamicClient(scheme, objects...)
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	return newKubeApplier(client, newSpokeReader(context.Background(), client, false), restMapper), client
+}
+
+func newTestConfigMap(t *testing.T, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("default")
+	obj.SetName(name)
+	if err := unstructured.SetNestedStringMap(obj.Object, map[string]string{"key": "value"}, "data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := setSpecHashAnnotation(obj); err != nil {
+		t.Fatal(err)
+	}
+	return obj
+}
+
+func TestFanOutApplierApply(t *testing.T) {
+	primary, primaryClient := newTestKubeApplier(t)
+	secondary, secondaryClient := newTestKubeApplier(t)
+	applier := NewFanOutApplier(primary, secondary)
+
+	actual, updated, _, err := applier.Apply(context.Background(), configMapGVR, newTestConfigMap(t, "cm"), 0, ApplyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated || actual.GetName() != "cm" {
+		t.Fatalf("expected the configmap to be created, got %v, %v", actual, updated)
+	}
+	for _, client := range []*dynamicfake.FakeDynamicClient{primaryClient, secondaryClient} {
+		if _, err := client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "cm", metav1.GetOptions{}); err != nil {
+			t.Fatalf("expected the configmap on every target: %v", err)
+		}
+	}
+
+	// the secondary target is not updated for the generation of the primary target
+	actual.SetGeneration(2)
+	if _, err := primaryClient.Resource(configMapGVR).Namespace("default").Update(context.Background(), actual, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, updated, _, err := applier.Apply(context.Background(), configMapGVR, newTestConfigMap(t, "cm"), 2, ApplyOptions{}); err != nil || updated {
+		t.Fatalf("expected the unchanged configmap not to be updated on any target, got %v, %v", updated, err)
+	}
+
+	worktest.FailOn(secondaryClient, "*", "configmaps", fmt.Errorf("unreachable"))
+	actual, _, _, err = applier.Apply(context.Background(), configMapGVR, newTestConfigMap(t, "cm"), 0, ApplyOptions{})
+	if err == nil {
+		t.Fatal("expected the failure of the secondary target")
+	}
+	if actual == nil || actual.GetName() != "cm" {
+		t.Fatalf("expected the resource of the primary target, got %v", actual)
+	}
+}
+
+func TestFanOutApplierDelete(t *testing.T) {
+	applied := newTestConfigMap(t, "cm")
+	applied.SetUID("uid")
+	primary, primaryClient := newTestKubeApplier(t, applied.DeepCopy())
+	unmanaged := newTestConfigMap(t, "cm")
+	unmanaged.SetAnnotations(nil)
+	secondary, secondaryClient := newTestKubeApplier(t, unmanaged)
+	copiedConfigMap := newTestConfigMap(t, "cm")
+	setAnnotation(copiedConfigMap, primaryUIDAnnotation, "uid")
+	copied, copiedClient := newTestKubeApplier(t, copiedConfigMap)
+	other := newTestConfigMap(t, "cm")
+	setAnnotation(other, primaryUIDAnnotation, "other")
+	otherWork, otherWorkClient := newTestKubeApplier(t, other)
+	applier := NewFanOutApplier(primary, secondary, copied, otherWork)
+
+	resource := workv1alpha1.AppliedResourceMeta{
+		ResourceIdentifier: workv1alpha1.ResourceIdentifier{
+			Version:   "v1",
+			Resource:  "configmaps",
+			Namespace: "default",
+			Name:      "cm",
+		},
+		UID: "uid",
+	}
+	deleted, err := applier.Delete(context.Background(), resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deleted {
+		t.Fatal("expected the configmap to be deleted")
+	}
+
+	tests := []struct {
+		name   string
+		client *dynamicfake.FakeDynamicClient
+		exists bool
+	}{
+		{name: "primary", client: primaryClient},
+		{name: "not applied by an agent", client: secondaryClient, exists: true},
+		{name: "copied from the primary target", client: copiedClient},
+		{name: "copied from another resource", client: otherWorkClient, exists: true},
+	}
+	for _, tt := range tests {
+		_, err := tt.client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "cm", metav1.GetOptions{})
+		if exists := !errors.IsNotFound(err); exists != tt.exists {
+			t.Errorf("%s: expected the configmap to exist %v, got %v", tt.name, tt.exists, exists)
+		}
+	}
+}
+
+func TestKubeApplierApplyRecreated(t *testing.T) {
+	recreated := newTestConfigMap(t, "cm")
+	recreated.SetUID("recreated")
+	unstructured.RemoveNestedField(recreated.Object, "data")
+	applier, _ := newTestKubeApplier(t, recreated)
+
+	required := newTestConfigMap(t, "cm")
+	required.SetUID("applied")
+	actual, updated, _, err := applier.Apply(context.Background(), configMapGVR, required, 0, ApplyOptions{})
+	if !isResourceRecreated(err) {
+		t.Fatalf("expected the configmap to be reported recreated, got %v", err)
+	}
+	if updated || actual.GetUID() != "recreated" {
+		t.Fatalf("expected the recreated configmap to be returned untouched, got %v, %v", actual, updated)
+	}
+
+	required.SetUID("recreated")
+	if _, updated, _, err := applier.Apply(context.Background(), configMapGVR, required, 0, ApplyOptions{}); err != nil || !updated {
+		t.Fatalf("expected the configmap of the applied UID to be updated, got %v, %v", updated, err)
+	}
+}
+
+func TestKubeApplierApplyTakeOver(t *testing.T) {
+	existing := newTestConfigMap(t, "cm")
+	existing.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:    "kubectl-client-side-apply",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:key":{}}}`)},
+	}})
+	applier, client := newTestKubeApplier(t, existing)
+
+	opts := ApplyOptions{FieldManager: "work-agent-cm", TakeOverFieldManagers: []string{"kubectl-client-side-apply"}}
+	_, updated, overwritten, err := applier.Apply(context.Background(), configMapGVR, newTestConfigMap(t, "cm"), 0, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated || overwritten != nil {
+		t.Fatalf("expected the unchanged configmap to be updated to take over its fields without conflict, got %v, %v", updated, overwritten)
+	}
+	live, err := client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "cm", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if managedFields := live.GetManagedFields(); len(managedFields) != 1 || managedFields[0].Manager != "work-agent-cm" {
+		t.Errorf("expected the fields to be owned by the agent, got %+v", managedFields)
+	}
+
+	if _, updated, _, err := applier.Apply(context.Background(), configMapGVR, newTestConfigMap(t, "cm"), 0, opts); err != nil || updated {
+		t.Errorf("expected nothing left to take over, got %v, %v", updated, err)
+	}
+}
+
+func TestApplyRecreated(t *testing.T) {
+	for _, policy := range []RecreatedResourcePolicy{RecreatedResourcePolicyAdopt, RecreatedResourcePolicyReapply, RecreatedResourcePolicyReport} {
+		t.Run(string(policy), func(t *testing.T) {
+			recreated := newTestConfigMap(t, "cm")
+			recreated.SetUID("recreated")
+			unstructured.RemoveNestedField(recreated.Object, "data")
+			applier, client := newTestKubeApplier(t, recreated.DeepCopy())
+			r := &ApplyWorkReconciler{applier: applier}
+
+			required := newTestConfigMap(t, "cm")
+			required.SetUID("applied")
+			if _, _, _, err := r.applyRecreated(context.Background(), policy, configMapGVR, required, recreated, 1, "", "", ApplyOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			live, err := client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "cm", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _, _ := unstructured.NestedStringMap(live.Object, "data")
+			expectedData := map[string]string{"key": "value"}
+			if policy == RecreatedResourcePolicyReport {
+				expectedData = nil
+			}
+			if len(data) != len(expectedData) || data["key"] != expectedData["key"] {
+				t.Errorf("expected the data %v, got %v", expectedData, data)
+			}
+			if reapplied := live.GetUID() != "recreated"; reapplied != (policy == RecreatedResourcePolicyReapply) {
+				t.Errorf("expected the configmap to be created again only when reapplied, got the UID %s", live.GetUID())
+			}
+		})
+	}
+}
@@ -21,34 +21,137 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/client-go/dynamic"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+	"sigs.k8s.io/work-api/pkg/statusstream"
 )
 
+const (
+	// availableTimeRefreshInterval is the minimum interval between updates of the last available time of a manifest.
+	availableTimeRefreshInterval = time.Minute
+
+	// maxConditions caps the number of conditions of a work and of each of its manifests, so
+	// conditions set by other writers do not grow the status without bound.
+	maxConditions = 16
+
+	// decodeCacheSize is the maximum number of decoded manifests cached.
+	decodeCacheSize = 1024
+	// decodeCacheTTL bounds how long a resolved resource is cached, so the changes of the APIs on the spoke are picked up.
+	decodeCacheTTL = 10 * time.Minute
+
+	// resyncJitterFactor spreads the resyncs of the works, a work is resynced after up to 25% more than the resync interval.
+	resyncJitterFactor = 0.25
+
+	// availabilityRecheckInterval is how often a work whose resources are not all available is
+	// synced again, as the agent does not watch the resources on the spoke cluster.
+	availabilityRecheckInterval = 30 * time.Second
+
+	// maxApplyErrors is the number of the last apply errors kept in the status of a manifest.
+	maxApplyErrors = 5
+	// maxApplyErrorMessageLength bounds the messages of the apply errors kept in the status.
+	maxApplyErrorMessageLength = 1024
+)
+
+var tracer = otel.Tracer("sigs.k8s.io/work-api/pkg/controllers")
+
 // ApplyWorkReconciler reconciles a Work object
 type ApplyWorkReconciler struct {
-	client             client.Client
-	spokeDynamicClient dynamic.Interface
-	log                logr.Logger
-	restMapper         meta.RESTMapper
+	client      client.Client
+	applier     Applier
+	spokeClient client.Client
+	log         logr.Logger
+	recorder    record.EventRecorder
+	// finalizer must be on the works before they are applied, unless disableFinalizer is set.
+	finalizer        string
+	disableFinalizer bool
+	// resyncInterval is how often a work is synced again without change, never if zero.
+	resyncInterval time.Duration
+	// backoff delays the retries of the manifests which keep failing to apply.
+	backoff *manifestBackoff
+	// appliedGenerations skips applying the works again while their generation is unchanged, if set.
+	appliedGenerations *appliedGenerations
+	// decodeCache caches the decoded manifests and their resources by the hash of their content.
+	decodeCache *utilcache.LRUExpireCache
+	// concurrency is the number of works applied concurrently.
+	concurrency int
+	// statusStream streams the status of the works to the hub instead of patching it, if set.
+	statusStream *statusstream.Client
+	// statusSizeBudget is the size of the manifest conditions of a work above which they are moved
+	// to a WorkStatusDetail, they are always kept in the work if zero.
+	statusSizeBudget int
+	// recreatedResourcePolicy is what is done with the resources deleted and recreated by someone else.
+	recreatedResourcePolicy RecreatedResourcePolicy
+	// availabilityEvaluators tell whether the applied resources are available by their kind, the
+	// resources of the other kinds are available as soon as they exist.
+	availabilityEvaluators map[schema.GroupKind]AvailabilityEvaluator
+	// degradedNotifier records events on the works on the hub when their manifests become degraded, if set.
+	degradedNotifier *degradedNotifier
+	// syncTimeout is the deadline of the apply of the manifests of a work, none if zero.
+	syncTimeout time.Duration
+	// agentConfig overrides the resync interval, the concurrency, the allowed kinds and the
+	// feature gates at runtime, if set.
+	agentConfig *agentConfig
+	// hubHash is the hash of the hub the default field managers of the works are derived from.
+	hubHash string
+	// workCache keeps the last applied spec of the works to apply them while the hub is unreachable, if set.
+	workCache *workCache
+}
+
+// decodedManifest is a manifest decoded and resolved by decodeUnstructured.
+type decodedManifest struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+	obj        *unstructured.Unstructured
 }
 
 type applyResult struct {
 	identifier workv1alpha1.ResourceIdentifier
 	generation int64
+	uid        types.UID
 	updated    bool
+	skipped    bool
+	// backingOff is set if the manifest failed before and is not applied until its backoff elapses.
+	backingOff bool
+	// retryAfter is the delay before the manifest is retried if it failed or is backing off.
+	retryAfter time.Duration
+	// externallyManaged is set if the resource on the spoke cluster opted out of management by the work.
+	externallyManaged bool
+	// recreated is the policy applied to the resource if it was found recreated by someone else.
+	recreated RecreatedResourcePolicy
+	// overwrittenManagers are the other field managers whose fields were overwritten by the update.
+	overwrittenManagers []string
+	// available is whether the applied resource is available, with availableMessage, nil if it
+	// was not evaluated.
+	available        *bool
+	availableMessage string
+	// completion is the completion of the applied Job or CronJob, nil for the other resources.
+	completion *completionStatus
 	err        error
 }
 
@@ -58,6 +161,10 @@ func (r *ApplyWorkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	err := r.client.Get(ctx, req.NamespacedName, work)
 	switch {
 	case errors.IsNotFound(err):
+		r.backoff.forget(req.NamespacedName)
+		r.appliedGenerations.forget(req.NamespacedName)
+		r.degradedNotifier.forget(req.NamespacedName)
+		r.forgetCachedWork(req.NamespacedName)
 		return ctrl.Result{}, nil
 	case err != nil:
 		return ctrl.Result{}, err
@@ -65,67 +172,372 @@ func (r *ApplyWorkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	// do nothing if the finalizer is not present
 	// it ensures all maintained resources will be cleaned once work is deleted
-	if !controllerutil.ContainsFinalizer(work, workFinalizer) {
+	if !r.disableFinalizer && !controllerutil.ContainsFinalizer(work, r.finalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// the resources of a deleting work are cleaned up by the finalize controller
+	if !work.DeletionTimestamp.IsZero() {
+		r.forgetCachedWork(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
-	results := r.applyManifests(work.Spec.Workload.Manifests, work.Status.ManifestConditions)
+	// nothing is applied before the WorkAgentConfig is loaded, it may forbid the kinds of the work
+	if err := r.agentConfig.waitLoaded(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+	// a work applied successfully is not applied again until its spec changes or it is resynced
+	resyncInterval := r.agentConfig.resyncIntervalOr(r.resyncInterval)
+	if r.agentConfig.enabled(workv1alpha1.FeatureApplyGenerationGating) {
+		if upToDate, resyncAfter := r.appliedGenerations.upToDate(work, resyncInterval, time.Now()); upToDate {
+			r.log.V(4).Info("skipping work applied at its generation", "work", req.NamespacedName, "generation", work.Generation)
+			return ctrl.Result{RequeueAfter: resyncAfter}, nil
+		}
+	}
+	release, err := r.agentConfig.acquire(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	defer release()
+	// only the status is modified, the rest of the work is shared with the original to save copying the manifests
+	original := &workv1alpha1.Work{TypeMeta: work.TypeMeta, ObjectMeta: work.ObjectMeta, Spec: work.Spec, Status: *work.Status.DeepCopy()}
+
+	ctx, span := tracer.Start(ctx, "ReconcileWork", trace.WithAttributes(
+		attribute.String("work.namespace", work.Namespace),
+		attribute.String("work.name", work.Name),
+	))
+
+	log := r.log.WithValues("work", req.NamespacedName)
+	log.V(2).Info("syncing work", "generation", work.Generation)
+	start := time.Now()
+	defer func() {
+		duration := time.Since(start)
+		workSyncDuration.Observe(duration.Seconds())
+		log.V(2).Info("synced work", "duration", duration)
+	}()
+
+	appliedWork, err := ensureAppliedWork(ctx, r.spokeClient, work)
+	if err != nil {
+		log.Error(err, "failed to get appliedwork")
+		endSpan(span, err)
+		return ctrl.Result{}, err
+	}
+
+	if err := r.loadStatusDetail(ctx, work); err != nil {
+		log.Error(err, "failed to get the status detail of the work")
+		endSpan(span, err)
+		return ctrl.Result{}, err
+	}
+
+	// a new value of the resync annotation forces all the manifests to be applied again
+	resync := work.Annotations[workv1alpha1.ResyncAnnotation]
+	forced := resync != "" && resync != appliedWork.Annotations[workv1alpha1.ResyncAnnotation]
+	if forced {
+		log.Info("forcing the resync of the work", "resync", resync)
+	}
+
+	// the manifests are applied under the sync deadline, cancelling the requests to the spoke cluster
+	// in flight once it expires, while the status is still written
+	syncCtx := ctx
+	if r.syncTimeout > 0 {
+		var cancel context.CancelFunc
+		syncCtx, cancel = context.WithTimeout(ctx, r.syncTimeout)
+		defer cancel()
+	}
+	results := r.applyManifests(syncCtx, log, work, appliedWork.Status.AppliedResources, forced)
 	errs := []error{}
+	manifestErrs := []error{}
+	var requeueAfter time.Duration
 
 	// Update manifestCondition based on the results
-	manifestConditions := []workv1alpha1.ManifestCondition{}
+	manifestConditions := make([]workv1alpha1.ManifestCondition, 0, len(results))
+	// degraded are the manifests which became degraded since the last sync
+	var degraded []workv1alpha1.ResourceIdentifier
+	now := metav1.Now()
 	for _, result := range results {
+		if result.retryAfter > 0 && (requeueAfter == 0 || result.retryAfter < requeueAfter) {
+			requeueAfter = result.retryAfter
+		}
 		if result.err != nil {
-			errs = append(errs, result.err)
+			manifestErrs = append(manifestErrs, result.err)
+			if result.identifier.Resource != "" {
+				r.recorder.Eventf(appliedWork, corev1.EventTypeWarning, eventReasonResourceApplyFailed,
+					"Failed to apply %s: %v", describeResource(result.identifier), result.err)
+			}
+		} else if result.updated {
+			r.recorder.Eventf(appliedWork, corev1.EventTypeNormal, eventReasonResourceApplied, "Applied %s", describeResource(result.identifier))
+		}
+		if len(result.overwrittenManagers) != 0 {
+			r.recorder.Eventf(appliedWork, corev1.EventTypeWarning, eventReasonManagedByConflict,
+				"Overwrote the fields of %s managed by %s", describeResource(result.identifier), strings.Join(result.overwrittenManagers, ", "))
 		}
-		appliedCondition := buildAppliedStatusCondition(result.err, result.generation)
-		manifestCondition := workv1alpha1.ManifestCondition{
-			Identifier: result.identifier,
-			Conditions: []metav1.Condition{appliedCondition},
+		if result.recreated != "" {
+			r.recorder.Eventf(appliedWork, corev1.EventTypeWarning, eventReasonResourceRecreated,
+				"%s was deleted and recreated by someone else, %s", describeResource(result.identifier), describeRecreatedResourcePolicy(result.recreated))
 		}
-		foundmanifestCondition := findManifestConditionByIdentifier(result.identifier, work.Status.ManifestConditions)
-		if foundmanifestCondition != nil {
-			manifestCondition.Conditions = foundmanifestCondition.Conditions
-			meta.SetStatusCondition(&manifestCondition.Conditions, appliedCondition)
+		manifestCondition := buildManifestCondition(result, work.Status.ManifestConditions, work.Generation, &now)
+		if conditions.IsDegraded(manifestCondition.Conditions) {
+			if previous := workv1alpha1.FindManifestCondition(result.identifier, work.Status.ManifestConditions); previous == nil || !conditions.IsDegraded(previous.Conditions) {
+				degraded = append(degraded, result.identifier)
+			}
 		}
 		manifestConditions = append(manifestConditions, manifestCondition)
 	}
+	if r.degradedNotifier.notify(work, degraded, now.Time) {
+		log.Info("manifests became degraded", "manifests", len(degraded))
+	}
 
+	// the manifests no longer in the work are dropped from the status with their conditions, once
+	// their resources are deleted below
+	previousManifestConditions := work.Status.ManifestConditions
 	work.Status.ManifestConditions = manifestConditions
+	applied := len(manifestErrs) == 0 && requeueAfter == 0
+	if applied {
+		// the hash only changes with the spec, so with the generation
+		if work.Status.ObservedGeneration != work.Generation || work.Status.WorkloadHash == "" {
+			if work.Status.WorkloadHash, err = workv1alpha1.WorkloadHash(work.Spec); err != nil {
+				log.Error(err, "failed to hash the workload")
+			}
+		}
+		work.Status.ObservedGeneration = work.Generation
+	}
 
 	// Update status condition of work
 	workCond := generateWorkAppliedStatusCondition(manifestConditions, work.Generation)
-	meta.SetStatusCondition(&work.Status.Conditions, workCond)
+	conditions.Set(&work.Status.Conditions, workCond, work.Generation)
+	optionalManifests := parseManifestKeys(work.Annotations[workv1alpha1.OptionalManifestsAnnotation])
+	availableCond := generateWorkAvailableStatusCondition(manifestConditions, optionalManifests,
+		work.Annotations[workv1alpha1.AvailabilityPolicyAnnotation], work.Generation)
+	conditions.Set(&work.Status.Conditions, availableCond, work.Generation)
+	if degradedCond := generateWorkDegradedStatusCondition(manifestConditions, optionalManifests, work.Generation); degradedCond != nil {
+		conditions.Set(&work.Status.Conditions, *degradedCond, work.Generation)
+	} else {
+		meta.RemoveStatusCondition(&work.Status.Conditions, conditions.TypeDegraded)
+	}
+	completeCond := generateWorkCompleteStatusCondition(manifestConditions, work.Generation)
+	if completeCond != nil {
+		conditions.Set(&work.Status.Conditions, *completeCond, work.Generation)
+	} else {
+		meta.RemoveStatusCondition(&work.Status.Conditions, conditions.TypeComplete)
+	}
+	if externallyManagedCond := generateWorkExternallyManagedStatusCondition(manifestConditions, work.Generation); externallyManagedCond != nil {
+		conditions.Set(&work.Status.Conditions, *externallyManagedCond, work.Generation)
+	} else {
+		meta.RemoveStatusCondition(&work.Status.Conditions, conditions.TypeExternallyManaged)
+	}
+	work.Status.Conditions = conditions.Compact(work.Status.Conditions, maxConditions)
+
+	// delete the resources which are no longer in the work and record what is applied on the AppliedWork
+	appliedResources := buildAppliedResources(results, appliedWork.Status.AppliedResources)
+	stale := findStaleResources(appliedWork.Status.AppliedResources, appliedResources)
+	remaining, pruneErrs := pruneAppliedResources(ctx, r.applier, r.recorder, appliedWork, stale)
+	errs = append(errs, pruneErrs...)
+	appliedResources = append(appliedResources, remaining...)
+	// the removed manifests whose resources failed to be deleted are reported until they are
+	for i, resource := range remaining {
+		work.Status.ManifestConditions = append(work.Status.ManifestConditions,
+			buildPruneFailedManifestCondition(resource.ResourceIdentifier, pruneErrs[i], previousManifestConditions, work.Generation))
+	}
+	if !equality.Semantic.DeepEqual(appliedResources, appliedWork.Status.AppliedResources) {
+		originalAppliedWork := appliedWork.DeepCopy()
+		appliedWork.Status.AppliedResources = appliedResources
+		if err := r.spokeClient.Status().Patch(ctx, appliedWork, client.MergeFrom(originalAppliedWork), client.FieldOwner(statusFieldManager)); err != nil {
+			log.Error(err, "failed to update appliedwork status")
+			errs = append(errs, err)
+		}
+	}
+	if forced {
+		originalAppliedWork := appliedWork.DeepCopy()
+		metav1.SetMetaDataAnnotation(&appliedWork.ObjectMeta, workv1alpha1.ResyncAnnotation, resync)
+		if err := r.spokeClient.Patch(ctx, appliedWork, client.MergeFrom(originalAppliedWork)); err != nil {
+			log.Error(err, "failed to record the resync on the appliedwork")
+			errs = append(errs, err)
+		}
+	}
 
-	err = r.client.Status().Update(ctx, work, &client.UpdateOptions{})
+	// the manifest conditions exceeding the status size budget are kept in a WorkStatusDetail
+	staleStatusDetail, err := r.spillStatusDetail(ctx, work)
 	if err != nil {
+		log.Error(err, "failed to update the status detail of the work")
 		errs = append(errs, err)
 	}
 
+	if isWorkStatusChanged(original.Status, work.Status) {
+		_, statusSpan := tracer.Start(ctx, "UpdateWorkStatus")
+		var err error
+		if r.statusStream != nil {
+			// the hub writes the status it receives, the latest status of the work replaces any not sent yet
+			r.statusStream.Send(work)
+		} else {
+			// the status is patched rather than updated, so it does not conflict with the other writers of the work
+			err = r.client.Status().Patch(ctx, work, client.MergeFrom(original), client.FieldOwner(statusFieldManager))
+		}
+		workStatusUpdates.WithLabelValues(statusUpdateResult(err)).Inc()
+		endSpan(statusSpan, err)
+		if err != nil {
+			log.Error(err, "failed to update work status")
+			errs = append(errs, err)
+		}
+	}
+	if staleStatusDetail != "" && len(errs) == 0 {
+		if err := r.deleteStatusDetail(ctx, work, staleStatusDetail); err != nil {
+			log.Error(err, "failed to delete the status detail of the work")
+			errs = append(errs, err)
+		}
+	}
+
 	if len(errs) != 0 {
-		return ctrl.Result{}, utilerrors.NewAggregate(errs)
+		err := utilerrors.NewAggregate(append(errs, manifestErrs...))
+		endSpan(span, err)
+		return ctrl.Result{}, err
+	}
+
+	// the manifests which failed are retried once their backoff elapses rather than by returning the error
+	endSpan(span, utilerrors.NewAggregate(manifestErrs))
+	// the optional manifests are checked again too, though they do not make the work unavailable
+	// so are the Jobs and CronJobs until they complete or fail, e.g. the CronJobs which are available
+	// before their first run
+	available := availableCond.Status == metav1.ConditionTrue && areManifestsAvailable(manifestConditions) &&
+		(completeCond == nil || completeCond.Reason != string(reasons.WorkIncomplete))
+	if applied && available {
+		r.appliedGenerations.applied(work, time.Now())
+	}
+	if applied && r.workCache != nil {
+		// the manifest conditions may have been moved to the status detail, the cache keeps them
+		cached := &workv1alpha1.Work{ObjectMeta: work.ObjectMeta, Spec: work.Spec}
+		cached.Status.ManifestConditions = manifestConditions
+		if err := r.workCache.store(cached); err != nil {
+			log.Error(err, "failed to cache the applied work")
+		}
+	}
+	if !available && r.agentConfig.enabled(workv1alpha1.FeatureAvailabilityRecheck) &&
+		(requeueAfter == 0 || availabilityRecheckInterval < requeueAfter) {
+		requeueAfter = availabilityRecheckInterval
 	}
+	if resyncInterval > 0 {
+		if resync := wait.Jitter(resyncInterval, resyncJitterFactor); requeueAfter == 0 || resync < requeueAfter {
+			requeueAfter = resync
+		}
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
 
-	return ctrl.Result{}, nil
+// forgetCachedWork removes a work deleted or being deleted from the work cache.
+func (r *ApplyWorkReconciler) forgetCachedWork(key types.NamespacedName) {
+	if err := r.workCache.remove(key); err != nil {
+		r.log.Error(err, "failed to remove the work from the cache", "work", key)
+	}
 }
 
-func (r *ApplyWorkReconciler) applyManifests(manifests []workv1alpha1.Manifest, manifestConditions []workv1alpha1.ManifestCondition) []applyResult {
-	results := []applyResult{}
+// applyManifests applies the manifests of a work. The resources applied before by the work are
+// used to find the names generated by the server for the manifests using generateName. The
+// manifests are all applied again, even if they did not change, if force is set.
+func (r *ApplyWorkReconciler) applyManifests(
+	ctx context.Context,
+	log logr.Logger,
+	work *workv1alpha1.Work,
+	appliedResources []workv1alpha1.AppliedResourceMeta,
+	force bool) []applyResult {
+	workKey := client.ObjectKeyFromObject(work)
+	workGeneration := work.Generation
+	manifestConditions := work.Status.ManifestConditions
+	skippedManifests := parseManifestKeys(work.Annotations[workv1alpha1.SkipManifestsAnnotation])
+	restartedAt := work.Annotations[workv1alpha1.RestartedAtAnnotation]
+	applyOpts, applyOptsErr := r.applyOptions(work)
+	// the manifests which cannot be encoded fail to decode below, the hash is then left unset
+	workloadHash, _ := workv1alpha1.WorkloadHash(work.Spec)
+	results := make([]applyResult, 0, len(work.Spec.Workload.Manifests))
 
-	for index, manifest := range manifests {
+	for index, manifest := range work.Spec.Workload.Manifests {
 		result := applyResult{
 			identifier: workv1alpha1.ResourceIdentifier{Ordinal: index},
 		}
-		gvr, required, err := r.decodeUnstructured(manifest)
+		_, decodeSpan := tracer.Start(ctx, "DecodeManifest", trace.WithAttributes(attribute.Int("manifest.ordinal", index)))
+		gvr, required, err := r.decodeUnstructured(manifest, work.Spec.DefaultNamespace)
+		endSpan(decodeSpan, err)
+		if err == nil {
+			resolveGeneratedName(index, required, gvr, appliedResources)
+		}
 		if err != nil {
+			log.Error(err, "failed to decode manifest", "manifest", index)
 			result.err = err
+			result.retryAfter = r.backoff.failed(workKey, index, workGeneration, time.Now())
+		} else if skippedManifests[manifestKey(required)] {
+			result.identifier = buildResourceIdentifier(index, required, gvr)
+			result.skipped = true
+			log.V(2).Info("skipped manifest", manifestLogValues(index, required)...)
+		} else if groupKind := required.GroupVersionKind().GroupKind(); !r.agentConfig.allowed(groupKind) {
+			result.identifier = buildResourceIdentifier(index, required, gvr)
+			result.err = fmt.Errorf("the kind %s is not allowed by the WorkAgentConfig of the agent", groupKind)
+			result.retryAfter = r.backoff.failed(workKey, index, workGeneration, time.Now())
+			log.Error(result.err, "refused to apply manifest", manifestLogValues(index, required)...)
+		} else if applyOptsErr != nil {
+			result.identifier = buildResourceIdentifier(index, required, gvr)
+			result.err = applyOptsErr
+			result.retryAfter = r.backoff.failed(workKey, index, workGeneration, time.Now())
+			log.Error(result.err, "refused to apply manifest", manifestLogValues(index, required)...)
+		} else if wait := r.backoff.wait(workKey, index, workGeneration, time.Now()); wait > 0 && !force {
+			result.identifier = buildResourceIdentifier(index, required, gvr)
+			result.backingOff = true
+			result.retryAfter = wait
+			log.V(4).Info("backing off manifest", append(manifestLogValues(index, required), "retryAfter", wait)...)
 		} else {
 			var obj *unstructured.Unstructured
 			result.identifier = buildResourceIdentifier(index, required, gvr)
 			observedGeneration := findObservedGenerationOfManifest(result.identifier, manifestConditions)
-			obj, result.updated, result.err = r.applyUnstructrued(gvr, required, observedGeneration)
+			if force {
+				// the resource is updated even if it did not change
+				observedGeneration = -1
+			}
+			// the UID the resource was applied with detects whether it was recreated by someone else since
+			var appliedUID types.UID
+			if applied := findAppliedResource(result.identifier, appliedResources); applied != nil {
+				appliedUID = applied.UID
+				required.SetUID(appliedUID)
+			}
+			applyCtx, applySpan := tracer.Start(ctx, "ApplyManifest", trace.WithAttributes(
+				attribute.Int("manifest.ordinal", index),
+				attribute.String("manifest.gvk", required.GroupVersionKind().String()),
+				attribute.String("manifest.namespace", required.GetNamespace()),
+				attribute.String("manifest.name", required.GetName()),
+			))
+			obj, result.updated, result.overwrittenManagers, result.err = r.applyUnstructrued(applyCtx, gvr, required, workGeneration, observedGeneration, restartedAt, workloadHash, applyOpts)
+			if isResourceRecreated(result.err) {
+				result.recreated = r.recreatedResourcePolicy
+				if result.recreated == "" {
+					result.recreated = RecreatedResourcePolicyAdopt
+				}
+				log.Info("resource was recreated by someone else", append(manifestLogValues(index, required), "policy", result.recreated)...)
+				obj, result.updated, result.overwrittenManagers, result.err = r.applyRecreated(applyCtx, result.recreated, gvr, required, obj, workGeneration, restartedAt, workloadHash, applyOpts)
+			}
+			endSpan(applySpan, result.err)
 			if obj != nil {
+				// the name generated by the server is recorded for the manifests using generateName
+				result.identifier.Name = obj.GetName()
 				result.generation = obj.GetGeneration()
+				result.uid = obj.GetUID()
+				result.externallyManaged = isExternallyManaged(obj)
+			}
+			if result.recreated == RecreatedResourcePolicyReport {
+				// the resource left to whoever recreated it is not the one of the work
+				result.uid = appliedUID
+			} else if obj != nil && result.err == nil {
+				available, message := evaluateAvailability(r.availabilityEvaluators, obj)
+				result.available, result.availableMessage = &available, message
+				result.completion = evaluateCompletion(obj)
+			}
+			if result.err != nil {
+				result.retryAfter = r.backoff.failed(workKey, index, workGeneration, time.Now())
+			} else {
+				r.backoff.succeeded(workKey, index)
+			}
+			switch {
+			case result.err != nil:
+				log.Error(result.err, "failed to apply manifest", manifestLogValues(index, required)...)
+			case result.externallyManaged:
+				log.V(2).Info("manifest is externally managed", manifestLogValues(index, required)...)
+			default:
+				log.V(4).Info("applied manifest", append(manifestLogValues(index, required), "updated", result.updated)...)
 			}
 		}
 		results = append(results, result)
@@ -133,57 +545,125 @@ func (r *ApplyWorkReconciler) applyManifests(manifests []workv1alpha1.Manifest,
 	return results
 }
 
-func (r *ApplyWorkReconciler) decodeUnstructured(manifest workv1alpha1.Manifest) (schema.GroupVersionResource, *unstructured.Unstructured, error) {
+// decodeUnstructured decodes a manifest and resolves its resource. A namespaced manifest without a
+// namespace is given defaultNamespace, if set. The decoded manifests are cached by the hash of their
+// content, a copy of the cached object is returned.
+func (r *ApplyWorkReconciler) decodeUnstructured(manifest workv1alpha1.Manifest, defaultNamespace string) (schema.GroupVersionResource, *unstructured.Unstructured, error) {
+	key := sha256.Sum256(manifest.Raw)
+	cached, ok := r.decodeCache.Get(key)
+	if !ok {
+		decoded, err := r.decodeManifest(manifest)
+		if err != nil {
+			return schema.GroupVersionResource{}, nil, err
+		}
+		r.decodeCache.Add(key, decoded, decodeCacheTTL)
+		cached = decoded
+	}
+
+	decoded := cached.(decodedManifest)
+	obj := decoded.obj.DeepCopy()
+	if decoded.namespaced && obj.GetNamespace() == "" && defaultNamespace != "" {
+		obj.SetNamespace(defaultNamespace)
+	}
+	return decoded.gvr, obj, nil
+}
+
+func (r *ApplyWorkReconciler) decodeManifest(manifest workv1alpha1.Manifest) (decodedManifest, error) {
 	unstructuredObj := &unstructured.Unstructured{}
 	err := unstructuredObj.UnmarshalJSON(manifest.Raw)
 	if err != nil {
-		return schema.GroupVersionResource{}, nil, fmt.Errorf("Failed to decode object: %w", err)
+		return decodedManifest{}, fmt.Errorf("Failed to decode object: %w", err)
 	}
-	mapping, err := r.restMapper.RESTMapping(unstructuredObj.GroupVersionKind().GroupKind(), unstructuredObj.GroupVersionKind().Version)
+	mapping, err := r.applier.RESTMapper().RESTMapping(unstructuredObj.GroupVersionKind().GroupKind(), unstructuredObj.GroupVersionKind().Version)
 	if err != nil {
-		return schema.GroupVersionResource{}, nil, fmt.Errorf("Failed to find gvr from restmapping: %w", err)
+		return decodedManifest{}, fmt.Errorf("Failed to find gvr from restmapping: %w", err)
 	}
 
-	return mapping.Resource, unstructuredObj, nil
+	return decodedManifest{
+		gvr:        mapping.Resource,
+		namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+		obj:        unstructuredObj,
+	}, nil
 }
 
 func (r *ApplyWorkReconciler) applyUnstructrued(
+	ctx context.Context,
 	gvr schema.GroupVersionResource,
 	required *unstructured.Unstructured,
-	observedGeneration int64) (*unstructured.Unstructured, bool, error) {
+	workGeneration int64,
+	observedGeneration int64,
+	restartedAt string,
+	workloadHash string,
+	opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error) {
 
+	// the restart changes the spec hash, so the workload is updated once per restart
+	if err := stampRestart(required, restartedAt); err != nil {
+		return nil, false, nil, err
+	}
 	err := setSpecHashAnnotation(required)
 	if err != nil {
-		return nil, false, err
+		return nil, false, nil, err
+	}
+	setAnnotation(required, workv1alpha1.WorkGenerationAnnotation, strconv.FormatInt(workGeneration, 10))
+	if workloadHash != "" {
+		setAnnotation(required, workv1alpha1.WorkloadHashAnnotation, workloadHash)
 	}
 
-	existing, err := r.spokeDynamicClient.
-		Resource(gvr).
-		Namespace(required.GetNamespace()).
-		Get(context.TODO(), required.GetName(), metav1.GetOptions{})
-	if errors.IsNotFound(err) {
-		actual, err := r.spokeDynamicClient.Resource(gvr).Namespace(required.GetNamespace()).Create(
-			context.TODO(), required, metav1.CreateOptions{})
-		return actual, true, err
+	return r.applier.Apply(ctx, gvr, required, observedGeneration, opts)
+}
+
+// applyRecreated applies a manifest whose resource was deleted and recreated by someone else,
+// according to the recreated resource policy. The recreated resource is returned as is if it is
+// left untouched, it is updated even if it looks unchanged if it is adopted.
+func (r *ApplyWorkReconciler) applyRecreated(
+	ctx context.Context,
+	policy RecreatedResourcePolicy,
+	gvr schema.GroupVersionResource,
+	required, recreated *unstructured.Unstructured,
+	workGeneration int64,
+	restartedAt string,
+	workloadHash string,
+	opts ApplyOptions) (*unstructured.Unstructured, bool, []string, error) {
+	switch policy {
+	case RecreatedResourcePolicyReport:
+		return recreated, false, nil, nil
+	case RecreatedResourcePolicyReapply:
+		live := workv1alpha1.AppliedResourceMeta{
+			ResourceIdentifier: buildResourceIdentifier(0, recreated, gvr),
+			UID:                recreated.GetUID(),
+		}
+		if _, err := r.applier.Delete(ctx, live); err != nil {
+			return nil, false, nil, err
+		}
+		required.SetUID("")
+	default:
+		required.SetUID(recreated.GetUID())
 	}
+	return r.applyUnstructrued(ctx, gvr, required, workGeneration, -1, restartedAt, workloadHash, opts)
+}
+
+// applyOptions returns the options the resources of a work are applied with, from its field-manager
+// and take-over-field-managers annotations.
+func (r *ApplyWorkReconciler) applyOptions(work *workv1alpha1.Work) (ApplyOptions, error) {
+	manager, err := fieldManagerFor(work, r.hubHash)
 	if err != nil {
-		return nil, false, err
+		return ApplyOptions{}, err
 	}
-
-	// Compare and update the unstrcuctured.
-	if isManifestModified(observedGeneration, gvr, existing, required) {
-		required.SetResourceVersion(existing.GetResourceVersion())
-		actual, err := r.spokeDynamicClient.Resource(gvr).Namespace(required.GetNamespace()).Update(
-			context.TODO(), required, metav1.UpdateOptions{})
-		return actual, true, err
+	opts := ApplyOptions{FieldManager: manager}
+	for _, takeOver := range strings.Split(work.Annotations[workv1alpha1.TakeOverFieldManagersAnnotation], ",") {
+		if takeOver = strings.TrimSpace(takeOver); takeOver != "" {
+			opts.TakeOverFieldManagers = append(opts.TakeOverFieldManagers, takeOver)
+		}
 	}
-
-	return existing, false, nil
+	return opts, nil
 }
 
 // SetupWithManager wires up the controller.
 func (r *ApplyWorkReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).For(&workv1alpha1.Work{}).Complete(r)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&workv1alpha1.Work{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.concurrency}).
+		Complete(r)
 }
 
 // Return true when label/annotation is changed or generation is changed
@@ -223,41 +703,119 @@ func isSameUnstructuredMeta(obj1, obj2 *unstructured.Unstructured) bool {
 	return true
 }
 
-// findManifestConditionByIdentifier return a ManifestCondition by identifier
-// 1. find the manifest condition with the whole identifier;
-// 2. if identifier only has ordinal and a matched cannot found, return nil
-// 3. try to find with properties other than ordinal in identifier
-func findManifestConditionByIdentifier(identifier workv1alpha1.ResourceIdentifier, manifestConditions []workv1alpha1.ManifestCondition) *workv1alpha1.ManifestCondition {
-	for _, manifestCondition := range manifestConditions {
-		if identifier == manifestCondition.Identifier {
-			return &manifestCondition
+// buildManifestCondition returns the condition of a manifest from the result of its apply, on top of
+// its previous condition. The previous conditions are modified in place and the times share now.
+func buildManifestCondition(
+	result applyResult,
+	previous []workv1alpha1.ManifestCondition,
+	workGeneration int64,
+	now *metav1.Time) workv1alpha1.ManifestCondition {
+	manifestCondition := workv1alpha1.ManifestCondition{
+		Identifier: result.identifier,
+	}
+	foundmanifestCondition := workv1alpha1.FindManifestCondition(result.identifier, previous)
+	if foundmanifestCondition != nil {
+		manifestCondition.Conditions = foundmanifestCondition.Conditions
+		manifestCondition.LastAppliedTime = foundmanifestCondition.LastAppliedTime
+		manifestCondition.LastAvailableTime = foundmanifestCondition.LastAvailableTime
+		manifestCondition.ApplyErrors = foundmanifestCondition.ApplyErrors
+	}
+	setManifestTimes(&manifestCondition, result, now)
+	if result.err != nil {
+		recordApplyError(&manifestCondition, result.err, now)
+	}
+
+	// a skipped, externally managed or backing off manifest keeps the conditions it had before
+	switch {
+	case result.backingOff:
+	case result.skipped:
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildSkippedStatusCondition(workGeneration))
+	case result.externallyManaged:
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeSkipped)
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildExternallyManagedStatusCondition(workGeneration))
+	case result.recreated == RecreatedResourcePolicyReport:
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeSkipped)
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeExternallyManaged)
+	default:
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeSkipped)
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeExternallyManaged)
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildAppliedStatusCondition(result.err, result.generation))
+	}
+	// the recreation is reported until the manifest is applied again without finding it recreated
+	if result.recreated != "" {
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildRecreatedStatusCondition(result.recreated, workGeneration))
+	} else if !result.backingOff && result.err == nil {
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeResourceRecreated)
+	}
+	if result.available != nil {
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildAvailableStatusCondition(*result.available, result.availableMessage, workGeneration))
+	}
+	if result.completion != nil {
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildCompleteStatusCondition(*result.completion, workGeneration))
+	}
+	// the degradation is only evaluated with the availability
+	if result.available != nil {
+		if degradedCond := buildDegradedStatusCondition(manifestCondition.Conditions, now.Time, workGeneration); degradedCond != nil {
+			meta.SetStatusCondition(&manifestCondition.Conditions, *degradedCond)
+		} else {
+			removeStatusCondition(&manifestCondition.Conditions, conditions.TypeDegraded)
 		}
 	}
+	// the conflict is reported until the resource is updated again without overwriting other managers
+	if len(result.overwrittenManagers) != 0 {
+		meta.SetStatusCondition(&manifestCondition.Conditions, buildManagedByConflictStatusCondition(result.overwrittenManagers, workGeneration))
+	} else if result.updated && result.err == nil {
+		removeStatusCondition(&manifestCondition.Conditions, conditions.TypeManagedByConflict)
+	}
+	manifestCondition.Conditions = conditions.Compact(manifestCondition.Conditions, maxConditions)
+	return manifestCondition
+}
 
-	if identifier == (workv1alpha1.ResourceIdentifier{Ordinal: identifier.Ordinal}) {
-		return nil
+// removeStatusCondition removes a condition, unlike meta.RemoveStatusCondition it does not
+// reallocate the conditions if the condition is absent.
+func removeStatusCondition(conditions *[]metav1.Condition, conditionType string) {
+	if meta.FindStatusCondition(*conditions, conditionType) != nil {
+		meta.RemoveStatusCondition(conditions, conditionType)
 	}
+}
 
-	identifierCopy := identifier.DeepCopy()
-	for _, manifestCondition := range manifestConditions {
-		identifierCopy.Ordinal = manifestCondition.Identifier.Ordinal
-		if *identifierCopy == manifestCondition.Identifier {
-			return &manifestCondition
+// isWorkStatusChanged returns true if the status of a work changed, ignoring the transition times of the conditions.
+func isWorkStatusChanged(original, current workv1alpha1.WorkStatus) bool {
+	if !conditions.Equal(original.Conditions, current.Conditions) || original.StatusDetailName != current.StatusDetailName ||
+		original.ObservedGeneration != current.ObservedGeneration || original.WorkloadHash != current.WorkloadHash {
+		return true
+	}
+	if len(original.ManifestConditions) != len(current.ManifestConditions) {
+		return true
+	}
+	for i := range current.ManifestConditions {
+		originalManifest, currentManifest := original.ManifestConditions[i], current.ManifestConditions[i]
+		if originalManifest.Identifier != currentManifest.Identifier ||
+			!originalManifest.LastAppliedTime.Equal(currentManifest.LastAppliedTime) ||
+			!originalManifest.LastAvailableTime.Equal(currentManifest.LastAvailableTime) ||
+			len(originalManifest.ApplyErrors) != len(currentManifest.ApplyErrors) ||
+			!conditions.Equal(originalManifest.Conditions, currentManifest.Conditions) {
+			return true
+		}
+		for j := range currentManifest.ApplyErrors {
+			if !originalManifest.ApplyErrors[j].Time.Equal(&currentManifest.ApplyErrors[j].Time) {
+				return true
+			}
 		}
 	}
-	return nil
+	return false
 }
 
 // Find observeredGeneration for applied condition type for a manifest.
 func findObservedGenerationOfManifest(
 	identifier workv1alpha1.ResourceIdentifier,
 	manifestConditions []workv1alpha1.ManifestCondition) int64 {
-	manifestCondition := findManifestConditionByIdentifier(identifier, manifestConditions)
+	manifestCondition := workv1alpha1.FindManifestCondition(identifier, manifestConditions)
 	if manifestCondition == nil {
 		return 0
 	}
 
-	condition := meta.FindStatusCondition(manifestCondition.Conditions, "Applied")
+	condition := meta.FindStatusCondition(manifestCondition.Conditions, conditions.TypeApplied)
 	if condition == nil {
 		return 0
 	}
@@ -279,13 +837,18 @@ func setSpecHashAnnotation(obj *unstructured.Unstructured) error {
 	}
 
 	specHash := fmt.Sprintf("%x", sha256.Sum256(jsonBytes))
+	setAnnotation(obj, specHashAnnotation, specHash)
+	return nil
+}
+
+// setAnnotation sets an annotation on the provided unstructured object.
+func setAnnotation(obj *unstructured.Unstructured, key, value string) {
 	annotation := obj.GetAnnotations()
 	if annotation == nil {
 		annotation = map[string]string{}
 	}
-	annotation[specHashAnnotation] = specHash
+	annotation[key] = value
 	obj.SetAnnotations(annotation)
-	return nil
 }
 
 func buildResourceIdentifier(index int, object *unstructured.Unstructured, gvr schema.GroupVersionResource) workv1alpha1.ResourceIdentifier {
@@ -303,23 +866,215 @@ func buildResourceIdentifier(index int, object *unstructured.Unstructured, gvr s
 	return identifier
 }
 
+// parseManifestKeys returns the keys of the manifests listed in the skip-manifests or the
+// optional-manifests annotation.
+func parseManifestKeys(annotation string) map[string]bool {
+	skipped := map[string]bool{}
+	for _, key := range strings.Split(annotation, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			skipped[strings.ToLower(key)] = true
+		}
+	}
+	return skipped
+}
+
+// manifestKey returns the key identifying a manifest in the skip-manifests and optional-manifests annotations.
+func manifestKey(obj *unstructured.Unstructured) string {
+	return identifierKey(workv1alpha1.ResourceIdentifier{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()})
+}
+
+// identifierKey returns the key identifying the manifest of a manifest condition in the
+// skip-manifests and optional-manifests annotations.
+func identifierKey(identifier workv1alpha1.ResourceIdentifier) string {
+	if identifier.Namespace == "" {
+		return strings.ToLower(identifier.Kind + "/" + identifier.Name)
+	}
+	return strings.ToLower(identifier.Kind + "/" + identifier.Namespace + "/" + identifier.Name)
+}
+
+// manifestLogValues returns the key/value pairs identifying a manifest in the logs.
+func manifestLogValues(index int, obj *unstructured.Unstructured) []interface{} {
+	return []interface{}{
+		"manifest", index,
+		"gvk", obj.GroupVersionKind().String(),
+		"namespace", obj.GetNamespace(),
+		"name", obj.GetName(),
+	}
+}
+
+// endSpan records the error, if any, on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// setManifestTimes records when the manifest was last applied and observed available. The available
+// time is refreshed at most once per availableTimeRefreshInterval, so the status update it causes
+// does not trigger another one.
+func setManifestTimes(manifestCondition *workv1alpha1.ManifestCondition, result applyResult, now *metav1.Time) {
+	if result.err != nil || result.skipped || result.uid == "" {
+		return
+	}
+	if !result.externallyManaged && (result.updated || manifestCondition.LastAppliedTime == nil) {
+		manifestCondition.LastAppliedTime = now
+	}
+	if manifestCondition.LastAvailableTime == nil || now.Sub(manifestCondition.LastAvailableTime.Time) >= availableTimeRefreshInterval {
+		manifestCondition.LastAvailableTime = now
+	}
+}
+
+// recordApplyError appends an apply error to the status of a manifest, keeping the last maxApplyErrors.
+func recordApplyError(manifestCondition *workv1alpha1.ManifestCondition, err error, now *metav1.Time) {
+	reason := string(errors.ReasonForError(err))
+	if reason == "" {
+		reason = string(reasons.AppliedManifestFailed)
+	}
+	message := err.Error()
+	if len(message) > maxApplyErrorMessageLength {
+		message = message[:maxApplyErrorMessageLength] + "..."
+	}
+	// the errors are copied, they are shared with the previous status
+	applyErrors := make([]workv1alpha1.ApplyError, 0, maxApplyErrors)
+	if previous := manifestCondition.ApplyErrors; len(previous) >= maxApplyErrors {
+		applyErrors = append(applyErrors, previous[len(previous)-maxApplyErrors+1:]...)
+	} else {
+		applyErrors = append(applyErrors, previous...)
+	}
+	manifestCondition.ApplyErrors = append(applyErrors, workv1alpha1.ApplyError{Time: *now, Reason: reason, Message: message})
+}
+
+// isExternallyManaged returns true if a resource on the spoke cluster opted out of management by the work.
+func isExternallyManaged(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[workv1alpha1.UnmanagedAnnotation] == "true"
+}
+
+func buildExternallyManagedStatusCondition(observedGeneration int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditions.TypeExternallyManaged,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.UnmanagedAnnotation),
+		Message:            fmt.Sprintf("Resource is managed on the spoke cluster as it is annotated with %s=true", workv1alpha1.UnmanagedAnnotation),
+	}
+}
+
+// buildPruneFailedManifestCondition builds the condition of a manifest removed from the work whose
+// resource failed to be deleted, keeping the conditions it had before.
+func buildPruneFailedManifestCondition(
+	identifier workv1alpha1.ResourceIdentifier,
+	err error,
+	previous []workv1alpha1.ManifestCondition,
+	observedGeneration int64) workv1alpha1.ManifestCondition {
+	manifestCondition := workv1alpha1.ManifestCondition{Identifier: identifier}
+	if found := workv1alpha1.FindManifestCondition(identifier, previous); found != nil {
+		manifestCondition = *found.DeepCopy()
+		manifestCondition.Identifier = identifier
+	}
+	meta.SetStatusCondition(&manifestCondition.Conditions, metav1.Condition{
+		Type:               conditions.TypePruned,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.ResourcePruneFailed),
+		Message:            fmt.Sprintf("The manifest was removed from the work but its resource failed to be deleted: %v", err),
+	})
+	manifestCondition.Conditions = conditions.Compact(manifestCondition.Conditions, maxConditions)
+	return manifestCondition
+}
+
+func buildRecreatedStatusCondition(policy RecreatedResourcePolicy, observedGeneration int64) metav1.Condition {
+	reason := reasons.RecreatedResourceAdopted
+	switch policy {
+	case RecreatedResourcePolicyReapply:
+		reason = reasons.RecreatedResourceReapplied
+	case RecreatedResourcePolicyReport:
+		reason = reasons.RecreatedResourceLeft
+	}
+	return metav1.Condition{
+		Type:               conditions.TypeResourceRecreated,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reason),
+		Message:            fmt.Sprintf("Resource was deleted and recreated by someone else, %s", describeRecreatedResourcePolicy(policy)),
+	}
+}
+
+func buildManagedByConflictStatusCondition(managers []string, observedGeneration int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditions.TypeManagedByConflict,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.FieldManagersOverwritten),
+		Message:            fmt.Sprintf("Resource fields managed by %s were overwritten by the manifest", strings.Join(managers, ", ")),
+	}
+}
+
+func buildAvailableStatusCondition(available bool, message string, observedGeneration int64) metav1.Condition {
+	if !available {
+		return metav1.Condition{
+			Type:               conditions.TypeAvailable,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: observedGeneration,
+			Reason:             string(reasons.ManifestNotAvailable),
+			Message:            message,
+		}
+	}
+	return metav1.Condition{
+		Type:               conditions.TypeAvailable,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.ManifestAvailable),
+		Message:            message,
+	}
+}
+
+func buildCompleteStatusCondition(completion completionStatus, observedGeneration int64) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               conditions.TypeComplete,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.ManifestIncomplete),
+		Message:            completion.message,
+	}
+	switch {
+	case completion.failed:
+		condition.Reason = string(reasons.ManifestFailed)
+	case completion.complete:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = string(reasons.ManifestComplete)
+	}
+	return condition
+}
+
+func buildSkippedStatusCondition(observedGeneration int64) metav1.Condition {
+	return metav1.Condition{
+		Type:               conditions.TypeSkipped,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.ManifestSkipped),
+		Message:            fmt.Sprintf("Manifest is skipped by the %s annotation", workv1alpha1.SkipManifestsAnnotation),
+	}
+}
+
 func buildAppliedStatusCondition(err error, observedGeneration int64) metav1.Condition {
 	if err != nil {
 		return metav1.Condition{
-			Type:               "Applied",
+			Type:               conditions.TypeApplied,
 			Status:             metav1.ConditionFalse,
 			LastTransitionTime: metav1.Now(),
-			Reason:             "AppliedManifestFailed",
+			Reason:             string(reasons.AppliedManifestFailed),
 			Message:            fmt.Sprintf("Failed to apply manifest: %v", err),
 		}
 	}
 
 	return metav1.Condition{
-		Type:               "Applied",
+		Type:               conditions.TypeApplied,
 		Status:             metav1.ConditionTrue,
 		LastTransitionTime: metav1.Now(),
 		ObservedGeneration: observedGeneration,
-		Reason:             "AppliedManifestComplete",
+		Reason:             string(reasons.AppliedManifestComplete),
 		Message:            "Apply manifest complete",
 	}
 }
@@ -328,11 +1083,11 @@ func buildAppliedStatusCondition(err error, observedGeneration int64) metav1.Con
 // If one of the manifests is applied failed on the spoke, the applied status condition of the work is false.
 func generateWorkAppliedStatusCondition(manifestConditions []workv1alpha1.ManifestCondition, observedGeneration int64) metav1.Condition {
 	for _, manifestCond := range manifestConditions {
-		if meta.IsStatusConditionFalse(manifestCond.Conditions, "Applied") {
+		if meta.IsStatusConditionFalse(manifestCond.Conditions, conditions.TypeApplied) {
 			return metav1.Condition{
-				Type:               "Applied",
+				Type:               conditions.TypeApplied,
 				Status:             metav1.ConditionFalse,
-				Reason:             "AppliedWorkFailed",
+				Reason:             string(reasons.AppliedWorkFailed),
 				Message:            "Failed to apply work",
 				ObservedGeneration: observedGeneration,
 			}
@@ -340,10 +1095,152 @@ func generateWorkAppliedStatusCondition(manifestConditions []workv1alpha1.Manife
 	}
 
 	return metav1.Condition{
-		Type:               "Applied",
+		Type:               conditions.TypeApplied,
 		Status:             metav1.ConditionTrue,
-		Reason:             "AppliedWorkComplete",
+		Reason:             string(reasons.AppliedWorkComplete),
 		Message:            "Apply work complete",
 		ObservedGeneration: observedGeneration,
 	}
 }
+
+// generateWorkAvailableStatusCondition generates the available status condition for work, true
+// once enough of the resources of the manifests which are neither skipped nor optional are
+// available according to the availability policy, all of them by default.
+func generateWorkAvailableStatusCondition(
+	manifestConditions []workv1alpha1.ManifestCondition,
+	optionalManifests map[string]bool,
+	policyValue string,
+	observedGeneration int64) metav1.Condition {
+	policy, err := parseAvailabilityPolicy(policyValue)
+	if err != nil {
+		return metav1.Condition{
+			Type:               conditions.TypeAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.WorkNotAvailable),
+			Message:            err.Error(),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+	total, available, optionalTotal, optionalAvailable := 0, 0, 0, 0
+	for _, manifestCond := range manifestConditions {
+		if meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeSkipped) {
+			continue
+		}
+		if optionalManifests[identifierKey(manifestCond.Identifier)] {
+			optionalTotal++
+			if meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeAvailable) {
+				optionalAvailable++
+			}
+			continue
+		}
+		total++
+		if meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeAvailable) {
+			available++
+		}
+	}
+	if !policy.satisfied(available, total) {
+		return metav1.Condition{
+			Type:               conditions.TypeAvailable,
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.WorkNotAvailable),
+			Message:            fmt.Sprintf("%d of %d manifests are available", available, total),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	message := "All the manifests are available"
+	switch {
+	case available < total:
+		message = fmt.Sprintf("%d of %d manifests are available, which satisfies the availability policy %q", available, total, policyValue)
+	case optionalAvailable < optionalTotal:
+		message = fmt.Sprintf("All the required manifests are available, %d of %d optional manifests are available", optionalAvailable, optionalTotal)
+	}
+	return metav1.Condition{
+		Type:               conditions.TypeAvailable,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.WorkAvailable),
+		Message:            message,
+		ObservedGeneration: observedGeneration,
+	}
+}
+
+// areManifestsAvailable returns true if the resources of all the manifests which are not skipped
+// are available, including the optional ones.
+func areManifestsAvailable(manifestConditions []workv1alpha1.ManifestCondition) bool {
+	for _, manifestCond := range manifestConditions {
+		if !meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeSkipped) &&
+			!meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeAvailable) {
+			return false
+		}
+	}
+	return true
+}
+
+// generateWorkCompleteStatusCondition generates the complete status condition for work, true once
+// the Jobs and CronJobs of all the manifests which are not skipped completed, or nil if the work
+// has none.
+func generateWorkCompleteStatusCondition(manifestConditions []workv1alpha1.ManifestCondition, observedGeneration int64) *metav1.Condition {
+	total, complete, failed := 0, 0, 0
+	for _, manifestCond := range manifestConditions {
+		completeCond := meta.FindStatusCondition(manifestCond.Conditions, conditions.TypeComplete)
+		if completeCond == nil || meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeSkipped) {
+			continue
+		}
+		total++
+		switch {
+		case completeCond.Status == metav1.ConditionTrue:
+			complete++
+		case completeCond.Reason == string(reasons.ManifestFailed):
+			failed++
+		}
+	}
+	switch {
+	case total == 0:
+		return nil
+	case failed > 0:
+		return &metav1.Condition{
+			Type:               conditions.TypeComplete,
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.WorkFailed),
+			Message:            fmt.Sprintf("%d of %d jobs failed", failed, total),
+			ObservedGeneration: observedGeneration,
+		}
+	case complete < total:
+		return &metav1.Condition{
+			Type:               conditions.TypeComplete,
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.WorkIncomplete),
+			Message:            fmt.Sprintf("%d of %d jobs are complete", complete, total),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+	return &metav1.Condition{
+		Type:               conditions.TypeComplete,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.WorkComplete),
+		Message:            "All the jobs are complete",
+		ObservedGeneration: observedGeneration,
+	}
+}
+
+// generateWorkExternallyManagedStatusCondition generates the externally managed status condition for work,
+// or nil if none of the manifests is externally managed on the spoke.
+func generateWorkExternallyManagedStatusCondition(manifestConditions []workv1alpha1.ManifestCondition, observedGeneration int64) *metav1.Condition {
+	count := 0
+	for _, manifestCond := range manifestConditions {
+		if meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeExternallyManaged) {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+
+	return &metav1.Condition{
+		Type:               conditions.TypeExternallyManaged,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.ManifestsExternallyManaged),
+		Message:            fmt.Sprintf("%d of %d manifests are managed on the spoke cluster", count, len(manifestConditions)),
+		ObservedGeneration: observedGeneration,
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+const benchmarkManifests = 200
+
+func newBenchmarkResults() ([]applyResult, []workv1alpha1.ManifestCondition) {
+	results := make([]applyResult, 0, benchmarkManifests)
+	manifestConditions := make([]workv1alpha1.ManifestCondition, 0, benchmarkManifests)
+	now := metav1.Now()
+	for i := 0; i < benchmarkManifests; i++ {
+		identifier := workv1alpha1.ResourceIdentifier{
+			Ordinal:   i,
+			Version:   "v1",
+			Kind:      "ConfigMap",
+			Resource:  "configmaps",
+			Namespace: "default",
+			Name:      fmt.Sprintf("cm-%d", i),
+		}
+		results = append(results, applyResult{identifier: identifier, generation: 1, uid: "uid"})
+		manifestConditions = append(manifestConditions, workv1alpha1.ManifestCondition{
+			Identifier:        identifier,
+			Conditions:        []metav1.Condition{buildAppliedStatusCondition(nil, 1)},
+			LastAppliedTime:   &now,
+			LastAvailableTime: &now,
+		})
+	}
+	return results, manifestConditions
+}
+
+func BenchmarkBuildManifestConditions(b *testing.B) {
+	results, previous := newBenchmarkResults()
+	now := metav1.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		manifestConditions := make([]workv1alpha1.ManifestCondition, 0, len(results))
+		for _, result := range results {
+			manifestConditions = append(manifestConditions, buildManifestCondition(result, previous, 1, &now))
+		}
+	}
+}
+
+func BenchmarkIsWorkStatusChanged(b *testing.B) {
+	_, manifestConditions := newBenchmarkResults()
+	status := workv1alpha1.WorkStatus{
+		Conditions:         []metav1.Condition{generateWorkAppliedStatusCondition(manifestConditions, 1)},
+		ManifestConditions: manifestConditions,
+	}
+	original := *status.DeepCopy()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if isWorkStatusChanged(original, status) {
+			b.Fatal("expected the status to be unchanged")
+		}
+	}
+}
@@ -25,6 +25,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -109,12 +110,388 @@ var _ = Describe("Work Controller", func() {
 					return fmt.Errorf("Exepect condition status of the manifest to be true")
 				}
 
+				if resultWork.Status.ManifestConditions[0].LastAppliedTime == nil || resultWork.Status.ManifestConditions[0].LastAvailableTime == nil {
+					return fmt.Errorf("Expect the applied and available times of the manifest to be set")
+				}
+
 				if !meta.IsStatusConditionTrue(resultWork.Status.Conditions, "Applied") {
 					return fmt.Errorf("Exepect condition status of the work to be true")
 				}
 
+				if resultWork.Status.ObservedGeneration != resultWork.Generation {
+					return fmt.Errorf("Expect the generation of the work to be observed")
+				}
+
+				if resultWork.Status.WorkloadHash == "" {
+					return fmt.Errorf("Expect the workload hash of the work to be set")
+				}
+
+				if !meta.IsStatusConditionTrue(resultWork.Status.Conditions, "Available") {
+					return fmt.Errorf("Expect the configmap of the work to be available")
+				}
+
+				return nil
+			}, timeout, interval).Should(Succeed())
+
+			resultCM, err := k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(context.Background(), cmName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resultCM.Annotations).To(HaveKeyWithValue(workv1alpha1.WorkGenerationAnnotation, "1"))
+			Expect(resultCM.Annotations).To(HaveKey(workv1alpha1.WorkloadHashAnnotation))
+			Expect(resultCM.Annotations).To(HaveKey(workv1alpha1.AppliedTimeAnnotation))
+			Expect(resultCM.Annotations).To(HaveKey(specHashAnnotation))
+		})
+
+		It("Should not apply a skipped manifest", func() {
+			cmName := "skippedcm"
+			cmNamespace := "default"
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cmName,
+					Namespace: cmNamespace,
+				},
+			}
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "skipped-configmap-work",
+					Namespace: workNamespace,
+					Annotations: map[string]string{
+						workv1alpha1.SkipManifestsAnnotation: "configmap/default/skippedcm",
+					},
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: cm},
+							},
+						},
+					},
+				},
+			}
+
+			_, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				resultWork, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if len(resultWork.Status.ManifestConditions) != 1 {
+					return fmt.Errorf("Expect the 1 manifest condition is updated")
+				}
+
+				if !meta.IsStatusConditionTrue(resultWork.Status.ManifestConditions[0].Conditions, "Skipped") {
+					return fmt.Errorf("Exepect the manifest to be skipped")
+				}
+
+				return nil
+			}, timeout, interval).Should(Succeed())
+
+			_, err = k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(context.Background(), cmName, metav1.GetOptions{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("Should not update a resource opted out on the spoke", func() {
+			cmName := "unmanagedcm"
+			cmNamespace := "default"
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      cmName,
+					Namespace: cmNamespace,
+				},
+				Data: map[string]string{
+					"test": "work",
+				},
+			}
+
+			spokeCM := cm.DeepCopy()
+			spokeCM.Annotations = map[string]string{workv1alpha1.UnmanagedAnnotation: "true"}
+			spokeCM.Data["test"] = "spoke"
+			_, err := k8sClient.CoreV1().ConfigMaps(cmNamespace).Create(context.Background(), spokeCM, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "unmanaged-configmap-work",
+					Namespace: workNamespace,
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: cm},
+							},
+						},
+					},
+				},
+			}
+
+			_, err = workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				resultWork, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if !meta.IsStatusConditionTrue(resultWork.Status.Conditions, "ExternallyManaged") {
+					return fmt.Errorf("Exepect the work to report an externally managed manifest")
+				}
+
 				return nil
 			}, timeout, interval).Should(Succeed())
+
+			resultCM, err := k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(context.Background(), cmName, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resultCM.Data["test"]).To(Equal("spoke"))
+		})
+
+		It("Should track applied resources and delete those removed from the work", func() {
+			cmNamespace := "default"
+			newConfigMap := func(name string) *corev1.ConfigMap {
+				return &corev1.ConfigMap{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: cmNamespace,
+					},
+					Data: map[string]string{
+						"test": "test",
+					},
+				}
+			}
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pruned-configmap-work",
+					Namespace: workNamespace,
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: newConfigMap("prunedcm")},
+							},
+						},
+					},
+				},
+			}
+
+			_, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				appliedWork, err := workClient.MulticlusterV1alpha1().AppliedWorks().Get(context.Background(), workv1alpha1.AppliedWorkName(work.Namespace, work.Name), metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if len(appliedWork.Status.AppliedResources) != 1 || appliedWork.Status.AppliedResources[0].Name != "prunedcm" {
+					return fmt.Errorf("Expect the configmap to be recorded as applied")
+				}
+
+				return nil
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() error {
+				resultWork, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				resultWork.Spec.Workload.Manifests = []workv1alpha1.Manifest{
+					{
+						RawExtension: runtime.RawExtension{Object: newConfigMap("keptcm")},
+					},
+				}
+				_, err = workClient.MulticlusterV1alpha1().Works(workNamespace).Update(context.Background(), resultWork, metav1.UpdateOptions{})
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() bool {
+				_, err := k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(context.Background(), "prunedcm", metav1.GetOptions{})
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+			_, err = k8sClient.CoreV1().ConfigMaps(cmNamespace).Get(context.Background(), "keptcm", metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should leave the resources of an orphaned work", func() {
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "orphanedcm",
+					Namespace: "default",
+				},
+			}
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "orphaned-configmap-work",
+					Namespace:   workNamespace,
+					Annotations: map[string]string{workv1alpha1.OrphanAnnotation: "true"},
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: cm},
+							},
+						},
+					},
+				},
+			}
+
+			_, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				_, err := k8sClient.CoreV1().ConfigMaps(cm.Namespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			err = workClient.MulticlusterV1alpha1().Works(workNamespace).Delete(context.Background(), work.Name, metav1.DeleteOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() bool {
+				_, err := workClient.MulticlusterV1alpha1().AppliedWorks().Get(context.Background(), workv1alpha1.AppliedWorkName(work.Namespace, work.Name), metav1.GetOptions{})
+				return errors.IsNotFound(err)
+			}, timeout, interval).Should(BeTrue())
+
+			_, err = k8sClient.CoreV1().ConfigMaps(cm.Namespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Should apply a work again when a resync is requested", func() {
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "resyncedcm",
+					Namespace: "default",
+				},
+				Data: map[string]string{
+					"test": "test",
+				},
+			}
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "resynced-configmap-work",
+					Namespace: workNamespace,
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: cm},
+							},
+						},
+					},
+				},
+			}
+
+			_, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				_, err := k8sClient.CoreV1().ConfigMaps(cm.Namespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			// the data of a configmap drifts without changing its generation
+			drifted, err := k8sClient.CoreV1().ConfigMaps(cm.Namespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			drifted.Data["test"] = "drifted"
+			_, err = k8sClient.CoreV1().ConfigMaps(cm.Namespace).Update(context.Background(), drifted, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			resultWork, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			resultWork.Annotations = map[string]string{workv1alpha1.ResyncAnnotation: "1"}
+			_, err = workClient.MulticlusterV1alpha1().Works(workNamespace).Update(context.Background(), resultWork, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() string {
+				resultCM, err := k8sClient.CoreV1().ConfigMaps(cm.Namespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+				if err != nil {
+					return ""
+				}
+				return resultCM.Data["test"]
+			}, timeout, interval).Should(Equal("test"))
+
+			Eventually(func() string {
+				appliedWork, err := workClient.MulticlusterV1alpha1().AppliedWorks().Get(context.Background(), workv1alpha1.AppliedWorkName(work.Namespace, work.Name), metav1.GetOptions{})
+				if err != nil {
+					return ""
+				}
+				return appliedWork.Annotations[workv1alpha1.ResyncAnnotation]
+			}, timeout, interval).Should(Equal("1"))
+		})
+
+		It("Should apply a manifest without a namespace to the default namespace of the work", func() {
+			cm := &corev1.ConfigMap{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ConfigMap",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "defaultedcm",
+				},
+				Data: map[string]string{
+					"test": "test",
+				},
+			}
+
+			work := &workv1alpha1.Work{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "defaulted-configmap-work",
+					Namespace: workNamespace,
+				},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{
+								RawExtension: runtime.RawExtension{Object: cm},
+							},
+						},
+					},
+					DefaultNamespace: workNamespace,
+				},
+			}
+
+			_, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Create(context.Background(), work, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(func() error {
+				_, err := k8sClient.CoreV1().ConfigMaps(workNamespace).Get(context.Background(), cm.Name, metav1.GetOptions{})
+				return err
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func() string {
+				resultWork, err := workClient.MulticlusterV1alpha1().Works(workNamespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+				if err != nil || len(resultWork.Status.ManifestConditions) != 1 {
+					return ""
+				}
+				return resultWork.Status.ManifestConditions[0].Identifier.Namespace
+			}, timeout, interval).Should(Equal(workNamespace))
 		})
 	})
 })
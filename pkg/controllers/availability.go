@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// AvailabilityEvaluator returns whether a resource applied on the spoke cluster is available,
+// i.e. ready to serve, and otherwise a message explaining why not. It reads the live resource,
+// including its status, and must not modify it.
+type AvailabilityEvaluator func(obj *unstructured.Unstructured) (bool, string)
+
+// DefaultAvailabilityEvaluators returns the built-in evaluators of the resource kinds whose
+// availability is more than their existence: Deployments, DaemonSets, Jobs and Pods. The
+// resources of the other kinds are available as soon as they exist.
+func DefaultAvailabilityEvaluators() map[schema.GroupKind]AvailabilityEvaluator {
+	return map[schema.GroupKind]AvailabilityEvaluator{
+		{Group: "apps", Kind: "Deployment"}: deploymentAvailable,
+		{Group: "apps", Kind: "DaemonSet"}:  daemonSetAvailable,
+		{Group: "batch", Kind: "Job"}:       jobAvailable,
+		{Kind: "Pod"}:                       podAvailable,
+	}
+}
+
+// availabilityPolicy is how the availability of the required manifests of a Work is aggregated
+// into the Available condition of the Work, set by the availability-policy annotation.
+type availabilityPolicy struct {
+	// any is true if a single available manifest makes the Work available.
+	any bool
+	// percent is the minimum percentage of available manifests, 100 by default.
+	percent int
+}
+
+// parseAvailabilityPolicy parses the value of the availability-policy annotation: All, Any or a
+// percentage such as 80%. An empty value is All.
+func parseAvailabilityPolicy(value string) (availabilityPolicy, error) {
+	switch value = strings.TrimSpace(value); {
+	case value == "" || strings.EqualFold(value, "All"):
+		return availabilityPolicy{percent: 100}, nil
+	case strings.EqualFold(value, "Any"):
+		return availabilityPolicy{any: true}, nil
+	case strings.HasSuffix(value, "%"):
+		percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+		if err == nil && percent >= 0 && percent <= 100 {
+			return availabilityPolicy{percent: percent}, nil
+		}
+	}
+	return availabilityPolicy{}, fmt.Errorf("invalid availability policy %q, expected All, Any or a percentage between 0%% and 100%%", value)
+}
+
+// satisfied returns whether available manifests out of total make the Work available.
+func (p availabilityPolicy) satisfied(available, total int) bool {
+	if p.any {
+		return total == 0 || available > 0
+	}
+	return available*100 >= p.percent*total
+}
+
+// evaluateAvailability returns whether a resource is available according to the evaluator of its kind.
+func evaluateAvailability(evaluators map[schema.GroupKind]AvailabilityEvaluator, obj *unstructured.Unstructured) (bool, string) {
+	evaluate, ok := evaluators[obj.GroupVersionKind().GroupKind()]
+	if !ok {
+		return true, "Resource exists"
+	}
+	return evaluate(obj)
+}
+
+func deploymentAvailable(obj *unstructured.Unstructured) (bool, string) {
+	if !isGenerationObserved(obj) {
+		return false, "Deployment spec is not observed yet"
+	}
+	replicas := nestedInt64(obj, 1, "spec", "replicas")
+	if updated := nestedInt64(obj, 0, "status", "updatedReplicas"); updated < replicas {
+		return false, fmt.Sprintf("%d of %d replicas are updated", updated, replicas)
+	}
+	if available := nestedInt64(obj, 0, "status", "availableReplicas"); available < replicas {
+		return false, fmt.Sprintf("%d of %d replicas are available", available, replicas)
+	}
+	return true, "Deployment is available"
+}
+
+func daemonSetAvailable(obj *unstructured.Unstructured) (bool, string) {
+	if !isGenerationObserved(obj) {
+		return false, "DaemonSet spec is not observed yet"
+	}
+	desired := nestedInt64(obj, 0, "status", "desiredNumberScheduled")
+	if updated := nestedInt64(obj, 0, "status", "updatedNumberScheduled"); updated < desired {
+		return false, fmt.Sprintf("%d of %d pods are updated", updated, desired)
+	}
+	if available := nestedInt64(obj, 0, "status", "numberAvailable"); available < desired {
+		return false, fmt.Sprintf("%d of %d pods are available", available, desired)
+	}
+	return true, "DaemonSet is available"
+}
+
+func jobAvailable(obj *unstructured.Unstructured) (bool, string) {
+	if condition := findUnstructuredCondition(obj, "Failed"); condition != nil && condition.Status == metav1.ConditionTrue {
+		return false, fmt.Sprintf("Job failed: %s", condition.Message)
+	}
+	if condition := findUnstructuredCondition(obj, "Complete"); condition != nil && condition.Status == metav1.ConditionTrue {
+		return true, "Job is complete"
+	}
+	return false, "Job is not complete yet"
+}
+
+func podAvailable(obj *unstructured.Unstructured) (bool, string) {
+	if phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase"); phase == "Succeeded" {
+		return true, "Pod succeeded"
+	}
+	if condition := findUnstructuredCondition(obj, "Ready"); condition != nil && condition.Status == metav1.ConditionTrue {
+		return true, "Pod is ready"
+	}
+	return false, "Pod is not ready"
+}
+
+// isGenerationObserved returns true if the controller of a resource observed its current spec.
+func isGenerationObserved(obj *unstructured.Unstructured) bool {
+	return nestedInt64(obj, 0, "status", "observedGeneration") >= obj.GetGeneration()
+}
+
+func nestedInt64(obj *unstructured.Unstructured, defaultValue int64, fields ...string) int64 {
+	value, found, err := unstructured.NestedInt64(obj.Object, fields...)
+	if !found || err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// findUnstructuredCondition returns the condition of the given type in the status of a resource, or nil.
+func findUnstructuredCondition(obj *unstructured.Unstructured, conditionType string) *metav1.Condition {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		message, _ := condition["message"].(string)
+		return &metav1.Condition{Type: conditionType, Status: metav1.ConditionStatus(status), Message: message}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newTestResource(apiVersion, kind string, generation int64, fields map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: fields}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetName("resource")
+	obj.SetGeneration(generation)
+	return obj
+}
+
+func TestEvaluateAvailability(t *testing.T) {
+	cases := []struct {
+		name              string
+		obj               *unstructured.Unstructured
+		expectedAvailable bool
+		expectedMessage   string
+	}{
+		{
+			name:              "configmap",
+			obj:               newTestResource("v1", "ConfigMap", 0, map[string]interface{}{}),
+			expectedAvailable: true,
+			expectedMessage:   "Resource exists",
+		},
+		{
+			name: "deployment not observed",
+			obj: newTestResource("apps/v1", "Deployment", 2, map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": int64(1)},
+			}),
+			expectedMessage: "Deployment spec is not observed yet",
+		},
+		{
+			name: "deployment rolling out",
+			obj: newTestResource("apps/v1", "Deployment", 1, map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(3)},
+				"status": map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": int64(3), "availableReplicas": int64(2)},
+			}),
+			expectedMessage: "2 of 3 replicas are available",
+		},
+		{
+			name: "deployment available",
+			obj: newTestResource("apps/v1", "Deployment", 1, map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": int64(1), "updatedReplicas": int64(1), "availableReplicas": int64(1)},
+			}),
+			expectedAvailable: true,
+			expectedMessage:   "Deployment is available",
+		},
+		{
+			name: "daemonset updating",
+			obj: newTestResource("apps/v1", "DaemonSet", 1, map[string]interface{}{
+				"status": map[string]interface{}{"observedGeneration": int64(1), "desiredNumberScheduled": int64(2), "updatedNumberScheduled": int64(1)},
+			}),
+			expectedMessage: "1 of 2 pods are updated",
+		},
+		{
+			name: "job complete",
+			obj: newTestResource("batch/v1", "Job", 1, map[string]interface{}{
+				"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Complete", "status": "True"}}},
+			}),
+			expectedAvailable: true,
+			expectedMessage:   "Job is complete",
+		},
+		{
+			name: "job failed",
+			obj: newTestResource("batch/v1", "Job", 1, map[string]interface{}{
+				"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Failed", "status": "True", "message": "BackoffLimitExceeded"}}},
+			}),
+			expectedMessage: "Job failed: BackoffLimitExceeded",
+		},
+		{
+			name: "pod not ready",
+			obj: newTestResource("v1", "Pod", 0, map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Running", "conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "False"}}},
+			}),
+			expectedMessage: "Pod is not ready",
+		},
+		{
+			name: "pod succeeded",
+			obj: newTestResource("v1", "Pod", 0, map[string]interface{}{
+				"status": map[string]interface{}{"phase": "Succeeded"},
+			}),
+			expectedAvailable: true,
+			expectedMessage:   "Pod succeeded",
+		},
+	}
+
+	evaluators := DefaultAvailabilityEvaluators()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			available, message := evaluateAvailability(evaluators, c.obj)
+			if available != c.expectedAvailable || message != c.expectedMessage {
+				t.Errorf("expected %v %q, got %v %q", c.expectedAvailable, c.expectedMessage, available, message)
+			}
+		})
+	}
+}
+
+func TestCustomAvailabilityEvaluator(t *testing.T) {
+	evaluators := DefaultAvailabilityEvaluators()
+	evaluators[schema.GroupKind{Group: "example.com", Kind: "Database"}] = func(obj *unstructured.Unstructured) (bool, string) {
+		ready, _, _ := unstructured.NestedBool(obj.Object, "status", "ready")
+		return ready, "Database readiness"
+	}
+
+	database := newTestResource("example.com/v1", "Database", 1, map[string]interface{}{})
+	if available, _ := evaluateAvailability(evaluators, database); available {
+		t.Error("expected the database not to be available before it is ready")
+	}
+	if err := unstructured.SetNestedField(database.Object, true, "status", "ready"); err != nil {
+		t.Fatal(err)
+	}
+	if available, _ := evaluateAvailability(evaluators, database); !available {
+		t.Error("expected the ready database to be available")
+	}
+}
+
+func newAvailabilityManifestCondition(name string, status metav1.ConditionStatus) workv1alpha1.ManifestCondition {
+	return workv1alpha1.ManifestCondition{
+		Identifier: workv1alpha1.ResourceIdentifier{Kind: "Deployment", Namespace: "default", Name: name},
+		Conditions: []metav1.Condition{{Type: "Available", Status: status}},
+	}
+}
+
+func TestGenerateWorkAvailableStatusCondition(t *testing.T) {
+	manifestConditions := []workv1alpha1.ManifestCondition{
+		newAvailabilityManifestCondition("app", metav1.ConditionTrue),
+		newAvailabilityManifestCondition("addon", metav1.ConditionFalse),
+	}
+
+	if condition := generateWorkAvailableStatusCondition(manifestConditions, nil, "", 1); condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected the work not to be available, got %+v", condition)
+	}
+
+	optional := parseManifestKeys("deployment/default/addon")
+	condition := generateWorkAvailableStatusCondition(manifestConditions, optional, "", 1)
+	if condition.Status != metav1.ConditionTrue {
+		t.Errorf("expected the work to be available without its optional manifest, got %+v", condition)
+	}
+	if expected := "All the required manifests are available, 0 of 1 optional manifests are available"; condition.Message != expected {
+		t.Errorf("expected the message %q, got %q", expected, condition.Message)
+	}
+	if areManifestsAvailable(manifestConditions) {
+		t.Error("expected the optional manifest to be checked again until it is available")
+	}
+}
+
+func TestAvailabilityPolicy(t *testing.T) {
+	manifestConditions := []workv1alpha1.ManifestCondition{
+		newAvailabilityManifestCondition("a", metav1.ConditionTrue),
+		newAvailabilityManifestCondition("b", metav1.ConditionTrue),
+		newAvailabilityManifestCondition("c", metav1.ConditionTrue),
+		newAvailabilityManifestCondition("d", metav1.ConditionFalse),
+	}
+	tests := map[string]struct {
+		policy    string
+		available metav1.ConditionStatus
+	}{
+		"all by default":        {policy: "", available: metav1.ConditionFalse},
+		"all":                   {policy: "All", available: metav1.ConditionFalse},
+		"any":                   {policy: "any", available: metav1.ConditionTrue},
+		"threshold reached":     {policy: "75%", available: metav1.ConditionTrue},
+		"threshold not reached": {policy: "80%", available: metav1.ConditionFalse},
+		"invalid":               {policy: "most", available: metav1.ConditionFalse},
+		"invalid percentage":    {policy: "120%", available: metav1.ConditionFalse},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			condition := generateWorkAvailableStatusCondition(manifestConditions, nil, tt.policy, 1)
+			if condition.Status != tt.available {
+				t.Errorf("expected the Available condition to be %s, got %+v", tt.available, condition)
+			}
+		})
+	}
+
+	if _, err := parseAvailabilityPolicy("ten%"); err == nil {
+		t.Error("expected an invalid percentage to be rejected")
+	}
+	if !(availabilityPolicy{any: true}).satisfied(0, 0) {
+		t.Error("expected a work without required manifests to be available")
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// manifestBackoffBase is the delay before retrying a manifest which failed once.
+	manifestBackoffBase = 5 * time.Second
+	// manifestBackoffMax caps the delay before retrying a manifest which keeps failing.
+	manifestBackoffMax = 5 * time.Minute
+)
+
+// manifestBackoff delays the retries of the manifests which keep failing to apply, doubling the
+// delay on every failure. The failures are forgotten when the manifest is applied or the spec of
+// its work changes.
+type manifestBackoff struct {
+	lock    sync.Mutex
+	entries map[string]backoffEntry
+}
+
+type backoffEntry struct {
+	generation int64
+	failures   int
+	retryAt    time.Time
+}
+
+func newManifestBackoff() *manifestBackoff {
+	return &manifestBackoff{entries: map[string]backoffEntry{}}
+}
+
+// wait returns how long a manifest of a work must wait before it is applied again.
+func (b *manifestBackoff) wait(work types.NamespacedName, ordinal int, generation int64, now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	entry, ok := b.entries[backoffKey(work, ordinal)]
+	if !ok || entry.generation != generation || !now.Before(entry.retryAt) {
+		return 0
+	}
+	return entry.retryAt.Sub(now)
+}
+
+// failed records a failure of a manifest of a work and returns the delay before its next retry.
+func (b *manifestBackoff) failed(work types.NamespacedName, ordinal int, generation int64, now time.Time) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	key := backoffKey(work, ordinal)
+	entry := b.entries[key]
+	if entry.generation != generation {
+		entry = backoffEntry{generation: generation}
+	}
+	entry.failures++
+
+	delay := manifestBackoffMax
+	if entry.failures <= 16 {
+		if d := manifestBackoffBase << (entry.failures - 1); d < delay {
+			delay = d
+		}
+	}
+	entry.retryAt = now.Add(delay)
+	b.entries[key] = entry
+	return delay
+}
+
+// succeeded forgets the failures of a manifest of a work.
+func (b *manifestBackoff) succeeded(work types.NamespacedName, ordinal int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.entries, backoffKey(work, ordinal))
+}
+
+// forget forgets the failures of all the manifests of a work.
+func (b *manifestBackoff) forget(work types.NamespacedName) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	prefix := work.String() + "#"
+	for key := range b.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(b.entries, key)
+		}
+	}
+}
+
+func backoffKey(work types.NamespacedName, ordinal int) string {
+	return fmt.Sprintf("%s#%d", work, ordinal)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// completionStatus is the completion of a one-shot workload applied on the spoke cluster.
+type completionStatus struct {
+	complete bool
+	failed   bool
+	message  string
+}
+
+// evaluateCompletion returns the completion of a Job, or of the runs of a CronJob, nil for the
+// resources of the other kinds which do not complete.
+func evaluateCompletion(obj *unstructured.Unstructured) *completionStatus {
+	if obj.GroupVersionKind().Group != "batch" {
+		return nil
+	}
+	switch obj.GetKind() {
+	case "Job":
+		if condition := findUnstructuredCondition(obj, "Failed"); condition != nil && condition.Status == metav1.ConditionTrue {
+			return &completionStatus{failed: true, message: fmt.Sprintf("Job failed: %s", condition.Message)}
+		}
+		if condition := findUnstructuredCondition(obj, "Complete"); condition != nil && condition.Status == metav1.ConditionTrue {
+			return &completionStatus{complete: true, message: "Job is complete"}
+		}
+		return &completionStatus{message: "Job is not complete yet"}
+	case "CronJob":
+		if lastSuccessfulTime, _, _ := unstructured.NestedString(obj.Object, "status", "lastSuccessfulTime"); lastSuccessfulTime != "" {
+			return &completionStatus{complete: true, message: fmt.Sprintf("CronJob last succeeded at %s", lastSuccessfulTime)}
+		}
+		return &completionStatus{message: "CronJob did not complete a run yet"}
+	}
+	return nil
+}
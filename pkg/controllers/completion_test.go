@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+func TestEvaluateCompletion(t *testing.T) {
+	newObj := func(kind string, status map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+		obj.SetAPIVersion("batch/v1")
+		obj.SetKind(kind)
+		return obj
+	}
+	jobConditions := func(conditionType string) map[string]interface{} {
+		return map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": conditionType, "status": "True", "message": "BackoffLimitExceeded"},
+		}}
+	}
+	tests := map[string]struct {
+		obj      *unstructured.Unstructured
+		expected *completionStatus
+	}{
+		"running job":  {obj: newObj("Job", map[string]interface{}{}), expected: &completionStatus{message: "Job is not complete yet"}},
+		"complete job": {obj: newObj("Job", jobConditions("Complete")), expected: &completionStatus{complete: true, message: "Job is complete"}},
+		"failed job":   {obj: newObj("Job", jobConditions("Failed")), expected: &completionStatus{failed: true, message: "Job failed: BackoffLimitExceeded"}},
+		"new cronjob":  {obj: newObj("CronJob", map[string]interface{}{}), expected: &completionStatus{message: "CronJob did not complete a run yet"}},
+		"cronjob ran":  {obj: newObj("CronJob", map[string]interface{}{"lastSuccessfulTime": "2021-06-01T10:00:00Z"}), expected: &completionStatus{complete: true, message: "CronJob last succeeded at 2021-06-01T10:00:00Z"}},
+		"other kind":   {obj: newObj("Deployment", map[string]interface{}{})},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual := evaluateCompletion(tt.obj)
+			if (actual == nil) != (tt.expected == nil) || actual != nil && *actual != *tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGenerateWorkCompleteStatusCondition(t *testing.T) {
+	newManifestCondition := func(name string, completion completionStatus) workv1alpha1.ManifestCondition {
+		return workv1alpha1.ManifestCondition{
+			Identifier: workv1alpha1.ResourceIdentifier{Kind: "Job", Namespace: "default", Name: name},
+			Conditions: []metav1.Condition{buildCompleteStatusCondition(completion, 1)},
+		}
+	}
+	deployment := workv1alpha1.ManifestCondition{Identifier: workv1alpha1.ResourceIdentifier{Kind: "Deployment", Name: "app"}}
+
+	if condition := generateWorkCompleteStatusCondition([]workv1alpha1.ManifestCondition{deployment}, 1); condition != nil {
+		t.Errorf("expected no Complete condition without jobs, got %+v", condition)
+	}
+
+	manifestConditions := []workv1alpha1.ManifestCondition{
+		deployment,
+		newManifestCondition("migrate", completionStatus{complete: true}),
+		newManifestCondition("seed", completionStatus{}),
+	}
+	condition := generateWorkCompleteStatusCondition(manifestConditions, 1)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != string(reasons.WorkIncomplete) {
+		t.Errorf("expected the work to be incomplete, got %+v", condition)
+	}
+
+	manifestConditions[2] = newManifestCondition("seed", completionStatus{complete: true})
+	if condition := generateWorkCompleteStatusCondition(manifestConditions, 1); condition == nil || !conditions.IsComplete([]metav1.Condition{*condition}) {
+		t.Errorf("expected the work to be complete, got %+v", condition)
+	}
+
+	manifestConditions[1] = newManifestCondition("migrate", completionStatus{failed: true})
+	condition = generateWorkCompleteStatusCondition(manifestConditions, 1)
+	if condition == nil || condition.Status != metav1.ConditionFalse || condition.Reason != string(reasons.WorkFailed) {
+		t.Errorf("expected the work to have failed, got %+v", condition)
+	}
+}
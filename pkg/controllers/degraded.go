@@ -0,0 +1,165 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+const (
+	// degradedAfter is how long the resource of a manifest is not available before the manifest
+	// is degraded.
+	degradedAfter = 5 * time.Minute
+
+	// degradedNotificationWindow is how long the Degraded events of a work are suppressed after
+	// one is recorded. The window doubles while the manifests of the work keep flapping, up to
+	// degradedNotificationMaxWindow.
+	degradedNotificationWindow    = time.Minute
+	degradedNotificationMaxWindow = time.Hour
+
+	// eventReasonManifestsDegraded is the reason of the events recorded on the works on the hub
+	// when manifests become degraded.
+	eventReasonManifestsDegraded = "ManifestsDegraded"
+)
+
+// buildDegradedStatusCondition returns the Degraded condition of a manifest whose resource has not
+// been available for degradedAfter, or nil if it is not degraded.
+func buildDegradedStatusCondition(manifestConditions []metav1.Condition, now time.Time, observedGeneration int64) *metav1.Condition {
+	available := meta.FindStatusCondition(manifestConditions, conditions.TypeAvailable)
+	if available == nil || available.Status != metav1.ConditionFalse {
+		return nil
+	}
+	if available.LastTransitionTime.IsZero() || now.Sub(available.LastTransitionTime.Time) < degradedAfter {
+		return nil
+	}
+	// the message does not change with the time, so the status is not updated on every sync
+	return &metav1.Condition{
+		Type:               conditions.TypeDegraded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reasons.ManifestDegraded),
+		Message:            fmt.Sprintf("Resource has not been available for more than %s: %s", degradedAfter, available.Message),
+	}
+}
+
+// generateWorkDegradedStatusCondition generates the degraded status condition for work, or nil if
+// none of its manifests which are neither skipped nor optional is degraded.
+func generateWorkDegradedStatusCondition(
+	manifestConditions []workv1alpha1.ManifestCondition,
+	optionalManifests map[string]bool,
+	observedGeneration int64) *metav1.Condition {
+	degraded := 0
+	for _, manifestCond := range manifestConditions {
+		if conditions.IsDegraded(manifestCond.Conditions) && !optionalManifests[identifierKey(manifestCond.Identifier)] &&
+			!meta.IsStatusConditionTrue(manifestCond.Conditions, conditions.TypeSkipped) {
+			degraded++
+		}
+	}
+	if degraded == 0 {
+		return nil
+	}
+	return &metav1.Condition{
+		Type:               conditions.TypeDegraded,
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.WorkDegraded),
+		Message:            fmt.Sprintf("%d of %d manifests are degraded", degraded, len(manifestConditions)),
+		ObservedGeneration: observedGeneration,
+	}
+}
+
+// degradedNotifier records an event on a work on the hub when some of its manifests become
+// degraded, at most once per window of the work. The window doubles when the manifests become
+// degraded again within twice the window, i.e. while they flap, and is reset once they settle.
+type degradedNotifier struct {
+	recorder record.EventRecorder
+
+	lock    sync.Mutex
+	entries map[types.NamespacedName]degradedNotification
+}
+
+type degradedNotification struct {
+	notifiedAt time.Time
+	window     time.Duration
+}
+
+func newDegradedNotifier(recorder record.EventRecorder) *degradedNotifier {
+	return &degradedNotifier{recorder: recorder, entries: map[types.NamespacedName]degradedNotification{}}
+}
+
+// notify counts the manifests of a work which became degraded and records an event on the work,
+// unless the events of the work are suppressed. It returns whether the event was recorded.
+func (n *degradedNotifier) notify(work *workv1alpha1.Work, degraded []workv1alpha1.ResourceIdentifier, now time.Time) bool {
+	if n == nil || len(degraded) == 0 {
+		return false
+	}
+	for _, identifier := range degraded {
+		manifestsDegraded.WithLabelValues(identifier.Group, identifier.Kind).Inc()
+	}
+	if !n.allow(types.NamespacedName{Namespace: work.Namespace, Name: work.Name}, now) {
+		degradedEventsSuppressed.Inc()
+		return false
+	}
+	described := make([]string, 0, len(degraded))
+	for _, identifier := range degraded {
+		described = append(described, describeResource(identifier))
+	}
+	n.recorder.Eventf(work, corev1.EventTypeWarning, eventReasonManifestsDegraded,
+		"%s not available for %s", strings.Join(described, ", "), degradedAfter)
+	return true
+}
+
+// allow returns whether an event may be recorded on a work, and if so starts its next window.
+func (n *degradedNotifier) allow(work types.NamespacedName, now time.Time) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	entry, ok := n.entries[work]
+	if ok && now.Before(entry.notifiedAt.Add(entry.window)) {
+		return false
+	}
+	window := degradedNotificationWindow
+	if ok && now.Before(entry.notifiedAt.Add(2*entry.window)) {
+		window = 2 * entry.window
+		if window > degradedNotificationMaxWindow {
+			window = degradedNotificationMaxWindow
+		}
+	}
+	n.entries[work] = degradedNotification{notifiedAt: now, window: window}
+	return true
+}
+
+// forget drops the window of a deleted work.
+func (n *degradedNotifier) forget(work types.NamespacedName) {
+	if n == nil {
+		return
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	delete(n.entries, work)
+}
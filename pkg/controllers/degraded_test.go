@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+func TestBuildDegradedStatusCondition(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	availableCondition := func(status metav1.ConditionStatus, since time.Duration) []metav1.Condition {
+		return []metav1.Condition{{
+			Type: conditions.TypeAvailable, Status: status, LastTransitionTime: metav1.NewTime(now.Add(-since)), Message: "0 of 1 replicas are available",
+		}}
+	}
+
+	if condition := buildDegradedStatusCondition(availableCondition(metav1.ConditionTrue, time.Hour), now, 1); condition != nil {
+		t.Errorf("expected an available manifest not to be degraded, got %+v", condition)
+	}
+	if condition := buildDegradedStatusCondition(availableCondition(metav1.ConditionFalse, time.Minute), now, 1); condition != nil {
+		t.Errorf("expected a manifest unavailable for a minute not to be degraded yet, got %+v", condition)
+	}
+	condition := buildDegradedStatusCondition(availableCondition(metav1.ConditionFalse, 10*time.Minute), now, 1)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected a manifest unavailable for 10 minutes to be degraded, got %+v", condition)
+	}
+	if later := buildDegradedStatusCondition(availableCondition(metav1.ConditionFalse, 20*time.Minute), now, 1); later.Message != condition.Message {
+		t.Errorf("expected the message not to change with time, got %q and %q", condition.Message, later.Message)
+	}
+}
+
+func TestDegradedNotifier(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	notifier := newDegradedNotifier(recorder)
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Namespace: "cluster1", Name: "app"}}
+	degraded := []workv1alpha1.ResourceIdentifier{{Group: "apps", Kind: "Deployment", Resource: "deployments", Namespace: "default", Name: "app"}}
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		after    time.Duration
+		notified bool
+	}{
+		{after: 0, notified: true},
+		// suppressed within the first window of a minute
+		{after: 30 * time.Second, notified: false},
+		// flapping within two windows doubles the window to 2 minutes
+		{after: 90 * time.Second, notified: true},
+		{after: 3 * time.Minute, notified: false},
+		{after: 4 * time.Minute, notified: true},
+		// settled for more than two windows of 4 minutes, the window is reset
+		{after: 20 * time.Minute, notified: true},
+		{after: 21*time.Minute + time.Second, notified: true},
+	}
+	for _, step := range steps {
+		if notified := notifier.notify(work, degraded, now.Add(step.after)); notified != step.notified {
+			t.Errorf("expected the notification after %s to be %v, got %v", step.after, step.notified, notified)
+		}
+	}
+	if len(recorder.Events) != 5 {
+		t.Errorf("expected 5 events, got %d", len(recorder.Events))
+	}
+
+	if notifier.notify(work, nil, now.Add(time.Hour)) {
+		t.Error("expected no event without degraded manifests")
+	}
+}
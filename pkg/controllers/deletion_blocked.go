@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+const (
+	// deletionBlockedRecheckInterval is how often a deleted work whose resources are still
+	// terminating is finalized again, as the agent does not watch the resources.
+	deletionBlockedRecheckInterval = 10 * time.Second
+	// maxDeletionBlockedResources bounds the resources listed in the DeletionBlocked condition.
+	maxDeletionBlockedResources = 10
+)
+
+// blockingResource is a resource of a deleted work which is not deleted from the spoke cluster yet.
+type blockingResource struct {
+	identifier workv1alpha1.ResourceIdentifier
+	// finalizers are the finalizers holding a terminating resource.
+	finalizers []string
+	// err is the error deleting the resource, if it failed to be deleted.
+	err error
+}
+
+// findTerminatingResources returns the resources applied by a work which are deleted but still on
+// the spoke cluster. The resources recreated by someone else or left on the cluster, e.g.
+// externally managed, are not the ones of the work anymore and are ignored.
+func findTerminatingResources(ctx context.Context, applier Applier, resources []workv1alpha1.AppliedResourceMeta) ([]blockingResource, error) {
+	terminating := []blockingResource{}
+	for _, resource := range resources {
+		live, err := applier.Get(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+		if live == nil || live.GetDeletionTimestamp().IsZero() || (resource.UID != "" && live.GetUID() != resource.UID) {
+			continue
+		}
+		terminating = append(terminating, blockingResource{identifier: resource.ResourceIdentifier, finalizers: live.GetFinalizers()})
+	}
+	return terminating, nil
+}
+
+// buildDeletionBlockedCondition returns the DeletionBlocked condition of a deleted work listing the
+// resources blocking its deletion, the first maxDeletionBlockedResources of them.
+func buildDeletionBlockedCondition(blocking []blockingResource, observedGeneration int64) metav1.Condition {
+	reason := reasons.ResourcesTerminating
+	descriptions := []string{}
+	for i, resource := range blocking {
+		if resource.err != nil {
+			reason = reasons.ResourcesDeleteFailed
+		}
+		if i >= maxDeletionBlockedResources {
+			continue
+		}
+		switch {
+		case resource.err != nil:
+			descriptions = append(descriptions, fmt.Sprintf("%s failed to be deleted: %v", describeResource(resource.identifier), resource.err))
+		case len(resource.finalizers) > 0:
+			descriptions = append(descriptions, fmt.Sprintf("%s is terminating with the finalizers %s", describeResource(resource.identifier), strings.Join(resource.finalizers, ", ")))
+		default:
+			descriptions = append(descriptions, fmt.Sprintf("%s is terminating", describeResource(resource.identifier)))
+		}
+	}
+	if more := len(blocking) - len(descriptions); more > 0 {
+		descriptions = append(descriptions, fmt.Sprintf("and %d more", more))
+	}
+	return metav1.Condition{
+		Type:               conditions.TypeDeletionBlocked,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             string(reason),
+		Message:            fmt.Sprintf("Waiting for %d resources to be deleted: %s", len(blocking), strings.Join(descriptions, "; ")),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+func TestFindTerminatingResources(t *testing.T) {
+	now := metav1.Now()
+	terminating := newTestConfigMap(t, "terminating")
+	terminating.SetUID("terminating")
+	terminating.SetDeletionTimestamp(&now)
+	terminating.SetFinalizers([]string{"example.com/protect"})
+	recreated := newTestConfigMap(t, "recreated")
+	recreated.SetUID("recreated")
+	recreated.SetDeletionTimestamp(&now)
+	left := newTestConfigMap(t, "left")
+	left.SetUID("left")
+	applier, _ := newTestKubeApplier(t, terminating, recreated, left)
+
+	appliedResource := func(name, uid string) workv1alpha1.AppliedResourceMeta {
+		return workv1alpha1.AppliedResourceMeta{
+			ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "default", Name: name},
+			UID:                types.UID(uid),
+		}
+	}
+	resources := []workv1alpha1.AppliedResourceMeta{
+		appliedResource("terminating", "terminating"),
+		appliedResource("recreated", "applied"),
+		appliedResource("left", "left"),
+		appliedResource("gone", "gone"),
+	}
+
+	blocking, err := findTerminatingResources(context.Background(), applier, resources)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocking) != 1 || blocking[0].identifier.Name != "terminating" || len(blocking[0].finalizers) != 1 {
+		t.Errorf("expected only the terminating resource of the work, got %+v", blocking)
+	}
+}
+
+func TestBuildDeletionBlockedCondition(t *testing.T) {
+	identifier := func(name string) workv1alpha1.ResourceIdentifier {
+		return workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "default", Name: name}
+	}
+
+	condition := buildDeletionBlockedCondition([]blockingResource{
+		{identifier: identifier("a"), finalizers: []string{"example.com/protect", "example.com/backup"}},
+	}, 2)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != string(reasons.ResourcesTerminating) || condition.ObservedGeneration != 2 {
+		t.Errorf("expected the deletion to be blocked by terminating resources, got %+v", condition)
+	}
+	if !strings.Contains(condition.Message, "configmaps default/a is terminating with the finalizers example.com/protect, example.com/backup") {
+		t.Errorf("expected the message to list the finalizers, got %q", condition.Message)
+	}
+
+	blocking := []blockingResource{{identifier: identifier("failed"), err: fmt.Errorf("forbidden")}}
+	for i := 0; i < maxDeletionBlockedResources+2; i++ {
+		blocking = append(blocking, blockingResource{identifier: identifier(fmt.Sprintf("cm%d", i))})
+	}
+	condition = buildDeletionBlockedCondition(blocking, 2)
+	if condition.Reason != string(reasons.ResourcesDeleteFailed) {
+		t.Errorf("expected a failure reason, got %q", condition.Reason)
+	}
+	if !strings.Contains(condition.Message, "configmaps default/failed failed to be deleted: forbidden") || !strings.HasSuffix(condition.Message, "and 3 more") {
+		t.Errorf("expected the message to list the first resources, got %q", condition.Message)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// maxFieldManagerLength is the maximum length of a field manager accepted by the API server.
+const maxFieldManagerLength = 128
+
+// managedFieldsKey identifies the fields owned by a manager, the fields of a manager are merged
+// across the API versions it used.
+type managedFieldsKey struct {
+	manager   string
+	operation metav1.ManagedFieldsOperationType
+}
+
+// fieldManagerFor returns the field manager the resources of a work are applied as: the one of its
+// field-manager annotation, or one derived from its name and the hash of its hub, see hashHub.
+func fieldManagerFor(work *workv1alpha1.Work, hubHash string) (string, error) {
+	if manager, ok := work.Annotations[workv1alpha1.FieldManagerAnnotation]; ok {
+		if manager == "" || len(manager) > maxFieldManagerLength {
+			return "", fmt.Errorf("invalid %s annotation %q: the field manager must be 1 to %d characters long",
+				workv1alpha1.FieldManagerAnnotation, manager, maxFieldManagerLength)
+		}
+		return manager, nil
+	}
+	suffix := ""
+	if hubHash != "" {
+		suffix = "-" + hubHash
+	}
+	name := work.Name
+	if max := maxFieldManagerLength - len(applyFieldManager) - 1 - len(suffix); len(name) > max {
+		name = name[:max]
+	}
+	return applyFieldManager + "-" + name + suffix, nil
+}
+
+// hashHub returns a short hash of the address of the hub, which tells apart the field managers of
+// Works of the same name from several hubs.
+func hashHub(host string) string {
+	sum := sha256.Sum256([]byte(host))
+	return hex.EncodeToString(sum[:])[:8]
+}
+
+// overwrittenManagers returns the managers, other than the agent, which owned fields of a resource
+// before the agent updated it as manager and no longer own them after, i.e. whose values the update
+// changed. Those are the tools, e.g. Helm, Argo CD or kubectl, fighting with the agent over the
+// resource. The fields of the subresources are not changed by the agent and are ignored. The
+// applyFieldManager, which the agent applied all the resources as before the per-Work field
+// managers, is not reported.
+func overwrittenManagers(before, after []metav1.ManagedFieldsEntry, manager string) []string {
+	afterFields := managedFieldSets(after)
+	managers := map[string]bool{}
+	for key, fields := range managedFieldSets(before) {
+		if key.manager == manager || key.manager == applyFieldManager {
+			continue
+		}
+		remaining, ok := afterFields[key]
+		if !ok {
+			remaining = &fieldpath.Set{}
+		}
+		if !fields.Difference(remaining).Empty() {
+			managers[key.manager] = true
+		}
+	}
+	return sortedKeys(managers)
+}
+
+// managedFieldSets parses the fields owned by the managers of the main resource. The entries
+// which cannot be parsed are ignored.
+func managedFieldSets(entries []metav1.ManagedFieldsEntry) map[managedFieldsKey]*fieldpath.Set {
+	sets := map[managedFieldsKey]*fieldpath.Set{}
+	for _, entry := range entries {
+		if entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		if err := fields.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			continue
+		}
+		key := managedFieldsKey{manager: entry.Manager, operation: entry.Operation}
+		if existing, ok := sets[key]; ok {
+			fields = existing.Union(fields)
+		}
+		sets[key] = fields
+	}
+	return sets
+}
+
+// takeOverManagedFields returns the managed fields of a resource with the fields owned by the
+// managers from transferred to the manager to, as if they had been set by its updates, or nil if
+// none of the managers owns fields. The transferred entries are merged with the entries of the
+// updates of to of the same API version and subresource, the API server rejects duplicates.
+func takeOverManagedFields(entries []metav1.ManagedFieldsEntry, from []string, to string) ([]metav1.ManagedFieldsEntry, error) {
+	type entryKey struct {
+		apiVersion  string
+		subresource string
+	}
+	takeOver := map[string]bool{}
+	for _, manager := range from {
+		if manager != to {
+			takeOver[manager] = true
+		}
+	}
+
+	result := make([]metav1.ManagedFieldsEntry, 0, len(entries))
+	updates := map[entryKey]int{}
+	transferred := []metav1.ManagedFieldsEntry{}
+	for _, entry := range entries {
+		switch {
+		case takeOver[entry.Manager]:
+			transferred = append(transferred, entry)
+			continue
+		case entry.Manager == to && entry.Operation == metav1.ManagedFieldsOperationUpdate:
+			updates[entryKey{apiVersion: entry.APIVersion, subresource: entry.Subresource}] = len(result)
+		}
+		result = append(result, entry)
+	}
+	if len(transferred) == 0 {
+		return nil, nil
+	}
+
+	for _, entry := range transferred {
+		entry.Manager = to
+		entry.Operation = metav1.ManagedFieldsOperationUpdate
+		key := entryKey{apiVersion: entry.APIVersion, subresource: entry.Subresource}
+		i, ok := updates[key]
+		if !ok {
+			updates[key] = len(result)
+			result = append(result, entry)
+			continue
+		}
+		fields, err := unionFields(result[i].FieldsV1, entry.FieldsV1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transfer the fields of %s: %w", entry.Manager, err)
+		}
+		result[i].FieldsV1 = fields
+		if entry.Time != nil && (result[i].Time == nil || result[i].Time.Before(entry.Time)) {
+			result[i].Time = entry.Time
+		}
+	}
+	return result, nil
+}
+
+// unionFields returns the fields of two managed fields entries together.
+func unionFields(a, b *metav1.FieldsV1) (*metav1.FieldsV1, error) {
+	union := &fieldpath.Set{}
+	for _, fieldsV1 := range []*metav1.FieldsV1{a, b} {
+		if fieldsV1 == nil {
+			continue
+		}
+		fields := &fieldpath.Set{}
+		if err := fields.FromJSON(bytes.NewReader(fieldsV1.Raw)); err != nil {
+			return nil, err
+		}
+		union = union.Union(fields)
+	}
+	raw, err := union.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.FieldsV1{Raw: raw}, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	if len(set) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
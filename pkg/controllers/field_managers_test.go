@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func managedFieldsEntry(manager, subresource, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:     manager,
+		Operation:   metav1.ManagedFieldsOperationUpdate,
+		APIVersion:  "apps/v1",
+		FieldsType:  "FieldsV1",
+		FieldsV1:    &metav1.FieldsV1{Raw: []byte(fields)},
+		Subresource: subresource,
+	}
+}
+
+func TestOverwrittenManagers(t *testing.T) {
+	before := []metav1.ManagedFieldsEntry{
+		managedFieldsEntry(applyFieldManager, "", `{"f:spec":{"f:replicas":{}}}`),
+		managedFieldsEntry("helm", "", `{"f:metadata":{"f:labels":{"f:app":{}}},"f:spec":{"f:paused":{}}}`),
+		managedFieldsEntry("kubectl-edit", "", `{"f:spec":{"f:minReadySeconds":{}}}`),
+		managedFieldsEntry("argocd-controller", "", `{"f:spec":{"f:revisionHistoryLimit":{}}}`),
+		managedFieldsEntry("kube-controller-manager", "status", `{"f:status":{"f:replicas":{}}}`),
+	}
+	after := []metav1.ManagedFieldsEntry{
+		managedFieldsEntry(applyFieldManager, "", `{"f:spec":{"f:replicas":{},"f:paused":{},"f:revisionHistoryLimit":{}}}`),
+		managedFieldsEntry("helm", "", `{"f:metadata":{"f:labels":{"f:app":{}}}}`),
+		managedFieldsEntry("kubectl-edit", "", `{"f:spec":{"f:minReadySeconds":{}}}`),
+	}
+
+	expected := []string{"argocd-controller", "helm"}
+	if actual := overwrittenManagers(before, after, applyFieldManager); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the managers %v, got %v", expected, actual)
+	}
+	if actual := overwrittenManagers(before, before, applyFieldManager); actual != nil {
+		t.Errorf("expected no manager overwritten by an update changing nothing, got %v", actual)
+	}
+}
+
+func TestTakeOverManagedFields(t *testing.T) {
+	const manager = "work-agent-app-0123abcd"
+	entries := []metav1.ManagedFieldsEntry{
+		managedFieldsEntry(manager, "", `{"f:spec":{"f:replicas":{}}}`),
+		managedFieldsEntry("kubectl-client-side-apply", "", `{"f:spec":{"f:paused":{}}}`),
+		managedFieldsEntry("helm", "", `{"f:metadata":{"f:labels":{"f:app":{}}}}`),
+		managedFieldsEntry("kube-controller-manager", "status", `{"f:status":{"f:replicas":{}}}`),
+	}
+	entries[2].Operation = metav1.ManagedFieldsOperationApply
+
+	actual, err := takeOverManagedFields(entries, []string{"kubectl-client-side-apply", "helm"}, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 2 {
+		t.Fatalf("expected the entries of the taken over managers to be merged into the one of the agent, got %+v", actual)
+	}
+	if actual[0].Manager != manager || actual[0].Operation != metav1.ManagedFieldsOperationUpdate {
+		t.Errorf("expected the update entry of the agent first, got %+v", actual[0])
+	}
+	fields := managedFieldSets(actual)[managedFieldsKey{manager: manager, operation: metav1.ManagedFieldsOperationUpdate}]
+	expected := managedFieldSets([]metav1.ManagedFieldsEntry{
+		managedFieldsEntry(manager, "", `{"f:metadata":{"f:labels":{"f:app":{}}},"f:spec":{"f:paused":{},"f:replicas":{}}}`),
+	})[managedFieldsKey{manager: manager, operation: metav1.ManagedFieldsOperationUpdate}]
+	if fields == nil || !fields.Equals(expected) {
+		t.Errorf("expected the agent to own %v, got %v", expected, fields)
+	}
+	if actual[1].Manager != "kube-controller-manager" {
+		t.Errorf("expected the other managers to be kept, got %+v", actual[1])
+	}
+
+	if actual, err := takeOverManagedFields(entries, []string{"argocd-controller"}, manager); err != nil || actual != nil {
+		t.Errorf("expected nothing to take over, got %+v, %v", actual, err)
+	}
+}
+
+func TestFieldManagerFor(t *testing.T) {
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Namespace: "cluster1", Name: "app"}}
+	if manager, err := fieldManagerFor(work, hashHub("https://hub:6443")); err != nil || manager != "work-agent-app-"+hashHub("https://hub:6443") {
+		t.Errorf("expected the manager to be derived from the work and the hub, got %q, %v", manager, err)
+	}
+
+	work.Name = strings.Repeat("a", 253)
+	if manager, err := fieldManagerFor(work, "0123abcd"); err != nil || len(manager) != maxFieldManagerLength {
+		t.Errorf("expected the manager of a long work name to be truncated, got %q, %v", manager, err)
+	}
+
+	work.Annotations = map[string]string{workv1alpha1.FieldManagerAnnotation: "team-a"}
+	if manager, err := fieldManagerFor(work, "0123abcd"); err != nil || manager != "team-a" {
+		t.Errorf("expected the manager of the annotation, got %q, %v", manager, err)
+	}
+	work.Annotations[workv1alpha1.FieldManagerAnnotation] = ""
+	if _, err := fieldManagerFor(work, "0123abcd"); err == nil {
+		t.Error("expected an empty manager to be refused")
+	}
+}
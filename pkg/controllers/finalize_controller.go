@@ -18,24 +18,40 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/client-go/dynamic"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/finalizers"
 )
 
 // FinalizeWorkReconciler reconciles a Work object for finalization
 type FinalizeWorkReconciler struct {
-	client             client.Client
-	spokeDynamicClient dynamic.Interface
-	restMapper         meta.RESTMapper
-	log                logr.Logger
+	client      client.Client
+	applier     Applier
+	spokeClient client.Client
+	// hubReader reads the cluster deletion policy of the namespace of the works, uncached.
+	hubReader client.Reader
+	log       logr.Logger
+	recorder  record.EventRecorder
+	// finalizer is the finalizer cleaning up the resources of the deleted works.
+	finalizer string
+	// disableFinalizer leaves the resources of the deleted works on the spoke cluster.
+	disableFinalizer bool
+	// concurrency is the number of works finalized concurrently.
+	concurrency int
 }
 
 // Reconcile implement the control loop logic for finalizing Work object.
@@ -43,32 +59,167 @@ func (r *FinalizeWorkReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	work := &workv1alpha1.Work{}
 	err := r.client.Get(ctx, types.NamespacedName{Name: req.Name, Namespace: req.Namespace}, work)
 	switch {
+	case errors.IsNotFound(err) && r.disableFinalizer:
+		return ctrl.Result{}, r.releaseAppliedWork(ctx, req.NamespacedName)
 	case errors.IsNotFound(err):
 		return ctrl.Result{}, nil
 	case err != nil:
 		return ctrl.Result{}, err
 	}
 
+	// the resources are left on the spoke cluster, a finalizer added before it was disabled is removed
+	if r.disableFinalizer {
+		if !controllerutil.ContainsFinalizer(work, r.finalizer) {
+			return ctrl.Result{}, nil
+		}
+		r.log.V(2).Info("removing disabled work finalizer", "work", req.NamespacedName)
+		return ctrl.Result{}, finalizers.Remove(ctx, r.client, work, r.finalizer)
+	}
+
 	// cleanup finalizer and resources
 	if !work.DeletionTimestamp.IsZero() {
-		// TODO add clean resource logic
-		if controllerutil.ContainsFinalizer(work, workFinalizer) {
-			controllerutil.RemoveFinalizer(work, workFinalizer)
+		if !controllerutil.ContainsFinalizer(work, r.finalizer) {
+			return ctrl.Result{}, nil
+		}
+		blocking, err := r.cleanupAppliedWork(ctx, work)
+		if len(blocking) > 0 {
+			r.setDeletionBlocked(ctx, work, blocking)
+		}
+		if err != nil {
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, r.client.Update(ctx, work, &client.UpdateOptions{})
+		if len(blocking) > 0 {
+			r.log.V(2).Info("waiting for the resources of the work to terminate", "work", req.NamespacedName, "resources", len(blocking))
+			return ctrl.Result{RequeueAfter: deletionBlockedRecheckInterval}, nil
+		}
+		r.log.V(2).Info("removing work finalizer", "work", req.NamespacedName)
+		return ctrl.Result{}, finalizers.Remove(ctx, r.client, work, r.finalizer)
 	}
 
 	// don't add finalizer to instances that already have it
-	if controllerutil.ContainsFinalizer(work, workFinalizer) {
+	if controllerutil.ContainsFinalizer(work, r.finalizer) {
 		return ctrl.Result{}, nil
 	}
 
 	// if this conflicts, we'll simply try again later
-	work.Finalizers = append(work.Finalizers, workFinalizer)
-	return ctrl.Result{}, r.client.Update(ctx, work, &client.UpdateOptions{})
+	r.log.V(2).Info("adding work finalizer", "work", req.NamespacedName)
+	return ctrl.Result{}, finalizers.Add(ctx, r.client, work, r.finalizer)
+}
+
+// releaseAppliedWork deletes the AppliedWork of a work deleted without the finalizer, leaving its
+// resources on the spoke cluster.
+func (r *FinalizeWorkReconciler) releaseAppliedWork(ctx context.Context, key types.NamespacedName) error {
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+	appliedWork, err := findAppliedWork(ctx, r.spokeClient, work)
+	if err != nil || appliedWork == nil {
+		return err
+	}
+	r.log.Info("orphaning the resources of the deleted work", "work", key,
+		"resources", len(appliedWork.Status.AppliedResources))
+	return r.deleteAppliedWork(ctx, work, appliedWork)
+}
+
+// cleanupAppliedWork deletes the resources applied by a work from the spoke cluster, unless the work
+// orphans them, then its AppliedWork once they are gone. The resources failing to be deleted or
+// still terminating are returned, the AppliedWork is kept until they are gone.
+func (r *FinalizeWorkReconciler) cleanupAppliedWork(ctx context.Context, work *workv1alpha1.Work) ([]blockingResource, error) {
+	appliedWork, err := findAppliedWork(ctx, r.spokeClient, work)
+	if err != nil || appliedWork == nil {
+		return nil, err
+	}
+
+	orphan, err := r.isOrphaned(ctx, work)
+	if err != nil {
+		return nil, err
+	}
+	if orphan {
+		r.log.Info("orphaning the resources of the work", "work", client.ObjectKeyFromObject(work),
+			"resources", len(appliedWork.Status.AppliedResources))
+		return nil, r.deleteAppliedWork(ctx, work, appliedWork)
+	}
+
+	remaining, errs := pruneAppliedResources(ctx, r.applier, r.recorder, appliedWork, appliedWork.Status.AppliedResources)
+	if len(errs) > 0 {
+		blocking := make([]blockingResource, len(remaining))
+		for i := range remaining {
+			blocking[i] = blockingResource{identifier: remaining[i].ResourceIdentifier, err: errs[i]}
+		}
+		original := appliedWork.DeepCopy()
+		appliedWork.Status.AppliedResources = remaining
+		if err := r.spokeClient.Status().Patch(ctx, appliedWork, client.MergeFrom(original), client.FieldOwner(statusFieldManager)); err != nil {
+			errs = append(errs, err)
+		}
+		return blocking, utilerrors.NewAggregate(errs)
+	}
+
+	// the resources held by finalizers are deleted but not gone yet
+	terminating, err := findTerminatingResources(ctx, r.applier, appliedWork.Status.AppliedResources)
+	if err != nil || len(terminating) > 0 {
+		return terminating, err
+	}
+	return nil, r.deleteAppliedWork(ctx, work, appliedWork)
+}
+
+// isOrphaned tells whether the resources of a deleted work are left on the spoke cluster: the work
+// is annotated to orphan them, or its namespace is deleted with the Orphan cluster deletion policy.
+// The namespace is read before pruning since its works are deleted before the hub annotates them.
+// It fails closed: the resources are only deleted when the namespace is live, gone, or terminating
+// with the Cascade policy recorded, any other outcome is an error and the work is requeued.
+func (r *FinalizeWorkReconciler) isOrphaned(ctx context.Context, work *workv1alpha1.Work) (bool, error) {
+	if work.Annotations[workv1alpha1.OrphanAnnotation] == "true" {
+		return true, nil
+	}
+	if r.hubReader == nil {
+		return false, nil
+	}
+
+	ns := &corev1.Namespace{}
+	err := r.hubReader.Get(ctx, types.NamespacedName{Name: work.Namespace}, ns)
+	switch {
+	case errors.IsNotFound(err):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to read the cluster deletion policy of namespace %s: %w", work.Namespace, err)
+	case ns.DeletionTimestamp.IsZero():
+		return false, nil
+	}
+
+	switch policy := ns.Annotations[workv1alpha1.ClusterDeletionPolicyAnnotation]; policy {
+	case "Orphan":
+		return true, nil
+	case "Cascade":
+		return false, nil
+	default:
+		return false, fmt.Errorf("namespace %s is terminating without a recorded cluster deletion policy", work.Namespace)
+	}
+}
+
+// setDeletionBlocked reports the resources blocking the deletion of a work in its DeletionBlocked
+// condition. A failure to update the status is only logged, the deletion is retried anyway.
+func (r *FinalizeWorkReconciler) setDeletionBlocked(ctx context.Context, work *workv1alpha1.Work, blocking []blockingResource) {
+	original := work.DeepCopy()
+	meta.SetStatusCondition(&work.Status.Conditions, buildDeletionBlockedCondition(blocking, work.Generation))
+	if equality.Semantic.DeepEqual(original.Status.Conditions, work.Status.Conditions) {
+		return
+	}
+	if err := r.client.Status().Patch(ctx, work, client.MergeFrom(original), client.FieldOwner(statusFieldManager)); err != nil {
+		r.log.Error(err, "failed to report the resources blocking the deletion of the work", "work", client.ObjectKeyFromObject(work))
+	}
+}
+
+// deleteAppliedWork deletes the AppliedWork of a work from the spoke cluster.
+func (r *FinalizeWorkReconciler) deleteAppliedWork(ctx context.Context, work *workv1alpha1.Work, appliedWork *workv1alpha1.AppliedWork) error {
+	r.log.V(2).Info("deleting appliedwork", "work", client.ObjectKeyFromObject(work))
+	if err := r.spokeClient.Delete(ctx, appliedWork); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
 }
 
 // SetupWithManager wires up the controller.
 func (r *FinalizeWorkReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).For(&workv1alpha1.Work{}).Complete(r)
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&workv1alpha1.Work{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.concurrency}).
+		Complete(r)
 }
@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+// failingReader fails to read any object.
+type failingReader struct {
+	client.Reader
+	err error
+}
+
+func (r failingReader) Get(context.Context, client.ObjectKey, client.Object) error {
+	return r.err
+}
+
+func TestIsOrphaned(t *testing.T) {
+	now := metav1.Now()
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		namespace   *corev1.Namespace
+		readErr     error
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name:        "orphan annotation",
+			annotations: map[string]string{workv1alpha1.OrphanAnnotation: "true"},
+			want:        true,
+		},
+		{
+			name: "namespace deleted with the orphan policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:              "cluster1",
+				Annotations:       map[string]string{workv1alpha1.ClusterDeletionPolicyAnnotation: "Orphan"},
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"kubernetes"},
+			}},
+			want: true,
+		},
+		{
+			name: "namespace deleted with the cascade policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:              "cluster1",
+				Annotations:       map[string]string{workv1alpha1.ClusterDeletionPolicyAnnotation: "Cascade"},
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"kubernetes"},
+			}},
+		},
+		{
+			name: "live namespace with the orphan policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster1",
+				Annotations: map[string]string{workv1alpha1.ClusterDeletionPolicyAnnotation: "Orphan"},
+			}},
+		},
+		{
+			name: "namespace deleted without a recorded policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:              "cluster1",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"kubernetes"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "namespace not found",
+		},
+		{
+			name:    "namespace forbidden",
+			readErr: errors.NewForbidden(corev1.Resource("namespaces"), "cluster1", fmt.Errorf("denied")),
+			wantErr: true,
+		},
+	}
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objs := []client.Object{}
+			if c.namespace != nil {
+				objs = append(objs, c.namespace)
+			}
+			var hubReader client.Reader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			if c.readErr != nil {
+				hubReader = failingReader{err: c.readErr}
+			}
+			r := &FinalizeWorkReconciler{hubReader: hubReader, log: ctrl.Log}
+			work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Annotations: c.annotations}}
+
+			got, err := r.isOrphaned(context.Background(), work)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got orphaned %t", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("expected orphaned %t, got %t", c.want, got)
+			}
+		})
+	}
+}
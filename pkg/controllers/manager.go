@@ -18,27 +18,176 @@ package controllers
 
 import (
 	"context"
+	"crypto/tls"
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	grpccredentials "google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/cloudevents"
+	"sigs.k8s.io/work-api/pkg/credentials"
+	"sigs.k8s.io/work-api/pkg/pull"
+	"sigs.k8s.io/work-api/pkg/statusstream"
 )
 
 const (
 	workFinalizer      = "multicluster.x-k8s.io/work-cleanup"
 	specHashAnnotation = "multicluster.x-k8s.io/spec-hash"
+	// primaryUIDAnnotation is the UID of the resource of the primary target a resource of a
+	// secondary target of a fan-out is copied from.
+	primaryUIDAnnotation = "multicluster.x-k8s.io/primary-uid"
+
+	// statusFieldManager is the field manager of the status patches of the agent.
+	statusFieldManager = "work-agent"
+	// applyFieldManager is the field manager of the resources applied by the agent.
+	applyFieldManager = "work-agent"
 )
 
+// Options configures the optional behavior of the agent
+type Options struct {
+	// ResyncInterval is how often every Work is applied and its status synced again without any
+	// change, so drift on the spoke cluster is corrected. Each Work is resynced after its own
+	// jittered interval, spreading the resyncs of all Works over the interval. In between, a Work
+	// applied successfully is only applied again when its generation or the annotations driving
+	// its apply change. Works are only synced when they change if zero.
+	ResyncInterval time.Duration
+
+	// SyncTimeout is the deadline of applying the manifests of a Work to the spoke cluster. The
+	// requests still in flight when it expires are cancelled, the manifests which were not applied
+	// fail and are retried, and the status of the Work is written anyway. There is no deadline if zero.
+	SyncTimeout time.Duration
+
+	// ApplyConcurrency and FinalizeConcurrency are the numbers of Works applied and finalized
+	// concurrently. The controller-runtime default of 1 applies if zero.
+	ApplyConcurrency    int
+	FinalizeConcurrency int
+
+	// DiscoveryTTL is how long the discovery of the spoke cluster is cached. It is refreshed
+	// earlier when a manifest has an unknown resource type. It is never expired if zero.
+	DiscoveryTTL time.Duration
+
+	// CacheSpokeResources reads the resources applied on the spoke cluster from shared informers
+	// rather than with a request per manifest. It cuts the requests to the spoke cluster at the
+	// cost of caching every resource of the applied types.
+	CacheSpokeResources bool
+
+	// SpokeQPS and SpokeBurst limit the rate of the requests the agent sends to the spoke
+	// cluster, and to each target cluster of NewKubeApplier, with one rate limiter shared by all
+	// the Works and controllers. The client-go defaults apply if zero.
+	SpokeQPS   float32
+	SpokeBurst int
+	// SpokeAdaptiveRate halves the rate each time the cluster throttles a request with a 429
+	// response, e.g. by API Priority and Fairness, down to a tenth of SpokeQPS, and doubles it
+	// back every 30 seconds without any throttled request.
+	SpokeAdaptiveRate bool
+
+	// CloudEventsTransport receives the Works of the cluster ClusterName from the hub over a
+	// broker, rather than reading them from the hub API. They are mirrored into the namespace of
+	// the manager options on the cluster of the hub config, which is then the spoke cluster, and
+	// their status is published back.
+	CloudEventsTransport cloudevents.Transport
+	ClusterName          string
+
+	// StatusStreamAddr is the address of the gRPC endpoint of the hub the status of the Works is
+	// streamed to, rather than written to the hub API, if set. The connection is encrypted with
+	// StatusStreamTLS if set.
+	StatusStreamAddr string
+	StatusStreamTLS  *tls.Config
+
+	// Pull mirrors the Works of the cluster ClusterName from a signed bundle of an HTTP(S)
+	// endpoint, rather than reading them from the hub API, if its BundleURL is set. Like with
+	// CloudEventsTransport, the hub config is then the one of the spoke cluster and the Works are
+	// mirrored into the namespace of the manager options.
+	Pull pull.Options
+
+	// Applier applies the resources of the Works, to the spoke cluster if nil. It is set to apply
+	// to other targets, e.g. NewKubeApplier for a remote cluster or NewFanOutApplier for several.
+	// The AppliedWorks are kept on the spoke cluster either way.
+	Applier Applier
+
+	// HubCredentials provides the client certificate the agent authenticates to the hub with,
+	// e.g. a SPIFFE X509-SVID, rather than the credentials of the hub config, if set. The
+	// connections to the hub are reopened when the certificate rotates.
+	HubCredentials credentials.Provider
+
+	// Finalizer is the finalizer the agent adds to the Works to clean up their resources when they
+	// are deleted, multicluster.x-k8s.io/work-cleanup if empty. The hub must use the same name.
+	Finalizer string
+	// DisableFinalizer applies the Works without adding the finalizer, for hubs which never want
+	// the agent to clean up. The resources of a deleted Work are left on the spoke cluster, like
+	// with the orphan annotation, and its AppliedWork is deleted. The finalizer is removed from
+	// the Works which had it before.
+	DisableFinalizer bool
+
+	// StatusSizeBudget is the size in bytes of the manifest conditions of a Work above which they
+	// are moved to a WorkStatusDetail named after the Work on the hub, keeping the Work small
+	// enough to be listed. They are always kept in the Work if zero, or if the status is not
+	// written to the hub API.
+	StatusSizeBudget int
+
+	// RecreatedResourcePolicy is whether the agent adopts, deletes and creates again, or leaves the
+	// resources of the Works which were deleted and recreated by someone else, Adopt if empty. The
+	// recreation is reported by the ResourceRecreated condition of the manifest either way.
+	RecreatedResourcePolicy RecreatedResourcePolicy
+
+	// AvailabilityEvaluators tell whether the resources of the given kinds are available, e.g. for
+	// custom resources with their own readiness semantics. They are added to, or replace, the
+	// DefaultAvailabilityEvaluators. The resources of the other kinds are available once they exist.
+	AvailabilityEvaluators map[schema.GroupKind]AvailabilityEvaluator
+
+	// AgentConfigName is the name of the cluster scoped WorkAgentConfig on the spoke cluster
+	// which tunes the agent at runtime, if set. Its resync interval, apply concurrency, allowed
+	// kinds and feature gates override the options as soon as it changes, without restarting the
+	// agent. The apply concurrency is capped by ApplyConcurrency, the number of workers started.
+	AgentConfigName string
+
+	// WorkCacheDir is the directory the last applied spec of each Work is kept in, if set. While
+	// the hub is unreachable, the cached Works are applied again every ResyncInterval, or every
+	// minute if zero, so the drift of their resources is still corrected. The Works are reconciled
+	// from the hub again once it is reachable.
+	WorkCacheDir string
+}
+
 // Start the controllers with the supplied config
-func Start(ctx context.Context, hubCfg, spokeCfg *rest.Config, setupLog logr.Logger, opts ctrl.Options) error {
+func Start(ctx context.Context, hubCfg, spokeCfg *rest.Config, setupLog logr.Logger, opts ctrl.Options, agentOpts Options) error {
+	var rotator *credentials.Rotator
+	if agentOpts.HubCredentials != nil {
+		var err error
+		hubCfg, rotator, err = credentials.ConfigFor(hubCfg, agentOpts.HubCredentials, ctrl.Log.WithName("credentials"))
+		if err != nil {
+			setupLog.Error(err, "unable to configure the hub credentials")
+			return err
+		}
+	}
+
 	mgr, err := ctrl.NewManager(hubCfg, opts)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if rotator != nil {
+		if err := mgr.Add(manager.RunnableFunc(rotator.Start)); err != nil {
+			setupLog.Error(err, "unable to start manager")
+			return err
+		}
+	}
+
+	// all the clients of the spoke cluster share one rate limiter, and count their requests
+	spokeCfg = countSpokeRequests(limitSpokeRate(spokeCfg, agentOpts))
 
 	spokeDynamicClient, err := dynamic.NewForConfig(spokeCfg)
 	if err != nil {
@@ -46,32 +195,189 @@ func Start(ctx context.Context, hubCfg, spokeCfg *rest.Config, setupLog logr.Log
 		os.Exit(1)
 	}
 
-	restMapper, err := apiutil.NewDynamicRESTMapper(spokeCfg, apiutil.WithLazyDiscovery)
+	// one cached RESTMapper of the spoke cluster is shared by all the controllers of the agent
+	restMapper, err := newCachedRESTMapper(spokeCfg, agentOpts.DiscoveryTTL)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&ApplyWorkReconciler{
-		client:             mgr.GetClient(),
-		spokeDynamicClient: spokeDynamicClient,
-		restMapper:         restMapper,
-		log:                ctrl.Log.WithName("controllers").WithName("WorkApply"),
-	}).SetupWithManager(mgr); err != nil {
+	finalizer := agentOpts.Finalizer
+	if finalizer == "" {
+		finalizer = workFinalizer
+	}
+
+	applier := agentOpts.Applier
+	if applier == nil {
+		applier = newKubeApplier(spokeDynamicClient, newSpokeReader(ctx, spokeDynamicClient, agentOpts.CacheSpokeResources), restMapper)
+	}
+
+	// the AppliedWorks are read from a cache of the spoke cluster, indexed by their work
+	spokeCluster, err := cluster.New(spokeCfg, func(o *cluster.Options) {
+		o.Scheme = mgr.GetScheme()
+		o.MapperProvider = func(*rest.Config) (meta.RESTMapper, error) {
+			return restMapper, nil
+		}
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+	if err := spokeCluster.GetFieldIndexer().IndexField(ctx, &workv1alpha1.AppliedWork{}, appliedWorkWorkIndex, indexAppliedWorkByWork); err != nil {
+		setupLog.Error(err, "unable to index appliedworks")
+		return err
+	}
+	if err := mgr.Add(spokeCluster); err != nil {
+		setupLog.Error(err, "unable to start manager")
+		return err
+	}
+	spokeClient := spokeCluster.GetClient()
+
+	// events about the resources applied on the spoke cluster are recorded on the spoke cluster
+	spokeKubeClient, err := kubernetes.NewForConfig(spokeCfg)
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: spokeKubeClient.CoreV1().Events("")})
+	defer eventBroadcaster.Shutdown()
+	recorder := eventBroadcaster.NewRecorder(mgr.GetScheme(), corev1.EventSource{Component: "work-agent"})
+
+	var statusStream *statusstream.Client
+	if agentOpts.StatusStreamAddr != "" {
+		credentials := grpc.WithInsecure()
+		if agentOpts.StatusStreamTLS != nil {
+			credentials = grpc.WithTransportCredentials(grpccredentials.NewTLS(agentOpts.StatusStreamTLS))
+		}
+		conn, err := grpc.DialContext(ctx, agentOpts.StatusStreamAddr, credentials)
+		if err != nil {
+			setupLog.Error(err, "unable to connect to the status stream endpoint")
+			return err
+		}
+		defer conn.Close()
+		statusStream = statusstream.NewClient(conn, ctrl.Log.WithName("statusstream"))
+		if err := mgr.Add(manager.RunnableFunc(statusStream.Start)); err != nil {
+			setupLog.Error(err, "unable to start manager")
+			return err
+		}
+	}
+
+	// the status detail is only written to the hub API, the status of the mirrored or streamed
+	// works is reported whole
+	statusSizeBudget := agentOpts.StatusSizeBudget
+	if agentOpts.CloudEventsTransport != nil || agentOpts.Pull.BundleURL != "" || statusStream != nil {
+		statusSizeBudget = 0
+	}
+
+	availabilityEvaluators := DefaultAvailabilityEvaluators()
+	for groupKind, evaluator := range agentOpts.AvailabilityEvaluators {
+		availabilityEvaluators[groupKind] = evaluator
+	}
+
+	var config *agentConfig
+	if agentOpts.AgentConfigName != "" {
+		config = newAgentConfig()
+		if err = (&AgentConfigReconciler{
+			spokeClient: spokeClient,
+			log:         ctrl.Log.WithName("controllers").WithName("WorkAgentConfig"),
+			name:        agentOpts.AgentConfigName,
+			config:      config,
+		}).SetupWithManager(mgr, spokeCluster.GetCache()); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "WorkAgentConfig")
+			return err
+		}
+	}
+
+	var cache *workCache
+	if agentOpts.WorkCacheDir != "" {
+		if cache, err = newWorkCache(agentOpts.WorkCacheDir); err != nil {
+			setupLog.Error(err, "unable to create the work cache")
+			return err
+		}
+	}
+
+	applyReconciler := &ApplyWorkReconciler{
+		client:                  mgr.GetClient(),
+		applier:                 applier,
+		spokeClient:             spokeClient,
+		log:                     ctrl.Log.WithName("controllers").WithName("WorkApply"),
+		recorder:                recorder,
+		finalizer:               finalizer,
+		disableFinalizer:        agentOpts.DisableFinalizer,
+		resyncInterval:          agentOpts.ResyncInterval,
+		backoff:                 newManifestBackoff(),
+		appliedGenerations:      newAppliedGenerations(),
+		decodeCache:             utilcache.NewLRUExpireCache(decodeCacheSize),
+		concurrency:             agentOpts.ApplyConcurrency,
+		statusStream:            statusStream,
+		statusSizeBudget:        statusSizeBudget,
+		recreatedResourcePolicy: agentOpts.RecreatedResourcePolicy,
+		availabilityEvaluators:  availabilityEvaluators,
+		degradedNotifier:        newDegradedNotifier(mgr.GetEventRecorderFor("work-agent")),
+		syncTimeout:             agentOpts.SyncTimeout,
+		agentConfig:             config,
+		hubHash:                 hashHub(hubCfg.Host),
+		workCache:               cache,
+	}
+	if err = applyReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "WorkApply")
 		return err
 	}
 
+	if cache != nil {
+		probeHub, err := newHubProbe(hubCfg)
+		if err != nil {
+			setupLog.Error(err, "unable to create the hub probe")
+			return err
+		}
+		interval := agentOpts.ResyncInterval
+		if interval == 0 {
+			interval = defaultOfflineResyncInterval
+		}
+		if err := mgr.Add(&offlineRemediator{
+			reconciler: applyReconciler,
+			cache:      cache,
+			probeHub:   probeHub,
+			interval:   interval,
+			log:        ctrl.Log.WithName("controllers").WithName("OfflineRemediation"),
+		}); err != nil {
+			setupLog.Error(err, "unable to start manager")
+			return err
+		}
+	}
+
 	if err = (&FinalizeWorkReconciler{
-		client:             mgr.GetClient(),
-		spokeDynamicClient: spokeDynamicClient,
-		restMapper:         restMapper,
-		log:                ctrl.Log.WithName("controllers").WithName("WorkFinalize"),
+		client:           mgr.GetClient(),
+		applier:          applier,
+		spokeClient:      spokeClient,
+		hubReader:        mgr.GetAPIReader(),
+		log:              ctrl.Log.WithName("controllers").WithName("WorkFinalize"),
+		recorder:         recorder,
+		finalizer:        finalizer,
+		disableFinalizer: agentOpts.DisableFinalizer,
+		concurrency:      agentOpts.FinalizeConcurrency,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "WorkFinalize")
 		return err
 	}
 
+	if agentOpts.CloudEventsTransport != nil {
+		if err = cloudevents.SetupAgentWithManager(mgr, agentOpts.CloudEventsTransport, agentOpts.ClusterName, opts.Namespace); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CloudEventsAgent")
+			return err
+		}
+	}
+
+	if agentOpts.Pull.BundleURL != "" {
+		pullOpts := agentOpts.Pull
+		pullOpts.Cluster, pullOpts.Namespace = agentOpts.ClusterName, opts.Namespace
+		if err = pull.SetupWithManager(mgr, pullOpts); err != nil {
+			setupLog.Error(err, "unable to pull works")
+			return err
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
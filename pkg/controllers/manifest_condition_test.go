@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+// TestBuildManifestConditionsDiverging checks that the manifest conditions are rebuilt from the
+// manifests when the status does not match them, e.g. right after manifests are added, removed
+// or reordered.
+func TestBuildManifestConditionsDiverging(t *testing.T) {
+	identifier := func(ordinal int, name string) workv1alpha1.ResourceIdentifier {
+		return workv1alpha1.ResourceIdentifier{
+			Ordinal: ordinal, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: name,
+		}
+	}
+	appliedAt := metav1.NewTime(time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC))
+	previousCondition := func(ordinal int, name string) workv1alpha1.ManifestCondition {
+		return workv1alpha1.ManifestCondition{
+			Identifier:      identifier(ordinal, name),
+			Conditions:      []metav1.Condition{buildAppliedStatusCondition(nil, 1)},
+			LastAppliedTime: &appliedAt,
+		}
+	}
+
+	cases := map[string]struct {
+		manifests []string
+		previous  []workv1alpha1.ManifestCondition
+		// carried are the manifests expected to keep the time they were last applied at
+		carried map[string]bool
+	}{
+		"status not initialized": {
+			manifests: []string{"a", "b"},
+			carried:   map[string]bool{},
+		},
+		"manifest added": {
+			manifests: []string{"a", "b", "c"},
+			previous:  []workv1alpha1.ManifestCondition{previousCondition(0, "a"), previousCondition(1, "b")},
+			carried:   map[string]bool{"a": true, "b": true},
+		},
+		"manifest removed": {
+			manifests: []string{"b"},
+			previous:  []workv1alpha1.ManifestCondition{previousCondition(0, "a"), previousCondition(1, "b")},
+			carried:   map[string]bool{"b": true},
+		},
+		"manifests reordered": {
+			manifests: []string{"b", "a"},
+			previous:  []workv1alpha1.ManifestCondition{previousCondition(0, "a"), previousCondition(1, "b")},
+			carried:   map[string]bool{"a": true, "b": true},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := metav1.Now()
+			manifestConditions := make([]workv1alpha1.ManifestCondition, 0, len(c.manifests))
+			for ordinal, manifest := range c.manifests {
+				// the manifests are unchanged, so they are not updated
+				result := applyResult{identifier: identifier(ordinal, manifest), generation: 1}
+				manifestConditions = append(manifestConditions, buildManifestCondition(result, c.previous, 1, &now))
+			}
+
+			if len(manifestConditions) != len(c.manifests) {
+				t.Fatalf("expected a condition per manifest, got %d for %d manifests", len(manifestConditions), len(c.manifests))
+			}
+			for ordinal, manifestCondition := range manifestConditions {
+				if manifestCondition.Identifier != identifier(ordinal, c.manifests[ordinal]) {
+					t.Errorf("expected the condition %d to identify %s, got %+v", ordinal, c.manifests[ordinal], manifestCondition.Identifier)
+				}
+				carried := manifestCondition.LastAppliedTime != nil && manifestCondition.LastAppliedTime.Equal(&appliedAt)
+				if carried != c.carried[c.manifests[ordinal]] {
+					t.Errorf("expected the last applied time of %s to be carried over %v, got %v",
+						c.manifests[ordinal], c.carried[c.manifests[ordinal]], manifestCondition.LastAppliedTime)
+				}
+			}
+			if !isWorkStatusChanged(workv1alpha1.WorkStatus{ManifestConditions: c.previous}, workv1alpha1.WorkStatus{ManifestConditions: manifestConditions}) {
+				t.Error("expected the status to change")
+			}
+		})
+	}
+}
+
+func TestBuildPruneFailedManifestCondition(t *testing.T) {
+	identifier := workv1alpha1.ResourceIdentifier{Ordinal: 2, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "removed"}
+	previous := []workv1alpha1.ManifestCondition{{
+		Identifier: identifier,
+		Conditions: []metav1.Condition{buildAppliedStatusCondition(nil, 1)},
+	}}
+
+	manifestCondition := buildPruneFailedManifestCondition(identifier, errors.New("forbidden"), previous, 2)
+	if manifestCondition.Identifier != identifier {
+		t.Errorf("expected the removed manifest to be identified, got %+v", manifestCondition.Identifier)
+	}
+	pruned := meta.FindStatusCondition(manifestCondition.Conditions, conditions.TypePruned)
+	if pruned == nil || pruned.Status != metav1.ConditionFalse || pruned.ObservedGeneration != 2 {
+		t.Errorf("expected the failed deletion to be reported, got %+v", manifestCondition.Conditions)
+	}
+	if !conditions.IsApplied(manifestCondition.Conditions) {
+		t.Errorf("expected the previous conditions to be kept, got %+v", manifestCondition.Conditions)
+	}
+	if len(previous[0].Conditions) != 1 {
+		t.Errorf("expected the previous status not to be modified, got %+v", previous[0].Conditions)
+	}
+
+	// a manifest not reported before only has the Pruned condition
+	manifestCondition = buildPruneFailedManifestCondition(identifier, errors.New("forbidden"), nil, 2)
+	if len(manifestCondition.Conditions) != 1 || manifestCondition.Conditions[0].Type != conditions.TypePruned {
+		t.Errorf("expected only the Pruned condition, got %+v", manifestCondition.Conditions)
+	}
+}
+
+func TestRecordApplyError(t *testing.T) {
+	manifestCondition := workv1alpha1.ManifestCondition{}
+	start := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	for i := 0; i < maxApplyErrors; i++ {
+		now := metav1.NewTime(start.Add(time.Duration(i) * time.Minute))
+		recordApplyError(&manifestCondition, errors.New("connection refused"), &now)
+	}
+	previous := manifestCondition.ApplyErrors
+
+	now := metav1.NewTime(start.Add(time.Hour))
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cm", errors.New(strings.Repeat("x", 2*maxApplyErrorMessageLength)))
+	recordApplyError(&manifestCondition, forbidden, &now)
+
+	applyErrors := manifestCondition.ApplyErrors
+	if len(applyErrors) != maxApplyErrors {
+		t.Fatalf("expected the last %d errors to be kept, got %d", maxApplyErrors, len(applyErrors))
+	}
+	if !applyErrors[0].Time.Equal(&previous[1].Time) {
+		t.Errorf("expected the oldest error to be dropped, got %v", applyErrors[0].Time)
+	}
+	if applyErrors[0].Reason != "AppliedManifestFailed" {
+		t.Errorf("expected the error without a reason to be AppliedManifestFailed, got %s", applyErrors[0].Reason)
+	}
+	last := applyErrors[maxApplyErrors-1]
+	if last.Reason != string(metav1.StatusReasonForbidden) || !last.Time.Equal(&now) {
+		t.Errorf("expected the forbidden error to be recorded last, got %+v", last)
+	}
+	if len(last.Message) > maxApplyErrorMessageLength+len("...") {
+		t.Errorf("expected the message to be truncated, got %d bytes", len(last.Message))
+	}
+	if !previous[0].Time.Equal(&metav1.Time{Time: start}) {
+		t.Errorf("expected the previous status not to be modified, got %+v", previous)
+	}
+}
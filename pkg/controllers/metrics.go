@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// workSyncDuration is how long the agent takes to apply a work and sync its status.
+	workSyncDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "work_sync_duration_seconds",
+		Help:    "Duration of applying the manifests of a work and syncing its status, in seconds.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+
+	// workStatusUpdates counts the status updates of works by result.
+	workStatusUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_status_updates_total",
+		Help: "Number of work status updates by result: success, conflict or error.",
+	}, []string{"result"})
+
+	// spokeRequests counts the requests the agent sends to the spoke cluster by verb, every client
+	// of the spoke cluster counts them in its transport, see countSpokeRequests.
+	spokeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_spoke_requests_total",
+		Help: "Number of requests sent to the spoke cluster by verb: get, watch, create, update, patch or delete.",
+	}, []string{"verb"})
+
+	// spokeCacheReads counts the reads of applied resources from the spoke cache by result.
+	spokeCacheReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_spoke_cache_reads_total",
+		Help: "Number of reads of applied resources from the spoke cache by result: hit or miss.",
+	}, []string{"result"})
+
+	// spokeDiscoveryRefreshes counts the refreshes of the cached discovery of the spoke cluster.
+	spokeDiscoveryRefreshes = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "work_spoke_discovery_refreshes_total",
+		Help: "Number of refreshes of the cached discovery of the spoke cluster.",
+	})
+
+	// manifestsDegraded counts the manifests which became degraded by the group and kind of their resource.
+	manifestsDegraded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_manifests_degraded_total",
+		Help: "Number of manifests which became degraded by the group and kind of their resource.",
+	}, []string{"group", "kind"})
+
+	// degradedEventsSuppressed counts the Degraded events not recorded on the works as they flap.
+	degradedEventsSuppressed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "work_degraded_events_suppressed_total",
+		Help: "Number of events about degraded manifests suppressed by the notification window of their work.",
+	})
+
+	// offlineRemediations counts the cached works applied while the hub is unreachable by result.
+	offlineRemediations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_offline_remediations_total",
+		Help: "Number of cached works applied while the hub is unreachable by result: success or error.",
+	}, []string{"result"})
+
+	// spokeRateLimit is the rate the requests to each cluster are limited to, lowered while it throttles them.
+	spokeRateLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "work_spoke_rate_limit_qps",
+		Help: "Rate the requests sent to a cluster are limited to, in requests per second, by host.",
+	}, []string{"host"})
+
+	// spokeThrottledRequests counts the requests throttled by each cluster.
+	spokeThrottledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "work_spoke_throttled_requests_total",
+		Help: "Number of requests throttled by a cluster with a 429 response, by host.",
+	}, []string{"host"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(workSyncDuration, workStatusUpdates, spokeRequests, spokeCacheReads, spokeDiscoveryRefreshes,
+		manifestsDegraded, degradedEventsSuppressed, offlineRemediations, spokeRateLimit, spokeThrottledRequests)
+}
+
+// statusUpdateResult returns the result label of a status update.
+func statusUpdateResult(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.IsConflict(err):
+		return "conflict"
+	default:
+		return "error"
+	}
+}
+
+// remediationResult returns the result label of an offline remediation whose failed manifests are given.
+func remediationResult(failed int) string {
+	if failed != 0 {
+		return "error"
+	}
+	return "success"
+}
+
+// countSpokeRequests returns a copy of the config of a cluster whose clients count their requests
+// in spokeRequests, including the lists and watches of the caches.
+func countSpokeRequests(cfg *rest.Config) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &requestCounter{next: rt}
+	})
+	return cfg
+}
+
+// requestCounter counts the requests sent to a cluster by verb.
+type requestCounter struct {
+	next http.RoundTripper
+}
+
+func (c *requestCounter) RoundTrip(req *http.Request) (*http.Response, error) {
+	spokeRequests.WithLabelValues(requestVerb(req)).Inc()
+	return c.next.RoundTrip(req)
+}
+
+// requestVerb returns the verb label of a request from its method.
+func requestVerb(req *http.Request) string {
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("watch") == "true" {
+			return "watch"
+		}
+		return "get"
+	case http.MethodPost:
+		return "create"
+	case http.MethodPut:
+		return "update"
+	case http.MethodPatch:
+		return "patch"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+func TestCountSpokeRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	client, err := dynamic.NewForConfig(countSpokeRequests(&rest.Config{Host: server.URL}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the deletes and patches of the pruning and the takeovers are counted along with the applies
+	deletes := testutil.ToFloat64(spokeRequests.WithLabelValues("delete"))
+	gets := testutil.ToFloat64(spokeRequests.WithLabelValues("get"))
+	_ = client.Resource(configMapGVR).Namespace("default").Delete(context.Background(), "cm", metav1.DeleteOptions{})
+	_, _ = client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "cm", metav1.GetOptions{})
+	if got := testutil.ToFloat64(spokeRequests.WithLabelValues("delete")) - deletes; got != 1 {
+		t.Errorf("expected 1 delete to be counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(spokeRequests.WithLabelValues("get")) - gets; got != 1 {
+		t.Errorf("expected 1 get to be counted, got %v", got)
+	}
+}
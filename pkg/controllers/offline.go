@@ -0,0 +1,229 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// defaultOfflineResyncInterval is how often the cached works are applied while the hub is
+// unreachable when the agent has no resync interval.
+const defaultOfflineResyncInterval = time.Minute
+
+// hubProbeTimeout is how long the agent waits for the hub to answer before it is unreachable.
+const hubProbeTimeout = 10 * time.Second
+
+// newHubProbe returns a probe requesting the version of the hub, it returns an error if the hub is unreachable.
+func newHubProbe(hubCfg *rest.Config) (func(ctx context.Context) error, error) {
+	cfg := rest.CopyConfig(hubCfg)
+	cfg.Timeout = hubProbeTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		_, err := discoveryClient.ServerVersion()
+		return err
+	}, nil
+}
+
+// workCache keeps the last applied spec of each work in files of a directory of the agent, so the
+// agent keeps correcting the drift of their resources while the hub is unreachable. The works are
+// stored with their apply input annotations and the manifest conditions recording the generations
+// their resources were applied at, but none of their other metadata or status.
+type workCache struct {
+	dir string
+}
+
+func newWorkCache(dir string) (*workCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the work cache directory %s: %w", dir, err)
+	}
+	return &workCache{dir: dir}, nil
+}
+
+// path returns the file of a work, the names of the namespaces and works never contain an underscore.
+func (c *workCache) path(key types.NamespacedName) string {
+	return filepath.Join(c.dir, key.Namespace+"_"+key.Name+".json")
+}
+
+// store writes the last applied spec of a work, replacing the file so it is never read half written.
+func (c *workCache) store(work *workv1alpha1.Work) error {
+	if c == nil {
+		return nil
+	}
+	cached := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  work.Namespace,
+			Name:       work.Name,
+			UID:        work.UID,
+			Generation: work.Generation,
+		},
+		Spec:   work.Spec,
+		Status: workv1alpha1.WorkStatus{ManifestConditions: work.Status.ManifestConditions},
+	}
+	for _, annotation := range applyInputAnnotations {
+		if value, ok := work.Annotations[annotation]; ok {
+			metav1.SetMetaDataAnnotation(&cached.ObjectMeta, annotation, value)
+		}
+	}
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(c.dir, ".work-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), c.path(client.ObjectKeyFromObject(work)))
+}
+
+// remove forgets a work, e.g. once it is deleted, so it is not applied again while the hub is unreachable.
+func (c *workCache) remove(key types.NamespacedName) error {
+	if c == nil {
+		return nil
+	}
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// load reads the works of the cache.
+func (c *workCache) load() ([]*workv1alpha1.Work, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	works := []*workv1alpha1.Work{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		work := &workv1alpha1.Work{}
+		if err := json.Unmarshal(data, work); err != nil {
+			return nil, fmt.Errorf("failed to decode the cached work %s: %w", entry.Name(), err)
+		}
+		works = append(works, work)
+	}
+	return works, nil
+}
+
+// offlineRemediator applies the cached works again every interval while the hub is unreachable,
+// correcting the drift of their resources until the agent reconciles the works from the hub again.
+// The manifest conditions of the cached works are updated in memory as they are applied, so the
+// resources which did not drift are not updated again.
+type offlineRemediator struct {
+	reconciler *ApplyWorkReconciler
+	cache      *workCache
+	// probeHub returns an error if the hub is unreachable.
+	probeHub func(ctx context.Context) error
+	interval time.Duration
+	log      logr.Logger
+
+	// works are the cached works being applied while the hub is unreachable, nil while it is reachable.
+	works []*workv1alpha1.Work
+}
+
+// Start applies the cached works every interval while the hub is unreachable, until the context is done.
+func (o *offlineRemediator) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, o.remediate, o.interval)
+	return nil
+}
+
+func (o *offlineRemediator) remediate(ctx context.Context) {
+	if err := o.probeHub(ctx); err == nil {
+		if o.works != nil {
+			o.log.Info("the hub is reachable again, stopping the offline remediation")
+		}
+		o.works = nil
+		return
+	} else if o.works == nil {
+		works, loadErr := o.cache.load()
+		if loadErr != nil {
+			o.log.Error(loadErr, "failed to load the cached works")
+			return
+		}
+		o.log.Info("the hub is unreachable, applying the cached works", "works", len(works), "reason", err.Error())
+		o.works = works
+	}
+
+	for _, work := range o.works {
+		o.remediateWork(ctx, work)
+	}
+}
+
+// remediateWork applies a cached work again, unless its AppliedWork was deleted since.
+func (o *offlineRemediator) remediateWork(ctx context.Context, work *workv1alpha1.Work) {
+	log := o.log.WithValues("work", client.ObjectKeyFromObject(work))
+	appliedWork, err := findAppliedWork(ctx, o.reconciler.spokeClient, work)
+	switch {
+	case err != nil:
+		log.Error(err, "failed to get appliedwork")
+		return
+	case appliedWork == nil:
+		log.V(2).Info("skipping cached work without appliedwork")
+		return
+	}
+
+	results := o.reconciler.applyManifests(ctx, log, work, appliedWork.Status.AppliedResources, false)
+	manifestConditions := make([]workv1alpha1.ManifestCondition, 0, len(results))
+	now := metav1.Now()
+	updated, failed := 0, 0
+	for _, result := range results {
+		if result.updated {
+			updated++
+		}
+		if result.err != nil {
+			failed++
+		}
+		manifestConditions = append(manifestConditions, buildManifestCondition(result, work.Status.ManifestConditions, work.Generation, &now))
+	}
+	work.Status.ManifestConditions = manifestConditions
+	offlineRemediations.WithLabelValues(remediationResult(failed)).Inc()
+	if updated != 0 || failed != 0 {
+		log.Info("applied cached work", "generation", work.Generation, "updated", updated, "failed", failed)
+	}
+}
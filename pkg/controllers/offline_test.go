@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func newTestCachedWork(t *testing.T) *workv1alpha1.Work {
+	raw, err := newTestConfigMap(t, "cm").MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "cluster1",
+			Name:       "work",
+			Generation: 2,
+			Labels:     map[string]string{"app": "test"},
+			Annotations: map[string]string{
+				workv1alpha1.SkipManifestsAnnotation: "v1/ConfigMap/default/skipped",
+				"example.com/unrelated":              "value",
+			},
+		},
+		Spec: workv1alpha1.WorkSpec{
+			Workload: workv1alpha1.WorkloadTemplate{
+				Manifests: []workv1alpha1.Manifest{{RawExtension: runtime.RawExtension{Raw: raw}}},
+			},
+		},
+	}
+}
+
+func TestWorkCache(t *testing.T) {
+	cache, err := newWorkCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.store(newTestCachedWork(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	works, err := cache.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 1 {
+		t.Fatalf("expected one cached work, got %d", len(works))
+	}
+	work := works[0]
+	if work.Namespace != "cluster1" || work.Name != "work" || work.Generation != 2 || len(work.Spec.Workload.Manifests) != 1 {
+		t.Fatalf("expected the work to be cached with its spec, got %v", work)
+	}
+	// only the annotations changing how the work is applied are kept
+	expectedAnnotations := map[string]string{workv1alpha1.SkipManifestsAnnotation: "v1/ConfigMap/default/skipped"}
+	if len(work.Labels) != 0 || !reflect.DeepEqual(work.Annotations, expectedAnnotations) {
+		t.Fatalf("expected the annotations %v, got %v and the labels %v", expectedAnnotations, work.Annotations, work.Labels)
+	}
+
+	if err := cache.remove(types.NamespacedName{Namespace: "cluster1", Name: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.remove(types.NamespacedName{Namespace: "cluster1", Name: "work"}); err != nil {
+		t.Fatalf("expected removing a missing work to succeed, got %v", err)
+	}
+	if works, err := cache.load(); err != nil || len(works) != 0 {
+		t.Fatalf("expected the cache to be empty, got %d works: %v", len(works), err)
+	}
+}
+
+func TestOfflineRemediatorRemediate(t *testing.T) {
+	ctx := context.Background()
+	cache, err := newWorkCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.store(newTestCachedWork(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "work"},
+		Spec:       workv1alpha1.AppliedWorkSpec{WorkNamespace: "cluster1", WorkName: "work"},
+	}).Build()
+	applier, dynamicClient := newTestKubeApplier(t)
+
+	var hubErr error
+	o := &offlineRemediator{
+		reconciler: &ApplyWorkReconciler{
+			applier:     applier,
+			spokeClient: spokeClient,
+			backoff:     newManifestBackoff(),
+			decodeCache: utilcache.NewLRUExpireCache(decodeCacheSize),
+		},
+		cache:    cache,
+		probeHub: func(context.Context) error { return hubErr },
+		log:      logr.Discard(),
+	}
+
+	// nothing is applied while the hub is reachable
+	o.remediate(ctx)
+	if _, err := dynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm", metav1.GetOptions{}); err == nil {
+		t.Fatal("expected the cached work not to be applied while the hub is reachable")
+	}
+
+	// the cached work is applied while it is not
+	hubErr = errors.New("connection refused")
+	o.remediate(ctx)
+	if _, err := dynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the cached work to be applied while the hub is unreachable: %v", err)
+	}
+	if len(o.works) != 1 || len(o.works[0].Status.ManifestConditions) != 1 {
+		t.Fatalf("expected the manifest conditions of the cached work to be recorded, got %v", o.works)
+	}
+
+	// the drift is corrected on the next pass
+	if err := dynamicClient.Resource(configMapGVR).Namespace("default").Delete(ctx, "cm", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	o.remediate(ctx)
+	if _, err := dynamicClient.Resource(configMapGVR).Namespace("default").Get(ctx, "cm", metav1.GetOptions{}); err != nil {
+		t.Fatalf("expected the deleted resource to be applied again: %v", err)
+	}
+
+	// the cached works are dropped once the hub is reachable again
+	hubErr = nil
+	o.remediate(ctx)
+	if o.works != nil {
+		t.Fatalf("expected the cached works to be dropped, got %v", o.works)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+const (
+	// minSpokeRateFactor is the fraction of the configured rate the adaptive rate limiter slows
+	// down to at most.
+	minSpokeRateFactor = 0.1
+	// spokeThrottleCooldown is how long the responses throttled after the rate was lowered are
+	// ignored, as they were likely sent before, so a burst of them lowers the rate once.
+	spokeThrottleCooldown = time.Second
+	// spokeRateRecoveryInterval is how long the spoke cluster must not throttle any request before
+	// the adaptive rate limiter doubles the rate again, up to the configured rate.
+	spokeRateRecoveryInterval = 30 * time.Second
+)
+
+// spokeRateLimiter limits the rate of the requests sent to a cluster by all the clients of the agent
+// sharing it, e.g. applying the manifests, reading their resources for their availability and
+// status, discovering the resources and recording events, so the agent never sends more than the
+// configured rate to the cluster, whatever the number of Works and controllers. If adaptive, the
+// rate is halved each time the cluster throttles a request, e.g. by API Priority and Fairness, and
+// doubled back once it has not for a while.
+type spokeRateLimiter struct {
+	// host is the cluster the rate of the requests is reported for.
+	host     string
+	qps      float32
+	burst    int
+	adaptive bool
+	now      func() time.Time
+
+	lock    sync.Mutex
+	limiter flowcontrol.RateLimiter
+	current float32
+	// changedAt is when the rate was last lowered or raised, throttledAt when a request was last throttled.
+	changedAt   time.Time
+	throttledAt time.Time
+}
+
+func newSpokeRateLimiter(host string, qps float32, burst int, adaptive bool) *spokeRateLimiter {
+	spokeRateLimit.WithLabelValues(host).Set(float64(qps))
+	return &spokeRateLimiter{
+		host:     host,
+		qps:      qps,
+		burst:    burst,
+		adaptive: adaptive,
+		now:      time.Now,
+		limiter:  flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+		current:  qps,
+	}
+}
+
+// limitSpokeRate returns a copy of the config of a cluster whose clients share one rate limiter
+// with the rate and burst of the options, the client-go defaults if they are zero.
+func limitSpokeRate(cfg *rest.Config, agentOpts Options) *rest.Config {
+	cfg = rest.CopyConfig(cfg)
+	cfg.QPS, cfg.Burst = agentOpts.SpokeQPS, agentOpts.SpokeBurst
+	if cfg.QPS == 0 {
+		cfg.QPS = rest.DefaultQPS
+	}
+	if cfg.Burst == 0 {
+		cfg.Burst = rest.DefaultBurst
+	}
+	limiter := newSpokeRateLimiter(cfg.Host, cfg.QPS, cfg.Burst, agentOpts.SpokeAdaptiveRate)
+	cfg.RateLimiter = limiter
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &throttleObserver{next: rt, limiter: limiter}
+	})
+	return cfg
+}
+
+// rateLimiter returns the limiter of the current rate.
+func (l *spokeRateLimiter) rateLimiter() flowcontrol.RateLimiter {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.limiter
+}
+
+// TryAccept returns whether a request can be sent right away, consuming a token if so.
+func (l *spokeRateLimiter) TryAccept() bool {
+	return l.rateLimiter().TryAccept()
+}
+
+// Accept waits until a request can be sent.
+func (l *spokeRateLimiter) Accept() {
+	l.rateLimiter().Accept()
+}
+
+// Wait waits until a request can be sent or the context is done.
+func (l *spokeRateLimiter) Wait(ctx context.Context) error {
+	return l.rateLimiter().Wait(ctx)
+}
+
+// Stop does nothing, the rate limiter is shared by the clients for the lifetime of the agent.
+func (l *spokeRateLimiter) Stop() {}
+
+// QPS returns the current rate.
+func (l *spokeRateLimiter) QPS() float32 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.current
+}
+
+// throttled halves the rate after the cluster throttled a request, down to a tenth of the configured rate.
+func (l *spokeRateLimiter) throttled() {
+	spokeThrottledRequests.WithLabelValues(l.host).Inc()
+	if !l.adaptive {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.throttledAt = now
+	if now.Sub(l.changedAt) < spokeThrottleCooldown {
+		return
+	}
+	l.setRate(l.current/2, now)
+}
+
+// succeeded doubles the rate, up to the configured rate, once the cluster has not throttled any
+// request for the recovery interval.
+func (l *spokeRateLimiter) succeeded() {
+	if !l.adaptive {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if l.current >= l.qps || now.Sub(l.throttledAt) < spokeRateRecoveryInterval || now.Sub(l.changedAt) < spokeRateRecoveryInterval {
+		return
+	}
+	l.setRate(l.current*2, now)
+}
+
+// setRate replaces the limiter with one of the given rate, between a tenth of the configured rate
+// and the configured rate. The new limiter starts drained, so changing the rate does not release a
+// burst of requests at a cluster which is throttling them.
+func (l *spokeRateLimiter) setRate(qps float32, now time.Time) {
+	if minimum := l.qps * minSpokeRateFactor; qps < minimum {
+		qps = minimum
+	}
+	if qps > l.qps {
+		qps = l.qps
+	}
+	l.changedAt = now
+	if qps == l.current {
+		return
+	}
+	l.current = qps
+	// the requests already waiting for the previous limiter are let through at its rate
+	limiter := flowcontrol.NewTokenBucketRateLimiter(qps, l.burst)
+	for limiter.TryAccept() {
+	}
+	l.limiter = limiter
+	spokeRateLimit.WithLabelValues(l.host).Set(float64(qps))
+}
+
+// throttleObserver reports the requests throttled by the cluster to the rate limiter of its clients.
+type throttleObserver struct {
+	next    http.RoundTripper
+	limiter *spokeRateLimiter
+}
+
+func (o *throttleObserver) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := o.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		o.limiter.throttled()
+	} else {
+		o.limiter.succeeded()
+	}
+	return resp, err
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestSpokeRateLimiterAdaptive(t *testing.T) {
+	now := time.Now()
+	limiter := newSpokeRateLimiter("https://spoke", 20, 10, true)
+	limiter.now = func() time.Time { return now }
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		throttled bool
+		expected  float32
+	}{
+		{name: "throttled", throttled: true, expected: 10},
+		{name: "throttled again right away", advance: 100 * time.Millisecond, throttled: true, expected: 10},
+		{name: "throttled after the cooldown", advance: time.Second, throttled: true, expected: 5},
+		{name: "throttled down to the minimum", advance: time.Second, throttled: true, expected: 2.5},
+		{name: "throttled at the minimum", advance: time.Second, throttled: true, expected: 2},
+		{name: "succeeded before the recovery interval", advance: 10 * time.Second, expected: 2},
+		{name: "succeeded after the recovery interval", advance: 20 * time.Second, expected: 4},
+		{name: "succeeded right after recovering", advance: time.Second, expected: 4},
+		{name: "recovering", advance: 30 * time.Second, expected: 8},
+		{name: "recovering again", advance: 30 * time.Second, expected: 16},
+		{name: "recovered up to the configured rate", advance: 30 * time.Second, expected: 20},
+	}
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		if step.throttled {
+			limiter.throttled()
+		} else {
+			limiter.succeeded()
+		}
+		if limiter.QPS() != step.expected {
+			t.Fatalf("%s: expected the rate %v, got %v", step.name, step.expected, limiter.QPS())
+		}
+	}
+}
+
+func TestSpokeRateLimiterThrottledNoBurst(t *testing.T) {
+	limiter := newSpokeRateLimiter("https://spoke", 2, 10, true)
+	limiter.throttled()
+
+	// the tokens of the previous limiter are not handed out again at the lower rate
+	accepted := 0
+	for limiter.TryAccept() {
+		accepted++
+	}
+	if accepted > 1 {
+		t.Fatalf("expected lowering the rate not to allow a burst, got %d requests accepted", accepted)
+	}
+}
+
+func TestSpokeRateLimiterNotAdaptive(t *testing.T) {
+	limiter := newSpokeRateLimiter("https://spoke", 20, 10, false)
+	limiter.throttled()
+	if limiter.QPS() != 20 {
+		t.Fatalf("expected the rate to be kept, got %v", limiter.QPS())
+	}
+}
+
+func TestLimitSpokeRate(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	cfg := limitSpokeRate(&rest.Config{Host: server.URL}, Options{SpokeAdaptiveRate: true})
+	limiter, ok := cfg.RateLimiter.(*spokeRateLimiter)
+	if !ok {
+		t.Fatalf("expected a shared rate limiter, got %T", cfg.RateLimiter)
+	}
+	if cfg.QPS != rest.DefaultQPS || cfg.Burst != rest.DefaultBurst || limiter.QPS() != rest.DefaultQPS {
+		t.Fatalf("expected the client-go defaults, got %v and %d", cfg.QPS, cfg.Burst)
+	}
+	// the copies of the config, one per client, share the rate limiter
+	if rest.CopyConfig(cfg).RateLimiter != cfg.RateLimiter {
+		t.Fatal("expected the copies of the config to share the rate limiter")
+	}
+
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusTooManyRequests
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if limiter.QPS() != rest.DefaultQPS/2 {
+		t.Fatalf("expected the throttled request to halve the rate, got %v", limiter.QPS())
+	}
+}
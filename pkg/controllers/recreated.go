@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RecreatedResourcePolicy is what the agent does with a resource it applied before which was
+// deleted and recreated by someone else, detected by the UID of the live resource differing from
+// the UID recorded on the AppliedWork.
+type RecreatedResourcePolicy string
+
+const (
+	// RecreatedResourcePolicyAdopt applies the manifest to the recreated resource, which becomes the
+	// resource of the Work and is deleted with it.
+	RecreatedResourcePolicyAdopt RecreatedResourcePolicy = "Adopt"
+	// RecreatedResourcePolicyReapply deletes the recreated resource and creates it again from the
+	// manifest, so no field set by whoever recreated it is kept.
+	RecreatedResourcePolicyReapply RecreatedResourcePolicy = "Reapply"
+	// RecreatedResourcePolicyReport leaves the recreated resource untouched and only reports it,
+	// it is not deleted with the Work.
+	RecreatedResourcePolicyReport RecreatedResourcePolicy = "Report"
+)
+
+// ParseRecreatedResourcePolicy returns the recreated resource policy of the given name, Adopt if empty.
+func ParseRecreatedResourcePolicy(name string) (RecreatedResourcePolicy, error) {
+	switch policy := RecreatedResourcePolicy(name); policy {
+	case "":
+		return RecreatedResourcePolicyAdopt, nil
+	case RecreatedResourcePolicyAdopt, RecreatedResourcePolicyReapply, RecreatedResourcePolicyReport:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown recreated resource policy %q, expected one of %s, %s or %s",
+			name, RecreatedResourcePolicyAdopt, RecreatedResourcePolicyReapply, RecreatedResourcePolicyReport)
+	}
+}
+
+// ErrResourceRecreated is returned by Applier.Apply, wrapped, when the live resource has another
+// UID than the one required, i.e. it was deleted and recreated by someone else.
+var ErrResourceRecreated = errors.New("the resource was deleted and recreated by someone else")
+
+// isResourceRecreated returns true if the error reports a resource recreated by someone else.
+func isResourceRecreated(err error) bool {
+	return errors.Is(err, ErrResourceRecreated)
+}
+
+// describeRecreatedResourcePolicy returns what is done with a recreated resource, for the events
+// and conditions.
+func describeRecreatedResourcePolicy(policy RecreatedResourcePolicy) string {
+	switch policy {
+	case RecreatedResourcePolicyReapply:
+		return "it was deleted and created again from the manifest"
+	case RecreatedResourcePolicyReport:
+		return "it is left untouched"
+	default:
+		return "it was adopted"
+	}
+}
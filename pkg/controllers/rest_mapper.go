@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+)
+
+// noMatchRefreshInterval is the minimum interval between the refreshes of the discovery caused by
+// an unknown resource type, so a manifest of a type missing on the spoke does not hammer discovery.
+const noMatchRefreshInterval = 30 * time.Second
+
+// cachedRESTMapper is the RESTMapper of the spoke cluster shared by the controllers of the agent.
+// The discovery is fetched lazily and cached for ttl, it is refreshed earlier if a resource type
+// is unknown, so new CRDs are found.
+type cachedRESTMapper struct {
+	mapper *restmapper.DeferredDiscoveryRESTMapper
+	ttl    time.Duration
+
+	lock        sync.Mutex
+	refreshedAt time.Time
+}
+
+func newCachedRESTMapper(cfg *rest.Config, ttl time.Duration) (*cachedRESTMapper, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &cachedRESTMapper{
+		mapper:      restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		ttl:         ttl,
+		refreshedAt: time.Now(),
+	}, nil
+}
+
+// refresh invalidates the cached discovery if it is older than the interval.
+func (m *cachedRESTMapper) refresh(interval time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if interval > 0 && time.Since(m.refreshedAt) < interval {
+		return
+	}
+	m.mapper.Reset()
+	m.refreshedAt = time.Now()
+	spokeDiscoveryRefreshes.Inc()
+}
+
+// do runs a lookup on the cached discovery, refreshing it if it expired or if the lookup does not
+// find the resource type.
+func (m *cachedRESTMapper) do(lookup func() error) {
+	if m.ttl > 0 {
+		m.refresh(m.ttl)
+	}
+	if err := lookup(); meta.IsNoMatchError(err) {
+		m.refresh(noMatchRefreshInterval)
+		_ = lookup()
+	}
+}
+
+func (m *cachedRESTMapper) KindFor(resource schema.GroupVersionResource) (gvk schema.GroupVersionKind, err error) {
+	m.do(func() error {
+		gvk, err = m.mapper.KindFor(resource)
+		return err
+	})
+	return gvk, err
+}
+
+func (m *cachedRESTMapper) KindsFor(resource schema.GroupVersionResource) (gvks []schema.GroupVersionKind, err error) {
+	m.do(func() error {
+		gvks, err = m.mapper.KindsFor(resource)
+		return err
+	})
+	return gvks, err
+}
+
+func (m *cachedRESTMapper) ResourceFor(input schema.GroupVersionResource) (gvr schema.GroupVersionResource, err error) {
+	m.do(func() error {
+		gvr, err = m.mapper.ResourceFor(input)
+		return err
+	})
+	return gvr, err
+}
+
+func (m *cachedRESTMapper) ResourcesFor(input schema.GroupVersionResource) (gvrs []schema.GroupVersionResource, err error) {
+	m.do(func() error {
+		gvrs, err = m.mapper.ResourcesFor(input)
+		return err
+	})
+	return gvrs, err
+}
+
+func (m *cachedRESTMapper) RESTMapping(gk schema.GroupKind, versions ...string) (mapping *meta.RESTMapping, err error) {
+	m.do(func() error {
+		mapping, err = m.mapper.RESTMapping(gk, versions...)
+		return err
+	})
+	return mapping, err
+}
+
+func (m *cachedRESTMapper) RESTMappings(gk schema.GroupKind, versions ...string) (mappings []*meta.RESTMapping, err error) {
+	m.do(func() error {
+		mappings, err = m.mapper.RESTMappings(gk, versions...)
+		return err
+	})
+	return mappings, err
+}
+
+func (m *cachedRESTMapper) ResourceSingularizer(resource string) (string, error) {
+	return m.mapper.ResourceSingularizer(resource)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestStampRestart(t *testing.T) {
+	cases := []struct {
+		name        string
+		manifest    string
+		restartedAt string
+		expected    map[string]string
+	}{
+		{
+			name:        "deployment",
+			manifest:    `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"app"},"spec":{"template":{"metadata":{"labels":{"app":"app"}}}}}`,
+			restartedAt: "2021-10-01T00:00:00Z",
+			expected:    map[string]string{restartedAtAnnotation: "2021-10-01T00:00:00Z"},
+		},
+		{
+			name:        "other pod template annotations are kept",
+			manifest:    `{"apiVersion":"apps/v1","kind":"DaemonSet","metadata":{"name":"app"},"spec":{"template":{"metadata":{"annotations":{"a":"b"}}}}}`,
+			restartedAt: "2021-10-01T00:00:00Z",
+			expected:    map[string]string{"a": "b", restartedAtAnnotation: "2021-10-01T00:00:00Z"},
+		},
+		{
+			name:     "no restart",
+			manifest: `{"apiVersion":"apps/v1","kind":"StatefulSet","metadata":{"name":"app"},"spec":{}}`,
+		},
+		{
+			name:        "not restartable",
+			manifest:    `{"apiVersion":"batch/v1","kind":"Job","metadata":{"name":"app"},"spec":{"template":{}}}`,
+			restartedAt: "2021-10-01T00:00:00Z",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON([]byte(c.manifest)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := stampRestart(obj, c.restartedAt); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			annotations, _, err := unstructured.NestedStringMap(obj.Object, "spec", "template", "metadata", "annotations")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(annotations) != len(c.expected) {
+				t.Fatalf("expected pod template annotations %v, got %v", c.expected, annotations)
+			}
+			for k, v := range c.expected {
+				if annotations[k] != v {
+					t.Errorf("expected pod template annotations %v, got %v", c.expected, annotations)
+				}
+			}
+		})
+	}
+}
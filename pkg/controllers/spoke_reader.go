@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+)
+
+// spokeReader reads the resources applied on the spoke cluster. If caching is enabled, a shared
+// informer is started for every applied resource type and the resources are read from its cache,
+// they are only read from the spoke cluster until the cache is synced or if missing from it.
+type spokeReader struct {
+	client dynamic.Interface
+	// factory is nil if caching is disabled.
+	factory dynamicinformer.DynamicSharedInformerFactory
+	stopCh  <-chan struct{}
+
+	lock      sync.Mutex
+	informers map[schema.GroupVersionResource]informers.GenericInformer
+}
+
+func newSpokeReader(ctx context.Context, client dynamic.Interface, cache bool) *spokeReader {
+	reader := &spokeReader{
+		client:    client,
+		stopCh:    ctx.Done(),
+		informers: map[schema.GroupVersionResource]informers.GenericInformer{},
+	}
+	if cache {
+		reader.factory = dynamicinformer.NewDynamicSharedInformerFactory(client, 0)
+	}
+	return reader
+}
+
+// Get returns a resource of the spoke cluster. The returned object may be modified by the caller.
+func (r *spokeReader) Get(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, error) {
+	if r.factory != nil {
+		if obj, ok := r.getFromCache(gvr, namespace, name); ok {
+			spokeCacheReads.WithLabelValues("hit").Inc()
+			return obj, nil
+		}
+		spokeCacheReads.WithLabelValues("miss").Inc()
+	}
+
+	return r.client.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (r *spokeReader) getFromCache(gvr schema.GroupVersionResource, namespace, name string) (*unstructured.Unstructured, bool) {
+	informer := r.informerFor(gvr)
+	if !informer.Informer().HasSynced() {
+		return nil, false
+	}
+
+	var obj runtime.Object
+	var err error
+	if namespace == "" {
+		obj, err = informer.Lister().Get(name)
+	} else {
+		obj, err = informer.Lister().ByNamespace(namespace).Get(name)
+	}
+	if err != nil {
+		return nil, false
+	}
+	cached, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false
+	}
+	return cached.DeepCopy(), true
+}
+
+// informerFor returns the informer of a resource type, it is started on first use.
+func (r *spokeReader) informerFor(gvr schema.GroupVersionResource) informers.GenericInformer {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	informer, ok := r.informers[gvr]
+	if !ok {
+		informer = r.factory.ForResource(gvr)
+		r.informers[gvr] = informer
+		r.factory.Start(r.stopCh)
+	}
+	return informer
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// loadStatusDetail sets the manifest conditions kept in the WorkStatusDetail of a work back on
+// its status, so the work is reconciled as if they were never moved. The conditions are rebuilt
+// if the WorkStatusDetail is gone.
+func (r *ApplyWorkReconciler) loadStatusDetail(ctx context.Context, work *workv1alpha1.Work) error {
+	if work.Status.StatusDetailName == "" {
+		return nil
+	}
+	detail := &workv1alpha1.WorkStatusDetail{}
+	err := r.client.Get(ctx, client.ObjectKey{Namespace: work.Namespace, Name: work.Status.StatusDetailName}, detail)
+	switch {
+	case errors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	work.Status.ManifestConditions = detail.ManifestConditions
+	return nil
+}
+
+// spillStatusDetail moves the manifest conditions of a work to its WorkStatusDetail when they
+// exceed the status size budget. It returns the name of the WorkStatusDetail which is no longer
+// needed once the status of the work is written, if the manifest conditions fit in it again.
+func (r *ApplyWorkReconciler) spillStatusDetail(ctx context.Context, work *workv1alpha1.Work) (string, error) {
+	if r.statusSizeBudget <= 0 {
+		return releaseStatusDetail(work), nil
+	}
+	size, err := manifestConditionsSize(work.Status.ManifestConditions)
+	if err != nil {
+		return "", err
+	}
+	if size <= r.statusSizeBudget {
+		return releaseStatusDetail(work), nil
+	}
+
+	detail := &workv1alpha1.WorkStatusDetail{ObjectMeta: metav1.ObjectMeta{Namespace: work.Namespace, Name: work.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, r.client, detail, func() error {
+		// the WorkStatusDetail is garbage collected with its work
+		detail.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(work, workv1alpha1.SchemeGroupVersion.WithKind(workv1alpha1.WorkKind))}
+		detail.ManifestConditions = work.Status.ManifestConditions
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	work.Status.ManifestConditions = nil
+	work.Status.StatusDetailName = detail.Name
+	return "", nil
+}
+
+// releaseStatusDetail keeps the manifest conditions in the status of a work and returns the name of
+// the WorkStatusDetail they were kept in before, if any.
+func releaseStatusDetail(work *workv1alpha1.Work) string {
+	name := work.Status.StatusDetailName
+	work.Status.StatusDetailName = ""
+	return name
+}
+
+// deleteStatusDetail deletes a WorkStatusDetail no longer referenced by its work.
+func (r *ApplyWorkReconciler) deleteStatusDetail(ctx context.Context, work *workv1alpha1.Work, name string) error {
+	detail := &workv1alpha1.WorkStatusDetail{ObjectMeta: metav1.ObjectMeta{Namespace: work.Namespace, Name: name}}
+	return client.IgnoreNotFound(r.client.Delete(ctx, detail))
+}
+
+// manifestConditionsSize returns the size of the manifest conditions in the status of a work.
+func manifestConditionsSize(manifestConditions []workv1alpha1.ManifestCondition) (int, error) {
+	raw, err := json.Marshal(manifestConditions)
+	if err != nil {
+		return 0, err
+	}
+	return len(raw), nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func TestStatusDetail(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestConditions := []workv1alpha1.ManifestCondition{
+		{Identifier: workv1alpha1.ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Name: "cm1"}},
+		{Identifier: workv1alpha1.ResourceIdentifier{Ordinal: 1, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Name: "cm2"}},
+	}
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", UID: "work-uid"},
+		Status:     workv1alpha1.WorkStatus{ManifestConditions: manifestConditions},
+	}
+	ctx := context.Background()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(work.DeepCopy()).Build()
+	r := &ApplyWorkReconciler{client: fakeClient, statusSizeBudget: 100}
+
+	// the manifest conditions exceeding the budget are moved to the status detail
+	stale, err := r.spillStatusDetail(ctx, work)
+	if err != nil || stale != "" {
+		t.Fatalf("expected the status detail to be written, got %q: %v", stale, err)
+	}
+	if work.Status.StatusDetailName != "work" || len(work.Status.ManifestConditions) != 0 {
+		t.Fatalf("expected the manifest conditions to be moved out of the work, got %v", work.Status)
+	}
+	detail := &workv1alpha1.WorkStatusDetail{}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "cluster1", Name: "work"}, detail); err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.ManifestConditions) != 2 || len(detail.OwnerReferences) != 1 || detail.OwnerReferences[0].UID != "work-uid" {
+		t.Errorf("expected the status detail to hold the manifest conditions and be owned by the work, got %v", detail)
+	}
+
+	// the manifest conditions are read back from the status detail
+	reloaded := &workv1alpha1.Work{
+		ObjectMeta: work.ObjectMeta,
+		Status:     workv1alpha1.WorkStatus{StatusDetailName: "work"},
+	}
+	if err := r.loadStatusDetail(ctx, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.Status.ManifestConditions) != 2 {
+		t.Fatalf("expected the manifest conditions to be loaded, got %v", reloaded.Status)
+	}
+
+	// the status detail is released once the manifest conditions fit in the work again
+	r.statusSizeBudget = 10000
+	stale, err = r.spillStatusDetail(ctx, reloaded)
+	if err != nil || stale != "work" {
+		t.Fatalf("expected the status detail to be stale, got %q: %v", stale, err)
+	}
+	if reloaded.Status.StatusDetailName != "" || len(reloaded.Status.ManifestConditions) != 2 {
+		t.Errorf("expected the manifest conditions to be kept in the work, got %v", reloaded.Status)
+	}
+	if err := r.deleteStatusDetail(ctx, reloaded, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Get(ctx, client.ObjectKey{Namespace: "cluster1", Name: "work"}, detail); !errors.IsNotFound(err) {
+		t.Errorf("expected the status detail to be deleted, got %v", err)
+	}
+}
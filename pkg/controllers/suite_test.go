@@ -19,7 +19,6 @@ package controllers
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
@@ -34,6 +33,7 @@ import (
 
 	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
 	workclient "sigs.k8s.io/work-api/pkg/client/clientset/versioned"
+	"sigs.k8s.io/work-api/pkg/worktest"
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
@@ -58,11 +58,10 @@ var _ = BeforeSuite(func(done Done) {
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
 	By("bootstrapping test environment")
-	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("../../", "config", "crd")},
-	}
-
 	var err error
+	testEnv, err = worktest.NewEnvironment()
+	Expect(err).ToNot(HaveOccurred())
+
 	cfg, err = testEnv.Start()
 	Expect(err).ToNot(HaveOccurred())
 	Expect(cfg).ToNot(BeNil())
@@ -80,7 +79,7 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).NotTo(HaveOccurred())
 
 	go func() {
-		if err := Start(ctrl.SetupSignalHandler(), cfg, cfg, setupLog, opts); err != nil {
+		if err := Start(ctrl.SetupSignalHandler(), cfg, cfg, setupLog, opts, Options{}); err != nil {
 			setupLog.Error(err, "problem running controllers")
 			os.Exit(1)
 		}
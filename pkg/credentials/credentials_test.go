@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+)
+
+// writeSVID writes a self-signed certificate with the URI SAN, and its key, to the files.
+func writeSVID(t *testing.T, certFile, keyFile, uri string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if uri != "" {
+		parsed, err := url.Parse(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.URIs = []*url.URL{parsed}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func svidFiles(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	return filepath.Join(dir, "svid.pem"), filepath.Join(dir, "svid_key.pem")
+}
+
+func TestSVIDFileProvider(t *testing.T) {
+	certFile, keyFile := svidFiles(t)
+	provider := NewSVIDFileProvider(certFile, keyFile)
+	if _, err := provider.Certificate(); err == nil {
+		t.Fatal("expected an error without the files")
+	}
+
+	writeSVID(t, certFile, keyFile, "")
+	if _, err := provider.Certificate(); err == nil {
+		t.Fatal("expected an error without a SPIFFE ID")
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		expected string
+	}{
+		{name: "first SVID", uri: "spiffe://example.org/agent/a", expected: "spiffe://example.org/agent/a"},
+		{name: "rotated SVID", uri: "spiffe://example.org/agent/b", expected: "spiffe://example.org/agent/b"},
+		{name: "invalid SVID keeps the previous one", uri: "https://example.org", expected: "spiffe://example.org/agent/b"},
+	}
+	for _, tt := range tests {
+		writeSVID(t, certFile, keyFile, tt.uri)
+		certificate, err := provider.Certificate()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		id, err := SPIFFEID(certificate)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if id != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, id)
+		}
+	}
+}
+
+func TestConfigForRotation(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.TLS.PeerCertificates[0].URIs[0].String())
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certFile, keyFile := svidFiles(t)
+	writeSVID(t, certFile, keyFile, "spiffe://example.org/agent/a")
+	cfg := &rest.Config{
+		Host:            server.URL,
+		TLSClientConfig: rest.TLSClientConfig{CAData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})},
+	}
+	cfg, rotator, err := ConfigFor(cfg, NewSVIDFileProvider(certFile, keyFile), logr.Discard())
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: transport}
+
+	get := func() string {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+	if id := get(); id != "spiffe://example.org/agent/a" {
+		t.Fatalf("expected the first SVID, got %s", id)
+	}
+
+	writeSVID(t, certFile, keyFile, "spiffe://example.org/agent/b")
+	rotator.refresh()
+	if id := get(); id != "spiffe://example.org/agent/b" {
+		t.Fatalf("expected the rotated SVID, got %s", id)
+	}
+}
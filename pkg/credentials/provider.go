@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials authenticates the agent to the hub with client certificates which rotate,
+// such as SPIFFE X509-SVIDs, rather than the long-lived credentials of a kubeconfig.
+package credentials
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Provider provides the client certificate the agent authenticates to the hub with. The
+// certificate may change at any time, e.g. when it is rotated before it expires.
+type Provider interface {
+	// Certificate returns the current client certificate.
+	Certificate() (*tls.Certificate, error)
+}
+
+// SVIDFileProvider provides the X509-SVID written to files by the SPIRE agent, or by the
+// spiffe-helper next to the agent. The files are read again whenever they change.
+type SVIDFileProvider struct {
+	certFile, keyFile string
+
+	lock            sync.Mutex
+	certPEM, keyPEM []byte
+	certificate     *tls.Certificate
+}
+
+// NewSVIDFileProvider returns a Provider of the X509-SVID of the PEM certificate and key files.
+func NewSVIDFileProvider(certFile, keyFile string) *SVIDFileProvider {
+	return &SVIDFileProvider{certFile: certFile, keyFile: keyFile}
+}
+
+// Certificate returns the X509-SVID of the files. The previous SVID is returned while the files
+// are being rewritten, until the certificate and key match again.
+func (p *SVIDFileProvider) Certificate() (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(p.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(p.keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.certificate != nil && bytes.Equal(certPEM, p.certPEM) && bytes.Equal(keyPEM, p.keyPEM) {
+		return p.certificate, nil
+	}
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err == nil {
+		_, err = SPIFFEID(&certificate)
+	}
+	if err != nil {
+		if p.certificate != nil {
+			return p.certificate, nil
+		}
+		return nil, fmt.Errorf("invalid X509-SVID %s: %w", p.certFile, err)
+	}
+	p.certPEM, p.keyPEM, p.certificate = certPEM, keyPEM, &certificate
+	return p.certificate, nil
+}
+
+// SPIFFEID returns the SPIFFE ID of an X509-SVID, the single spiffe URI SAN of its leaf certificate.
+func SPIFFEID(certificate *tls.Certificate) (string, error) {
+	if len(certificate.Certificate) == 0 {
+		return "", fmt.Errorf("no certificate")
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return "", err
+	}
+	if len(leaf.URIs) != 1 || leaf.URIs[0].Scheme != "spiffe" || leaf.URIs[0].Host == "" {
+		return "", fmt.Errorf("the certificate must have exactly one spiffe URI SAN")
+	}
+	return leaf.URIs[0].String(), nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/connrotation"
+)
+
+// refreshInterval is how often the certificate of the provider is checked for a rotation.
+const refreshInterval = 30 * time.Second
+
+// Rotator authenticates the connections to the hub with the certificate of its provider. The
+// connections are closed when the certificate rotates, so they are opened again with the new one
+// before the old one expires.
+type Rotator struct {
+	provider Provider
+	dialer   *connrotation.Dialer
+	log      logr.Logger
+
+	lock    sync.Mutex
+	current *tls.Certificate
+}
+
+// ConfigFor returns a copy of a config of the hub which authenticates with the certificate of
+// the provider, in place of the client certificate of the config, and its Rotator to start.
+func ConfigFor(cfg *rest.Config, provider Provider, log logr.Logger) (*rest.Config, *Rotator, error) {
+	cfg = rest.CopyConfig(cfg)
+	cfg.CertFile, cfg.KeyFile, cfg.CertData, cfg.KeyData = "", "", nil, nil
+	tlsConfig, err := rest.TLSConfigFor(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	dial := cfg.Dial
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	proxy := cfg.Proxy
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+	r := &Rotator{
+		provider: provider,
+		dialer:   connrotation.NewDialer(dial),
+		log:      log,
+	}
+	tlsConfig.GetClientCertificate = r.clientCertificate
+
+	cfg.Transport = utilnet.SetTransportDefaults(&http.Transport{
+		Proxy:               proxy,
+		TLSHandshakeTimeout: 10 * time.Second,
+		TLSClientConfig:     tlsConfig,
+		DialContext:         r.dialer.DialContext,
+	})
+	cfg.TLSClientConfig = rest.TLSClientConfig{}
+	cfg.Dial, cfg.Proxy = nil, nil
+	return cfg, r, nil
+}
+
+func (r *Rotator) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	certificate, err := r.provider.Certificate()
+	if err != nil {
+		r.log.Error(err, "unable to get the client certificate")
+		return nil, err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.current = certificate
+	return certificate, nil
+}
+
+// Start checks for a rotation of the certificate until the context is done.
+func (r *Rotator) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(context.Context) { r.refresh() }, refreshInterval)
+	return nil
+}
+
+// refresh closes the connections if the certificate rotated since they were opened.
+func (r *Rotator) refresh() {
+	certificate, err := r.provider.Certificate()
+	if err != nil {
+		r.log.Error(err, "unable to get the client certificate")
+		return
+	}
+
+	r.lock.Lock()
+	rotated := r.current != nil && !bytes.Equal(r.current.Certificate[0], certificate.Certificate[0])
+	if rotated {
+		r.current = certificate
+	}
+	r.lock.Unlock()
+
+	if rotated {
+		r.log.Info("client certificate rotated, closing the connections")
+		r.dialer.CloseAll()
+	}
+}
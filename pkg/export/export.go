@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export reads the live resources applied by a Work from its cluster, for a backup before
+// destructive changes to the Work or to migrate its workload off the hub.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// agentAnnotations are set by the agent on the resources it applies. They are removed from the
+// exported resources, which are no longer applied by the Work, the spec-hash one would otherwise
+// let an agent delete them.
+var agentAnnotations = []string{
+	"multicluster.x-k8s.io/spec-hash",
+	workv1alpha1.AppliedTimeAnnotation,
+	workv1alpha1.WorkGenerationAnnotation,
+}
+
+// Export returns the live resources recorded in the AppliedWork of a Work, read with the client and
+// the RESTMapper of its cluster. The status and the metadata set by the server or the agent are
+// removed, so the resources can be applied again, on the same or another cluster. The resources no
+// longer on the cluster are skipped.
+func Export(ctx context.Context, c client.Reader, restMapper meta.RESTMapper, workNamespace, workName string) ([]*unstructured.Unstructured, error) {
+	appliedWork, err := findAppliedWork(ctx, c, workNamespace, workName)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := []*unstructured.Unstructured{}
+	for _, resource := range appliedWork.Status.AppliedResources {
+		gvk := schema.GroupVersionKind{Group: resource.Group, Version: resource.Version, Kind: resource.Kind}
+		if gvk.Kind == "" {
+			// the kind is not recorded by older agents
+			gvr := schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource}
+			if gvk, err = restMapper.KindFor(gvr); err != nil {
+				return nil, fmt.Errorf("failed to map %s: %w", gvr, err)
+			}
+		}
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		err := c.Get(ctx, client.ObjectKey{Namespace: resource.Namespace, Name: resource.Name}, live)
+		switch {
+		case errors.IsNotFound(err):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		resources = append(resources, clean(live))
+	}
+	return resources, nil
+}
+
+// findAppliedWork returns the AppliedWork of a Work.
+func findAppliedWork(ctx context.Context, c client.Reader, workNamespace, workName string) (*workv1alpha1.AppliedWork, error) {
+	appliedWorks := &workv1alpha1.AppliedWorkList{}
+	if err := c.List(ctx, appliedWorks); err != nil {
+		return nil, err
+	}
+	for i := range appliedWorks.Items {
+		if appliedWorks.Items[i].Spec.WorkNamespace == workNamespace && appliedWorks.Items[i].Spec.WorkName == workName {
+			return &appliedWorks.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("the work %s/%s has no AppliedWork on the cluster", workNamespace, workName)
+}
+
+// clean removes the status and the metadata set by the server or the agent from a live resource.
+func clean(live *unstructured.Unstructured) *unstructured.Unstructured {
+	delete(live.Object, "status")
+	for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(live.Object, "metadata", field)
+	}
+	if annotations := live.GetAnnotations(); annotations != nil {
+		for _, annotation := range agentAnnotations {
+			delete(annotations, annotation)
+		}
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		live.SetAnnotations(annotations)
+	}
+	return live
+}
+
+// WriteYAML writes resources as a stream of YAML documents, which kubectl apply -f accepts.
+func WriteYAML(out io.Writer, resources []*unstructured.Unstructured) error {
+	for i, resource := range resources {
+		content, err := yaml.Marshal(resource.Object)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			if _, err := fmt.Fprintln(out, "---"); err != nil {
+				return err
+			}
+		}
+		if _, err := out.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func TestExport(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	appliedWork := &workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "app"},
+		Spec:       workv1alpha1.AppliedWorkSpec{WorkNamespace: "cluster1", WorkName: "app"},
+		Status: workv1alpha1.AppliedtWorkStatus{AppliedResources: []workv1alpha1.AppliedResourceMeta{
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "config"}},
+			// recorded without its kind by an older agent
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "default", Name: "settings"}},
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "deleted"}},
+		}},
+	}
+	config := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "config",
+			UID:       "uid",
+			Annotations: map[string]string{
+				"multicluster.x-k8s.io/spec-hash":     "hash",
+				workv1alpha1.WorkGenerationAnnotation: "2",
+				"example.com/owner":                   "team-a",
+			},
+		},
+		Data: map[string]string{"key": "value"},
+	}
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "settings"},
+		Data:       map[string]string{"mode": "fast"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(appliedWork, config, settings).Build()
+
+	resources, err := Export(context.Background(), c, restMapper, "cluster1", "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 || resources[0].GetName() != "config" || resources[1].GetName() != "settings" {
+		t.Fatalf("expected the resources still on the cluster, got %v", resources)
+	}
+	if resources[0].GetUID() != "" || resources[0].GetResourceVersion() != "" {
+		t.Errorf("expected the metadata set by the server to be removed, got %v", resources[0])
+	}
+	if annotations := resources[0].GetAnnotations(); len(annotations) != 1 || annotations["example.com/owner"] != "team-a" {
+		t.Errorf("expected only the annotations of the agent to be removed, got %v", annotations)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteYAML(out, resources); err != nil {
+		t.Fatal(err)
+	}
+	if documents := strings.Split(out.String(), "---\n"); len(documents) != 2 || !strings.Contains(documents[1], "mode: fast") {
+		t.Errorf("expected a YAML document per resource, got %s", out.String())
+	}
+
+	if _, err := Export(context.Background(), c, restMapper, "cluster1", "other"); err == nil {
+		t.Error("expected an error for a work without AppliedWork")
+	}
+}
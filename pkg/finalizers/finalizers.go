@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package finalizers adds and removes finalizers with JSON patches. Unlike updates, the patches
+// compose with the finalizers other controllers add or remove concurrently, and do not send the
+// whole object.
+package finalizers
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// operation is an operation of a JSON patch.
+type operation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Add adds the finalizer to the object if it does not have it. The object is updated with the
+// result of the patch.
+func Add(ctx context.Context, c client.Client, obj client.Object, finalizer string) error {
+	if controllerutil.ContainsFinalizer(obj, finalizer) {
+		return nil
+	}
+	patch := []operation{{Op: "add", Path: "/metadata/finalizers/-", Value: finalizer}}
+	if len(obj.GetFinalizers()) == 0 {
+		// adding the list replaces it, so it conflicts if another finalizer was added meanwhile
+		patch = []operation{
+			{Op: "test", Path: "/metadata/resourceVersion", Value: obj.GetResourceVersion()},
+			{Op: "add", Path: "/metadata/finalizers", Value: []string{finalizer}},
+		}
+	}
+	return apply(ctx, c, obj, patch)
+}
+
+// Remove removes the finalizer from the object if it has it. The object is updated with the
+// result of the patch. The patch fails if the finalizers moved since the object was read.
+func Remove(ctx context.Context, c client.Client, obj client.Object, finalizer string) error {
+	patch := []operation{}
+	// removed from the last, so the indexes of the others still hold
+	finalizers := obj.GetFinalizers()
+	for i := len(finalizers) - 1; i >= 0; i-- {
+		if finalizers[i] != finalizer {
+			continue
+		}
+		path := "/metadata/finalizers/" + strconv.Itoa(i)
+		patch = append(patch, operation{Op: "test", Path: path, Value: finalizer}, operation{Op: "remove", Path: path})
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	return apply(ctx, c, obj, patch)
+}
+
+func apply(ctx context.Context, c client.Client, obj client.Object, patch []operation) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, client.RawPatch(types.JSONPatchType, data))
+}
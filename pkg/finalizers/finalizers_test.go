@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package finalizers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testFinalizer = "multicluster.x-k8s.io/work-cleanup"
+
+func newTestClient(finalizers ...string) (client.Client, *corev1.ConfigMap) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", Finalizers: finalizers}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(obj).Build()
+	read := &corev1.ConfigMap{}
+	utilruntime.Must(c.Get(context.Background(), client.ObjectKeyFromObject(obj), read))
+	return c, read
+}
+
+func TestAdd(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing []string
+		expected []string
+	}{
+		{name: "no finalizers", expected: []string{testFinalizer}},
+		{name: "other finalizers are kept", existing: []string{"other"}, expected: []string{"other", testFinalizer}},
+		{name: "already added", existing: []string{testFinalizer}, expected: []string{testFinalizer}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fakeClient, obj := newTestClient(c.existing...)
+			if err := Add(context.Background(), fakeClient, obj, testFinalizer); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(obj.Finalizers, c.expected) {
+				t.Errorf("expected finalizers %v, got %v", c.expected, obj.Finalizers)
+			}
+		})
+	}
+}
+
+func TestAddConflict(t *testing.T) {
+	fakeClient, obj := newTestClient()
+	stale := obj.DeepCopy()
+	if err := Add(context.Background(), fakeClient, obj, "other"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the finalizer added meanwhile must not be replaced
+	if err := Add(context.Background(), fakeClient, stale, testFinalizer); err == nil {
+		t.Fatal("expected the patch of the stale object to fail")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	cases := []struct {
+		name     string
+		existing []string
+		expected []string
+	}{
+		{name: "other finalizers are kept", existing: []string{"first", testFinalizer, "last"}, expected: []string{"first", "last"}},
+		{name: "duplicates", existing: []string{testFinalizer, "other", testFinalizer}, expected: []string{"other"}},
+		{name: "not present", existing: []string{"other"}, expected: []string{"other"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fakeClient, obj := newTestClient(c.existing...)
+			if err := Remove(context.Background(), fakeClient, obj, testFinalizer); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(obj.Finalizers, c.expected) {
+				t.Errorf("expected finalizers %v, got %v", c.expected, obj.Finalizers)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+const (
+	// placementLabel is set on every PlacementDecision, its value is the name of the Placement.
+	placementLabel = "cluster.open-cluster-management.io/placement"
+)
+
+var placementDecisionGVK = schema.GroupVersionKind{
+	Group:   "cluster.open-cluster-management.io",
+	Version: "v1beta1",
+	Kind:    "PlacementDecision",
+}
+
+// ClusterDecider decides which cluster namespaces a WorkSet is stamped into.
+type ClusterDecider interface {
+	// Decide returns the sorted names of the cluster namespaces selected for the workset.
+	Decide(ctx context.Context, workSet *workv1alpha1.WorkSet) ([]string, error)
+}
+
+// labelSelectorDecider selects the cluster namespaces matching the cluster selector of a workset.
+type labelSelectorDecider struct {
+	client client.Client
+}
+
+func (d *labelSelectorDecider) Decide(ctx context.Context, workSet *workv1alpha1.WorkSet) ([]string, error) {
+	if workSet.Spec.ClusterSelector == nil {
+		return []string{}, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(workSet.Spec.ClusterSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster selector: %w", err)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := d.client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	clusters := []string{}
+	for _, ns := range namespaces.Items {
+		if !ns.DeletionTimestamp.IsZero() {
+			continue
+		}
+		clusters = append(clusters, ns.Name)
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// placementDecider selects the clusters listed in the PlacementDecisions of the placement
+// referred by a workset.
+type placementDecider struct {
+	client client.Client
+}
+
+func (d *placementDecider) Decide(ctx context.Context, workSet *workv1alpha1.WorkSet) ([]string, error) {
+	ref := workSet.Spec.PlacementRef
+	if ref == nil {
+		return []string{}, nil
+	}
+
+	decisions := &unstructured.UnstructuredList{}
+	decisions.SetGroupVersionKind(placementDecisionGVK.GroupVersion().WithKind(placementDecisionGVK.Kind + "List"))
+	if err := d.client.List(ctx, decisions, client.InNamespace(ref.Namespace), client.MatchingLabels{placementLabel: ref.Name}); err != nil {
+		return nil, fmt.Errorf("failed to list placement decisions of %s/%s: %w", ref.Namespace, ref.Name, err)
+	}
+
+	clusters := sets.NewString()
+	for _, decision := range decisions.Items {
+		items, _, err := unstructured.NestedSlice(decision.Object, "status", "decisions")
+		if err != nil {
+			return nil, fmt.Errorf("invalid placement decision %s/%s: %w", decision.GetNamespace(), decision.GetName(), err)
+		}
+		for _, item := range items {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if clusterName, ok := itemMap["clusterName"].(string); ok && clusterName != "" {
+				clusters.Insert(clusterName)
+			}
+		}
+	}
+	return clusters.List(), nil
+}
+
+// defaultClusterDecider uses the placement decisions when a workset refers to a placement
+// and the cluster selector otherwise.
+type defaultClusterDecider struct {
+	selector  ClusterDecider
+	placement ClusterDecider
+}
+
+// NewClusterDecider returns the ClusterDecider used by the WorkSet controller by default.
+func NewClusterDecider(c client.Client) ClusterDecider {
+	return &defaultClusterDecider{
+		selector:  &labelSelectorDecider{client: c},
+		placement: &placementDecider{client: c},
+	}
+}
+
+func (d *defaultClusterDecider) Decide(ctx context.Context, workSet *workv1alpha1.WorkSet) ([]string, error) {
+	if workSet.Spec.PlacementRef != nil {
+		return d.placement.Decide(ctx, workSet)
+	}
+	return d.selector.Decide(ctx, workSet)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newPlacementDecision(namespace, name, placement string, clusters ...string) *unstructured.Unstructured {
+	decisions := []interface{}{}
+	for _, cluster := range clusters {
+		decisions = append(decisions, map[string]interface{}{"clusterName": cluster, "reason": ""})
+	}
+
+	decision := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"decisions": decisions},
+	}}
+	decision.SetGroupVersionKind(placementDecisionGVK)
+	decision.SetNamespace(namespace)
+	decision.SetName(name)
+	decision.SetLabels(map[string]string{placementLabel: placement})
+	return decision
+}
+
+func TestPlacementDecider(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(
+		newPlacementDecision("default", "placement1-decision-1", "placement1", "cluster2", "cluster1"),
+		newPlacementDecision("default", "placement1-decision-2", "placement1", "cluster3", "cluster1"),
+		newPlacementDecision("default", "placement2-decision-1", "placement2", "cluster4"),
+		newPlacementDecision("other", "placement1-decision-1", "placement1", "cluster5"),
+		newClusterNamespace("cluster6", true),
+	).Build()
+
+	workSet := newTestWorkSet()
+	workSet.Spec.PlacementRef = &workv1alpha1.PlacementReference{Name: "placement1", Namespace: "default"}
+
+	clusters, err := NewClusterDecider(fakeClient).Decide(context.Background(), workSet)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"cluster1", "cluster2", "cluster3"}
+	if !reflect.DeepEqual(clusters, expected) {
+		t.Errorf("expected clusters %v, got %v", expected, clusters)
+	}
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import "fmt"
+
+// ClusterDeletionPolicy is what is done with the resources of the Works of a cluster namespace
+// deleted on the hub, e.g. when the cluster is deregistered.
+type ClusterDeletionPolicy string
+
+const (
+	// ClusterDeletionPolicyCascade lets the agent delete the resources of the Works from the
+	// cluster, as when the Works are deleted one by one.
+	ClusterDeletionPolicyCascade ClusterDeletionPolicy = "Cascade"
+	// ClusterDeletionPolicyOrphan sets the orphan annotation on the Works, so the agent leaves their
+	// resources on the cluster and only deletes their AppliedWorks.
+	ClusterDeletionPolicyOrphan ClusterDeletionPolicy = "Orphan"
+)
+
+// ParseClusterDeletionPolicy returns the cluster deletion policy of the given name, Cascade if empty.
+func ParseClusterDeletionPolicy(name string) (ClusterDeletionPolicy, error) {
+	switch policy := ClusterDeletionPolicy(name); policy {
+	case "":
+		return ClusterDeletionPolicyCascade, nil
+	case ClusterDeletionPolicyCascade, ClusterDeletionPolicyOrphan:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown cluster deletion policy %q, expected %s or %s",
+			name, ClusterDeletionPolicyCascade, ClusterDeletionPolicyOrphan)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// ClusterDeletionPolicyReconciler records the cluster deletion policy on the namespaces with Works,
+// so the agent knows it when the Works are deleted along with the namespace, before the hub can
+// annotate them.
+type ClusterDeletionPolicyReconciler struct {
+	client client.Client
+	log    logr.Logger
+	// policy is the cluster deletion policy recorded on the namespaces, Cascade if empty.
+	policy ClusterDeletionPolicy
+}
+
+// Reconcile annotates a namespace with the cluster deletion policy, the request is named after the
+// namespace. The policy of a terminating namespace is only recorded if it has none yet, it is not
+// changed while the agent is cleaning up.
+func (r *ClusterDeletionPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	policy := r.policy
+	if policy == "" {
+		policy = ClusterDeletionPolicyCascade
+	}
+	ns := &corev1.Namespace{}
+	err := r.client.Get(ctx, req.NamespacedName, ns)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+	recorded, ok := ns.Annotations[workv1alpha1.ClusterDeletionPolicyAnnotation]
+	if recorded == string(policy) || (ok && !ns.DeletionTimestamp.IsZero()) {
+		return ctrl.Result{}, nil
+	}
+
+	works := &workv1alpha1.WorkList{}
+	if err := r.client.List(ctx, works, client.InNamespace(req.Name)); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(works.Items) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	r.log.V(2).Info("recording the cluster deletion policy", "namespace", req.Name, "policy", policy)
+	original := ns.DeepCopy()
+	metav1.SetMetaDataAnnotation(&ns.ObjectMeta, workv1alpha1.ClusterDeletionPolicyAnnotation, string(policy))
+	return ctrl.Result{}, r.client.Patch(ctx, ns, client.MergeFrom(original))
+}
+
+// SetupWithManager wires up the controller. The namespace of a work is only enqueued when the work
+// is created, the namespace events cover the policy being removed or changed afterwards.
+func (r *ClusterDeletionPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("cluster-deletion-policy").
+		For(&corev1.Namespace{}).
+		Watches(&source.Kind{Type: &workv1alpha1.Work{}}, handler.EnqueueRequestsFromMapFunc(namespaceOfWork),
+			builder.WithPredicates(predicate.Funcs{
+				UpdateFunc: func(event.UpdateEvent) bool { return false },
+				DeleteFunc: func(event.DeleteEvent) bool { return false },
+			})).
+		Complete(r)
+}
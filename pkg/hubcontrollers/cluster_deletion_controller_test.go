@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestClusterDeletionPolicyReconcile(t *testing.T) {
+	now := metav1.Now()
+	cases := []struct {
+		name      string
+		namespace *corev1.Namespace
+		works     bool
+		want      string
+	}{
+		{
+			name:      "namespace with works",
+			namespace: newClusterNamespace("cluster1", true),
+			works:     true,
+			want:      string(ClusterDeletionPolicyOrphan),
+		},
+		{
+			name:      "namespace without works",
+			namespace: newClusterNamespace("cluster1", true),
+		},
+		{
+			name: "policy changed",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "cluster1",
+				Annotations: map[string]string{workv1alpha1.ClusterDeletionPolicyAnnotation: "Cascade"},
+			}},
+			works: true,
+			want:  string(ClusterDeletionPolicyOrphan),
+		},
+		{
+			name: "terminating namespace keeps its policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:              "cluster1",
+				Annotations:       map[string]string{workv1alpha1.ClusterDeletionPolicyAnnotation: "Cascade"},
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"kubernetes"},
+			}},
+			works: true,
+			want:  string(ClusterDeletionPolicyCascade),
+		},
+		{
+			name: "terminating namespace without a policy",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:              "cluster1",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{"kubernetes"},
+			}},
+			works: true,
+			want:  string(ClusterDeletionPolicyOrphan),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objs := []client.Object{c.namespace}
+			if c.works {
+				objs = append(objs, newGCWork("work", metav1.ConditionTrue, time.Now(), nil))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(objs...).Build()
+			r := &ClusterDeletionPolicyReconciler{client: fakeClient, log: ctrl.Log, policy: ClusterDeletionPolicyOrphan}
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKey{Name: "cluster1"}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ns := &corev1.Namespace{}
+			if err := fakeClient.Get(context.Background(), client.ObjectKey{Name: "cluster1"}, ns); err != nil {
+				t.Fatal(err)
+			}
+			if policy := ns.Annotations[workv1alpha1.ClusterDeletionPolicyAnnotation]; policy != c.want {
+				t.Errorf("expected the policy %q, got %q", c.want, policy)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// FluxSourceKinds are the kinds of the Flux sources whose artifacts can be materialized as Works.
+var FluxSourceKinds = []schema.GroupVersionKind{
+	{Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Kind: "GitRepository"},
+	{Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Kind: "OCIRepository"},
+}
+
+// fluxArtifactTimeout bounds the download of an artifact, so a slow artifact server does not
+// block the reconciler.
+const fluxArtifactTimeout = 2 * time.Minute
+
+// allAllowedClusters allows the Flux sources of a namespace to materialize Works in any cluster.
+const allAllowedClusters = "*"
+
+// FluxSourceReconciler materializes the manifests of the artifact of a Flux source as Works in the
+// cluster namespaces listed in its source-clusters annotation, and updates the Works as the
+// revision of the artifact advances.
+type FluxSourceReconciler struct {
+	client     client.Client
+	httpClient *http.Client
+	log        logr.Logger
+	// gvk is the kind of the Flux sources reconciled.
+	gvk schema.GroupVersionKind
+	// allowedClusters are the cluster namespaces the sources of each namespace may materialize
+	// Works in, any if they include "*". The clusters listed by a source but not allowed are ignored.
+	allowedClusters map[string][]string
+}
+
+// ParseFluxSourceClusters parses the cluster namespaces the Flux sources of each namespace may
+// materialize Works in, a comma separated list of namespace:cluster pairs, where the cluster "*"
+// allows any cluster, e.g. flux-system:*,team-a:cluster1,team-a:cluster2.
+func ParseFluxSourceClusters(list string) (map[string][]string, error) {
+	allowed := map[string][]string{}
+	for _, pair := range splitList(list) {
+		namespace, cluster := "", ""
+		if i := strings.Index(pair, ":"); i >= 0 {
+			namespace, cluster = strings.TrimSpace(pair[:i]), strings.TrimSpace(pair[i+1:])
+		}
+		if namespace == "" || cluster == "" {
+			return nil, fmt.Errorf("invalid flux source clusters %q, expected namespace:cluster", pair)
+		}
+		allowed[namespace] = append(allowed[namespace], cluster)
+	}
+	return allowed, nil
+}
+
+// allowedSourceClusters returns the clusters listed by a source which its namespace may
+// materialize Works in, and those it may not.
+func (r *FluxSourceReconciler) allowedSourceClusters(namespace string, clusters []string) ([]string, []string) {
+	allowed := map[string]bool{}
+	for _, cluster := range r.allowedClusters[namespace] {
+		allowed[cluster] = true
+	}
+	permitted, denied := []string{}, []string{}
+	for _, cluster := range clusters {
+		if allowed[allAllowedClusters] || allowed[cluster] {
+			permitted = append(permitted, cluster)
+		} else {
+			denied = append(denied, cluster)
+		}
+	}
+	return permitted, denied
+}
+
+// fluxArtifact is the latest artifact of a Flux source.
+type fluxArtifact struct {
+	URL      string `json:"url"`
+	Revision string `json:"revision"`
+	// Checksum is the sha256 of the artifact, Digest replaces it in newer Flux versions.
+	Checksum string `json:"checksum,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+}
+
+// Reconcile creates or updates the Works of a Flux source and deletes those of the clusters which
+// are no longer listed.
+func (r *FluxSourceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(r.gvk)
+	err := r.client.Get(ctx, req.NamespacedName, source)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, r.deleteStaleWorks(ctx, r.sourceRef(req.NamespacedName), req.Name, nil)
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	sourceRef := r.sourceRef(req.NamespacedName)
+	clusters := []string{}
+	if source.GetDeletionTimestamp().IsZero() {
+		var denied []string
+		clusters, denied = r.allowedSourceClusters(source.GetNamespace(), splitList(source.GetAnnotations()[workv1alpha1.SourceClustersAnnotation]))
+		if len(denied) != 0 {
+			r.log.Info("ignoring the clusters the namespace of the source may not materialize works in", "source", sourceRef, "clusters", denied)
+		}
+	}
+	if err := r.deleteStaleWorks(ctx, sourceRef, source.GetName(), clusters); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(clusters) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	artifact := &fluxArtifact{}
+	content, found, err := unstructured.NestedMap(source.Object, "status", "artifact")
+	if err != nil || !found {
+		r.log.V(2).Info("source has no artifact yet", "source", sourceRef)
+		return ctrl.Result{}, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, artifact); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	outdated := []string{}
+	for _, cluster := range clusters {
+		existing := &workv1alpha1.Work{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: cluster, Name: source.GetName()}, existing)
+		if err != nil || existing.Annotations[workv1alpha1.SourceRevisionAnnotation] != artifact.Revision {
+			outdated = append(outdated, cluster)
+		}
+	}
+	if len(outdated) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	r.log.Info("materializing source artifact", "source", sourceRef, "revision", artifact.Revision, "clusters", outdated)
+	manifests, err := r.fetchManifests(ctx, artifact, source.GetAnnotations()[workv1alpha1.SourcePathAnnotation])
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to read the artifact of %s: %w", sourceRef, err)
+	}
+	errs := []error{}
+	for _, cluster := range outdated {
+		required := buildSourceWork(source.GetName(), cluster, sourceRef, artifact.Revision, manifests)
+		if err := r.applySourceWork(ctx, required); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return ctrl.Result{}, utilerrors.NewAggregate(errs)
+}
+
+// sourceRef returns the kind/namespace/name of a source, the value of the source annotation.
+func (r *FluxSourceReconciler) sourceRef(key types.NamespacedName) string {
+	return r.gvk.Kind + "/" + key.String()
+}
+
+// fetchManifests downloads the artifact of a source and returns the manifests of the YAML and JSON
+// files under the directory, sorted by path.
+func (r *FluxSourceReconciler) fetchManifests(ctx context.Context, artifact *fluxArtifact, dir string) ([]workv1alpha1.Manifest, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, artifact.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := r.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s downloading %s", response.Status, artifact.URL)
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxArtifactSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxArtifactSize {
+		return nil, fmt.Errorf("artifact %s is larger than %d bytes", artifact.URL, maxArtifactSize)
+	}
+	if err := verifyArtifact(artifact, body); err != nil {
+		return nil, err
+	}
+	return readArtifactManifests(body, dir)
+}
+
+// maxArtifactSize bounds the size of the artifacts downloaded, the manifests of a Work are bounded
+// by DefaultMaxWorkSize anyway.
+const maxArtifactSize = 64 * 1024 * 1024
+
+// verifyArtifact checks the sha256 of an artifact against the one advertised by its source.
+func verifyArtifact(artifact *fluxArtifact, body []byte) error {
+	expected := strings.TrimPrefix(artifact.Digest, "sha256:")
+	if expected == "" {
+		expected = artifact.Checksum
+	}
+	if expected == "" {
+		return nil
+	}
+	sum := sha256.Sum256(body)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("artifact %s has checksum %s, expected %s", artifact.URL, actual, expected)
+	}
+	return nil
+}
+
+// readArtifactManifests returns the manifests of the YAML and JSON files of a gzipped tarball under
+// the directory, sorted by path. A file may hold several YAML documents.
+func readArtifactManifests(artifact []byte, dir string) ([]workv1alpha1.Manifest, error) {
+	gzipReader, err := gzip.NewReader(bytes.NewReader(artifact))
+	if err != nil {
+		return nil, err
+	}
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	files := map[string][]byte{}
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if header.Typeflag != tar.TypeReg || (dir != "" && !strings.HasPrefix(name, dir+"/")) {
+			continue
+		}
+		switch path.Ext(name) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = content
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	manifests := []workv1alpha1.Manifest{}
+	for _, name := range names {
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(files[name]), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			err := decoder.Decode(&obj.Object)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+			}
+			if len(obj.Object) == 0 {
+				continue
+			}
+			raw, err := json.Marshal(obj)
+			if err != nil {
+				return nil, err
+			}
+			manifests = append(manifests, workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+		}
+	}
+	return manifests, nil
+}
+
+// buildSourceWork returns the Work of the manifests of a source in a cluster namespace.
+func buildSourceWork(name, cluster, sourceRef, revision string, manifests []workv1alpha1.Manifest) *workv1alpha1.Work {
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cluster,
+			Labels:    map[string]string{workv1alpha1.SourceLabel: name},
+			Annotations: map[string]string{
+				workv1alpha1.SourceAnnotation:         sourceRef,
+				workv1alpha1.SourceRevisionAnnotation: revision,
+			},
+		},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: manifests}},
+	}
+}
+
+// applySourceWork creates or updates the Work of a source. A Work which was not materialized from
+// the source is never overwritten.
+func (r *FluxSourceReconciler) applySourceWork(ctx context.Context, required *workv1alpha1.Work) error {
+	size := 0
+	for _, manifest := range required.Spec.Workload.Manifests {
+		size += len(manifest.Raw)
+	}
+	if size > DefaultMaxWorkSize {
+		return fmt.Errorf("manifests of %s are %d bytes, larger than %d", required.Annotations[workv1alpha1.SourceAnnotation], size, DefaultMaxWorkSize)
+	}
+
+	existing := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, client.ObjectKeyFromObject(required), existing)
+	switch {
+	case errors.IsNotFound(err):
+		return r.client.Create(ctx, required)
+	case err != nil:
+		return err
+	}
+
+	if existing.Annotations[workv1alpha1.SourceAnnotation] != required.Annotations[workv1alpha1.SourceAnnotation] {
+		return fmt.Errorf("work %s/%s already exists and was not materialized from %s",
+			existing.Namespace, existing.Name, required.Annotations[workv1alpha1.SourceAnnotation])
+	}
+	if equality.Semantic.DeepEqual(existing.Spec.Workload, required.Spec.Workload) &&
+		isSubset(required.Annotations, existing.Annotations) {
+		return nil
+	}
+
+	existing.Spec.Workload = required.Spec.Workload
+	existing.Labels = mergeStringMap(existing.Labels, required.Labels)
+	existing.Annotations = mergeStringMap(existing.Annotations, required.Annotations)
+	return r.client.Update(ctx, existing)
+}
+
+// deleteStaleWorks deletes the Works materialized from a source in the cluster namespaces which
+// are no longer listed.
+func (r *FluxSourceReconciler) deleteStaleWorks(ctx context.Context, sourceRef, name string, clusters []string) error {
+	works := &workv1alpha1.WorkList{}
+	if err := r.client.List(ctx, works, client.MatchingLabels{workv1alpha1.SourceLabel: name}); err != nil {
+		return err
+	}
+	listed := map[string]bool{}
+	for _, cluster := range clusters {
+		listed[cluster] = true
+	}
+
+	errs := []error{}
+	for i := range works.Items {
+		work := &works.Items[i]
+		if work.Annotations[workv1alpha1.SourceAnnotation] != sourceRef || listed[work.Namespace] || !work.DeletionTimestamp.IsZero() {
+			continue
+		}
+		r.log.Info("deleting work of source", "source", sourceRef, "work", client.ObjectKeyFromObject(work))
+		if err := r.client.Delete(ctx, work); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// splitList returns the items of a comma separated list.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// SetupWithManager wires up the controller.
+func (r *FluxSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(r.gvk)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("flux-" + strings.ToLower(r.gvk.Kind)).
+		For(source).
+		Complete(r)
+}
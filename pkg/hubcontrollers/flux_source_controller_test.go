@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newTestArtifact(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFluxSourceReconcile(t *testing.T) {
+	artifact := newTestArtifact(t, map[string]string{
+		"deploy/b.yaml":    "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: test\n",
+		"deploy/a.yaml":    "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n---\n",
+		"deploy/README.md": "not a manifest",
+		"other/c.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: c\n  namespace: test\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(artifact)
+	}))
+	defer server.Close()
+	sum := sha256.Sum256(artifact)
+
+	gvk := FluxSourceKinds[0]
+	scheme := newTestScheme()
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(gvk)
+	source.SetNamespace("flux-system")
+	source.SetName("app")
+	source.SetAnnotations(map[string]string{
+		workv1alpha1.SourceClustersAnnotation: "cluster1, cluster2",
+		workv1alpha1.SourcePathAnnotation:     "./deploy",
+	})
+	if err := unstructured.SetNestedMap(source.Object, map[string]interface{}{
+		"url":      server.URL,
+		"revision": "main/1",
+		"checksum": hex.EncodeToString(sum[:]),
+	}, "status", "artifact"); err != nil {
+		t.Fatal(err)
+	}
+	// a work in cluster2 which was not materialized from the source
+	conflicting := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "cluster2"}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, conflicting).Build()
+	r := &FluxSourceReconciler{
+		client:          fakeClient,
+		httpClient:      server.Client(),
+		log:             ctrl.Log,
+		gvk:             gvk,
+		allowedClusters: map[string][]string{"flux-system": {"cluster1", "cluster2"}},
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(source)}
+
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatalf("expected an error materializing the source in cluster2")
+	}
+	work := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster1", Name: "app"}, work); err != nil {
+		t.Fatalf("expected the source to be materialized in cluster1: %v", err)
+	}
+	if work.Labels[workv1alpha1.SourceLabel] != "app" ||
+		work.Annotations[workv1alpha1.SourceAnnotation] != "GitRepository/flux-system/app" ||
+		work.Annotations[workv1alpha1.SourceRevisionAnnotation] != "main/1" {
+		t.Errorf("unexpected work metadata %v", work.ObjectMeta)
+	}
+	manifests := work.Spec.Workload.Manifests
+	if len(manifests) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(manifests))
+	}
+	if !bytes.Contains(manifests[0].Raw, []byte(`"Namespace"`)) || !bytes.Contains(manifests[1].Raw, []byte(`"ConfigMap"`)) {
+		t.Errorf("expected the manifests in the order of the files, got %s and %s", manifests[0].Raw, manifests[1].Raw)
+	}
+
+	// the work of cluster1 is deleted once the cluster is no longer listed
+	if err := fakeClient.Get(context.Background(), req.NamespacedName, source); err != nil {
+		t.Fatal(err)
+	}
+	source.SetAnnotations(map[string]string{workv1alpha1.SourceClustersAnnotation: "cluster2"})
+	if err := fakeClient.Update(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Delete(context.Background(), conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster1", Name: "app"}, work)
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the work of cluster1 to be deleted, got %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster2", Name: "app"}, work); err != nil {
+		t.Errorf("expected the source to be materialized in cluster2: %v", err)
+	}
+}
+
+func TestFluxSourceReconcileDeniedClusters(t *testing.T) {
+	gvk := FluxSourceKinds[0]
+	scheme := newTestScheme()
+	scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(gvk)
+	source.SetNamespace("team-a")
+	source.SetName("app")
+	source.SetAnnotations(map[string]string{workv1alpha1.SourceClustersAnnotation: "cluster2"})
+	if err := unstructured.SetNestedMap(source.Object, map[string]interface{}{"url": "http://artifacts", "revision": "main/1"}, "status", "artifact"); err != nil {
+		t.Fatal(err)
+	}
+	// a work of the source materialized before its cluster was denied
+	materialized := buildSourceWork("app", "cluster2", "GitRepository/team-a/app", "main/1", nil)
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, materialized).Build()
+	r := &FluxSourceReconciler{
+		client:          fakeClient,
+		log:             ctrl.Log,
+		gvk:             gvk,
+		allowedClusters: map[string][]string{"team-a": {"cluster1"}, "flux-system": {"*"}},
+	}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(source)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster2", Name: "app"}, &workv1alpha1.Work{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected no work in the cluster the namespace may not deploy to, got %v", err)
+	}
+}
+
+func TestParseFluxSourceClusters(t *testing.T) {
+	allowed, err := ParseFluxSourceClusters("flux-system:*, team-a:cluster1,team-a:cluster2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{"flux-system": {"*"}, "team-a": {"cluster1", "cluster2"}}
+	if !reflect.DeepEqual(allowed, expected) {
+		t.Errorf("expected %v, got %v", expected, allowed)
+	}
+	for _, invalid := range []string{"team-a", "team-a:", ":cluster1"} {
+		if _, err := ParseFluxSourceClusters(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestVerifyArtifact(t *testing.T) {
+	if err := verifyArtifact(&fluxArtifact{URL: "url", Checksum: "bad"}, []byte("artifact")); err == nil {
+		t.Error("expected a checksum mismatch to fail")
+	}
+	sum := sha256.Sum256([]byte("artifact"))
+	if err := verifyArtifact(&fluxArtifact{URL: "url", Digest: "sha256:" + hex.EncodeToString(sum[:])}, []byte("artifact")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/work-api/pkg/cloudevents"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+	"sigs.k8s.io/work-api/pkg/webhook"
+)
+
+// Options configures the optional behavior of the hub controllers
+type Options struct {
+	// EnablePlacement lets WorkSets target the clusters decided by a Placement. The
+	// Placement API must be installed on the hub.
+	EnablePlacement bool
+
+	// EnableWorkGC deletes the Works whose TTL elapsed or whose apply failed longer than
+	// FailedWorkRetention ago, and releases the Works left by agents in deleted cluster namespaces.
+	EnableWorkGC bool
+	// FailedWorkRetention is how long a failed Work is kept, failed Works are kept forever if zero.
+	FailedWorkRetention time.Duration
+	// AbandonedWorkGracePeriod is how long the agent has to clean up a Work in a deleted cluster namespace.
+	AbandonedWorkGracePeriod time.Duration
+	// AgentFinalizer is the finalizer the agents add to the Works, multicluster.x-k8s.io/work-cleanup if empty.
+	AgentFinalizer string
+	// ClusterDeletionPolicy is whether the agent deletes or orphans the resources of the Works of a
+	// deleted cluster namespace, it is recorded on the namespaces with Works whether or not
+	// EnableWorkGC is set. The resources are deleted if empty.
+	ClusterDeletionPolicy ClusterDeletionPolicy
+
+	// EnableWebhook serves the admission webhooks validating the Works on the hub.
+	EnableWebhook bool
+	// WebhookCerts provisions self-signed certificates for the webhooks, rotates them and patches
+	// the CA bundle of the webhook configurations, if set. The certificates are read from the cert
+	// dir of the manager otherwise, e.g. issued by cert-manager.
+	WebhookCerts *webhook.CertOptions
+	// WebhookSecretPolicy is whether the webhook allows, warns about or rejects the Works whose
+	// manifests include Secrets with plaintext data, they are allowed if empty.
+	WebhookSecretPolicy webhook.SecretPolicy
+
+	// EnableWorkStateMetrics exports the conditions of every Work as the work_status_condition
+	// and work_manifest_condition gauges on the metrics endpoint of the elected replica.
+	EnableWorkStateMetrics bool
+
+	// EnableWorkSummaries maintains a work-summary ConfigMap in every namespace with Works, counting
+	// how many are applied, available and degraded, for dashboards of the health of the fleet.
+	EnableWorkSummaries bool
+
+	// EnableFluxSources materializes the artifacts of the Flux GitRepositories and OCIRepositories
+	// as Works in the cluster namespaces listed in their source-clusters annotation. The Flux
+	// source API must be installed on the hub.
+	EnableFluxSources bool
+	// FluxSourceClusters are the cluster namespaces the Flux sources of each namespace may
+	// materialize Works in, any if they include "*", see ParseFluxSourceClusters. The sources of
+	// the namespaces which are not listed are not materialized, so the tenants who may create
+	// sources cannot deploy to the clusters of others.
+	FluxSourceClusters map[string][]string
+
+	// CloudEventsTransport publishes the Works of the cluster namespaces labeled with
+	// cloudevents.ClusterLabel to their agents over a broker, and records the status they report.
+	// Works are only read by the agents from the hub API if nil.
+	CloudEventsTransport cloudevents.Transport
+
+	// StatusStreamAddr is the address of the gRPC endpoint the agents stream the status of their
+	// Works to, the endpoint is disabled if empty. The connections are encrypted with
+	// StatusStreamTLS if set. The agents must present a client certificate verified by it, unless
+	// StatusStreamInsecure is set, which lets any client update the status of any Work.
+	StatusStreamAddr     string
+	StatusStreamTLS      *tls.Config
+	StatusStreamInsecure bool
+}
+
+// Start the hub controllers with the supplied config
+func Start(ctx context.Context, hubCfg *rest.Config, setupLog logr.Logger, opts ctrl.Options, hubOpts Options) error {
+	mgr, err := ctrl.NewManager(hubCfg, opts)
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		return err
+	}
+
+	if err = (&WorkSetReconciler{
+		client: mgr.GetClient(),
+		scheme: mgr.GetScheme(),
+		log:    ctrl.Log.WithName("controllers").WithName("WorkSet"),
+
+		watchPlacements: hubOpts.EnablePlacement,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "WorkSet")
+		return err
+	}
+
+	if err = (&WorkPromotionReconciler{
+		client: mgr.GetClient(),
+		log:    ctrl.Log.WithName("controllers").WithName("WorkPromotion"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "WorkPromotion")
+		return err
+	}
+
+	if err = (&ClusterDeletionPolicyReconciler{
+		client: mgr.GetClient(),
+		log:    ctrl.Log.WithName("controllers").WithName("ClusterDeletionPolicy"),
+		policy: hubOpts.ClusterDeletionPolicy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterDeletionPolicy")
+		return err
+	}
+
+	if hubOpts.EnableWorkGC {
+		agentFinalizer := hubOpts.AgentFinalizer
+		if agentFinalizer == "" {
+			agentFinalizer = workFinalizer
+		}
+		if err = (&WorkGCReconciler{
+			client: mgr.GetClient(),
+			log:    ctrl.Log.WithName("controllers").WithName("WorkGC"),
+
+			failedWorkRetention:      hubOpts.FailedWorkRetention,
+			abandonedWorkGracePeriod: hubOpts.AbandonedWorkGracePeriod,
+			agentFinalizer:           agentFinalizer,
+			clusterDeletionPolicy:    hubOpts.ClusterDeletionPolicy,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "WorkGC")
+			return err
+		}
+	}
+
+	if hubOpts.EnableWorkSummaries {
+		if err = (&WorkSummaryReconciler{
+			client: mgr.GetClient(),
+			log:    ctrl.Log.WithName("controllers").WithName("WorkSummary"),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "WorkSummary")
+			return err
+		}
+	}
+
+	if hubOpts.EnableFluxSources {
+		for _, gvk := range FluxSourceKinds {
+			if err = (&FluxSourceReconciler{
+				client:          mgr.GetClient(),
+				httpClient:      &http.Client{Timeout: fluxArtifactTimeout},
+				log:             ctrl.Log.WithName("controllers").WithName(gvk.Kind),
+				gvk:             gvk,
+				allowedClusters: hubOpts.FluxSourceClusters,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", gvk.Kind)
+				return err
+			}
+		}
+	}
+
+	if hubOpts.CloudEventsTransport != nil {
+		if err = cloudevents.SetupHubWithManager(mgr, hubOpts.CloudEventsTransport); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CloudEventsHub")
+			return err
+		}
+	}
+
+	if hubOpts.StatusStreamAddr != "" {
+		server := statusstream.NewServer(mgr.GetClient(), ctrl.Log.WithName("statusstream"), hubOpts.StatusStreamInsecure)
+		if err = mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return server.Serve(ctx, hubOpts.StatusStreamAddr, hubOpts.StatusStreamTLS)
+		})); err != nil {
+			setupLog.Error(err, "unable to serve status streams")
+			return err
+		}
+	}
+
+	if hubOpts.EnableWebhook {
+		if hubOpts.WebhookCerts != nil {
+			if err = webhook.SetupCertsWithManager(ctx, mgr, *hubOpts.WebhookCerts); err != nil {
+				setupLog.Error(err, "unable to provision the webhook certificates")
+				return err
+			}
+		}
+		webhook.SetupWithManager(mgr, hubOpts.WebhookSecretPolicy)
+	}
+
+	if hubOpts.EnableWorkStateMetrics {
+		if err := metrics.Registry.Register(&workStateCollector{
+			client:  mgr.GetClient(),
+			log:     ctrl.Log.WithName("metrics").WithName("WorkState"),
+			elected: mgr.Elected(),
+		}); err != nil {
+			setupLog.Error(err, "unable to register work state metrics")
+			return err
+		}
+	}
+
+	setupLog.Info("starting manager")
+	if err := mgr.Start(ctx); err != nil {
+		setupLog.Error(err, "problem running manager")
+		return err
+	}
+	return nil
+}
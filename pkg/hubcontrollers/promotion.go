@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// BuildPromotedWork returns a copy of the source work in another cluster namespace. The labels
+// and annotations are preserved, except the ones tying the source to a workset or requesting a
+// promotion, and the provenance of the copy is recorded in its annotations.
+func BuildPromotedWork(source *workv1alpha1.Work, cluster string) *workv1alpha1.Work {
+	workLabels := mergeStringMap(nil, source.Labels)
+	delete(workLabels, workv1alpha1.WorkSetLabel)
+
+	workAnnotations := mergeStringMap(nil, source.Annotations)
+	delete(workAnnotations, workv1alpha1.PromoteToAnnotation)
+	delete(workAnnotations, templateHashAnnotation)
+	workAnnotations[workv1alpha1.PromotedFromAnnotation] = source.Namespace + "/" + source.Name
+	workAnnotations[workv1alpha1.PromotedGenerationAnnotation] = strconv.FormatInt(source.Generation, 10)
+
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        source.Name,
+			Namespace:   cluster,
+			Labels:      workLabels,
+			Annotations: workAnnotations,
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+}
+
+// PromoteWork creates or updates a copy of the source work in every cluster namespace. A work
+// in a cluster namespace that was not promoted from the source is never overwritten.
+func PromoteWork(ctx context.Context, c client.Client, source *workv1alpha1.Work, clusters []string) error {
+	errs := []error{}
+	for _, cluster := range clusters {
+		if cluster == source.Namespace {
+			continue
+		}
+		if err := promoteWork(ctx, c, source, cluster); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+func promoteWork(ctx context.Context, c client.Client, source *workv1alpha1.Work, cluster string) error {
+	required := BuildPromotedWork(source, cluster)
+
+	existing := &workv1alpha1.Work{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: cluster, Name: source.Name}, existing)
+	switch {
+	case errors.IsNotFound(err):
+		return c.Create(ctx, required)
+	case err != nil:
+		return err
+	}
+
+	if existing.Annotations[workv1alpha1.PromotedFromAnnotation] != required.Annotations[workv1alpha1.PromotedFromAnnotation] {
+		return fmt.Errorf("work %s/%s already exists and was not promoted from %s/%s", cluster, source.Name, source.Namespace, source.Name)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, required.Spec) &&
+		isSubset(required.Labels, existing.Labels) &&
+		isSubset(required.Annotations, existing.Annotations) {
+		return nil
+	}
+
+	existing.Spec = required.Spec
+	existing.Labels = mergeStringMap(existing.Labels, required.Labels)
+	existing.Annotations = mergeStringMap(existing.Annotations, required.Annotations)
+	return c.Update(ctx, existing)
+}
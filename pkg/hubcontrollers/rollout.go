@@ -0,0 +1,177 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+const (
+	// templateHashAnnotation records the hash of the workset template a work is stamped from.
+	templateHashAnnotation = "multicluster.x-k8s.io/workset-template-hash"
+)
+
+// computeTemplateHash returns the hash of a workset template.
+func computeTemplateHash(template *workv1alpha1.WorkTemplateSpec) (string, error) {
+	jsonBytes, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(jsonBytes)), nil
+}
+
+// clusterRolloutPhase returns the rollout phase of the template on a cluster given the work
+// currently stamped into it, and how long to wait for the soak time to elapse if the work is
+// applied but still soaking.
+func clusterRolloutPhase(
+	work *workv1alpha1.Work,
+	templateHash string,
+	previous workv1alpha1.ClusterRolloutStatus,
+	soakTime time.Duration,
+	now time.Time) (workv1alpha1.RolloutPhase, time.Duration) {
+	if work == nil || work.Annotations[templateHashAnnotation] != templateHash {
+		return workv1alpha1.RolloutPhasePending, 0
+	}
+
+	applied := meta.FindStatusCondition(work.Status.Conditions, conditions.TypeApplied)
+	if applied == nil || applied.ObservedGeneration != work.Generation {
+		return workv1alpha1.RolloutPhaseProgressing, 0
+	}
+	if applied.Status == metav1.ConditionFalse {
+		return workv1alpha1.RolloutPhaseFailed, 0
+	}
+	if applied.Status != metav1.ConditionTrue {
+		return workv1alpha1.RolloutPhaseProgressing, 0
+	}
+
+	// a cluster which succeeded stays succeeded while the same work is applied, the soak time is
+	// not counted again
+	if previous.Phase == workv1alpha1.RolloutPhaseSucceeded {
+		return workv1alpha1.RolloutPhaseSucceeded, 0
+	}
+
+	// the soak time counts from the moment the cluster started progressing
+	progressingSince := now
+	if previous.Phase == workv1alpha1.RolloutPhaseProgressing {
+		progressingSince = previous.LastTransitionTime.Time
+	}
+	if remaining := soakTime - now.Sub(progressingSince); remaining > 0 {
+		return workv1alpha1.RolloutPhaseProgressing, remaining
+	}
+	return workv1alpha1.RolloutPhaseSucceeded, 0
+}
+
+// rolloutBudget returns how many pending clusters may start rolling out now, a negative value
+// means no limit, and whether the rollout is halted because too many clusters failed.
+func rolloutBudget(strategy *workv1alpha1.RolloutStrategy, rollouts []workv1alpha1.ClusterRolloutStatus) (int, bool) {
+	if strategy == nil {
+		return -1, false
+	}
+
+	progressing, failed := 0, 0
+	for _, rollout := range rollouts {
+		switch rollout.Phase {
+		case workv1alpha1.RolloutPhaseProgressing:
+			progressing++
+		case workv1alpha1.RolloutPhaseFailed:
+			failed++
+		}
+	}
+
+	if strategy.FailureThreshold > 0 && failed >= int(strategy.FailureThreshold) {
+		return 0, true
+	}
+	if strategy.MaxConcurrentClusters == 0 {
+		return -1, false
+	}
+	if budget := int(strategy.MaxConcurrentClusters) - progressing; budget > 0 {
+		return budget, false
+	}
+	return 0, false
+}
+
+// soakTimeOf returns the soak time of the rollout strategy of a workset.
+func soakTimeOf(workSet *workv1alpha1.WorkSet) time.Duration {
+	if workSet.Spec.RolloutStrategy == nil || workSet.Spec.RolloutStrategy.SoakTime == nil {
+		return 0
+	}
+	return workSet.Spec.RolloutStrategy.SoakTime.Duration
+}
+
+// setRolloutTransitionTimes keeps the transition time of the clusters whose phase is unchanged
+// and sets it to now for the others.
+func setRolloutTransitionTimes(rollouts, previous []workv1alpha1.ClusterRolloutStatus, now time.Time) {
+	previousByCluster := map[string]workv1alpha1.ClusterRolloutStatus{}
+	for _, rollout := range previous {
+		previousByCluster[rollout.ClusterName] = rollout
+	}
+
+	for i := range rollouts {
+		last, ok := previousByCluster[rollouts[i].ClusterName]
+		if ok && last.Phase == rollouts[i].Phase {
+			rollouts[i].LastTransitionTime = last.LastTransitionTime
+			continue
+		}
+		rollouts[i].LastTransitionTime = metav1.NewTime(now)
+	}
+}
+
+func buildRolloutCondition(rollouts []workv1alpha1.ClusterRolloutStatus, halted bool, observedGeneration int64) metav1.Condition {
+	counts := map[workv1alpha1.RolloutPhase]int{}
+	for _, rollout := range rollouts {
+		counts[rollout.Phase]++
+	}
+
+	if halted {
+		return metav1.Condition{
+			Type:               "RolloutProgressing",
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.RolloutHalted),
+			Message:            fmt.Sprintf("Rollout halted after %d clusters failed", counts[workv1alpha1.RolloutPhaseFailed]),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	if counts[workv1alpha1.RolloutPhasePending]+counts[workv1alpha1.RolloutPhaseProgressing] > 0 {
+		return metav1.Condition{
+			Type:   "RolloutProgressing",
+			Status: metav1.ConditionTrue,
+			Reason: string(reasons.RolloutInProgress),
+			Message: fmt.Sprintf("Rollout in progress: %d pending, %d progressing, %d succeeded, %d failed",
+				counts[workv1alpha1.RolloutPhasePending], counts[workv1alpha1.RolloutPhaseProgressing],
+				counts[workv1alpha1.RolloutPhaseSucceeded], counts[workv1alpha1.RolloutPhaseFailed]),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	return metav1.Condition{
+		Type:               "RolloutProgressing",
+		Status:             metav1.ConditionFalse,
+		Reason:             string(reasons.RolloutComplete),
+		Message:            fmt.Sprintf("Rollout complete: %d succeeded, %d failed", counts[workv1alpha1.RolloutPhaseSucceeded], counts[workv1alpha1.RolloutPhaseFailed]),
+		ObservedGeneration: observedGeneration,
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newAppliedWork(hash string, generation int64, status metav1.ConditionStatus, observedGeneration int64) *workv1alpha1.Work {
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Generation:  generation,
+			Annotations: map[string]string{templateHashAnnotation: hash},
+		},
+		Status: workv1alpha1.WorkStatus{
+			Conditions: []metav1.Condition{{Type: "Applied", Status: status, ObservedGeneration: observedGeneration}},
+		},
+	}
+}
+
+func TestClusterRolloutPhase(t *testing.T) {
+	now := time.Now()
+	progressing := workv1alpha1.ClusterRolloutStatus{
+		Phase:              workv1alpha1.RolloutPhaseProgressing,
+		LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+	}
+
+	cases := []struct {
+		name          string
+		work          *workv1alpha1.Work
+		previous      workv1alpha1.ClusterRolloutStatus
+		soakTime      time.Duration
+		expectedPhase workv1alpha1.RolloutPhase
+		expectedWait  time.Duration
+	}{
+		{
+			name:          "work missing",
+			expectedPhase: workv1alpha1.RolloutPhasePending,
+		},
+		{
+			name:          "work outdated",
+			work:          newAppliedWork("old", 1, metav1.ConditionTrue, 1),
+			expectedPhase: workv1alpha1.RolloutPhasePending,
+		},
+		{
+			name:          "generation not observed yet",
+			work:          newAppliedWork("hash", 2, metav1.ConditionTrue, 1),
+			expectedPhase: workv1alpha1.RolloutPhaseProgressing,
+		},
+		{
+			name:          "apply failed",
+			work:          newAppliedWork("hash", 2, metav1.ConditionFalse, 2),
+			expectedPhase: workv1alpha1.RolloutPhaseFailed,
+		},
+		{
+			name:          "applied without soak time",
+			work:          newAppliedWork("hash", 2, metav1.ConditionTrue, 2),
+			expectedPhase: workv1alpha1.RolloutPhaseSucceeded,
+		},
+		{
+			name:          "applied and still soaking",
+			work:          newAppliedWork("hash", 2, metav1.ConditionTrue, 2),
+			previous:      progressing,
+			soakTime:      3 * time.Minute,
+			expectedPhase: workv1alpha1.RolloutPhaseProgressing,
+			expectedWait:  2 * time.Minute,
+		},
+		{
+			name:          "applied and soaked",
+			work:          newAppliedWork("hash", 2, metav1.ConditionTrue, 2),
+			previous:      progressing,
+			soakTime:      time.Minute,
+			expectedPhase: workv1alpha1.RolloutPhaseSucceeded,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			phase, wait := clusterRolloutPhase(c.work, "hash", c.previous, c.soakTime, now)
+			if phase != c.expectedPhase {
+				t.Errorf("expected phase %s, got %s", c.expectedPhase, phase)
+			}
+			if wait != c.expectedWait {
+				t.Errorf("expected wait %v, got %v", c.expectedWait, wait)
+			}
+		})
+	}
+}
+
+func TestClusterRolloutPhaseStaysSucceeded(t *testing.T) {
+	now := time.Now()
+	work := newAppliedWork("hash", 2, metav1.ConditionTrue, 2)
+	rollouts := []workv1alpha1.ClusterRolloutStatus{{
+		ClusterName:        "cluster1",
+		Phase:              workv1alpha1.RolloutPhaseProgressing,
+		LastTransitionTime: metav1.NewTime(now.Add(-2 * time.Minute)),
+	}}
+
+	// the work soaked on the first reconcile, the next ones must keep the cluster succeeded
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		phase, wait := clusterRolloutPhase(work, "hash", rollouts[0], time.Minute, now)
+		if phase != workv1alpha1.RolloutPhaseSucceeded || wait != 0 {
+			t.Fatalf("reconcile %d: expected the cluster to stay succeeded, got %s, waiting %v", i, phase, wait)
+		}
+		current := []workv1alpha1.ClusterRolloutStatus{{ClusterName: "cluster1", Phase: phase}}
+		setRolloutTransitionTimes(current, rollouts, now)
+		rollouts = current
+	}
+}
+
+func TestRolloutBudget(t *testing.T) {
+	rollouts := []workv1alpha1.ClusterRolloutStatus{
+		{ClusterName: "cluster1", Phase: workv1alpha1.RolloutPhaseProgressing},
+		{ClusterName: "cluster2", Phase: workv1alpha1.RolloutPhaseFailed},
+		{ClusterName: "cluster3", Phase: workv1alpha1.RolloutPhasePending},
+		{ClusterName: "cluster4", Phase: workv1alpha1.RolloutPhasePending},
+	}
+
+	cases := []struct {
+		name           string
+		strategy       *workv1alpha1.RolloutStrategy
+		expectedBudget int
+		expectedHalted bool
+	}{
+		{name: "no strategy", expectedBudget: -1},
+		{name: "no concurrency limit", strategy: &workv1alpha1.RolloutStrategy{}, expectedBudget: -1},
+		{name: "concurrency left", strategy: &workv1alpha1.RolloutStrategy{MaxConcurrentClusters: 3}, expectedBudget: 2},
+		{name: "concurrency exhausted", strategy: &workv1alpha1.RolloutStrategy{MaxConcurrentClusters: 1}, expectedBudget: 0},
+		{name: "failure threshold reached", strategy: &workv1alpha1.RolloutStrategy{FailureThreshold: 1}, expectedBudget: 0, expectedHalted: true},
+		{name: "failure threshold not reached", strategy: &workv1alpha1.RolloutStrategy{FailureThreshold: 2}, expectedBudget: -1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			budget, halted := rolloutBudget(c.strategy, rollouts)
+			if budget != c.expectedBudget || halted != c.expectedHalted {
+				t.Errorf("expected budget %d halted %v, got %d %v", c.expectedBudget, c.expectedHalted, budget, halted)
+			}
+		})
+	}
+}
+
+func TestWorkSetReconcileProgressiveRollout(t *testing.T) {
+	scheme := newTestScheme()
+	workSet := newTestWorkSet()
+	workSet.Spec.RolloutStrategy = &workv1alpha1.RolloutStrategy{MaxConcurrentClusters: 1}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		workSet,
+		newClusterNamespace("cluster1", true),
+		newClusterNamespace("cluster2", true),
+	).Build()
+
+	r := &WorkSetReconciler{client: fakeClient, scheme: scheme, log: ctrl.Log, decider: NewClusterDecider(fakeClient)}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	work := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster1", Name: workSet.Name}, work); err != nil {
+		t.Fatalf("expected work in cluster1: %v", err)
+	}
+	err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster2", Name: workSet.Name}, &workv1alpha1.Work{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected work in cluster2 to wait for cluster1, got %v", err)
+	}
+
+	// the agent reports the work in cluster1 is applied
+	meta.SetStatusCondition(&work.Status.Conditions, metav1.Condition{
+		Type: "Applied", Status: metav1.ConditionTrue, Reason: "AppliedWorkComplete", ObservedGeneration: work.Generation,
+	})
+	if err := fakeClient.Status().Update(context.Background(), work); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster2", Name: workSet.Name}, &workv1alpha1.Work{}); err != nil {
+		t.Fatalf("expected work in cluster2: %v", err)
+	}
+
+	updated := &workv1alpha1.WorkSet{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workSet), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]workv1alpha1.RolloutPhase{
+		"cluster1": workv1alpha1.RolloutPhaseSucceeded,
+		"cluster2": workv1alpha1.RolloutPhaseProgressing,
+	}
+	for _, rollout := range updated.Status.ClusterRollouts {
+		if rollout.Phase != expected[rollout.ClusterName] {
+			t.Errorf("expected phase %s for %s, got %s", expected[rollout.ClusterName], rollout.ClusterName, rollout.Phase)
+		}
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, "RolloutProgressing") {
+		t.Errorf("expected RolloutProgressing condition to be true, got %v", updated.Status.Conditions)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+// DefaultMaxWorkSize is the default size of the manifests of a Work above which it is split, it
+// leaves room below the 1.5MiB object size limit of etcd for the metadata and the status.
+const DefaultMaxWorkSize = 1024 * 1024
+
+// SplitWork splits a Work whose manifests are larger than maxSize bytes into Works of at most
+// maxSize bytes, keeping the order of the manifests. The Works are named after the source with
+// an index suffix and linked by the work group label, the source is returned unchanged if it
+// fits. A single manifest larger than maxSize cannot be split.
+func SplitWork(source *workv1alpha1.Work, maxSize int) ([]*workv1alpha1.Work, error) {
+	groups := [][]workv1alpha1.Manifest{}
+	current := []workv1alpha1.Manifest{}
+	size := 0
+	for index, manifest := range source.Spec.Workload.Manifests {
+		manifestSize := len(manifest.Raw)
+		if manifestSize > maxSize {
+			return nil, fmt.Errorf("manifest %d of work %s/%s is %d bytes, larger than %d", index, source.Namespace, source.Name, manifestSize, maxSize)
+		}
+		if size+manifestSize > maxSize {
+			groups = append(groups, current)
+			current, size = []workv1alpha1.Manifest{}, 0
+		}
+		current = append(current, manifest)
+		size += manifestSize
+	}
+	if len(groups) == 0 {
+		return []*workv1alpha1.Work{source}, nil
+	}
+	groups = append(groups, current)
+
+	works := make([]*workv1alpha1.Work, 0, len(groups))
+	for index, manifests := range groups {
+		work := source.DeepCopy()
+		work.ObjectMeta = metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", source.Name, index),
+			Namespace:   source.Namespace,
+			Labels:      mergeStringMap(source.Labels, map[string]string{workv1alpha1.WorkGroupLabel: source.Name}),
+			Annotations: mergeStringMap(source.Annotations, map[string]string{workv1alpha1.WorkGroupSizeAnnotation: strconv.Itoa(len(groups))}),
+		}
+		work.Spec.Workload.Manifests = manifests
+		work.Status = workv1alpha1.WorkStatus{}
+		works = append(works, work)
+	}
+	return works, nil
+}
+
+// WorkGroupConditions aggregates the Applied and Available conditions of the Works of a group.
+// A condition is true if it is true on every Work of the group, false if it is false on any of
+// them and unknown otherwise, such as while some Works of the group are missing.
+func WorkGroupConditions(works []workv1alpha1.Work, groupSize int) []metav1.Condition {
+	groupConditions := []metav1.Condition{}
+	for _, conditionType := range []string{conditions.TypeApplied, conditions.TypeAvailable} {
+		trueCount, falseCount := 0, 0
+		for i := range works {
+			switch {
+			case meta.IsStatusConditionTrue(works[i].Status.Conditions, conditionType):
+				trueCount++
+			case meta.IsStatusConditionFalse(works[i].Status.Conditions, conditionType):
+				falseCount++
+			}
+		}
+
+		condition := metav1.Condition{
+			Type:    conditionType,
+			Status:  metav1.ConditionUnknown,
+			Reason:  string(reasons.WorkGroupIncomplete),
+			Message: fmt.Sprintf("%s is true on %d of %d works", conditionType, trueCount, groupSize),
+		}
+		switch {
+		case falseCount > 0:
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = string(reasons.WorkGroupFailed)
+		case trueCount == groupSize:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = string(reasons.WorkGroupComplete)
+		}
+		groupConditions = append(groupConditions, condition)
+	}
+	return groupConditions
+}
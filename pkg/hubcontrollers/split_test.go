@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newSizedManifest(size int) workv1alpha1.Manifest {
+	return workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Raw: []byte(strings.Repeat("x", size))}}
+}
+
+func TestSplitWork(t *testing.T) {
+	source := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Labels: map[string]string{"app": "test"}},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+			newSizedManifest(40), newSizedManifest(40), newSizedManifest(40),
+		}}},
+	}
+
+	works, err := SplitWork(source, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 1 || works[0] != source {
+		t.Errorf("expected the work to be kept whole, got %d works", len(works))
+	}
+
+	works, err = SplitWork(source, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 2 {
+		t.Fatalf("expected 2 works, got %d", len(works))
+	}
+	for i, expected := range []struct {
+		name      string
+		manifests int
+	}{{name: "work-0", manifests: 2}, {name: "work-1", manifests: 1}} {
+		work := works[i]
+		if work.Name != expected.name || len(work.Spec.Workload.Manifests) != expected.manifests {
+			t.Errorf("expected %s with %d manifests, got %s with %d", expected.name, expected.manifests, work.Name, len(work.Spec.Workload.Manifests))
+		}
+		if work.Labels[workv1alpha1.WorkGroupLabel] != "work" || work.Labels["app"] != "test" {
+			t.Errorf("unexpected labels %v", work.Labels)
+		}
+		if work.Annotations[workv1alpha1.WorkGroupSizeAnnotation] != "2" {
+			t.Errorf("unexpected annotations %v", work.Annotations)
+		}
+	}
+
+	if _, err := SplitWork(source, 30); err == nil {
+		t.Errorf("expected an error for a manifest larger than the max size")
+	}
+}
+
+func TestWorkGroupConditions(t *testing.T) {
+	newGroupWork := func(applied metav1.ConditionStatus) workv1alpha1.Work {
+		return workv1alpha1.Work{Status: workv1alpha1.WorkStatus{Conditions: []metav1.Condition{{Type: "Applied", Status: applied}}}}
+	}
+
+	cases := []struct {
+		name           string
+		works          []workv1alpha1.Work
+		expectedStatus metav1.ConditionStatus
+	}{
+		{name: "all applied", works: []workv1alpha1.Work{newGroupWork(metav1.ConditionTrue), newGroupWork(metav1.ConditionTrue)}, expectedStatus: metav1.ConditionTrue},
+		{name: "one failed", works: []workv1alpha1.Work{newGroupWork(metav1.ConditionTrue), newGroupWork(metav1.ConditionFalse)}, expectedStatus: metav1.ConditionFalse},
+		{name: "one missing", works: []workv1alpha1.Work{newGroupWork(metav1.ConditionTrue)}, expectedStatus: metav1.ConditionUnknown},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conditions := WorkGroupConditions(c.works, 2)
+			applied := meta.FindStatusCondition(conditions, "Applied")
+			if applied == nil || applied.Status != c.expectedStatus {
+				t.Errorf("expected Applied %s, got %v", c.expectedStatus, applied)
+			}
+		})
+	}
+}
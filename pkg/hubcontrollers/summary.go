@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+// countedConditionTypes are the work conditions always counted in the workset status.
+var countedConditionTypes = []string{conditions.TypeApplied, conditions.TypeAvailable, conditions.TypeDegraded}
+
+// reservedConditionTypes are the workset conditions set by the controller itself, which a summary
+// would overwrite.
+var reservedConditionTypes = []string{"Synced", "RolloutProgressing"}
+
+var defaultConditionSummaries = []workv1alpha1.ConditionSummaryPolicy{
+	{Type: conditions.TypeApplied, Policy: workv1alpha1.SummaryPolicyAll},
+}
+
+// countConditions counts the status of the counted and summarized conditions across the works
+// stamped into the selected clusters. A nil work counts as unknown.
+func countConditions(works []*workv1alpha1.Work, summaries []workv1alpha1.ConditionSummaryPolicy) []workv1alpha1.ConditionCount {
+	conditionTypes := append([]string{}, countedConditionTypes...)
+	for _, summary := range summaries {
+		if !containsString(conditionTypes, summary.Type) {
+			conditionTypes = append(conditionTypes, summary.Type)
+		}
+	}
+
+	counts := make([]workv1alpha1.ConditionCount, 0, len(conditionTypes))
+	for _, conditionType := range conditionTypes {
+		count := workv1alpha1.ConditionCount{Type: conditionType}
+		for _, work := range works {
+			var condition *metav1.Condition
+			if work != nil {
+				condition = meta.FindStatusCondition(work.Status.Conditions, conditionType)
+			}
+			switch {
+			case condition == nil || condition.ObservedGeneration != work.Generation:
+				count.Unknown++
+			case condition.Status == metav1.ConditionTrue:
+				count.True++
+			case condition.Status == metav1.ConditionFalse:
+				count.False++
+			default:
+				count.Unknown++
+			}
+		}
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+// buildSummaryConditions returns a workset condition for every summary policy.
+func buildSummaryConditions(
+	summaries []workv1alpha1.ConditionSummaryPolicy,
+	counts []workv1alpha1.ConditionCount,
+	observedGeneration int64) []metav1.Condition {
+	countByType := map[string]workv1alpha1.ConditionCount{}
+	for _, count := range counts {
+		countByType[count.Type] = count
+	}
+
+	conditions := make([]metav1.Condition, 0, len(summaries))
+	for _, summary := range summaries {
+		count := countByType[summary.Type]
+		total := count.True + count.False + count.Unknown
+
+		var satisfied bool
+		switch summary.Policy {
+		case workv1alpha1.SummaryPolicyAny:
+			satisfied = count.True > 0
+		case workv1alpha1.SummaryPolicyPercentage:
+			satisfied = total > 0 && count.True*100 >= summary.Percentage*total
+		default:
+			satisfied = total > 0 && count.True == total
+		}
+
+		condition := metav1.Condition{
+			Type:               summary.Type,
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.PolicyNotSatisfied(summary.Policy)),
+			Message:            fmt.Sprintf("%s is true on %d of %d clusters", summary.Type, count.True, total),
+			ObservedGeneration: observedGeneration,
+		}
+		if satisfied {
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = string(reasons.PolicySatisfied(summary.Policy))
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions
+}
+
+// conditionSummariesOf returns the summary policies of a workset.
+func conditionSummariesOf(workSet *workv1alpha1.WorkSet) []workv1alpha1.ConditionSummaryPolicy {
+	if len(workSet.Spec.ConditionSummaries) == 0 {
+		return defaultConditionSummaries
+	}
+	return workSet.Spec.ConditionSummaries
+}
+
+// validateConditionSummaries returns an error listing the summary policies of the reserved types.
+func validateConditionSummaries(summaries []workv1alpha1.ConditionSummaryPolicy) error {
+	reserved := []string{}
+	for _, summary := range summaries {
+		if containsString(reservedConditionTypes, summary.Type) {
+			reserved = append(reserved, summary.Type)
+		}
+	}
+	if len(reserved) != 0 {
+		return fmt.Errorf("the condition types %s are reserved for the workset and cannot be summarized", strings.Join(reserved, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestConditionSummaries(t *testing.T) {
+	works := []*workv1alpha1.Work{
+		newAppliedWork("hash", 1, metav1.ConditionTrue, 1),
+		newAppliedWork("hash", 1, metav1.ConditionTrue, 1),
+		newAppliedWork("hash", 1, metav1.ConditionFalse, 1),
+		// the applied condition is stale
+		newAppliedWork("hash", 2, metav1.ConditionTrue, 1),
+		nil,
+	}
+
+	summaries := []workv1alpha1.ConditionSummaryPolicy{{Type: "Applied", Policy: workv1alpha1.SummaryPolicyAll}}
+	counts := countConditions(works, summaries)
+	if len(counts) != 3 {
+		t.Fatalf("expected Applied, Available and Degraded to be counted, got %v", counts)
+	}
+	expected := workv1alpha1.ConditionCount{Type: "Applied", True: 2, False: 1, Unknown: 2}
+	if counts[0] != expected {
+		t.Errorf("expected %v, got %v", expected, counts[0])
+	}
+
+	cases := []struct {
+		name     string
+		summary  workv1alpha1.ConditionSummaryPolicy
+		expected metav1.ConditionStatus
+	}{
+		{name: "all", summary: workv1alpha1.ConditionSummaryPolicy{Type: "Applied", Policy: workv1alpha1.SummaryPolicyAll}, expected: metav1.ConditionFalse},
+		{name: "any", summary: workv1alpha1.ConditionSummaryPolicy{Type: "Applied", Policy: workv1alpha1.SummaryPolicyAny}, expected: metav1.ConditionTrue},
+		{name: "percentage satisfied", summary: workv1alpha1.ConditionSummaryPolicy{Type: "Applied", Policy: workv1alpha1.SummaryPolicyPercentage, Percentage: 40}, expected: metav1.ConditionTrue},
+		{name: "percentage not satisfied", summary: workv1alpha1.ConditionSummaryPolicy{Type: "Applied", Policy: workv1alpha1.SummaryPolicyPercentage, Percentage: 50}, expected: metav1.ConditionFalse},
+		{name: "any on a condition nobody reports", summary: workv1alpha1.ConditionSummaryPolicy{Type: "Available", Policy: workv1alpha1.SummaryPolicyAny}, expected: metav1.ConditionFalse},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			conditions := buildSummaryConditions([]workv1alpha1.ConditionSummaryPolicy{c.summary}, counts, 1)
+			if len(conditions) != 1 || conditions[0].Type != c.summary.Type {
+				t.Fatalf("expected a %s condition, got %v", c.summary.Type, conditions)
+			}
+			if conditions[0].Status != c.expected {
+				t.Errorf("expected status %s, got %s", c.expected, conditions[0].Status)
+			}
+		})
+	}
+}
+
+func TestValidateConditionSummaries(t *testing.T) {
+	valid := []workv1alpha1.ConditionSummaryPolicy{{Type: "Available", Policy: workv1alpha1.SummaryPolicyAny}}
+	if err := validateConditionSummaries(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, conditionType := range []string{"Synced", "RolloutProgressing"} {
+		reserved := append(valid, workv1alpha1.ConditionSummaryPolicy{Type: conditionType, Policy: workv1alpha1.SummaryPolicyAll})
+		if err := validateConditionSummaries(reserved); err == nil {
+			t.Errorf("expected the summary of %s to be rejected", conditionType)
+		}
+	}
+}
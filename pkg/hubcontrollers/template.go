@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// clusterNameValue is always set to the name of the cluster namespace the template is rendered for.
+const clusterNameValue = "clusterName"
+
+// valueReference matches a ${name} reference to a value in a workset template.
+var valueReference = regexp.MustCompile(`\$\{([A-Za-z0-9_.-]+)\}`)
+
+// valuesForCluster returns the values substituted in the template of a workset for a cluster.
+func valuesForCluster(workSet *workv1alpha1.WorkSet, cluster string) map[string]string {
+	values := mergeStringMap(nil, workSet.Spec.Values)
+	for _, clusterValues := range workSet.Spec.ClusterValues {
+		if clusterValues.ClusterName == cluster {
+			values = mergeStringMap(values, clusterValues.Values)
+		}
+	}
+	values[clusterNameValue] = cluster
+	return values
+}
+
+// renderTemplate returns a copy of the template with the value references substituted.
+func renderTemplate(template *workv1alpha1.WorkTemplateSpec, values map[string]string) (*workv1alpha1.WorkTemplateSpec, error) {
+	rendered := template.DeepCopy()
+
+	for k, v := range rendered.Labels {
+		rendered.Labels[k] = substituteValues(v, values, false)
+	}
+	for k, v := range rendered.Annotations {
+		rendered.Annotations[k] = substituteValues(v, values, false)
+	}
+
+	for index := range rendered.Spec.Workload.Manifests {
+		manifest := &rendered.Spec.Workload.Manifests[index]
+		raw := manifest.Raw
+		if raw == nil && manifest.Object != nil {
+			var err error
+			if raw, err = json.Marshal(manifest.Object); err != nil {
+				return nil, fmt.Errorf("failed to encode manifest %d: %w", index, err)
+			}
+		}
+
+		substituted := []byte(substituteValues(string(raw), values, true))
+		if !json.Valid(substituted) {
+			return nil, fmt.Errorf("manifest %d is not valid JSON after substituting values", index)
+		}
+		manifest.Raw = substituted
+		manifest.Object = nil
+	}
+
+	return rendered, nil
+}
+
+// substituteValues replaces the references to defined values in s. Values are escaped for use
+// inside a JSON string if escapeJSON is true.
+func substituteValues(s string, values map[string]string, escapeJSON bool) string {
+	return valueReference.ReplaceAllStringFunc(s, func(reference string) string {
+		value, ok := values[valueReference.FindStringSubmatch(reference)[1]]
+		if !ok {
+			return reference
+		}
+		if !escapeJSON {
+			return value
+		}
+		quoted, _ := json.Marshal(value)
+		return string(quoted[1 : len(quoted)-1])
+	})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestBuildWorkFromTemplate(t *testing.T) {
+	workSet := newTestWorkSet()
+	workSet.Spec.Template.Labels = map[string]string{"region": "${region}"}
+	workSet.Spec.Template.Spec.Workload.Manifests = []workv1alpha1.Manifest{
+		{RawExtension: runtime.RawExtension{Raw: []byte(
+			`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"},` +
+				`"data":{"cluster":"${clusterName}","region":"${region}","message":"${message}","undefined":"${undefined}"}}`,
+		)}},
+	}
+	workSet.Spec.Values = map[string]string{"region": "us-east", "message": `say "hi"`}
+	workSet.Spec.ClusterValues = []workv1alpha1.ClusterValues{
+		{ClusterName: "cluster2", Values: map[string]string{"region": "eu-west"}},
+	}
+
+	cases := []struct {
+		cluster          string
+		expectedRegion   string
+		expectedManifest string
+	}{
+		{
+			cluster:        "cluster1",
+			expectedRegion: "us-east",
+			expectedManifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"},` +
+				`"data":{"cluster":"cluster1","region":"us-east","message":"say \"hi\"","undefined":"${undefined}"}}`,
+		},
+		{
+			cluster:        "cluster2",
+			expectedRegion: "eu-west",
+			expectedManifest: `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"},` +
+				`"data":{"cluster":"cluster2","region":"eu-west","message":"say \"hi\"","undefined":"${undefined}"}}`,
+		},
+	}
+
+	hashes := map[string]bool{}
+	for _, c := range cases {
+		t.Run(c.cluster, func(t *testing.T) {
+			work, err := buildWorkFromTemplate(workSet, c.cluster)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if work.Labels["region"] != c.expectedRegion {
+				t.Errorf("expected region label %q, got %q", c.expectedRegion, work.Labels["region"])
+			}
+			if manifest := string(work.Spec.Workload.Manifests[0].Raw); manifest != c.expectedManifest {
+				t.Errorf("expected manifest %s, got %s", c.expectedManifest, manifest)
+			}
+			hashes[work.Annotations[templateHashAnnotation]] = true
+		})
+	}
+	if len(hashes) != len(cases) {
+		t.Errorf("expected a different template hash per cluster, got %v", hashes)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/finalizers"
+)
+
+const (
+	// workFinalizer is the default finalizer added to works by the agent.
+	workFinalizer = "multicluster.x-k8s.io/work-cleanup"
+)
+
+// WorkGCReconciler deletes the works on the hub that are no longer needed
+type WorkGCReconciler struct {
+	client client.Client
+	log    logr.Logger
+	// failedWorkRetention is how long a work whose apply failed is kept, failed works are kept forever if zero.
+	failedWorkRetention time.Duration
+	// abandonedWorkGracePeriod is how long the agent has to clean up a work deleted along with its cluster namespace.
+	abandonedWorkGracePeriod time.Duration
+	// agentFinalizer is the finalizer added to works by the agents.
+	agentFinalizer string
+	// clusterDeletionPolicy is what the agent does with the resources of the works of a deleted
+	// cluster namespace, Cascade if empty.
+	clusterDeletionPolicy ClusterDeletionPolicy
+}
+
+// Reconcile deletes a work whose TTL elapsed or whose apply failed longer than the retention
+// period ago, and releases a work left in a terminating cluster namespace by an agent that is gone.
+func (r *WorkGCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, req.NamespacedName, work)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	if !work.DeletionTimestamp.IsZero() {
+		return r.releaseAbandonedWork(ctx, work, now)
+	}
+
+	if err := r.recordAppliedGenerationTime(ctx, work, now); err != nil {
+		return ctrl.Result{}, err
+	}
+	expireAt, reason := workExpiry(work, r.failedWorkRetention)
+	if expireAt.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	if remaining := expireAt.Sub(now); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	r.log.Info("deleting expired work", "work", req.NamespacedName, "reason", reason)
+	if err := r.client.Delete(ctx, work); err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// recordAppliedGenerationTime records when the current generation of a work with a TTL was first
+// seen applied, the Applied condition does not transition when a new generation is applied.
+func (r *WorkGCReconciler) recordAppliedGenerationTime(ctx context.Context, work *workv1alpha1.Work, now time.Time) error {
+	if work.Spec.TTLSecondsAfterApplied == nil || !isAppliedAtGeneration(work) {
+		return nil
+	}
+	if generation, _, ok := appliedGenerationTime(work); ok && generation == work.Generation {
+		return nil
+	}
+
+	original := work.DeepCopy()
+	metav1.SetMetaDataAnnotation(&work.ObjectMeta, workv1alpha1.AppliedGenerationTimeAnnotation,
+		fmt.Sprintf("%d@%s", work.Generation, now.UTC().Format(time.RFC3339)))
+	return r.client.Patch(ctx, work, client.MergeFrom(original))
+}
+
+// appliedGenerationTime returns the generation and the time recorded by recordAppliedGenerationTime.
+func appliedGenerationTime(work *workv1alpha1.Work) (int64, time.Time, bool) {
+	value, ok := work.Annotations[workv1alpha1.AppliedGenerationTimeAnnotation]
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	parts := strings.SplitN(value, "@", 2)
+	if len(parts) != 2 {
+		return 0, time.Time{}, false
+	}
+	generation, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	appliedAt, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return generation, appliedAt, true
+}
+
+// isAppliedAtGeneration returns whether the current generation of a work is applied.
+func isAppliedAtGeneration(work *workv1alpha1.Work) bool {
+	applied := meta.FindStatusCondition(work.Status.Conditions, conditions.TypeApplied)
+	return applied != nil && applied.ObservedGeneration == work.Generation && applied.Status == metav1.ConditionTrue
+}
+
+// releaseAbandonedWork removes the agent finalizer of a work being deleted with its cluster
+// namespace once the grace period elapsed, so the namespace is not stuck terminating when the
+// agent of the cluster is gone. With the Orphan cluster deletion policy, the work is first
+// annotated for the agent to leave its resources on the cluster.
+func (r *WorkGCReconciler) releaseAbandonedWork(ctx context.Context, work *workv1alpha1.Work, now time.Time) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(work, r.agentFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	ns := &corev1.Namespace{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: work.Namespace}, ns)
+	switch {
+	case errors.IsNotFound(err):
+	case err != nil:
+		return ctrl.Result{}, err
+	case ns.DeletionTimestamp.IsZero():
+		return ctrl.Result{}, nil
+	}
+
+	if r.clusterDeletionPolicy == ClusterDeletionPolicyOrphan && work.Annotations[workv1alpha1.OrphanAnnotation] != "true" {
+		r.log.Info("orphaning the resources of the work of a deleted cluster namespace", "work", client.ObjectKeyFromObject(work))
+		original := work.DeepCopy()
+		if work.Annotations == nil {
+			work.Annotations = map[string]string{}
+		}
+		work.Annotations[workv1alpha1.OrphanAnnotation] = "true"
+		if err := r.client.Patch(ctx, work, client.MergeFrom(original)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if remaining := r.abandonedWorkGracePeriod - now.Sub(work.DeletionTimestamp.Time); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	r.log.Info("releasing abandoned work", "work", client.ObjectKeyFromObject(work))
+	return ctrl.Result{}, finalizers.Remove(ctx, r.client, work, r.agentFinalizer)
+}
+
+// workExpiry returns when a work expires and why, the time is zero if the work never expires. The
+// works stamped by a workset never expire, it would stamp them again right away. The TTL is counted
+// from the time recorded for the current generation, the work does not expire until it is recorded.
+func workExpiry(work *workv1alpha1.Work, failedWorkRetention time.Duration) (time.Time, string) {
+	if owner := metav1.GetControllerOf(work); owner != nil && owner.Kind == workv1alpha1.WorkSetKind &&
+		owner.APIVersion == workv1alpha1.GroupVersion.String() {
+		return time.Time{}, ""
+	}
+	applied := meta.FindStatusCondition(work.Status.Conditions, conditions.TypeApplied)
+	if applied == nil || applied.ObservedGeneration != work.Generation {
+		return time.Time{}, ""
+	}
+
+	switch {
+	case applied.Status == metav1.ConditionTrue && work.Spec.TTLSecondsAfterApplied != nil:
+		generation, appliedAt, ok := appliedGenerationTime(work)
+		if !ok || generation != work.Generation {
+			return time.Time{}, ""
+		}
+		ttl := time.Duration(*work.Spec.TTLSecondsAfterApplied) * time.Second
+		return appliedAt.Add(ttl), "TTLExpired"
+	case applied.Status == metav1.ConditionFalse && failedWorkRetention > 0:
+		return applied.LastTransitionTime.Add(failedWorkRetention), "FailedRetentionExpired"
+	}
+	return time.Time{}, ""
+}
+
+// SetupWithManager wires up the controller.
+func (r *WorkGCReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("work-gc").
+		For(&workv1alpha1.Work{}).
+		Complete(r)
+}
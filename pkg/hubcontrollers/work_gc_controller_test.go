@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newGCWork(name string, status metav1.ConditionStatus, transitionTime time.Time, ttlSeconds *int64) *workv1alpha1.Work {
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cluster1", Generation: 1},
+		Spec:       workv1alpha1.WorkSpec{TTLSecondsAfterApplied: ttlSeconds},
+		Status: workv1alpha1.WorkStatus{
+			Conditions: []metav1.Condition{{
+				Type:               "Applied",
+				Status:             status,
+				ObservedGeneration: 1,
+				LastTransitionTime: metav1.NewTime(transitionTime),
+			}},
+		},
+	}
+}
+
+// withAppliedGenerationTime records when a generation of a work was applied.
+func withAppliedGenerationTime(work *workv1alpha1.Work, generation int64, appliedAt time.Time) *workv1alpha1.Work {
+	metav1.SetMetaDataAnnotation(&work.ObjectMeta, workv1alpha1.AppliedGenerationTimeAnnotation,
+		fmt.Sprintf("%d@%s", generation, appliedAt.UTC().Format(time.RFC3339)))
+	return work
+}
+
+func TestWorkGCReconcile(t *testing.T) {
+	now := time.Now()
+	ttl := int64(60)
+
+	cases := []struct {
+		name            string
+		work            *workv1alpha1.Work
+		expectedDeleted bool
+		expectedRequeue bool
+	}{
+		{
+			name: "applied without ttl",
+			work: newGCWork("work", metav1.ConditionTrue, now.Add(-time.Hour), nil),
+		},
+		{
+			name:            "ttl elapsed",
+			work:            withAppliedGenerationTime(newGCWork("work", metav1.ConditionTrue, now.Add(-2*time.Minute), &ttl), 1, now.Add(-2*time.Minute)),
+			expectedDeleted: true,
+		},
+		{
+			name:            "ttl not elapsed",
+			work:            withAppliedGenerationTime(newGCWork("work", metav1.ConditionTrue, now, &ttl), 1, now),
+			expectedRequeue: true,
+		},
+		{
+			name:            "ttl counted from when the hub first saw the work applied",
+			work:            newGCWork("work", metav1.ConditionTrue, now.Add(-2*time.Minute), &ttl),
+			expectedRequeue: true,
+		},
+		{
+			name: "ttl elapsed on a previous generation",
+			work: func() *workv1alpha1.Work {
+				// the Applied condition stayed true while the new generation was applied
+				work := withAppliedGenerationTime(newGCWork("work", metav1.ConditionTrue, now.Add(-time.Hour), &ttl), 1, now.Add(-time.Hour))
+				work.Generation = 2
+				work.Status.Conditions[0].ObservedGeneration = 2
+				return work
+			}(),
+			expectedRequeue: true,
+		},
+		{
+			name: "ttl elapsed on a work stamped by a workset",
+			work: func() *workv1alpha1.Work {
+				work := withAppliedGenerationTime(newGCWork("work", metav1.ConditionTrue, now.Add(-2*time.Minute), &ttl), 1, now.Add(-2*time.Minute))
+				isController := true
+				work.OwnerReferences = []metav1.OwnerReference{{
+					APIVersion: workv1alpha1.GroupVersion.String(),
+					Kind:       workv1alpha1.WorkSetKind,
+					Name:       "workset",
+					UID:        "workset-uid",
+					Controller: &isController,
+				}}
+				return work
+			}(),
+		},
+		{
+			name:            "failed beyond retention",
+			work:            newGCWork("work", metav1.ConditionFalse, now.Add(-2*time.Hour), nil),
+			expectedDeleted: true,
+		},
+		{
+			name:            "failed within retention",
+			work:            newGCWork("work", metav1.ConditionFalse, now.Add(-time.Minute), nil),
+			expectedRequeue: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(c.work).Build()
+			r := &WorkGCReconciler{client: fakeClient, log: ctrl.Log, failedWorkRetention: time.Hour}
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(c.work)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != c.expectedRequeue {
+				t.Errorf("expected requeue %v, got %v", c.expectedRequeue, result)
+			}
+
+			updated := &workv1alpha1.Work{}
+			err = fakeClient.Get(context.Background(), client.ObjectKeyFromObject(c.work), updated)
+			if deleted := errors.IsNotFound(err); deleted != c.expectedDeleted {
+				t.Errorf("expected deleted %v, got %v", c.expectedDeleted, err)
+			}
+			// the time the current generation was applied is recorded for the works with a TTL
+			if generation, _, ok := appliedGenerationTime(updated); err == nil && updated.Spec.TTLSecondsAfterApplied != nil &&
+				(!ok || generation != updated.Generation) {
+				t.Errorf("expected the applied time of generation %d to be recorded, got %q", updated.Generation,
+					updated.Annotations[workv1alpha1.AppliedGenerationTimeAnnotation])
+			}
+		})
+	}
+}
+
+func TestWorkGCReleaseAbandonedWork(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	ns := newClusterNamespace("cluster1", true)
+	ns.DeletionTimestamp = &deletedAt
+	ns.Finalizers = []string{"kubernetes"}
+
+	work := newGCWork("work", metav1.ConditionTrue, deletedAt.Time, nil)
+	work.DeletionTimestamp = &deletedAt
+	work.Finalizers = []string{workFinalizer}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(ns, work).Build()
+	r := &WorkGCReconciler{client: fakeClient, log: ctrl.Log, abandonedWorkGracePeriod: time.Hour, agentFinalizer: workFinalizer}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(work)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the work is gone once the finalizer is removed
+	err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(work), &workv1alpha1.Work{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected the abandoned work to be released, got %v", err)
+	}
+
+	// a work being deleted in a live cluster namespace is left to the agent
+	work.Namespace = "cluster2"
+	fakeClient = fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(newClusterNamespace("cluster2", true), work).Build()
+	r.client = fakeClient
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(work)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(work), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Finalizers) != 1 {
+		t.Errorf("expected the finalizer to be kept, got %v", updated.Finalizers)
+	}
+}
+
+func TestWorkGCOrphanWorkOfDeletedCluster(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Now().Add(-time.Minute))
+	ns := newClusterNamespace("cluster1", true)
+	ns.DeletionTimestamp = &deletedAt
+	ns.Finalizers = []string{"kubernetes"}
+
+	work := newGCWork("work", metav1.ConditionTrue, deletedAt.Time, nil)
+	work.DeletionTimestamp = &deletedAt
+	work.Finalizers = []string{workFinalizer}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(ns, work).Build()
+	r := &WorkGCReconciler{
+		client:                   fakeClient,
+		log:                      ctrl.Log,
+		abandonedWorkGracePeriod: time.Hour,
+		agentFinalizer:           workFinalizer,
+		clusterDeletionPolicy:    ClusterDeletionPolicyOrphan,
+	}
+	result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(work)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.RequeueAfter <= 0 {
+		t.Errorf("expected the work to be released after the grace period, got %+v", result)
+	}
+
+	updated := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(work), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Annotations[workv1alpha1.OrphanAnnotation] != "true" {
+		t.Errorf("expected the work to be annotated for the agent to orphan its resources, got %v", updated.Annotations)
+	}
+	if len(updated.Finalizers) != 1 {
+		t.Errorf("expected the finalizer to be kept for the agent, got %v", updated.Finalizers)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+var (
+	workStatusConditionDesc = prometheus.NewDesc(
+		"work_status_condition",
+		"The condition of a work, one series per condition status with the value 1 for the current status.",
+		[]string{"namespace", "work", "type", "status"}, nil,
+	)
+	workManifestConditionDesc = prometheus.NewDesc(
+		"work_manifest_condition",
+		"The condition of a manifest of a work, one series per condition status with the value 1 for the current status.",
+		[]string{"namespace", "work", "ordinal", "group", "kind", "resource_namespace", "resource_name", "type", "status"}, nil,
+	)
+
+	conditionStatuses = []metav1.ConditionStatus{metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionUnknown}
+)
+
+// workStateCollector exports the conditions of the Works on the hub as gauges in the style of
+// kube-state-metrics, so standard alerting rules can be written on them. The Works are read
+// from the cache of the manager when the metrics are scraped. Only the elected replica of the
+// hub controller exports them, so each series is exported once whatever the number of replicas.
+type workStateCollector struct {
+	client client.Reader
+	log    logr.Logger
+	// elected is closed once the replica is the leader, the gauges are exported from then on.
+	elected <-chan struct{}
+}
+
+// Describe implements prometheus.Collector.
+func (c *workStateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- workStatusConditionDesc
+	ch <- workManifestConditionDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *workStateCollector) Collect(ch chan<- prometheus.Metric) {
+	select {
+	case <-c.elected:
+	default:
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	works := &workv1alpha1.WorkList{}
+	if err := c.client.List(ctx, works); err != nil {
+		c.log.Error(err, "failed to list works")
+		return
+	}
+
+	for _, work := range works.Items {
+		for _, condition := range work.Status.Conditions {
+			for _, status := range conditionStatuses {
+				ch <- prometheus.MustNewConstMetric(workStatusConditionDesc, prometheus.GaugeValue, boolValue(condition.Status == status),
+					work.Namespace, work.Name, condition.Type, strings.ToLower(string(status)))
+			}
+		}
+		for _, manifestCondition := range work.Status.ManifestConditions {
+			identifier := manifestCondition.Identifier
+			for _, condition := range manifestCondition.Conditions {
+				for _, status := range conditionStatuses {
+					ch <- prometheus.MustNewConstMetric(workManifestConditionDesc, prometheus.GaugeValue, boolValue(condition.Status == status),
+						work.Namespace, work.Name, strconv.Itoa(identifier.Ordinal), identifier.Group, identifier.Kind,
+						identifier.Namespace, identifier.Name, condition.Type, strings.ToLower(string(status)))
+				}
+			}
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
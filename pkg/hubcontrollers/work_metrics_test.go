@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestWorkStateCollector(t *testing.T) {
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1"},
+		Status: workv1alpha1.WorkStatus{
+			Conditions: []metav1.Condition{{Type: "Applied", Status: metav1.ConditionFalse}},
+			ManifestConditions: []workv1alpha1.ManifestCondition{{
+				Identifier: workv1alpha1.ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "ConfigMap", Namespace: "default", Name: "cm"},
+				Conditions: []metav1.Condition{{Type: "Applied", Status: metav1.ConditionTrue}},
+			}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(work).Build()
+
+	expected := `
+# HELP work_manifest_condition The condition of a manifest of a work, one series per condition status with the value 1 for the current status.
+# TYPE work_manifest_condition gauge
+work_manifest_condition{group="",kind="ConfigMap",namespace="cluster1",ordinal="0",resource_name="cm",resource_namespace="default",status="false",type="Applied",work="work"} 0
+work_manifest_condition{group="",kind="ConfigMap",namespace="cluster1",ordinal="0",resource_name="cm",resource_namespace="default",status="true",type="Applied",work="work"} 1
+work_manifest_condition{group="",kind="ConfigMap",namespace="cluster1",ordinal="0",resource_name="cm",resource_namespace="default",status="unknown",type="Applied",work="work"} 0
+# HELP work_status_condition The condition of a work, one series per condition status with the value 1 for the current status.
+# TYPE work_status_condition gauge
+work_status_condition{namespace="cluster1",status="false",type="Applied",work="work"} 1
+work_status_condition{namespace="cluster1",status="true",type="Applied",work="work"} 0
+work_status_condition{namespace="cluster1",status="unknown",type="Applied",work="work"} 0
+`
+	elected := make(chan struct{})
+	collector := &workStateCollector{client: fakeClient, log: ctrl.Log, elected: elected}
+	// the replicas which are not elected export nothing, so the series are not counted twice
+	if count := testutil.CollectAndCount(collector); count != 0 {
+		t.Errorf("expected no series before the replica is elected, got %d", count)
+	}
+
+	close(elected)
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
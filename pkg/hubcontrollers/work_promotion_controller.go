@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+// WorkPromotionReconciler promotes a Work to the cluster namespaces listed in its promote-to annotation
+type WorkPromotionReconciler struct {
+	client client.Client
+	log    logr.Logger
+}
+
+// Reconcile copies a work to the cluster namespaces it is promoted to once its workload is
+// applied on the cluster of its own namespace.
+func (r *WorkPromotionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, req.NamespacedName, work)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	if !work.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	clusters := promotionTargets(work)
+	if len(clusters) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	// only the validated generation of the work is promoted, a status update requeues the work
+	if !conditions.IsFresh(work.Status.Conditions, conditions.TypeApplied, work.Generation) || !conditions.IsApplied(work.Status.Conditions) {
+		r.log.V(2).Info("work is not applied yet, skip promotion", "work", req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	r.log.Info("promoting work", "work", req.NamespacedName, "clusters", clusters)
+	return ctrl.Result{}, PromoteWork(ctx, r.client, work, clusters)
+}
+
+// promotionTargets returns the cluster namespaces listed in the promote-to annotation of a work.
+func promotionTargets(work *workv1alpha1.Work) []string {
+	clusters := []string{}
+	for _, cluster := range strings.Split(work.Annotations[workv1alpha1.PromoteToAnnotation], ",") {
+		if cluster = strings.TrimSpace(cluster); cluster != "" {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+// SetupWithManager wires up the controller.
+func (r *WorkPromotionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("work-promotion").
+		For(&workv1alpha1.Work{}).
+		Complete(r)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestWorkPromotionReconcile(t *testing.T) {
+	source := newAppliedWork("hash", 2, metav1.ConditionFalse, 2)
+	source.Name = "work"
+	source.Namespace = "staging"
+	source.Labels = map[string]string{"app": "test", workv1alpha1.WorkSetLabel: "workset"}
+	source.Annotations[workv1alpha1.PromoteToAnnotation] = "cluster1, cluster2"
+
+	// a work in cluster2 which was not promoted from the staging work
+	conflicting := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster2"}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(newTestScheme()).WithObjects(source, conflicting).Build()
+	r := &WorkPromotionReconciler{client: fakeClient, log: ctrl.Log}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(source)}
+
+	// the work is not promoted before it is applied on the staging cluster
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster1", Name: "work"}, &workv1alpha1.Work{})
+	if !errors.IsNotFound(err) {
+		t.Fatalf("expected work not to be promoted yet, got %v", err)
+	}
+
+	if err := fakeClient.Get(context.Background(), req.NamespacedName, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	source.Status.Conditions[0].Status = metav1.ConditionTrue
+	source.Status.Conditions[0].ObservedGeneration = source.Generation
+	if err := fakeClient.Status().Update(context.Background(), source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err == nil {
+		t.Fatalf("expected an error promoting to cluster2")
+	}
+
+	promoted := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster1", Name: "work"}, promoted); err != nil {
+		t.Fatalf("expected work to be promoted to cluster1: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(promoted.Spec, source.Spec) {
+		t.Errorf("expected spec %v, got %v", source.Spec, promoted.Spec)
+	}
+	if promoted.Labels["app"] != "test" || promoted.Labels[workv1alpha1.WorkSetLabel] != "" {
+		t.Errorf("unexpected labels %v", promoted.Labels)
+	}
+	if promoted.Annotations[workv1alpha1.PromotedFromAnnotation] != "staging/work" {
+		t.Errorf("unexpected provenance %v", promoted.Annotations)
+	}
+	if _, ok := promoted.Annotations[workv1alpha1.PromoteToAnnotation]; ok {
+		t.Errorf("expected the promote-to annotation not to be copied, got %v", promoted.Annotations)
+	}
+
+	updated := &workv1alpha1.Work{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(conflicting), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Annotations[workv1alpha1.PromotedFromAnnotation] != "" {
+		t.Errorf("expected the work in cluster2 not to be overwritten, got %v", updated.Annotations)
+	}
+}
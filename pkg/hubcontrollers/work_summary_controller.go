@@ -0,0 +1,142 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+const (
+	// WorkSummaryName is the name of the ConfigMap summarizing the Works of a cluster namespace.
+	WorkSummaryName = "work-summary"
+	// WorkSummaryLabel labels the work summaries, so the summaries of the whole fleet are listed
+	// with a label selector.
+	WorkSummaryLabel = "multicluster.x-k8s.io/work-summary"
+)
+
+// Keys of the counts in the data of a work summary.
+const (
+	WorkSummaryWorks     = "works"
+	WorkSummaryApplied   = "applied"
+	WorkSummaryAvailable = "available"
+	WorkSummaryDegraded  = "degraded"
+)
+
+// WorkSummaryReconciler maintains a ConfigMap in every namespace with Works counting how many of
+// them are applied, available and degraded, for dashboards which only need the coarse health of
+// the fleet rather than listing and parsing all the Works. The summary of a namespace without
+// Works is deleted.
+type WorkSummaryReconciler struct {
+	client client.Client
+	// apiReader reads the summaries from the API, so the ConfigMaps of the hub are not cached.
+	apiReader client.Reader
+	log       logr.Logger
+}
+
+// Reconcile updates the summary of the works of a namespace, the request is named after the namespace.
+func (r *WorkSummaryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	works := &workv1alpha1.WorkList{}
+	if err := r.client.List(ctx, works, client.InNamespace(req.Name)); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	summary := &corev1.ConfigMap{}
+	err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: req.Name, Name: WorkSummaryName}, summary)
+	switch {
+	case errors.IsNotFound(err):
+		if len(works.Items) == 0 {
+			return ctrl.Result{}, nil
+		}
+		summary = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: req.Name,
+				Name:      WorkSummaryName,
+				Labels:    map[string]string{WorkSummaryLabel: "true"},
+			},
+			Data: summarizeWorks(works.Items),
+		}
+		r.log.V(2).Info("creating work summary", "namespace", req.Name)
+		return ctrl.Result{}, r.client.Create(ctx, summary)
+	case err != nil:
+		return ctrl.Result{}, err
+	case len(works.Items) == 0:
+		r.log.V(2).Info("deleting work summary", "namespace", req.Name)
+		return ctrl.Result{}, client.IgnoreNotFound(r.client.Delete(ctx, summary))
+	}
+
+	data := summarizeWorks(works.Items)
+	if equality.Semantic.DeepEqual(summary.Data, data) {
+		return ctrl.Result{}, nil
+	}
+	original := summary.DeepCopy()
+	summary.Data = data
+	return ctrl.Result{}, r.client.Patch(ctx, summary, client.MergeFrom(original))
+}
+
+// summarizeWorks returns the counts of a work summary.
+func summarizeWorks(works []workv1alpha1.Work) map[string]string {
+	var applied, available, degraded int
+	for i := range works {
+		if conditions.IsApplied(works[i].Status.Conditions) {
+			applied++
+		}
+		if conditions.IsAvailable(works[i].Status.Conditions) {
+			available++
+		}
+		if conditions.IsDegraded(works[i].Status.Conditions) {
+			degraded++
+		}
+	}
+	return map[string]string{
+		WorkSummaryWorks:     strconv.Itoa(len(works)),
+		WorkSummaryApplied:   strconv.Itoa(applied),
+		WorkSummaryAvailable: strconv.Itoa(available),
+		WorkSummaryDegraded:  strconv.Itoa(degraded),
+	}
+}
+
+// SetupWithManager wires up the controller.
+func (r *WorkSummaryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.apiReader == nil {
+		r.apiReader = mgr.GetAPIReader()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("work-summary").
+		For(&corev1.Namespace{}).
+		Watches(&source.Kind{Type: &workv1alpha1.Work{}}, handler.EnqueueRequestsFromMapFunc(namespaceOfWork)).
+		Complete(r)
+}
+
+// namespaceOfWork enqueues the namespace of a work.
+func namespaceOfWork(obj client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+}
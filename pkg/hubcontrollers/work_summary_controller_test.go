@@ -0,0 +1,98 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func newSummaryWork(name string, conditionTypes ...string) *workv1alpha1.Work {
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "cluster1"}}
+	for _, conditionType := range conditionTypes {
+		work.Status.Conditions = append(work.Status.Conditions, metav1.Condition{Type: conditionType, Status: metav1.ConditionTrue})
+	}
+	return work
+}
+
+func TestWorkSummaryReconcile(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newSummaryWork("applied", "Applied", "Available"),
+		newSummaryWork("degraded", "Applied", "Degraded"),
+		newSummaryWork("pending"),
+	).Build()
+	r := &WorkSummaryReconciler{client: fakeClient, apiReader: fakeClient, log: ctrl.Log}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "cluster1"}}
+	summaryKey := types.NamespacedName{Namespace: "cluster1", Name: WorkSummaryName}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	summary := &corev1.ConfigMap{}
+	if err := fakeClient.Get(context.Background(), summaryKey, summary); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{WorkSummaryWorks: "3", WorkSummaryApplied: "2", WorkSummaryAvailable: "1", WorkSummaryDegraded: "1"}
+	if !reflect.DeepEqual(summary.Data, expected) || summary.Labels[WorkSummaryLabel] != "true" {
+		t.Errorf("expected the summary %v, got %v with the labels %v", expected, summary.Data, summary.Labels)
+	}
+
+	if err := fakeClient.Delete(context.Background(), newSummaryWork("pending")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Get(context.Background(), summaryKey, summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Data[WorkSummaryWorks] != "2" {
+		t.Errorf("expected the summary to be updated, got %v", summary.Data)
+	}
+
+	for _, name := range []string{"applied", "degraded"} {
+		if err := fakeClient.Delete(context.Background(), newSummaryWork(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Get(context.Background(), summaryKey, summary); !errors.IsNotFound(err) {
+		t.Errorf("expected the summary of a namespace without works to be deleted, got %v", err)
+	}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "other"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "other", Name: WorkSummaryName}, summary); !errors.IsNotFound(err) {
+		t.Errorf("expected no summary in a namespace without works, got %v", err)
+	}
+}
@@ -0,0 +1,426 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+// statusFieldManager is the field manager of the status patches of the hub controllers.
+const statusFieldManager = "work-hub-controller"
+
+// WorkSetReconciler reconciles a WorkSet object
+type WorkSetReconciler struct {
+	client  client.Client
+	scheme  *runtime.Scheme
+	log     logr.Logger
+	decider ClusterDecider
+	// watchPlacements enables watching PlacementDecisions, it requires the Placement API to be installed on the hub.
+	watchPlacements bool
+}
+
+// Reconcile stamps the Work template of a WorkSet into every selected cluster namespace
+// and deletes the Works in cluster namespaces that are no longer selected.
+func (r *WorkSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	workSet := &workv1alpha1.WorkSet{}
+	err := r.client.Get(ctx, req.NamespacedName, workSet)
+	switch {
+	case errors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	// stamped works are garbage collected through their owner reference
+	if !workSet.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	original := workSet.DeepCopy()
+
+	// the placement decisions are not watched, the works would not follow them
+	if workSet.Spec.PlacementRef != nil && !r.watchPlacements {
+		return ctrl.Result{}, r.rejectWorkSet(ctx, workSet, original, reasons.PlacementDisabled,
+			"The hub controller does not watch placements, start it with --enable-placement")
+	}
+	if err := validateConditionSummaries(workSet.Spec.ConditionSummaries); err != nil {
+		return ctrl.Result{}, r.rejectWorkSet(ctx, workSet, original, reasons.ConditionSummariesInvalid, err.Error())
+	}
+
+	clusters, err := r.decider.Decide(ctx, workSet)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := time.Now()
+	previous := map[string]workv1alpha1.ClusterRolloutStatus{}
+	for _, rollout := range workSet.Status.ClusterRollouts {
+		previous[rollout.ClusterName] = rollout
+	}
+
+	// observe the rollout phase of the current template on every selected cluster
+	var requeueAfter time.Duration
+	errs := []error{}
+	selected := map[string]bool{}
+	stampedWorks := map[string]*workv1alpha1.Work{}
+	requiredWorks := map[string]*workv1alpha1.Work{}
+	rollouts := make([]workv1alpha1.ClusterRolloutStatus, 0, len(clusters))
+	for _, cluster := range clusters {
+		selected[cluster] = true
+		work, err := r.getStampedWork(ctx, workSet, cluster)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		stampedWorks[cluster] = work
+
+		// a cluster whose template fails to render stays pending
+		templateHash := ""
+		required, err := buildWorkFromTemplate(workSet, cluster)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to render template for cluster %s: %w", cluster, err))
+		} else {
+			requiredWorks[cluster] = required
+			templateHash = required.Annotations[templateHashAnnotation]
+		}
+
+		phase, wait := clusterRolloutPhase(work, templateHash, previous[cluster], soakTimeOf(workSet), now)
+		if wait > 0 && (requeueAfter == 0 || wait < requeueAfter) {
+			requeueAfter = wait
+		}
+		rollouts = append(rollouts, workv1alpha1.ClusterRolloutStatus{ClusterName: cluster, Phase: phase})
+	}
+
+	// sync the works, pending clusters only start rolling out within the budget of the rollout strategy
+	budget, halted := rolloutBudget(workSet.Spec.RolloutStrategy, rollouts)
+	for i := range rollouts {
+		rollout := &rollouts[i]
+		required := requiredWorks[rollout.ClusterName]
+		if required == nil {
+			continue
+		}
+		if rollout.Phase == workv1alpha1.RolloutPhasePending {
+			if budget == 0 {
+				continue
+			}
+			budget--
+		}
+		if err := r.syncWork(ctx, workSet, stampedWorks[rollout.ClusterName], required); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if rollout.Phase == workv1alpha1.RolloutPhasePending {
+			rollout.Phase = workv1alpha1.RolloutPhaseProgressing
+			if soakTime := soakTimeOf(workSet); soakTime > 0 && (requeueAfter == 0 || soakTime < requeueAfter) {
+				requeueAfter = soakTime
+			}
+		}
+	}
+	setRolloutTransitionTimes(rollouts, workSet.Status.ClusterRollouts, now)
+
+	// remove works from cluster namespaces which are no longer selected
+	workList := &workv1alpha1.WorkList{}
+	if err := r.client.List(ctx, workList, client.MatchingLabels{workv1alpha1.WorkSetLabel: workSet.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range workList.Items {
+		work := &workList.Items[i]
+		if selected[work.Namespace] || !metav1.IsControlledBy(work, workSet) {
+			continue
+		}
+		if err := r.client.Delete(ctx, work); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	workSet.Status.Clusters = clusters
+	workSet.Status.ClusterRollouts = rollouts
+
+	works := make([]*workv1alpha1.Work, 0, len(clusters))
+	for _, cluster := range clusters {
+		works = append(works, stampedWorks[cluster])
+	}
+	summaries := conditionSummariesOf(workSet)
+	workSet.Status.ConditionCounts = countConditions(works, summaries)
+
+	conditions := []metav1.Condition{
+		buildSyncedCondition(errs, workSet.Generation),
+		buildRolloutCondition(rollouts, halted, workSet.Generation),
+	}
+	conditions = append(conditions, buildSummaryConditions(summaries, workSet.Status.ConditionCounts, workSet.Generation)...)
+	setConditions(&workSet.Status.Conditions, conditions)
+	if isWorkSetStatusChanged(original.Status, workSet.Status) {
+		if err := r.client.Status().Patch(ctx, workSet, client.MergeFrom(original), client.FieldOwner(statusFieldManager)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	r.log.V(2).Info("synced workset", "workset", workSet.Name, "clusters", len(clusters), "errors", len(errs))
+	return ctrl.Result{RequeueAfter: requeueAfter}, utilerrors.NewAggregate(errs)
+}
+
+// rejectWorkSet reports a workset which cannot be synced as is, e.g. referring to a placement while
+// the placements are disabled, in its Synced condition. Its works are left as they are.
+func (r *WorkSetReconciler) rejectWorkSet(ctx context.Context, workSet, original *workv1alpha1.WorkSet, reason reasons.Reason, message string) error {
+	r.log.Info("ignoring invalid workset", "workset", workSet.Name, "reason", reason, "message", message)
+	meta.SetStatusCondition(&workSet.Status.Conditions, metav1.Condition{
+		Type:               "Synced",
+		Status:             metav1.ConditionFalse,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: workSet.Generation,
+	})
+	if !isWorkSetStatusChanged(original.Status, workSet.Status) {
+		return nil
+	}
+	return r.client.Status().Patch(ctx, workSet, client.MergeFrom(original), client.FieldOwner(statusFieldManager))
+}
+
+// getStampedWork returns the work named after the workset in a cluster namespace, or nil if it does not exist.
+func (r *WorkSetReconciler) getStampedWork(ctx context.Context, workSet *workv1alpha1.WorkSet, cluster string) (*workv1alpha1.Work, error) {
+	work := &workv1alpha1.Work{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: cluster, Name: workSet.Name}, work)
+	switch {
+	case errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return work, nil
+}
+
+// syncWork creates or updates the work stamped from the workset template in a cluster namespace.
+func (r *WorkSetReconciler) syncWork(ctx context.Context, workSet *workv1alpha1.WorkSet, existing, required *workv1alpha1.Work) error {
+	if err := controllerutil.SetControllerReference(workSet, required, r.scheme); err != nil {
+		return err
+	}
+
+	if existing == nil {
+		return r.client.Create(ctx, required)
+	}
+
+	if !metav1.IsControlledBy(existing, workSet) {
+		return fmt.Errorf("work %s/%s already exists and is not managed by workset %s", existing.Namespace, existing.Name, workSet.Name)
+	}
+
+	if equality.Semantic.DeepEqual(existing.Spec, required.Spec) &&
+		isSubset(required.Labels, existing.Labels) &&
+		isSubset(required.Annotations, existing.Annotations) {
+		return nil
+	}
+
+	existing.Spec = required.Spec
+	existing.Labels = mergeStringMap(existing.Labels, required.Labels)
+	existing.Annotations = mergeStringMap(existing.Annotations, required.Annotations)
+	return r.client.Update(ctx, existing)
+}
+
+// SetupWithManager wires up the controller.
+func (r *WorkSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.decider == nil {
+		r.decider = NewClusterDecider(r.client)
+	}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&workv1alpha1.WorkSet{}).
+		Owns(&workv1alpha1.Work{}).
+		Watches(&source.Kind{Type: &corev1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.workSetsForNamespace))
+
+	if r.watchPlacements {
+		decision := &unstructured.Unstructured{}
+		decision.SetGroupVersionKind(placementDecisionGVK)
+		builder = builder.Watches(&source.Kind{Type: decision}, handler.EnqueueRequestsFromMapFunc(r.workSetsForPlacementDecision))
+	}
+
+	return builder.Complete(r)
+}
+
+// workSetsForNamespace enqueues every workset whose cluster selector matches, or used to match, the namespace.
+func (r *WorkSetReconciler) workSetsForNamespace(obj client.Object) []reconcile.Request {
+	workSets := &workv1alpha1.WorkSetList{}
+	if err := r.client.List(context.TODO(), workSets); err != nil {
+		r.log.Error(err, "unable to list worksets", "namespace", obj.GetName())
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, workSet := range workSets.Items {
+		if !selectsNamespace(&workSet, obj) && !containsString(workSet.Status.Clusters, obj.GetName()) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}})
+	}
+	return requests
+}
+
+// workSetsForPlacementDecision enqueues every workset referring to the placement of the decision.
+func (r *WorkSetReconciler) workSetsForPlacementDecision(obj client.Object) []reconcile.Request {
+	placementName := obj.GetLabels()[placementLabel]
+	if placementName == "" {
+		return nil
+	}
+
+	workSets := &workv1alpha1.WorkSetList{}
+	if err := r.client.List(context.TODO(), workSets); err != nil {
+		r.log.Error(err, "unable to list worksets", "placementDecision", client.ObjectKeyFromObject(obj))
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, workSet := range workSets.Items {
+		ref := workSet.Spec.PlacementRef
+		if ref == nil || ref.Name != placementName || ref.Namespace != obj.GetNamespace() {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}})
+	}
+	return requests
+}
+
+func selectsNamespace(workSet *workv1alpha1.WorkSet, ns client.Object) bool {
+	if workSet.Spec.PlacementRef != nil || workSet.Spec.ClusterSelector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(workSet.Spec.ClusterSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(ns.GetLabels()))
+}
+
+// buildWorkFromTemplate returns the work the workset requires in the cluster namespace, the
+// template is rendered with the values of the cluster.
+func buildWorkFromTemplate(workSet *workv1alpha1.WorkSet, cluster string) (*workv1alpha1.Work, error) {
+	template, err := renderTemplate(&workSet.Spec.Template, valuesForCluster(workSet, cluster))
+	if err != nil {
+		return nil, err
+	}
+	templateHash, err := computeTemplateHash(template)
+	if err != nil {
+		return nil, err
+	}
+
+	workLabels := mergeStringMap(nil, template.Labels)
+	workLabels[workv1alpha1.WorkSetLabel] = workSet.Name
+	workAnnotations := mergeStringMap(nil, template.Annotations)
+	workAnnotations[templateHashAnnotation] = templateHash
+
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        workSet.Name,
+			Namespace:   cluster,
+			Labels:      workLabels,
+			Annotations: workAnnotations,
+		},
+		Spec: template.Spec,
+	}, nil
+}
+
+func buildSyncedCondition(errs []error, observedGeneration int64) metav1.Condition {
+	if len(errs) != 0 {
+		return metav1.Condition{
+			Type:               "Synced",
+			Status:             metav1.ConditionFalse,
+			Reason:             string(reasons.SyncWorksFailed),
+			Message:            fmt.Sprintf("Failed to sync works: %v", utilerrors.NewAggregate(errs)),
+			ObservedGeneration: observedGeneration,
+		}
+	}
+
+	return metav1.Condition{
+		Type:               "Synced",
+		Status:             metav1.ConditionTrue,
+		Reason:             string(reasons.SyncWorksComplete),
+		Message:            "Works in all selected clusters are synced",
+		ObservedGeneration: observedGeneration,
+	}
+}
+
+// isWorkSetStatusChanged returns true if the status of a workset changed, ignoring the transition times of the conditions.
+func isWorkSetStatusChanged(original, current workv1alpha1.WorkSetStatus) bool {
+	if !conditions.Equal(original.Conditions, current.Conditions) {
+		return true
+	}
+	original.Conditions, current.Conditions = nil, nil
+	return !equality.Semantic.DeepEqual(original, current)
+}
+
+// setConditions sets the conditions and removes the existing conditions of any other type.
+func setConditions(existing *[]metav1.Condition, conditions []metav1.Condition) {
+	conditionTypes := map[string]bool{}
+	for _, condition := range conditions {
+		conditionTypes[condition.Type] = true
+		meta.SetStatusCondition(existing, condition)
+	}
+	for _, condition := range *existing {
+		if !conditionTypes[condition.Type] {
+			meta.RemoveStatusCondition(existing, condition.Type)
+		}
+	}
+}
+
+// mergeStringMap returns a copy of existing with the entries of required added on top.
+func mergeStringMap(existing, required map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range required {
+		merged[k] = v
+	}
+	return merged
+}
+
+// isSubset returns true if every entry of required is also present in existing.
+func isSubset(required, existing map[string]string) bool {
+	for k, v := range required {
+		if actual, ok := existing[k]; !ok || actual != v {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hubcontrollers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/reasons"
+)
+
+func newTestScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(workv1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func newClusterNamespace(name string, selected bool) *corev1.Namespace {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if selected {
+		ns.Labels = map[string]string{"cluster": "true"}
+	}
+	return ns
+}
+
+func newTestWorkSet() *workv1alpha1.WorkSet {
+	return &workv1alpha1.WorkSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-workset", UID: "workset-uid", Generation: 1},
+		Spec: workv1alpha1.WorkSetSpec{
+			ClusterSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"cluster": "true"}},
+			Template: workv1alpha1.WorkTemplateSpec{
+				Labels: map[string]string{"app": "test"},
+				Spec: workv1alpha1.WorkSpec{
+					Workload: workv1alpha1.WorkloadTemplate{
+						Manifests: []workv1alpha1.Manifest{
+							{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","namespace":"default"}}`)}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestWorkSetReconcile(t *testing.T) {
+	scheme := newTestScheme()
+	workSet := newTestWorkSet()
+
+	// a work stamped into a cluster namespace that is no longer selected
+	staleWork, err := buildWorkFromTemplate(workSet, "cluster3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	isController := true
+	staleWork.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: workv1alpha1.GroupVersion.String(),
+		Kind:       workv1alpha1.WorkSetKind,
+		Name:       workSet.Name,
+		UID:        workSet.UID,
+		Controller: &isController,
+	}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		workSet,
+		staleWork,
+		newClusterNamespace("cluster1", true),
+		newClusterNamespace("cluster2", true),
+		newClusterNamespace("cluster3", false),
+	).Build()
+
+	r := &WorkSetReconciler{client: fakeClient, scheme: scheme, log: ctrl.Log, decider: NewClusterDecider(fakeClient)}
+	_, err = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, cluster := range []string{"cluster1", "cluster2"} {
+		work := &workv1alpha1.Work{}
+		if err := fakeClient.Get(context.Background(), types.NamespacedName{Namespace: cluster, Name: workSet.Name}, work); err != nil {
+			t.Fatalf("expected work in %s: %v", cluster, err)
+		}
+		if work.Labels[workv1alpha1.WorkSetLabel] != workSet.Name || work.Labels["app"] != "test" {
+			t.Errorf("unexpected labels on work in %s: %v", cluster, work.Labels)
+		}
+		if !metav1.IsControlledBy(work, workSet) {
+			t.Errorf("expected work in %s to be controlled by the workset", cluster)
+		}
+		if len(work.Spec.Workload.Manifests) != 1 {
+			t.Errorf("expected 1 manifest in %s, got %d", cluster, len(work.Spec.Workload.Manifests))
+		}
+	}
+
+	err = fakeClient.Get(context.Background(), types.NamespacedName{Namespace: "cluster3", Name: workSet.Name}, &workv1alpha1.Work{})
+	if !errors.IsNotFound(err) {
+		t.Errorf("expected work in cluster3 to be deleted, got %v", err)
+	}
+
+	updated := &workv1alpha1.WorkSet{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workSet), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(updated.Status.Clusters) != 2 {
+		t.Errorf("expected 2 clusters in status, got %v", updated.Status.Clusters)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, "Synced") {
+		t.Errorf("expected Synced condition to be true, got %v", updated.Status.Conditions)
+	}
+}
+
+func TestWorkSetReconcileConflict(t *testing.T) {
+	scheme := newTestScheme()
+	workSet := newTestWorkSet()
+
+	// a work with the same name created by someone else
+	existing := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: workSet.Name, Namespace: "cluster1"}}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		workSet,
+		existing,
+		newClusterNamespace("cluster1", true),
+	).Build()
+
+	r := &WorkSetReconciler{client: fakeClient, scheme: scheme, log: ctrl.Log, decider: NewClusterDecider(fakeClient)}
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}})
+	if err == nil {
+		t.Fatalf("expected an error when the work is not managed by the workset")
+	}
+
+	updated := &workv1alpha1.WorkSet{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workSet), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(updated.Status.Conditions, "Synced") {
+		t.Errorf("expected Synced condition to be false, got %v", updated.Status.Conditions)
+	}
+}
+
+func TestWorkSetReconcilePlacementDisabled(t *testing.T) {
+	scheme := newTestScheme()
+	workSet := newTestWorkSet()
+	workSet.Spec.PlacementRef = &workv1alpha1.PlacementReference{Name: "placement1", Namespace: "default"}
+	stamped, err := buildWorkFromTemplate(workSet, "cluster1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workSet, stamped, newClusterNamespace("cluster1", true)).Build()
+	r := &WorkSetReconciler{client: fakeClient, scheme: scheme, log: ctrl.Log, decider: NewClusterDecider(fakeClient)}
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: workSet.Name}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the works are left as they are while the placement decisions are not watched
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(stamped), &workv1alpha1.Work{}); err != nil {
+		t.Errorf("expected the work to be kept, got %v", err)
+	}
+	updated := &workv1alpha1.WorkSet{}
+	if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workSet), updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	synced := meta.FindStatusCondition(updated.Status.Conditions, "Synced")
+	if synced == nil || synced.Status != metav1.ConditionFalse || synced.Reason != string(reasons.PlacementDisabled) {
+		t.Errorf("expected the workset to be reported not synced, got %v", updated.Status.Conditions)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loadgen generates load on the hub and on the agent of a cluster: it creates many Works
+// of many manifests and measures how long the agent takes to apply them, how many requests it
+// sends and how much memory it uses meanwhile.
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+)
+
+// RunLabel is set on the Works of a load run, its value is the name of the run.
+const RunLabel = "multicluster.x-k8s.io/loadgen"
+
+// Options configures a load run
+type Options struct {
+	// Name is the name of the run, the Works are named after it and labeled with it.
+	Name string
+	// Namespace is the cluster namespace on the hub the Works are created in.
+	Namespace string
+	// TargetNamespace is the namespace on the spoke cluster the manifests are applied to.
+	TargetNamespace string
+
+	// Works is the number of Works created, each of Manifests ConfigMaps holding ManifestSize
+	// bytes of data.
+	Works        int
+	Manifests    int
+	ManifestSize int
+	// Concurrency is the number of Works created concurrently.
+	Concurrency int
+
+	// Timeout is how long the agent has to apply the Works, and then to delete them.
+	Timeout time.Duration
+	// PollInterval is how often the Works and the metrics of the agent are read.
+	PollInterval time.Duration
+	// AgentMetricsURL is the metrics endpoint of the agent, e.g. port-forwarded to localhost. The
+	// agent is not measured if empty.
+	AgentMetricsURL string
+	// Cleanup deletes the Works once they are applied, and measures how long their deletion takes.
+	Cleanup bool
+}
+
+// Report is the outcome of a load run
+type Report struct {
+	Works     int
+	Manifests int
+	Created   int
+	Applied   int
+
+	// CreateDuration is how long the creation of the Works took.
+	CreateDuration time.Duration
+	// ApplyDuration is how long it took from the first creation until the last Work was applied.
+	ApplyDuration time.Duration
+	// DeleteDuration is how long the deletion of the Works took, if cleaned up.
+	DeleteDuration time.Duration
+	// Latencies are the durations from the creation of each applied Work until it was observed
+	// applied, sorted.
+	Latencies []time.Duration
+
+	// Agent is measured from the metrics of the agent, if its endpoint is set.
+	Agent *AgentStats
+}
+
+// Run creates the Works of a load run on the hub, waits until they are applied and deletes them
+// if cleanup is set.
+func Run(ctx context.Context, c client.Client, opts Options) (*Report, error) {
+	report := &Report{Works: opts.Works, Manifests: opts.Manifests}
+	var agent *agentSampler
+	if opts.AgentMetricsURL != "" {
+		agent = newAgentSampler(opts.AgentMetricsURL)
+		if err := agent.start(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	createdAt, err := createWorks(ctx, c, opts)
+	report.CreateDuration = time.Since(start)
+	report.Created = len(createdAt)
+	if err != nil && len(createdAt) == 0 {
+		return nil, err
+	}
+
+	applyCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	report.Latencies, err = waitApplied(applyCtx, c, opts, createdAt, agent)
+	report.Applied = len(report.Latencies)
+	report.ApplyDuration = time.Since(start)
+	// the works not applied before the timeout are reported as such
+	if err != nil && (applyCtx.Err() == nil || ctx.Err() != nil) {
+		return report, err
+	}
+
+	if agent != nil {
+		if report.Agent, err = agent.stop(ctx, report.ApplyDuration); err != nil {
+			return report, err
+		}
+	}
+
+	if opts.Cleanup {
+		deleteStart := time.Now()
+		deleteCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		if err := deleteWorks(deleteCtx, c, opts); err != nil {
+			return report, fmt.Errorf("failed to delete the works: %w", err)
+		}
+		report.DeleteDuration = time.Since(deleteStart)
+	}
+	return report, nil
+}
+
+// createWorks creates the Works concurrently and returns when each of them was created. It
+// returns the last error if any Work failed to be created.
+func createWorks(ctx context.Context, c client.Client, opts Options) (map[string]time.Time, error) {
+	var lock sync.Mutex
+	var lastErr error
+	createdAt := map[string]time.Time{}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				work := buildWork(opts, index)
+				now := time.Now()
+				err := c.Create(ctx, work)
+				lock.Lock()
+				if err != nil {
+					lastErr = fmt.Errorf("failed to create work %s: %w", work.Name, err)
+				} else {
+					createdAt[work.Name] = now
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < opts.Works && ctx.Err() == nil; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return createdAt, lastErr
+}
+
+// buildWork returns the Work of the given index, of ConfigMaps named after it.
+func buildWork(opts Options, index int) *workv1alpha1.Work {
+	name := fmt.Sprintf("%s-%d", opts.Name, index)
+	payload := strings.Repeat("x", opts.ManifestSize)
+	manifests := make([]workv1alpha1.Manifest, 0, opts.Manifests)
+	for i := 0; i < opts.Manifests; i++ {
+		manifests = append(manifests, workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Object: &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", name, i), Namespace: opts.TargetNamespace},
+			Data:       map[string]string{"payload": payload},
+		}}})
+	}
+	return &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: opts.Namespace,
+			Labels:    map[string]string{RunLabel: opts.Name},
+		},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: manifests}},
+	}
+}
+
+// waitApplied polls the Works until all the created ones are applied, and returns the latency of
+// each applied Work, sorted. The agent is sampled at every poll.
+func waitApplied(ctx context.Context, c client.Client, opts Options, createdAt map[string]time.Time, agent *agentSampler) ([]time.Duration, error) {
+	applied := map[string]bool{}
+	latencies := make([]time.Duration, 0, len(createdAt))
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		works := &workv1alpha1.WorkList{}
+		if err := c.List(ctx, works, client.InNamespace(opts.Namespace), client.MatchingLabels{RunLabel: opts.Name}); err != nil {
+			return sortDurations(latencies), err
+		}
+		now := time.Now()
+		for i := range works.Items {
+			work := &works.Items[i]
+			created, ok := createdAt[work.Name]
+			if !ok || applied[work.Name] {
+				continue
+			}
+			if conditions.IsApplied(work.Status.Conditions) && conditions.IsFresh(work.Status.Conditions, conditions.TypeApplied, work.Generation) {
+				applied[work.Name] = true
+				latencies = append(latencies, now.Sub(created))
+			}
+		}
+		if agent != nil {
+			agent.sample(ctx)
+		}
+		if len(applied) == len(createdAt) {
+			return sortDurations(latencies), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return sortDurations(latencies), ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deleteWorks deletes the Works of the run and waits until the agent released them.
+func deleteWorks(ctx context.Context, c client.Client, opts Options) error {
+	if err := c.DeleteAllOf(ctx, &workv1alpha1.Work{}, client.InNamespace(opts.Namespace), client.MatchingLabels{RunLabel: opts.Name}); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		works := &workv1alpha1.WorkList{}
+		if err := c.List(ctx, works, client.InNamespace(opts.Namespace), client.MatchingLabels{RunLabel: opts.Name}); err != nil {
+			return err
+		}
+		if len(works.Items) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d works are not deleted yet: %w", len(works.Items), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func sortDurations(durations []time.Duration) []time.Duration {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	return durations
+}
+
+// percentile returns the p-th percentile of sorted durations, zero if there are none.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p/100+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// Print writes the report in a human readable form.
+func (r *Report) Print(out io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Works:            %d of %d manifests, %d created, %d applied\n", r.Works, r.Manifests, r.Created, r.Applied)
+	fmt.Fprintf(&b, "Create duration:  %s (%.1f works/s)\n", r.CreateDuration.Round(time.Millisecond), rate(float64(r.Created), r.CreateDuration))
+	fmt.Fprintf(&b, "Apply duration:   %s (%.1f works/s)\n", r.ApplyDuration.Round(time.Millisecond), rate(float64(r.Applied), r.ApplyDuration))
+	fmt.Fprintf(&b, "Apply latency:    p50 %s, p90 %s, p99 %s, max %s\n",
+		percentile(r.Latencies, 50).Round(time.Millisecond), percentile(r.Latencies, 90).Round(time.Millisecond),
+		percentile(r.Latencies, 99).Round(time.Millisecond), percentile(r.Latencies, 100).Round(time.Millisecond))
+	if r.DeleteDuration > 0 {
+		fmt.Fprintf(&b, "Delete duration:  %s\n", r.DeleteDuration.Round(time.Millisecond))
+	}
+	if r.Agent != nil {
+		fmt.Fprintf(&b, "Agent reconciles: %.0f, mean %s\n", r.Agent.Reconciles, r.Agent.MeanReconcile().Round(time.Millisecond))
+		fmt.Fprintf(&b, "Agent API QPS:    %.1f, %.1f to the spoke cluster\n", r.Agent.APIQPS(), r.Agent.SpokeQPS())
+		fmt.Fprintf(&b, "Agent memory:     peak RSS %s, peak heap %s\n", formatBytes(r.Agent.PeakResidentMemory), formatBytes(r.Agent.PeakHeap))
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func rate(count float64, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return count / duration.Seconds()
+}
+
+func formatBytes(bytes float64) string {
+	return fmt.Sprintf("%.1fMiB", bytes/(1<<20))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+// applyingClient applies the Works as soon as they are created, like an instant agent.
+type applyingClient struct {
+	client.Client
+}
+
+func (c applyingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if work, ok := obj.(*workv1alpha1.Work); ok {
+		meta.SetStatusCondition(&work.Status.Conditions, metav1.Condition{
+			Type: conditions.TypeApplied, Status: metav1.ConditionTrue, Reason: "Applied", ObservedGeneration: 1,
+		})
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestRun(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := applyingClient{fake.NewClientBuilder().WithScheme(scheme).Build()}
+
+	var scrapes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&scrapes, 1)
+		fmt.Fprintf(w, "# TYPE work_sync_duration_seconds histogram\n")
+		fmt.Fprintf(w, "work_sync_duration_seconds_bucket{le=\"+Inf\"} %d\n", 10*n)
+		fmt.Fprintf(w, "work_sync_duration_seconds_sum %d\n", n)
+		fmt.Fprintf(w, "work_sync_duration_seconds_count %d\n", 10*n)
+		fmt.Fprintf(w, "# TYPE rest_client_requests_total counter\n")
+		fmt.Fprintf(w, "rest_client_requests_total{code=\"200\",host=\"hub\",method=\"GET\"} %d\n", 20*n)
+		fmt.Fprintf(w, "rest_client_requests_total{code=\"200\",host=\"spoke\",method=\"GET\"} %d\n", 20*n)
+		fmt.Fprintf(w, "# TYPE process_resident_memory_bytes gauge\n")
+		fmt.Fprintf(w, "process_resident_memory_bytes %d\n", n<<20)
+	}))
+	defer server.Close()
+
+	opts := Options{
+		Name:            "run",
+		Namespace:       "cluster1",
+		TargetNamespace: "default",
+		Works:           5,
+		Manifests:       3,
+		ManifestSize:    16,
+		Concurrency:     2,
+		Timeout:         10 * time.Second,
+		PollInterval:    10 * time.Millisecond,
+		AgentMetricsURL: server.URL,
+		Cleanup:         true,
+	}
+	report, err := Run(context.Background(), c, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 5 || report.Applied != 5 || len(report.Latencies) != 5 {
+		t.Errorf("expected all the works to be created and applied, got %+v", report)
+	}
+	if report.Agent == nil || report.Agent.Reconciles <= 0 || report.Agent.APIRequests <= 0 || report.Agent.PeakResidentMemory <= 1<<20 {
+		t.Errorf("expected the agent to be measured, got %+v", report.Agent)
+	}
+	if mean := report.Agent.MeanReconcile(); mean != 100*time.Millisecond {
+		t.Errorf("expected a mean reconcile of 100ms, got %s", mean)
+	}
+
+	works := &workv1alpha1.WorkList{}
+	if err := c.List(context.Background(), works); err != nil {
+		t.Fatal(err)
+	}
+	if len(works.Items) != 0 {
+		t.Errorf("expected the works to be cleaned up, got %d", len(works.Items))
+	}
+
+	var out bytes.Buffer
+	if err := report.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"Works:            5 of 3 manifests, 5 created, 5 applied", "Agent reconciles:", "Delete duration:"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("expected the report to contain %q, got\n%s", line, out.String())
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	for p, expected := range map[float64]time.Duration{50: 5, 90: 9, 99: 10, 100: 10, 1: 1} {
+		if actual := percentile(latencies, p); actual != expected {
+			t.Errorf("expected the p%v to be %v, got %v", p, expected, actual)
+		}
+	}
+	if actual := percentile(nil, 50); actual != 0 {
+		t.Errorf("expected no percentile of no latency, got %v", actual)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// The metrics of the agent the load run is measured with.
+const (
+	reconcileDurationMetric = "work_sync_duration_seconds"
+	apiRequestsMetric       = "rest_client_requests_total"
+	spokeRequestsMetric     = "work_spoke_requests_total"
+	residentMemoryMetric    = "process_resident_memory_bytes"
+	heapMetric              = "go_memstats_heap_inuse_bytes"
+)
+
+// AgentStats is the activity of the agent during a load run
+type AgentStats struct {
+	// Duration is how long the agent was measured.
+	Duration time.Duration
+	// Reconciles is the number of Works the agent synced, taking ReconcileSeconds overall.
+	Reconciles       float64
+	ReconcileSeconds float64
+	// APIRequests is the number of requests the agent sent to the hub and spoke clusters,
+	// SpokeRequests the number of them which went to the spoke cluster.
+	APIRequests   float64
+	SpokeRequests float64
+	// PeakResidentMemory and PeakHeap are the highest memory usage of the agent sampled.
+	PeakResidentMemory float64
+	PeakHeap           float64
+}
+
+// MeanReconcile returns the mean duration of the syncs of the Works.
+func (s *AgentStats) MeanReconcile() time.Duration {
+	if s.Reconciles == 0 {
+		return 0
+	}
+	return time.Duration(s.ReconcileSeconds / s.Reconciles * float64(time.Second))
+}
+
+// APIQPS returns the rate of the requests the agent sent.
+func (s *AgentStats) APIQPS() float64 {
+	return rate(s.APIRequests, s.Duration)
+}
+
+// SpokeQPS returns the rate of the requests the agent sent to the spoke cluster.
+func (s *AgentStats) SpokeQPS() float64 {
+	return rate(s.SpokeRequests, s.Duration)
+}
+
+// agentSampler scrapes the metrics endpoint of the agent.
+type agentSampler struct {
+	url      string
+	client   *http.Client
+	baseline map[string]*dto.MetricFamily
+	stats    AgentStats
+}
+
+func newAgentSampler(url string) *agentSampler {
+	return &agentSampler{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// start records the metrics of the agent before the load run.
+func (s *agentSampler) start(ctx context.Context) error {
+	families, err := s.scrape(ctx)
+	if err != nil {
+		return err
+	}
+	s.baseline = families
+	s.sampleMemory(families)
+	return nil
+}
+
+// sample records the memory usage of the agent. The agent may be briefly unreachable under load,
+// a failed sample is skipped.
+func (s *agentSampler) sample(ctx context.Context) {
+	if families, err := s.scrape(ctx); err == nil {
+		s.sampleMemory(families)
+	}
+}
+
+// stop returns the activity of the agent since the start of the load run.
+func (s *agentSampler) stop(ctx context.Context, duration time.Duration) (*AgentStats, error) {
+	families, err := s.scrape(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.sampleMemory(families)
+	stats := s.stats
+	stats.Duration = duration
+	stats.Reconciles, stats.ReconcileSeconds = histogramDelta(s.baseline[reconcileDurationMetric], families[reconcileDurationMetric])
+	stats.APIRequests = counterDelta(s.baseline[apiRequestsMetric], families[apiRequestsMetric])
+	stats.SpokeRequests = counterDelta(s.baseline[spokeRequestsMetric], families[spokeRequestsMetric])
+	return &stats, nil
+}
+
+func (s *agentSampler) sampleMemory(families map[string]*dto.MetricFamily) {
+	if rss := gaugeValue(families[residentMemoryMetric]); rss > s.stats.PeakResidentMemory {
+		s.stats.PeakResidentMemory = rss
+	}
+	if heap := gaugeValue(families[heapMetric]); heap > s.stats.PeakHeap {
+		s.stats.PeakHeap = heap
+	}
+}
+
+func (s *agentSampler) scrape(ctx context.Context) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scrape the metrics of the agent: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to scrape the metrics of the agent: %s", resp.Status)
+	}
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// counterDelta returns how much the sum of the series of a counter increased.
+func counterDelta(before, after *dto.MetricFamily) float64 {
+	return sumCounter(after) - sumCounter(before)
+}
+
+func sumCounter(family *dto.MetricFamily) float64 {
+	var sum float64
+	if family != nil {
+		for _, metric := range family.Metric {
+			sum += metric.GetCounter().GetValue()
+		}
+	}
+	return sum
+}
+
+// histogramDelta returns how much the count and the sum of the series of a histogram increased.
+func histogramDelta(before, after *dto.MetricFamily) (float64, float64) {
+	beforeCount, beforeSum := sumHistogram(before)
+	afterCount, afterSum := sumHistogram(after)
+	return afterCount - beforeCount, afterSum - beforeSum
+}
+
+func sumHistogram(family *dto.MetricFamily) (float64, float64) {
+	var count, sum float64
+	if family != nil {
+		for _, metric := range family.Metric {
+			count += float64(metric.GetHistogram().GetSampleCount())
+			sum += metric.GetHistogram().GetSampleSum()
+		}
+	}
+	return count, sum
+}
+
+// gaugeValue returns the value of a gauge of a single series, zero if it is missing.
+func gaugeValue(family *dto.MetricFamily) float64 {
+	if family == nil || len(family.Metric) == 0 {
+		return 0
+	}
+	return family.Metric[0].GetGauge().GetValue()
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mirror copies the Works of the hub into a namespace of the spoke cluster, where the agent
+// applies them, for the agents which do not read their Works from the hub API. The status of a
+// mirror is mapped back to the generation of the Work on the hub.
+package mirror
+
+import (
+	"context"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// HubGenerationAnnotation is set on the mirrors of the Works. Its value is the generation of the
+// Work on the hub.
+const HubGenerationAnnotation = "multicluster.x-k8s.io/hub-generation"
+
+// Apply creates or updates the mirror of a Work of the hub in the namespace and returns whether it
+// changed. The mirror is left alone if it mirrors a newer generation, as the Works may be received
+// out of order, or if it is being deleted.
+func Apply(ctx context.Context, c client.Client, namespace string, work *workv1alpha1.Work) (bool, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: work.Name}
+	hubGeneration := strconv.FormatInt(work.Generation, 10)
+	mirror := &workv1alpha1.Work{}
+	err := c.Get(ctx, key, mirror)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	exists := err == nil
+	if exists {
+		current, _ := strconv.ParseInt(mirror.Annotations[HubGenerationAnnotation], 10, 64)
+		if current > work.Generation || !mirror.DeletionTimestamp.IsZero() {
+			return false, nil
+		}
+	}
+
+	annotations := map[string]string{}
+	for k, v := range work.Annotations {
+		annotations[k] = v
+	}
+	annotations[HubGenerationAnnotation] = hubGeneration
+	if exists && equality.Semantic.DeepEqual(mirror.Spec, work.Spec) &&
+		equality.Semantic.DeepEqual(mirror.Labels, work.Labels) && equality.Semantic.DeepEqual(mirror.Annotations, annotations) {
+		return false, nil
+	}
+	mirror.Name, mirror.Namespace = key.Name, key.Namespace
+	mirror.Labels = work.Labels
+	mirror.Annotations = annotations
+	mirror.Spec = work.Spec
+	if !exists {
+		return true, c.Create(ctx, mirror)
+	}
+	return true, c.Update(ctx, mirror)
+}
+
+// HubStatus returns the generation of the Work on the hub and the status of its mirror, with the
+// observed generations of the hub. The conditions of an older generation of the mirror are not
+// mapped, they report generation 0. It returns false if the Work is not a mirror.
+func HubStatus(mirror *workv1alpha1.Work) (int64, *workv1alpha1.WorkStatus, bool) {
+	hubGeneration, err := strconv.ParseInt(mirror.Annotations[HubGenerationAnnotation], 10, 64)
+	if err != nil {
+		return 0, nil, false
+	}
+	status := mirror.Status.DeepCopy()
+	toHubGeneration(status.Conditions, mirror.Generation, hubGeneration)
+	for i := range status.ManifestConditions {
+		toHubGeneration(status.ManifestConditions[i].Conditions, mirror.Generation, hubGeneration)
+	}
+	return hubGeneration, status, true
+}
+
+func toHubGeneration(conditions []metav1.Condition, generation, hubGeneration int64) {
+	for i := range conditions {
+		if conditions[i].ObservedGeneration == generation {
+			conditions[i].ObservedGeneration = hubGeneration
+		} else {
+			conditions[i].ObservedGeneration = 0
+		}
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ocm converts the Works and AppliedWorks to and from the ManifestWorks and
+// AppliedManifestWorks of Open Cluster Management, to migrate between the two APIs or run them
+// side by side. The OCM objects are unstructured so the OCM API is not a dependency.
+package ocm
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+var (
+	// ManifestWorkGVK is the kind of the OCM ManifestWorks.
+	ManifestWorkGVK = schema.GroupVersionKind{Group: "work.open-cluster-management.io", Version: "v1", Kind: "ManifestWork"}
+	// AppliedManifestWorkGVK is the kind of the OCM AppliedManifestWorks.
+	AppliedManifestWorkGVK = schema.GroupVersionKind{Group: "work.open-cluster-management.io", Version: "v1", Kind: "AppliedManifestWork"}
+)
+
+// manifestWork mirrors the fields of an OCM ManifestWork which have a counterpart in a Work.
+type manifestWork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              manifestWorkSpec   `json:"spec,omitempty"`
+	Status            manifestWorkStatus `json:"status,omitempty"`
+}
+
+type manifestWorkSpec struct {
+	Workload workv1alpha1.WorkloadTemplate `json:"workload,omitempty"`
+}
+
+type manifestWorkStatus struct {
+	Conditions     []metav1.Condition     `json:"conditions,omitempty"`
+	ResourceStatus manifestResourceStatus `json:"resourceStatus,omitempty"`
+}
+
+type manifestResourceStatus struct {
+	Manifests []manifestCondition `json:"manifests,omitempty"`
+}
+
+type manifestCondition struct {
+	ResourceMeta manifestResourceMeta `json:"resourceMeta"`
+	Conditions   []metav1.Condition   `json:"conditions"`
+}
+
+type manifestResourceMeta struct {
+	Ordinal   int32  `json:"ordinal"`
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// appliedManifestWork mirrors the fields of an OCM AppliedManifestWork which have a counterpart in
+// an AppliedWork.
+type appliedManifestWork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              appliedManifestWorkSpec   `json:"spec,omitempty"`
+	Status            appliedManifestWorkStatus `json:"status,omitempty"`
+}
+
+type appliedManifestWorkSpec struct {
+	HubHash          string `json:"hubHash"`
+	ManifestWorkName string `json:"manifestWorkName"`
+}
+
+type appliedManifestWorkStatus struct {
+	AppliedResources []appliedManifestResourceMeta `json:"appliedResources,omitempty"`
+}
+
+type appliedManifestResourceMeta struct {
+	Group     string    `json:"group"`
+	Version   string    `json:"version"`
+	Resource  string    `json:"resource"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid,omitempty"`
+}
+
+// ToManifestWork converts a Work, with its status, into a ManifestWork. The fields of the Work
+// without a counterpart in a ManifestWork, such as its TTL and the times of its manifest
+// conditions, are dropped.
+func ToManifestWork(work *workv1alpha1.Work) (*unstructured.Unstructured, error) {
+	converted := &manifestWork{
+		ObjectMeta: convertObjectMeta(work.ObjectMeta),
+		Spec:       manifestWorkSpec{Workload: *work.Spec.Workload.DeepCopy()},
+		Status:     manifestWorkStatus{Conditions: copyConditions(work.Status.Conditions)},
+	}
+	converted.SetGroupVersionKind(ManifestWorkGVK)
+	for _, condition := range work.Status.ManifestConditions {
+		identifier := condition.Identifier
+		converted.Status.ResourceStatus.Manifests = append(converted.Status.ResourceStatus.Manifests, manifestCondition{
+			ResourceMeta: manifestResourceMeta{
+				Ordinal:   int32(identifier.Ordinal),
+				Group:     identifier.Group,
+				Version:   identifier.Version,
+				Kind:      identifier.Kind,
+				Resource:  identifier.Resource,
+				Name:      identifier.Name,
+				Namespace: identifier.Namespace,
+			},
+			Conditions: copyConditions(condition.Conditions),
+		})
+	}
+	return toUnstructured(converted)
+}
+
+// FromManifestWork converts a ManifestWork, with its status, into a Work. The fields of the
+// ManifestWork without a counterpart in a Work, such as its delete and manifest configs, are
+// dropped.
+func FromManifestWork(obj *unstructured.Unstructured) (*workv1alpha1.Work, error) {
+	if gvk := obj.GroupVersionKind(); gvk != ManifestWorkGVK {
+		return nil, fmt.Errorf("expected a %s, got %s", ManifestWorkGVK, gvk)
+	}
+	converted := &manifestWork{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, converted); err != nil {
+		return nil, err
+	}
+
+	work := &workv1alpha1.Work{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: workv1alpha1.GroupVersion.String(),
+			Kind:       workv1alpha1.WorkKind,
+		},
+		ObjectMeta: convertObjectMeta(converted.ObjectMeta),
+		Spec:       workv1alpha1.WorkSpec{Workload: converted.Spec.Workload},
+		Status:     workv1alpha1.WorkStatus{Conditions: converted.Status.Conditions},
+	}
+	for _, condition := range converted.Status.ResourceStatus.Manifests {
+		meta := condition.ResourceMeta
+		work.Status.ManifestConditions = append(work.Status.ManifestConditions, workv1alpha1.ManifestCondition{
+			Identifier: workv1alpha1.ResourceIdentifier{
+				Ordinal:   int(meta.Ordinal),
+				Group:     meta.Group,
+				Version:   meta.Version,
+				Kind:      meta.Kind,
+				Resource:  meta.Resource,
+				Namespace: meta.Namespace,
+				Name:      meta.Name,
+			},
+			Conditions: condition.Conditions,
+		})
+	}
+	return work, nil
+}
+
+// ToAppliedManifestWork converts an AppliedWork into the AppliedManifestWork of the OCM agent of
+// the hub with the given hash. The AppliedManifestWork is named {hub hash}-{work name} like the
+// OCM agent names it.
+func ToAppliedManifestWork(appliedWork *workv1alpha1.AppliedWork, hubHash string) (*unstructured.Unstructured, error) {
+	converted := &appliedManifestWork{
+		ObjectMeta: convertObjectMeta(appliedWork.ObjectMeta),
+		Spec: appliedManifestWorkSpec{
+			HubHash:          hubHash,
+			ManifestWorkName: appliedWork.Spec.WorkName,
+		},
+	}
+	converted.SetGroupVersionKind(AppliedManifestWorkGVK)
+	converted.Name = fmt.Sprintf("%s-%s", hubHash, appliedWork.Spec.WorkName)
+	for _, resource := range appliedWork.Status.AppliedResources {
+		converted.Status.AppliedResources = append(converted.Status.AppliedResources, appliedManifestResourceMeta{
+			Group:     resource.Group,
+			Version:   resource.Version,
+			Resource:  resource.Resource,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+			UID:       resource.UID,
+		})
+	}
+	return toUnstructured(converted)
+}
+
+// FromAppliedManifestWork converts an AppliedManifestWork into an AppliedWork. The namespace of the
+// work is the namespace of the cluster on the hub, which an AppliedManifestWork does not record.
+func FromAppliedManifestWork(obj *unstructured.Unstructured, workNamespace string) (*workv1alpha1.AppliedWork, error) {
+	if gvk := obj.GroupVersionKind(); gvk != AppliedManifestWorkGVK {
+		return nil, fmt.Errorf("expected a %s, got %s", AppliedManifestWorkGVK, gvk)
+	}
+	converted := &appliedManifestWork{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, converted); err != nil {
+		return nil, err
+	}
+
+	workName := converted.Spec.ManifestWorkName
+	if workName == "" {
+		workName = strings.TrimPrefix(converted.Name, converted.Spec.HubHash+"-")
+	}
+	appliedWork := &workv1alpha1.AppliedWork{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: workv1alpha1.GroupVersion.String(),
+			Kind:       workv1alpha1.AppliedWorkKind,
+		},
+		ObjectMeta: convertObjectMeta(converted.ObjectMeta),
+		Spec: workv1alpha1.AppliedWorkSpec{
+			WorkName:      workName,
+			WorkNamespace: workNamespace,
+		},
+	}
+	appliedWork.Name = workName
+	for _, resource := range converted.Status.AppliedResources {
+		appliedWork.Status.AppliedResources = append(appliedWork.Status.AppliedResources, workv1alpha1.AppliedResourceMeta{
+			ResourceIdentifier: workv1alpha1.ResourceIdentifier{
+				Group:     resource.Group,
+				Version:   resource.Version,
+				Resource:  resource.Resource,
+				Namespace: resource.Namespace,
+				Name:      resource.Name,
+			},
+			UID: resource.UID,
+		})
+	}
+	return appliedWork, nil
+}
+
+// convertObjectMeta keeps the metadata which make sense on an object of the other API: the name,
+// the namespace, the labels and the annotations.
+func convertObjectMeta(objectMeta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        objectMeta.Name,
+		Namespace:   objectMeta.Namespace,
+		Labels:      copyStringMap(objectMeta.Labels),
+		Annotations: copyStringMap(objectMeta.Annotations),
+	}
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	return append([]metav1.Condition{}, conditions...)
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
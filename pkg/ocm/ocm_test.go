@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ocm
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestManifestWorkRoundTrip(t *testing.T) {
+	identifier := workv1alpha1.ResourceIdentifier{Ordinal: 0, Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "default", Name: "config"}
+	applied := metav1.Condition{Type: "Applied", Status: metav1.ConditionTrue, Reason: "AppliedWorkComplete", ObservedGeneration: 1,
+		LastTransitionTime: metav1.Unix(1600000000, 0)}
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Labels: map[string]string{"app": "test"}, ResourceVersion: "12"},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+			{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config","namespace":"default"}}`)}},
+		}}},
+		Status: workv1alpha1.WorkStatus{
+			Conditions:         []metav1.Condition{applied},
+			ManifestConditions: []workv1alpha1.ManifestCondition{{Identifier: identifier, Conditions: []metav1.Condition{applied}}},
+		},
+	}
+
+	manifestWork, err := ToManifestWork(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifestWork.GroupVersionKind() != ManifestWorkGVK || manifestWork.GetResourceVersion() != "" {
+		t.Errorf("unexpected manifestwork metadata %v", manifestWork.Object["metadata"])
+	}
+	manifests, _, _ := unstructured.NestedFieldNoCopy(manifestWork.Object, "status", "resourceStatus", "manifests")
+	if manifests, ok := manifests.([]interface{}); !ok || len(manifests) != 1 {
+		t.Errorf("expected the manifest conditions in the resource status, got %v", manifests)
+	}
+
+	converted, err := FromManifestWork(manifestWork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.Name != work.Name || converted.Namespace != work.Namespace || !reflect.DeepEqual(converted.Labels, work.Labels) {
+		t.Errorf("unexpected work metadata %v", converted.ObjectMeta)
+	}
+	if !reflect.DeepEqual(converted.Spec, work.Spec) {
+		t.Errorf("expected the spec %v, got %v", work.Spec, converted.Spec)
+	}
+	if !reflect.DeepEqual(converted.Status, work.Status) {
+		t.Errorf("expected the status %v, got %v", work.Status, converted.Status)
+	}
+
+	if _, err := FromManifestWork(&unstructured.Unstructured{Object: map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap"}}); err == nil {
+		t.Error("expected converting another kind to fail")
+	}
+}
+
+func TestAppliedManifestWorkRoundTrip(t *testing.T) {
+	appliedWork := &workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "work"},
+		Spec:       workv1alpha1.AppliedWorkSpec{WorkName: "work", WorkNamespace: "cluster1"},
+		Status: workv1alpha1.AppliedtWorkStatus{AppliedResources: []workv1alpha1.AppliedResourceMeta{{
+			ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "default", Name: "config"},
+			UID:                "uid",
+		}}},
+	}
+
+	appliedManifestWork, err := ToAppliedManifestWork(appliedWork, "hubhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appliedManifestWork.GetName() != "hubhash-work" {
+		t.Errorf("expected the appliedmanifestwork to be named hubhash-work, got %s", appliedManifestWork.GetName())
+	}
+
+	converted, err := FromAppliedManifestWork(appliedManifestWork, "cluster1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.Name != appliedWork.Name || !reflect.DeepEqual(converted.Spec, appliedWork.Spec) || !reflect.DeepEqual(converted.Status, appliedWork.Status) {
+		t.Errorf("expected %v, got %v", appliedWork, converted)
+	}
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plan previews what the agent would change on a cluster to apply a Work, as a summary of
+// the resources to create, update with the fields changed, and delete.
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Action is what the agent would do to a resource of a Work.
+type Action string
+
+const (
+	// Create means the resource does not exist on the cluster.
+	Create Action = "Create"
+	// Update means fields of the manifest differ from the resource on the cluster.
+	Update Action = "Update"
+	// Delete means the resource was applied by the Work but is no longer in it.
+	Delete Action = "Delete"
+	// NoChange means the resource on the cluster matches the manifest.
+	NoChange Action = "NoChange"
+	// Skip means the manifest is skipped by the Work, or the resource is externally managed.
+	Skip Action = "Skip"
+)
+
+// FieldDiff is a field of a resource changed by an update. Old is nil if the field is added.
+type FieldDiff struct {
+	// Path is the dotted path of the field, with the index of list items in brackets.
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// Change is what the agent would do to a resource of a Work.
+type Change struct {
+	Action     Action
+	Identifier workv1alpha1.ResourceIdentifier
+	// Diffs are the fields changed by an update.
+	Diffs []FieldDiff
+	// Reason explains a skip.
+	Reason string
+}
+
+// Plan is the preview of the changes applying a Work to a cluster.
+type Plan struct {
+	Changes []Change
+}
+
+// Compute returns the plan of applying a Work with the client and the RESTMapper of its cluster. The
+// resources to delete are those of the AppliedWork of the Work
+// which are no longer in it.
+func Compute(ctx context.Context, c client.Reader, restMapper meta.RESTMapper, work *workv1alpha1.Work) (*Plan, error) {
+	plan := &Plan{}
+	skipped := map[string]bool{}
+	for _, key := range strings.Split(work.Annotations[workv1alpha1.SkipManifestsAnnotation], ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			skipped[strings.ToLower(key)] = true
+		}
+	}
+
+	appliedResources, err := findAppliedResources(ctx, c, work)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := []workv1alpha1.ResourceIdentifier{}
+	for ordinal, manifest := range work.Spec.Workload.Manifests {
+		required := &unstructured.Unstructured{}
+		if err := required.UnmarshalJSON(manifest.Raw); err != nil {
+			return nil, fmt.Errorf("failed to decode manifest %d: %w", ordinal, err)
+		}
+		if err := setDefaultNamespace(restMapper, required, work.Spec.DefaultNamespace); err != nil {
+			return nil, fmt.Errorf("failed to map manifest %d: %w", ordinal, err)
+		}
+		if required.GetName() == "" {
+			required.SetName(generatedName(ordinal, required, appliedResources))
+		}
+		change, err := planManifest(ctx, c, restMapper, ordinal, required, skipped)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, *change)
+		planned = append(planned, change.Identifier)
+	}
+
+	for _, resource := range appliedResources {
+		if isPlanned(resource.ResourceIdentifier, planned) {
+			continue
+		}
+		change, err := planDelete(ctx, c, restMapper, resource)
+		if err != nil {
+			return nil, err
+		}
+		if change != nil {
+			plan.Changes = append(plan.Changes, *change)
+		}
+	}
+	return plan, nil
+}
+
+// planManifest returns the change of the resource of a manifest.
+func planManifest(ctx context.Context, c client.Reader, restMapper meta.RESTMapper, ordinal int, required *unstructured.Unstructured, skipped map[string]bool) (*Change, error) {
+	gvk := required.GroupVersionKind()
+	change := &Change{
+		Identifier: workv1alpha1.ResourceIdentifier{
+			Ordinal:   ordinal,
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: required.GetNamespace(),
+			Name:      required.GetName(),
+		},
+	}
+	mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to map manifest %d: %w", ordinal, err)
+	}
+	change.Identifier.Resource = mapping.Resource.Resource
+
+	key := strings.ToLower(gvk.Kind + "/" + required.GetName())
+	if required.GetNamespace() != "" {
+		key = strings.ToLower(gvk.Kind + "/" + required.GetNamespace() + "/" + required.GetName())
+	}
+	if skipped[key] {
+		change.Action, change.Reason = Skip, fmt.Sprintf("skipped by the %s annotation", workv1alpha1.SkipManifestsAnnotation)
+		return change, nil
+	}
+
+	if required.GetName() == "" && required.GetGenerateName() != "" {
+		change.Action, change.Reason = Create, fmt.Sprintf("named %s<generated>", required.GetGenerateName())
+		return change, nil
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(gvk)
+	err = c.Get(ctx, client.ObjectKeyFromObject(required), existing)
+	switch {
+	case errors.IsNotFound(err):
+		change.Action = Create
+		return change, nil
+	case err != nil:
+		return nil, err
+	}
+	if existing.GetAnnotations()[workv1alpha1.UnmanagedAnnotation] == "true" {
+		change.Action, change.Reason = Skip, "externally managed"
+		return change, nil
+	}
+
+	// the status and the metadata set by the server are not applied
+	delete(required.Object, "status")
+	for _, field := range []string{"creationTimestamp", "resourceVersion", "uid", "generation", "managedFields"} {
+		unstructured.RemoveNestedField(required.Object, "metadata", field)
+	}
+	change.Diffs = diffFields("", existing.Object, required.Object)
+	change.Action = NoChange
+	if len(change.Diffs) > 0 {
+		change.Action = Update
+	}
+	return change, nil
+}
+
+// setDefaultNamespace sets defaultNamespace on a namespaced manifest without a namespace, as the
+// agent does when it applies the manifest.
+func setDefaultNamespace(restMapper meta.RESTMapper, required *unstructured.Unstructured, defaultNamespace string) error {
+	if defaultNamespace == "" || required.GetNamespace() != "" {
+		return nil
+	}
+	gvk := required.GroupVersionKind()
+	mapping, err := restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return err
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		required.SetNamespace(defaultNamespace)
+	}
+	return nil
+}
+
+// generatedName returns the name the server generated for a manifest using generateName when the
+// agent first applied it, or an empty name if it was not applied yet.
+func generatedName(ordinal int, required *unstructured.Unstructured, appliedResources []workv1alpha1.AppliedResourceMeta) string {
+	if required.GetGenerateName() == "" {
+		return ""
+	}
+	gvk := required.GroupVersionKind()
+	for _, resource := range appliedResources {
+		if resource.Ordinal == ordinal && resource.Group == gvk.Group && resource.Kind == gvk.Kind &&
+			resource.Namespace == required.GetNamespace() && strings.HasPrefix(resource.Name, required.GetGenerateName()) {
+			return resource.Name
+		}
+	}
+	return ""
+}
+
+// planDelete returns the deletion of a resource applied by the Work, or nil if the resource is
+// gone, was recreated by someone else or is externally managed, as the agent leaves it then.
+func planDelete(ctx context.Context, c client.Reader, restMapper meta.RESTMapper, resource workv1alpha1.AppliedResourceMeta) (*Change, error) {
+	gvk, err := restMapper.KindFor(schema.GroupVersionResource{Group: resource.Group, Version: resource.Version, Resource: resource.Resource})
+	if err != nil {
+		return nil, err
+	}
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(gvk)
+	err = c.Get(ctx, client.ObjectKey{Namespace: resource.Namespace, Name: resource.Name}, live)
+	switch {
+	case errors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	if live.GetUID() != resource.UID || live.GetAnnotations()[workv1alpha1.UnmanagedAnnotation] == "true" {
+		return nil, nil
+	}
+	identifier := resource.ResourceIdentifier
+	identifier.Kind = gvk.Kind
+	return &Change{Action: Delete, Identifier: identifier}, nil
+}
+
+// findAppliedResources returns the resources applied by a Work from its AppliedWork on the cluster.
+func findAppliedResources(ctx context.Context, c client.Reader, work *workv1alpha1.Work) ([]workv1alpha1.AppliedResourceMeta, error) {
+	appliedWorks := &workv1alpha1.AppliedWorkList{}
+	if err := c.List(ctx, appliedWorks); err != nil {
+		return nil, err
+	}
+	for _, appliedWork := range appliedWorks.Items {
+		if appliedWork.Spec.WorkNamespace == work.Namespace && appliedWork.Spec.WorkName == work.Name {
+			return appliedWork.Status.AppliedResources, nil
+		}
+	}
+	return nil, nil
+}
+
+// isPlanned returns true if an applied resource is one of the manifests, ignoring the ordinal and
+// the version as the same resource may be applied through another version.
+func isPlanned(identifier workv1alpha1.ResourceIdentifier, planned []workv1alpha1.ResourceIdentifier) bool {
+	identifier.Ordinal, identifier.Version, identifier.Kind = 0, "", ""
+	for _, candidate := range planned {
+		candidate.Ordinal, candidate.Version, candidate.Kind = 0, "", ""
+		if candidate == identifier {
+			return true
+		}
+	}
+	return false
+}
+
+// diffFields returns the fields of the required object which differ from the existing one. The
+// fields only set on the existing object, such as those defaulted by the server, are ignored,
+// and lists of the same length are compared item by item for the same reason.
+func diffFields(path string, existing, required interface{}) []FieldDiff {
+	switch required := required.(type) {
+	case map[string]interface{}:
+		existing, ok := existing.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(required))
+		for key := range required {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		diffs := []FieldDiff{}
+		for _, key := range keys {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+			if _, found := existing[key]; !found {
+				diffs = append(diffs, FieldDiff{Path: child, New: required[key]})
+				continue
+			}
+			diffs = append(diffs, diffFields(child, existing[key], required[key])...)
+		}
+		return diffs
+	case []interface{}:
+		existing, ok := existing.([]interface{})
+		if !ok || len(existing) != len(required) {
+			break
+		}
+		diffs := []FieldDiff{}
+		for i := range required {
+			diffs = append(diffs, diffFields(fmt.Sprintf("%s[%d]", path, i), existing[i], required[i])...)
+		}
+		return diffs
+	}
+	if equality.Semantic.DeepEqual(existing, required) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, Old: existing, New: required}}
+}
+
+// Count returns the number of changes with the action.
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// HasChanges returns true if applying the Work would create, update or delete resources.
+func (p *Plan) HasChanges() bool {
+	return p.Count(Create)+p.Count(Update)+p.Count(Delete) > 0
+}
+
+// Print writes the plan in a human readable form, one line per resource followed by the fields
+// of the updates, and the count of each action.
+func (p *Plan) Print(out io.Writer) error {
+	symbols := map[Action]string{Create: "+", Update: "~", Delete: "-", NoChange: " ", Skip: "!"}
+	verbs := map[Action]string{Create: "created", Update: "updated", Delete: "deleted", NoChange: "unchanged"}
+	for _, change := range p.Changes {
+		line := fmt.Sprintf("%s %s will be %s", symbols[change.Action], describe(change.Identifier), verbs[change.Action])
+		if change.Action == Skip {
+			line = fmt.Sprintf("%s %s is skipped: %s", symbols[Skip], describe(change.Identifier), change.Reason)
+		}
+		if _, err := fmt.Fprintln(out, line); err != nil {
+			return err
+		}
+		for _, diff := range change.Diffs {
+			if _, err := fmt.Fprintf(out, "    %s\n", formatDiff(diff)); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(out, "\nPlan: %d to create, %d to update, %d to delete, %d unchanged, %d skipped.\n",
+		p.Count(Create), p.Count(Update), p.Count(Delete), p.Count(NoChange), p.Count(Skip))
+	return err
+}
+
+// String returns the plan as written by Print.
+func (p *Plan) String() string {
+	builder := &strings.Builder{}
+	_ = p.Print(builder)
+	return builder.String()
+}
+
+// describe returns a human readable identity of a resource.
+func describe(identifier workv1alpha1.ResourceIdentifier) string {
+	kind := identifier.Kind
+	if kind == "" {
+		kind = identifier.Resource
+	}
+	if identifier.Group != "" {
+		kind += "." + identifier.Group
+	}
+	if identifier.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, identifier.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, identifier.Namespace, identifier.Name)
+}
+
+// formatDiff returns a field change as "path: old => new", or "+ path: new" for an added field.
+func formatDiff(diff FieldDiff) string {
+	if diff.Old == nil {
+		return fmt.Sprintf("+ %s: %s", diff.Path, formatValue(diff.New))
+	}
+	return fmt.Sprintf("~ %s: %s => %s", diff.Path, formatValue(diff.Old), formatValue(diff.New))
+}
+
+// formatValue returns the JSON of a field value.
+func formatValue(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(raw)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func newManifest(t *testing.T, obj runtime.Object) workv1alpha1.Manifest {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workv1alpha1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}
+}
+
+func newConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", UID: types.UID("uid-" + name)},
+		Data:       data,
+	}
+}
+
+func TestCompute(t *testing.T) {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "work",
+			Namespace:   "cluster1",
+			Annotations: map[string]string{workv1alpha1.SkipManifestsAnnotation: "ConfigMap/test/skipped"},
+		},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+			newManifest(t, newConfigMap("created", map[string]string{"key": "value"})),
+			newManifest(t, newConfigMap("updated", map[string]string{"key": "new", "added": "value"})),
+			newManifest(t, newConfigMap("unchanged", map[string]string{"key": "value"})),
+			newManifest(t, newConfigMap("skipped", map[string]string{"key": "value"})),
+		}}},
+	}
+	appliedWork := &workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "work"},
+		Spec:       workv1alpha1.AppliedWorkSpec{WorkNamespace: "cluster1", WorkName: "work"},
+		Status: workv1alpha1.AppliedtWorkStatus{AppliedResources: []workv1alpha1.AppliedResourceMeta{
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "test", Name: "updated"}, UID: "uid-updated"},
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "test", Name: "removed"}, UID: "uid-removed"},
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Resource: "configmaps", Namespace: "test", Name: "recreated"}, UID: "uid-old"},
+		}},
+	}
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newConfigMap("updated", map[string]string{"key": "old"}),
+		newConfigMap("unchanged", map[string]string{"key": "value", "extra": "value"}),
+		newConfigMap("removed", nil),
+		newConfigMap("recreated", nil),
+		appliedWork,
+	).Build()
+
+	plan, err := Compute(context.Background(), fakeClient, restMapper, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]Action{"created": Create, "updated": Update, "unchanged": NoChange, "skipped": Skip, "removed": Delete}
+	if len(plan.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), plan.Changes)
+	}
+	for _, change := range plan.Changes {
+		if action := expected[change.Identifier.Name]; change.Action != action {
+			t.Errorf("expected %s to be %s, got %s", change.Identifier.Name, action, change.Action)
+		}
+	}
+	diffs := plan.Changes[1].Diffs
+	if len(diffs) != 2 || diffs[0].Path != "data.added" || diffs[0].Old != nil ||
+		diffs[1].Path != "data.key" || diffs[1].Old != "old" || diffs[1].New != "new" {
+		t.Errorf("unexpected diffs %v", diffs)
+	}
+	if !plan.HasChanges() {
+		t.Error("expected the plan to have changes")
+	}
+
+	output := plan.String()
+	for _, line := range []string{
+		"+ ConfigMap test/created will be created",
+		`    ~ data.key: "old" => "new"`,
+		"- ConfigMap test/removed will be deleted",
+		"Plan: 1 to create, 1 to update, 1 to delete, 1 unchanged, 1 skipped.",
+	} {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected the output to contain %q, got\n%s", line, output)
+		}
+	}
+}
+
+func TestComputeGenerateName(t *testing.T) {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	generated := newConfigMap("", map[string]string{"key": "value"})
+	generated.GenerateName = "config-"
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1"},
+		Spec: workv1alpha1.WorkSpec{Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+			newManifest(t, generated),
+			newManifest(t, generated),
+		}}},
+	}
+	appliedWork := &workv1alpha1.AppliedWork{
+		ObjectMeta: metav1.ObjectMeta{Name: "work"},
+		Spec:       workv1alpha1.AppliedWorkSpec{WorkNamespace: "cluster1", WorkName: "work"},
+		Status: workv1alpha1.AppliedtWorkStatus{AppliedResources: []workv1alpha1.AppliedResourceMeta{
+			{ResourceIdentifier: workv1alpha1.ResourceIdentifier{Version: "v1", Kind: "ConfigMap", Resource: "configmaps", Namespace: "test", Name: "config-abcde"}, UID: "uid-config-abcde"},
+		}},
+	}
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the server keeps the generateName of the objects it named
+	existing := newConfigMap("config-abcde", map[string]string{"key": "value"})
+	existing.GenerateName = "config-"
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing, appliedWork).Build()
+
+	plan, err := Compute(context.Background(), fakeClient, restMapper, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", plan.Changes)
+	}
+	if change := plan.Changes[0]; change.Action != NoChange || change.Identifier.Name != "config-abcde" {
+		t.Errorf("expected the applied configmap to be unchanged, got %v", change)
+	}
+	if change := plan.Changes[1]; change.Action != Create || change.Identifier.Name != "" {
+		t.Errorf("expected a configmap to be created, got %v", change)
+	}
+}
+
+func TestComputeDefaultNamespace(t *testing.T) {
+	restMapper := meta.NewDefaultRESTMapper(nil)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+	restMapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, meta.RESTScopeRoot)
+
+	defaulted := newConfigMap("config", map[string]string{"key": "value"})
+	defaulted.Namespace = ""
+	namespace := &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: "created"},
+	}
+	work := &workv1alpha1.Work{
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1"},
+		Spec: workv1alpha1.WorkSpec{
+			Workload: workv1alpha1.WorkloadTemplate{Manifests: []workv1alpha1.Manifest{
+				newManifest(t, defaulted),
+				newManifest(t, namespace),
+			}},
+			DefaultNamespace: "test",
+		},
+	}
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newConfigMap("config", map[string]string{"key": "value"})).Build()
+
+	plan, err := Compute(context.Background(), fakeClient, restMapper, work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", plan.Changes)
+	}
+	if change := plan.Changes[0]; change.Action != NoChange || change.Identifier.Namespace != "test" {
+		t.Errorf("expected the configmap in the default namespace to be unchanged, got %v", change)
+	}
+	if change := plan.Changes[1]; change.Action != Create || change.Identifier.Namespace != "" {
+		t.Errorf("expected the namespace to be created without a namespace, got %v", change)
+	}
+}
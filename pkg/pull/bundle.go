@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pull lets an agent pull its Works as a signed bundle from a plain HTTP(S) endpoint, such
+// as object storage or a CDN, and put their status back, for topologies where no hub API is
+// exposed to the agents at all. The Works are mirrored into a namespace of the spoke cluster,
+// where the agent applies them.
+package pull
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+)
+
+// SignatureSuffix is appended to the URL of a bundle for the URL of its signature.
+const SignatureSuffix = ".sig"
+
+// Bundle is the set of Works of a cluster.
+type Bundle struct {
+	Cluster string `json:"cluster"`
+	// Serial increases with every bundle of the cluster, the agent rejects the bundles which are
+	// not newer than the one it mirrored last.
+	Serial int64 `json:"serial"`
+	// Expires is the time after which the agent rejects the bundle, so an older bundle cannot be
+	// replayed to an agent which restarted and forgot the serial it mirrored last.
+	Expires metav1.Time         `json:"expires"`
+	Works   []workv1alpha1.Work `json:"works"`
+}
+
+// StatusReport is the status of the Works of a cluster put back by its agent.
+type StatusReport struct {
+	Cluster string                      `json:"cluster"`
+	Works   []statusstream.StatusUpdate `json:"works"`
+}
+
+// NewBundle returns the bundle of the Works of a cluster, without the fields set by the hub API
+// server and the status.
+func NewBundle(cluster string, serial int64, expires time.Time, works []workv1alpha1.Work) *Bundle {
+	bundle := &Bundle{Cluster: cluster, Serial: serial, Expires: metav1.NewTime(expires), Works: []workv1alpha1.Work{}}
+	for _, work := range works {
+		if !work.DeletionTimestamp.IsZero() {
+			continue
+		}
+		bundle.Works = append(bundle.Works, workv1alpha1.Work{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        work.Name,
+				Labels:      work.Labels,
+				Annotations: work.Annotations,
+				Generation:  work.Generation,
+			},
+			Spec: work.Spec,
+		})
+	}
+	return bundle
+}
+
+// Sign returns the JSON of a bundle and its signature, the base64 encoded ed25519 signature of the
+// JSON.
+func Sign(bundle *Bundle, key ed25519.PrivateKey) ([]byte, []byte, error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, nil, err
+	}
+	signature := ed25519.Sign(key, data)
+	return data, []byte(base64.StdEncoding.EncodeToString(signature)), nil
+}
+
+// Verify checks the signature of the JSON of a bundle and decodes it.
+func Verify(data, signature []byte, key ed25519.PublicKey) (*Bundle, error) {
+	decoded, err := base64.StdEncoding.DecodeString(string(signature))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the signature of the bundle: %w", err)
+	}
+	if !ed25519.Verify(key, data, decoded) {
+		return nil, fmt.Errorf("the signature of the bundle is invalid")
+	}
+	bundle := &Bundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode the bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+// LoadPublicKey reads an ed25519 public key from a PEM file.
+func LoadPublicKey(file string) (ed25519.PublicKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 public key", file)
+	}
+	return publicKey, nil
+}
+
+// LoadPrivateKey reads an ed25519 private key from a PKCS #8 PEM file.
+func LoadPrivateKey(file string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 private key", file)
+	}
+	return privateKey, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", file)
+	}
+	return block, nil
+}
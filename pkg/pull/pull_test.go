@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/mirror"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func TestSync(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := NewBundle("cluster1", 1, time.Now().Add(time.Hour), []workv1alpha1.Work{
+		{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Generation: 4, ResourceVersion: "10", Labels: map[string]string{"app": "test"}}},
+	})
+	data, signature, err := Sign(bundle, privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report *StatusReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/status.json":
+			report = &StatusReport{}
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, report); err != nil {
+				t.Errorf("failed to decode the report: %v", err)
+			}
+		case r.URL.Path == "/bundle.json" && r.Header.Get("If-None-Match") == `"v1"`:
+			w.WriteHeader(http.StatusNotModified)
+		case r.URL.Path == "/bundle.json":
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write(data)
+		case r.URL.Path == "/bundle.json.sig":
+			_, _ = w.Write(signature)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: "works"}}
+	spokeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale).Build()
+	p := &Puller{client: spokeClient, log: ctrl.Log, opts: Options{
+		BundleURL:  server.URL + "/bundle.json",
+		StatusURL:  server.URL + "/status.json",
+		PublicKey:  publicKey,
+		Cluster:    "cluster1",
+		Namespace:  "works",
+		HTTPClient: server.Client(),
+	}}
+	ctx := context.Background()
+
+	if err := p.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mirrored := &workv1alpha1.Work{}
+	if err := spokeClient.Get(ctx, types.NamespacedName{Namespace: "works", Name: "work"}, mirrored); err != nil {
+		t.Fatalf("expected the work to be mirrored: %v", err)
+	}
+	if mirrored.Labels["app"] != "test" || mirrored.Annotations[mirror.HubGenerationAnnotation] != "4" {
+		t.Errorf("unexpected mirror metadata %v", mirrored.ObjectMeta)
+	}
+	if err := spokeClient.Get(ctx, types.NamespacedName{Namespace: "works", Name: "stale"}, stale); !errors.IsNotFound(err) {
+		t.Errorf("expected the work not in the bundle to be deleted, got %v", err)
+	}
+
+	// the status is reported with the generation of the hub, the bundle is not modified
+	mirrored.Status.Conditions = []metav1.Condition{{
+		Type: conditions.TypeApplied, Status: metav1.ConditionTrue, Reason: "Applied",
+		ObservedGeneration: mirrored.Generation, LastTransitionTime: metav1.Now(),
+	}}
+	if err := spokeClient.Status().Update(ctx, mirrored); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if report == nil || len(report.Works) != 1 || report.Works[0].Generation != 4 ||
+		!conditions.IsFresh(report.Works[0].Status.Conditions, conditions.TypeApplied, 4) {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, signature, err := Sign(NewBundle("cluster1", 1, time.Now().Add(time.Hour), nil), privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(data, signature, publicKey); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	data[0] = ' '
+	if _, err := Verify(data, signature, publicKey); err == nil {
+		t.Error("expected a tampered bundle to fail the verification")
+	}
+}
+
+func TestFetchRejectsReplayedBundles(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		serial    int64
+		expires   time.Time
+		wantErr   bool
+		wantFresh bool
+	}{
+		{name: "newer bundle", serial: 6, expires: time.Now().Add(time.Hour), wantFresh: true},
+		{name: "bundle last mirrored", serial: 5, expires: time.Now().Add(time.Hour)},
+		{name: "older bundle", serial: 4, expires: time.Now().Add(time.Hour), wantErr: true},
+		{name: "expired bundle", serial: 6, expires: time.Now().Add(-time.Minute), wantErr: true},
+		{name: "bundle without expiry", serial: 6, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			bundle := NewBundle("cluster1", c.serial, c.expires, nil)
+			data, signature, err := Sign(bundle, privateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/bundle.json.sig" {
+					_, _ = w.Write(signature)
+					return
+				}
+				_, _ = w.Write(data)
+			}))
+			defer server.Close()
+
+			p := &Puller{log: ctrl.Log, serial: 5, opts: Options{
+				BundleURL:  server.URL + "/bundle.json",
+				PublicKey:  publicKey,
+				Cluster:    "cluster1",
+				HTTPClient: server.Client(),
+			}}
+			fetched, _, err := p.fetch(context.Background())
+			if (err != nil) != c.wantErr {
+				t.Fatalf("expected error %t, got %v", c.wantErr, err)
+			}
+			if (fetched != nil) != c.wantFresh {
+				t.Errorf("expected a bundle to mirror %t, got %+v", c.wantFresh, fetched)
+			}
+		})
+	}
+}
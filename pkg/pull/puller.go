@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pull
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/mirror"
+	"sigs.k8s.io/work-api/pkg/statusstream"
+)
+
+const (
+	// maxBundleSize bounds the size of the bundles downloaded.
+	maxBundleSize = 64 * 1024 * 1024
+	// httpTimeout bounds the requests of the default HTTP client.
+	httpTimeout = 2 * time.Minute
+)
+
+// Options configures the pulling of the Works of a cluster.
+type Options struct {
+	// BundleURL is the URL of the bundle of the Works, its signature is at BundleURL with
+	// SignatureSuffix appended.
+	BundleURL string
+	// StatusURL is the URL the status report of the Works is put to, the status is not reported
+	// if empty.
+	StatusURL string
+	// PublicKey verifies the signature of the bundle.
+	PublicKey ed25519.PublicKey
+	// Cluster is the cluster of the agent, the bundle must be the one of the cluster.
+	Cluster string
+	// Namespace is the namespace of the spoke cluster holding the mirrors of the Works, the
+	// Works in the namespace which are not in the bundle are deleted.
+	Namespace string
+	// Interval is how often the bundle is pulled and the status reported.
+	Interval time.Duration
+	// HTTPClient sends the requests, a client timing out after two minutes if nil.
+	HTTPClient *http.Client
+}
+
+// Puller mirrors the Works of the bundle of a cluster into a namespace of the spoke cluster, and
+// reports their status.
+type Puller struct {
+	client client.Client
+	log    logr.Logger
+	opts   Options
+
+	// etag is the entity tag of the bundle last mirrored.
+	etag string
+	// serial is the serial of the bundle last mirrored.
+	serial int64
+	// lastReport is the status report last put.
+	lastReport []byte
+}
+
+// SetupWithManager pulls the Works while the manager runs. The manager must be the one of the
+// spoke cluster.
+func SetupWithManager(mgr ctrl.Manager, opts Options) error {
+	if opts.Cluster == "" || opts.Namespace == "" {
+		return fmt.Errorf("the cluster and the namespace of the works are required")
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: httpTimeout}
+	}
+	p := &Puller{
+		client: mgr.GetClient(),
+		log:    ctrl.Log.WithName("pull"),
+		opts:   opts,
+	}
+	return mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		wait.JitterUntilWithContext(ctx, func(ctx context.Context) {
+			if err := p.Sync(ctx); err != nil {
+				p.log.Error(err, "failed to pull works", "url", opts.BundleURL)
+			}
+		}, opts.Interval, 0.1, true)
+		return nil
+	}))
+}
+
+// Sync mirrors the bundle if it changed since the last sync, then reports the status.
+func (p *Puller) Sync(ctx context.Context) error {
+	bundle, etag, err := p.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if bundle != nil {
+		if err := p.mirror(ctx, bundle); err != nil {
+			return err
+		}
+		p.etag = etag
+		p.serial = bundle.Serial
+	}
+	return p.report(ctx)
+}
+
+// fetch downloads and verifies the bundle, it returns nil if it is not modified. A bundle which
+// expired or is older than the one last mirrored is rejected.
+func (p *Puller) fetch(ctx context.Context) (*Bundle, string, error) {
+	header := http.Header{}
+	if p.etag != "" {
+		header.Set("If-None-Match", p.etag)
+	}
+	data, etag, err := p.get(ctx, p.opts.BundleURL, header)
+	if err != nil || data == nil {
+		return nil, "", err
+	}
+	signature, _, err := p.get(ctx, p.opts.BundleURL+SignatureSuffix, http.Header{})
+	if err != nil {
+		return nil, "", err
+	}
+	bundle, err := Verify(data, bytes.TrimSpace(signature), p.opts.PublicKey)
+	if err != nil {
+		return nil, "", err
+	}
+	if bundle.Cluster != p.opts.Cluster {
+		return nil, "", fmt.Errorf("the bundle is the one of cluster %q, not %q", bundle.Cluster, p.opts.Cluster)
+	}
+	switch {
+	case bundle.Expires.IsZero():
+		return nil, "", fmt.Errorf("the bundle has no expiry")
+	case !time.Now().Before(bundle.Expires.Time):
+		return nil, "", fmt.Errorf("the bundle expired at %s", bundle.Expires.UTC().Format(time.RFC3339))
+	case bundle.Serial < p.serial:
+		return nil, "", fmt.Errorf("the bundle has serial %d, older than the serial %d last mirrored", bundle.Serial, p.serial)
+	case bundle.Serial == p.serial:
+		return nil, "", nil
+	}
+	return bundle, etag, nil
+}
+
+// get downloads a URL and returns its content and entity tag, or nil if it is not modified.
+func (p *Puller) get(ctx context.Context, url string, header http.Header) ([]byte, string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	request.Header = header
+	response, err := p.opts.HTTPClient.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, "", nil
+	default:
+		return nil, "", fmt.Errorf("unexpected status %s downloading %s", response.Status, url)
+	}
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxBundleSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxBundleSize {
+		return nil, "", fmt.Errorf("%s is larger than %d bytes", url, maxBundleSize)
+	}
+	return data, response.Header.Get("ETag"), nil
+}
+
+// mirror creates or updates the mirrors of the Works of the bundle, and deletes the others.
+func (p *Puller) mirror(ctx context.Context, bundle *Bundle) error {
+	names := map[string]bool{}
+	errs := []error{}
+	for i := range bundle.Works {
+		work := &bundle.Works[i]
+		names[work.Name] = true
+		changed, err := mirror.Apply(ctx, p.client, p.opts.Namespace, work)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if changed {
+			p.log.V(2).Info("mirrored work", "work", work.Name, "generation", work.Generation)
+		}
+	}
+
+	mirrors := &workv1alpha1.WorkList{}
+	if err := p.client.List(ctx, mirrors, client.InNamespace(p.opts.Namespace)); err != nil {
+		return err
+	}
+	for i := range mirrors.Items {
+		work := &mirrors.Items[i]
+		if names[work.Name] || !work.DeletionTimestamp.IsZero() {
+			continue
+		}
+		p.log.Info("deleting work no longer in the bundle", "work", client.ObjectKeyFromObject(work))
+		if err := p.client.Delete(ctx, work); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// report puts the status of the mirrors if it changed since the last report.
+func (p *Puller) report(ctx context.Context) error {
+	if p.opts.StatusURL == "" {
+		return nil
+	}
+	mirrors := &workv1alpha1.WorkList{}
+	if err := p.client.List(ctx, mirrors, client.InNamespace(p.opts.Namespace)); err != nil {
+		return err
+	}
+	report := &StatusReport{Cluster: p.opts.Cluster, Works: []statusstream.StatusUpdate{}}
+	for i := range mirrors.Items {
+		generation, status, ok := mirror.HubStatus(&mirrors.Items[i])
+		if !ok {
+			continue
+		}
+		report.Works = append(report.Works, statusstream.StatusUpdate{
+			Cluster:    p.opts.Cluster,
+			Name:       mirrors.Items[i].Name,
+			Generation: generation,
+			Status:     *status,
+		})
+	}
+	sort.Slice(report.Works, func(i, j int) bool { return report.Works[i].Name < report.Works[j].Name })
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, p.lastReport) {
+		return nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, p.opts.StatusURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := p.opts.HTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s putting the status report to %s", response.Status, p.opts.StatusURL)
+	}
+	p.lastReport = data
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reasons defines the reasons of the conditions set by the work controllers. Consumers
+// of the Work and WorkSet status should compare condition reasons with these constants, or use
+// the predicates, rather than with string literals.
+package reasons
+
+import (
+	"strings"
+
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Reason is the reason of a condition set by the work controllers.
+type Reason string
+
+// Reasons of the manifest conditions of a Work, set by the agent.
+const (
+	// AppliedManifestComplete is the reason of a true Applied condition of a manifest.
+	AppliedManifestComplete Reason = "AppliedManifestComplete"
+	// AppliedManifestFailed is the reason of a false Applied condition of a manifest.
+	AppliedManifestFailed Reason = "AppliedManifestFailed"
+	// ManifestAvailable is the reason of a true Available condition of a manifest.
+	ManifestAvailable Reason = "ManifestAvailable"
+	// ManifestNotAvailable is the reason of a false Available condition of a manifest.
+	ManifestNotAvailable Reason = "ManifestNotAvailable"
+	// ManifestSkipped is the reason of the Skipped condition of a manifest listed in the
+	// skip-manifests annotation of the Work.
+	ManifestSkipped Reason = "ManifestSkipped"
+	// UnmanagedAnnotation is the reason of the ExternallyManaged condition of a manifest whose
+	// resource is annotated as unmanaged on the spoke cluster.
+	UnmanagedAnnotation Reason = "UnmanagedAnnotation"
+	// RecreatedResourceAdopted is the reason of the ResourceRecreated condition of a manifest whose
+	// resource, recreated by someone else, was adopted.
+	RecreatedResourceAdopted Reason = "RecreatedResourceAdopted"
+	// RecreatedResourceReapplied is the reason of the ResourceRecreated condition of a manifest
+	// whose resource, recreated by someone else, was deleted and created again.
+	RecreatedResourceReapplied Reason = "RecreatedResourceReapplied"
+	// RecreatedResourceLeft is the reason of the ResourceRecreated condition of a manifest whose
+	// resource, recreated by someone else, is left untouched.
+	RecreatedResourceLeft Reason = "RecreatedResourceLeft"
+	// FieldManagersOverwritten is the reason of the ManagedByConflict condition of a manifest whose
+	// resource had fields of other field managers overwritten.
+	FieldManagersOverwritten Reason = "FieldManagersOverwritten"
+	// ManifestComplete is the reason of a true Complete condition of a manifest.
+	ManifestComplete Reason = "ManifestComplete"
+	// ManifestIncomplete is the reason of a false Complete condition of a manifest whose Job or
+	// CronJob did not complete yet.
+	ManifestIncomplete Reason = "ManifestIncomplete"
+	// ManifestFailed is the reason of a false Complete condition of a manifest whose Job failed.
+	ManifestFailed Reason = "ManifestFailed"
+	// ManifestDegraded is the reason of a true Degraded condition of a manifest whose resource has
+	// not been available for a while.
+	ManifestDegraded Reason = "ManifestDegraded"
+	// ResourcePruneFailed is the reason of the false Pruned condition of a manifest removed from
+	// the Work whose resource failed to be deleted.
+	ResourcePruneFailed Reason = "ResourcePruneFailed"
+)
+
+// Reasons of the Accepted condition of a WorkAgentConfig, set by the agent.
+const (
+	// AgentConfigApplied is the reason of a true Accepted condition of a WorkAgentConfig.
+	AgentConfigApplied Reason = "AgentConfigApplied"
+	// AgentConfigInvalid is the reason of a false Accepted condition of a WorkAgentConfig, e.g.
+	// with an unknown feature gate.
+	AgentConfigInvalid Reason = "AgentConfigInvalid"
+)
+
+// Reasons of the conditions of a Work, set by the agent.
+const (
+	// AppliedWorkComplete is the reason of a true Applied condition of a Work.
+	AppliedWorkComplete Reason = "AppliedWorkComplete"
+	// AppliedWorkFailed is the reason of a false Applied condition of a Work.
+	AppliedWorkFailed Reason = "AppliedWorkFailed"
+	// WorkAvailable is the reason of a true Available condition of a Work.
+	WorkAvailable Reason = "WorkAvailable"
+	// WorkNotAvailable is the reason of a false Available condition of a Work.
+	WorkNotAvailable Reason = "WorkNotAvailable"
+	// WorkDegraded is the reason of a true Degraded condition of a Work.
+	WorkDegraded Reason = "WorkDegraded"
+	// WorkComplete is the reason of a true Complete condition of a Work.
+	WorkComplete Reason = "WorkComplete"
+	// WorkIncomplete is the reason of a false Complete condition of a Work whose Jobs or CronJobs
+	// did not all complete yet.
+	WorkIncomplete Reason = "WorkIncomplete"
+	// WorkFailed is the reason of a false Complete condition of a Work with a failed Job.
+	WorkFailed Reason = "WorkFailed"
+	// ManifestsExternallyManaged is the reason of the ExternallyManaged condition of a Work.
+	ManifestsExternallyManaged Reason = "ManifestsExternallyManaged"
+	// ResourcesTerminating is the reason of the DeletionBlocked condition of a deleted Work whose
+	// resources are still terminating on the spoke cluster, e.g. held by finalizers.
+	ResourcesTerminating Reason = "ResourcesTerminating"
+	// ResourcesDeleteFailed is the reason of the DeletionBlocked condition of a deleted Work whose
+	// resources failed to be deleted from the spoke cluster.
+	ResourcesDeleteFailed Reason = "ResourcesDeleteFailed"
+)
+
+// Reasons of the conditions of a WorkSet, set by the hub.
+const (
+	// SyncWorksComplete is the reason of a true Synced condition of a WorkSet.
+	SyncWorksComplete Reason = "SyncWorksComplete"
+	// SyncWorksFailed is the reason of a false Synced condition of a WorkSet.
+	SyncWorksFailed Reason = "SyncWorksFailed"
+	// PlacementDisabled is the reason of a false Synced condition of a WorkSet referring to a
+	// Placement while the hub controller runs without --enable-placement.
+	PlacementDisabled Reason = "PlacementDisabled"
+	// ConditionSummariesInvalid is the reason of a false Synced condition of a WorkSet summarizing
+	// a condition type reserved for the WorkSet itself.
+	ConditionSummariesInvalid Reason = "ConditionSummariesInvalid"
+	// RolloutInProgress is the reason of a true RolloutProgressing condition of a WorkSet.
+	RolloutInProgress Reason = "RolloutInProgress"
+	// RolloutComplete is the reason of a false RolloutProgressing condition of a WorkSet whose
+	// Works are rolled out to all selected clusters.
+	RolloutComplete Reason = "RolloutComplete"
+	// RolloutHalted is the reason of a false RolloutProgressing condition of a WorkSet whose
+	// rollout stopped after too many clusters failed.
+	RolloutHalted Reason = "RolloutHalted"
+)
+
+// Reasons of the conditions aggregated over the Works split from an oversized Work.
+const (
+	// WorkGroupComplete is the reason of a condition true on every Work of a group.
+	WorkGroupComplete Reason = "WorkGroupComplete"
+	// WorkGroupFailed is the reason of a condition false on a Work of a group.
+	WorkGroupFailed Reason = "WorkGroupFailed"
+	// WorkGroupIncomplete is the reason of a condition not yet reported by every Work of a group.
+	WorkGroupIncomplete Reason = "WorkGroupIncomplete"
+)
+
+const (
+	policySatisfiedSuffix    = "PolicySatisfied"
+	policyNotSatisfiedSuffix = "PolicyNotSatisfied"
+)
+
+// PolicySatisfied returns the reason of a true summarized condition of a WorkSet.
+func PolicySatisfied(policy workv1alpha1.SummaryPolicyType) Reason {
+	return Reason(string(policy) + policySatisfiedSuffix)
+}
+
+// PolicyNotSatisfied returns the reason of a false summarized condition of a WorkSet.
+func PolicyNotSatisfied(policy workv1alpha1.SummaryPolicyType) Reason {
+	return Reason(string(policy) + policyNotSatisfiedSuffix)
+}
+
+// IsFailure returns true if the reason reports a failure, which needs an action of the owner of
+// the Work or WorkSet, or of the spoke cluster admin.
+func (r Reason) IsFailure() bool {
+	switch r {
+	case AppliedManifestFailed, AppliedWorkFailed, ManifestFailed, WorkFailed, ResourcePruneFailed, ResourcesDeleteFailed, ManifestDegraded, WorkDegraded, AgentConfigInvalid, SyncWorksFailed, PlacementDisabled, ConditionSummariesInvalid, RolloutHalted, WorkGroupFailed:
+		return true
+	}
+	return strings.HasSuffix(string(r), policyNotSatisfiedSuffix)
+}
+
+// IsSuccess returns true if the reason reports that the controllers completed their work.
+func (r Reason) IsSuccess() bool {
+	switch r {
+	case AppliedManifestComplete, AppliedWorkComplete, ManifestAvailable, WorkAvailable, ManifestComplete, WorkComplete, AgentConfigApplied, SyncWorksComplete, RolloutComplete, WorkGroupComplete:
+		return true
+	}
+	return strings.HasSuffix(string(r), policySatisfiedSuffix) && !strings.HasSuffix(string(r), policyNotSatisfiedSuffix)
+}
+
+// IsKnown returns true if the reason is set by the work controllers.
+func (r Reason) IsKnown() bool {
+	switch r {
+	case ManifestSkipped, UnmanagedAnnotation, ManifestsExternallyManaged, RolloutInProgress, WorkGroupIncomplete,
+		RecreatedResourceAdopted, RecreatedResourceReapplied, RecreatedResourceLeft, FieldManagersOverwritten,
+		ManifestNotAvailable, WorkNotAvailable, ManifestIncomplete, WorkIncomplete, ResourcesTerminating:
+		return true
+	}
+	return r.IsFailure() || r.IsSuccess()
+}
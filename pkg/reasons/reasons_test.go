@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reasons
+
+import (
+	"testing"
+
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestReasonPredicates(t *testing.T) {
+	cases := []struct {
+		reason          Reason
+		expectedFailure bool
+		expectedSuccess bool
+		expectedKnown   bool
+	}{
+		{reason: AppliedWorkComplete, expectedSuccess: true, expectedKnown: true},
+		{reason: AppliedManifestFailed, expectedFailure: true, expectedKnown: true},
+		{reason: RolloutHalted, expectedFailure: true, expectedKnown: true},
+		{reason: PlacementDisabled, expectedFailure: true, expectedKnown: true},
+		{reason: ConditionSummariesInvalid, expectedFailure: true, expectedKnown: true},
+		{reason: RolloutInProgress, expectedKnown: true},
+		{reason: RecreatedResourceLeft, expectedKnown: true},
+		{reason: WorkAvailable, expectedSuccess: true, expectedKnown: true},
+		{reason: ManifestNotAvailable, expectedKnown: true},
+		{reason: WorkFailed, expectedFailure: true, expectedKnown: true},
+		{reason: WorkIncomplete, expectedKnown: true},
+		{reason: WorkDegraded, expectedFailure: true, expectedKnown: true},
+		{reason: ResourcesTerminating, expectedKnown: true},
+		{reason: ResourcesDeleteFailed, expectedFailure: true, expectedKnown: true},
+		{reason: AgentConfigInvalid, expectedFailure: true, expectedKnown: true},
+		{reason: ResourcePruneFailed, expectedFailure: true, expectedKnown: true},
+		{reason: PolicySatisfied(workv1alpha1.SummaryPolicyAll), expectedSuccess: true, expectedKnown: true},
+		{reason: PolicyNotSatisfied(workv1alpha1.SummaryPolicyAny), expectedFailure: true, expectedKnown: true},
+		{reason: "IncompletedResourceMeta"},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.reason), func(t *testing.T) {
+			if actual := c.reason.IsFailure(); actual != c.expectedFailure {
+				t.Errorf("expected IsFailure %v, got %v", c.expectedFailure, actual)
+			}
+			if actual := c.reason.IsSuccess(); actual != c.expectedSuccess {
+				t.Errorf("expected IsSuccess %v, got %v", c.expectedSuccess, actual)
+			}
+			if actual := c.reason.IsKnown(); actual != c.expectedKnown {
+				t.Errorf("expected IsKnown %v, got %v", c.expectedKnown, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusstream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/types"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// Client streams the status of the Works of an agent to the hub. Send never blocks: the latest
+// status of each Work is kept until it is sent, and the stream is reopened with a backoff when it
+// fails.
+type Client struct {
+	conn grpc.ClientConnInterface
+	log  logr.Logger
+
+	lock sync.Mutex
+	// pending holds the latest status of each Work not sent yet.
+	pending map[types.NamespacedName]*StatusUpdate
+	notify  chan struct{}
+}
+
+// NewClient returns a Client streaming over a connection to the hub.
+func NewClient(conn grpc.ClientConnInterface, log logr.Logger) *Client {
+	return &Client{
+		conn:    conn,
+		log:     log,
+		pending: map[types.NamespacedName]*StatusUpdate{},
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Send queues the status of a Work, it replaces a status of the Work not sent yet.
+func (c *Client) Send(work *workv1alpha1.Work) {
+	update := &StatusUpdate{
+		Cluster:    work.Namespace,
+		Name:       work.Name,
+		Generation: work.Generation,
+		Status:     *work.Status.DeepCopy(),
+	}
+	c.requeue(update, true)
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// requeue records an update as pending, unless replace is false and a newer one is pending.
+func (c *Client) requeue(update *StatusUpdate, replace bool) {
+	key := types.NamespacedName{Namespace: update.Cluster, Name: update.Name}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.pending[key]; ok && !replace {
+		return
+	}
+	c.pending[key] = update
+}
+
+// takePending returns the pending updates and forgets them.
+func (c *Client) takePending() []*StatusUpdate {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	updates := make([]*StatusUpdate, 0, len(c.pending))
+	for key, update := range c.pending {
+		updates = append(updates, update)
+		delete(c.pending, key)
+	}
+	return updates
+}
+
+// Start sends the queued updates until the context is done, then closes the stream.
+func (c *Client) Start(ctx context.Context) error {
+	var stream grpc.ClientStream
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			if stream != nil {
+				_ = stream.CloseSend()
+			}
+			return nil
+		case <-c.notify:
+		}
+
+		updates := c.takePending()
+		for i, update := range updates {
+			var err error
+			if stream == nil {
+				// the stream outlives the context of a single send
+				stream, err = c.conn.NewStream(ctx, &streamDesc, "/"+serviceName+"/"+methodName, grpc.CallContentSubtype(codec{}.Name()))
+			}
+			if err == nil {
+				err = stream.SendMsg(update)
+			}
+			if err != nil {
+				c.log.Error(err, "failed to stream work status", "work", types.NamespacedName{Namespace: update.Cluster, Name: update.Name})
+				stream = nil
+				for _, unsent := range updates[i:] {
+					c.requeue(unsent, false)
+				}
+				failures++
+				c.retryAfter(ctx, retryDelay(failures))
+				break
+			}
+			failures = 0
+		}
+	}
+}
+
+// retryDelay returns the delay before the stream is reopened after consecutive failures, doubling
+// from a second up to maxRetryDelay.
+func retryDelay(failures int) time.Duration {
+	if failures > 7 {
+		return maxRetryDelay
+	}
+	if delay := time.Second << (failures - 1); delay < maxRetryDelay {
+		return delay
+	}
+	return maxRetryDelay
+}
+
+// maxRetryDelay bounds the delay before the stream is reopened.
+const maxRetryDelay = 2 * time.Minute
+
+// retryAfter notifies the sending loop again after the delay.
+func (c *Client) retryAfter(ctx context.Context, delay time.Duration) {
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+			select {
+			case c.notify <- struct{}{}:
+			default:
+			}
+		}
+	}()
+}
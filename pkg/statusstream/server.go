@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusstream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// serverWorkers is the number of Work statuses written to the hub concurrently.
+const serverWorkers = 4
+
+// statusFieldManager is the field manager the status of the Works is written as, the one of the
+// agents it is streamed from.
+const statusFieldManager = "work-agent"
+
+// Server receives the status streams of the agents and applies the latest status of each Work.
+// An agent must present a verified client certificate, and may only update the Works of the
+// cluster namespace named by its common name. The streams without one are rejected, unless the
+// server is insecure, e.g. for development.
+type Server struct {
+	client client.Client
+	log    logr.Logger
+	queue  workqueue.RateLimitingInterface
+	// insecure accepts the streams without a verified client certificate, for any cluster.
+	insecure bool
+
+	lock sync.Mutex
+	// pending holds the latest update of each Work not written yet.
+	pending map[types.NamespacedName]*StatusUpdate
+}
+
+// NewServer returns a Server writing the status of the Works with the client of the hub. The
+// streams without a verified client certificate are accepted if insecure.
+func NewServer(c client.Client, log logr.Logger, insecure bool) *Server {
+	return &Server{
+		client:   c,
+		log:      log,
+		queue:    workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "statusstream"),
+		insecure: insecure,
+		pending:  map[types.NamespacedName]*StatusUpdate{},
+	}
+}
+
+// Serve listens on the address and applies the received updates until the context is done. The
+// connections are encrypted if tlsConfig is set.
+func (s *Server) Serve(ctx context.Context, addr string, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	options := []grpc.ServerOption{}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	server := grpc.NewServer(options...)
+	s.Register(server)
+
+	var wg sync.WaitGroup
+	for i := 0; i < serverWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for s.processNext(ctx) {
+			}
+		}()
+	}
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+		s.queue.ShutDown()
+	}()
+
+	s.log.Info("serving status streams", "addr", addr)
+	err = server.Serve(listener)
+	wg.Wait()
+	return err
+}
+
+// Register registers the status service on a gRPC server.
+func (s *Server) Register(server *grpc.Server) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*interface{})(nil),
+		Streams: []grpc.StreamDesc{{
+			StreamName: methodName,
+			Handler: func(_ interface{}, stream grpc.ServerStream) error {
+				return s.streamStatus(stream)
+			},
+			ClientStreams: true,
+		}},
+	}, s)
+}
+
+// streamStatus queues the updates of a stream until the agent closes it.
+func (s *Server) streamStatus(stream grpc.ServerStream) error {
+	summary := &StreamSummary{}
+	for {
+		update := &StatusUpdate{}
+		err := stream.RecvMsg(update)
+		if err == io.EOF {
+			return stream.SendMsg(summary)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.authorize(stream.Context(), update.Cluster); err != nil {
+			return err
+		}
+		summary.Received++
+		s.enqueue(update)
+	}
+}
+
+// authorize checks that the client certificate of the agent is the one of the cluster. The
+// agents without a verified client certificate are only accepted by an insecure server.
+func (s *Server) authorize(ctx context.Context, cluster string) error {
+	var tlsInfo credentials.TLSInfo
+	if p, ok := peer.FromContext(ctx); ok {
+		tlsInfo, _ = p.AuthInfo.(credentials.TLSInfo)
+	}
+	if len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		if s.insecure {
+			return nil
+		}
+		return status.Errorf(codes.Unauthenticated, "a verified client certificate is required to update the works of cluster %s", cluster)
+	}
+	if commonName := tlsInfo.State.VerifiedChains[0][0].Subject.CommonName; commonName != cluster {
+		return status.Errorf(codes.PermissionDenied, "agent %s cannot update the works of cluster %s", commonName, cluster)
+	}
+	return nil
+}
+
+// enqueue records the update as the latest of its Work, it replaces an update not written yet.
+func (s *Server) enqueue(update *StatusUpdate) {
+	key := types.NamespacedName{Namespace: update.Cluster, Name: update.Name}
+	s.lock.Lock()
+	s.pending[key] = update
+	s.lock.Unlock()
+	s.queue.Add(key)
+}
+
+// processNext writes the latest update of the next Work of the queue.
+func (s *Server) processNext(ctx context.Context) bool {
+	item, shutdown := s.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer s.queue.Done(item)
+	key := item.(types.NamespacedName)
+
+	s.lock.Lock()
+	update := s.pending[key]
+	delete(s.pending, key)
+	s.lock.Unlock()
+	if update == nil {
+		s.queue.Forget(item)
+		return true
+	}
+
+	if err := ApplyStatus(ctx, s.client, update); err != nil {
+		s.log.Error(err, "failed to update work status", "work", key)
+		// retry the update unless a newer one arrived meanwhile
+		s.lock.Lock()
+		if _, ok := s.pending[key]; !ok {
+			s.pending[key] = update
+		}
+		s.lock.Unlock()
+		s.queue.AddRateLimited(item)
+		return true
+	}
+	s.queue.Forget(item)
+	return true
+}
+
+// ApplyStatus writes the status of an update to its Work on the hub. The update of another
+// generation of the Work is dropped, the agent sends the status of the current one later.
+func ApplyStatus(ctx context.Context, c client.Client, update *StatusUpdate) error {
+	work := &workv1alpha1.Work{}
+	err := c.Get(ctx, types.NamespacedName{Namespace: update.Cluster, Name: update.Name}, work)
+	switch {
+	case errors.IsNotFound(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	if work.Generation != update.Generation || equality.Semantic.DeepEqual(work.Status, update.Status) {
+		return nil
+	}
+	original := work.DeepCopy()
+	work.Status = update.Status
+	if err := c.Status().Patch(ctx, work, client.MergeFrom(original), client.FieldOwner(statusFieldManager)); err != nil {
+		return fmt.Errorf("failed to update the status of generation %d: %w", update.Generation, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statusstream
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+	"sigs.k8s.io/work-api/pkg/conditions"
+	"sigs.k8s.io/work-api/pkg/worktest"
+)
+
+func TestStreamStatus(t *testing.T) {
+	scheme, err := worktest.NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	work := &workv1alpha1.Work{ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1", Generation: 2}}
+	hubClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(work).Build()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the connection is not encrypted, the server accepts the agent without a client certificate
+	s := NewServer(hubClient, ctrl.Log, true)
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	s.Register(server)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+	go func() {
+		for s.processNext(ctx) {
+		}
+	}()
+	defer s.queue.ShutDown()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	c := NewClient(conn, ctrl.Log)
+	go func() { _ = c.Start(ctx) }()
+
+	// the status of an older generation is dropped, the latest status of the work is applied
+	stale := work.DeepCopy()
+	stale.Generation = 1
+	stale.Status.Conditions = []metav1.Condition{{Type: conditions.TypeApplied, Status: metav1.ConditionFalse, Reason: "Failed", ObservedGeneration: 1}}
+	c.Send(stale)
+	reported := work.DeepCopy()
+	reported.Status.Conditions = []metav1.Condition{{Type: conditions.TypeApplied, Status: metav1.ConditionTrue, Reason: "Applied", ObservedGeneration: 2}}
+	c.Send(reported)
+
+	err = wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		current := &workv1alpha1.Work{}
+		if err := hubClient.Get(ctx, client.ObjectKeyFromObject(work), current); err != nil {
+			return false, err
+		}
+		return conditions.IsApplied(current.Status.Conditions) && conditions.IsFresh(current.Status.Conditions, conditions.TypeApplied, 2), nil
+	})
+	if err != nil {
+		t.Fatalf("expected the streamed status to be applied: %v", err)
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	verified := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "cluster1"}}}},
+	}}})
+	unverified := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	cases := []struct {
+		name     string
+		ctx      context.Context
+		insecure bool
+		cluster  string
+		expected codes.Code
+	}{
+		{name: "certificate of the cluster", ctx: verified, cluster: "cluster1", expected: codes.OK},
+		{name: "certificate of another cluster", ctx: verified, cluster: "cluster2", expected: codes.PermissionDenied},
+		{name: "no client certificate", ctx: unverified, cluster: "cluster1", expected: codes.Unauthenticated},
+		{name: "no peer", ctx: context.Background(), cluster: "cluster1", expected: codes.Unauthenticated},
+		{name: "no client certificate, insecure", ctx: unverified, insecure: true, cluster: "cluster1", expected: codes.OK},
+		{name: "certificate of another cluster, insecure", ctx: verified, insecure: true, cluster: "cluster2", expected: codes.PermissionDenied},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := NewServer(nil, ctrl.Log, c.insecure)
+			if code := status.Code(s.authorize(c.ctx, c.cluster)); code != c.expected {
+				t.Errorf("expected %s, got %s", c.expected, code)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for failures, expected := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 8: maxRetryDelay, 100: maxRetryDelay} {
+		if delay := retryDelay(failures); delay != expected {
+			t.Errorf("expected a delay of %s after %d failures, got %s", expected, failures, delay)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package statusstream streams the status of the Works from the agents to a gRPC endpoint of the
+// hub, which applies them to the Works. It spares the kube-apiserver of the hub the status
+// requests of every agent of a large fleet: the agents hold one stream each, and the hub
+// coalesces the updates of a Work before writing its status.
+package statusstream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// StatusUpdate is the status of a Work sent by an agent. The cluster is the namespace of the Work.
+type StatusUpdate struct {
+	Cluster    string                  `json:"cluster"`
+	Name       string                  `json:"name"`
+	Generation int64                   `json:"generation"`
+	Status     workv1alpha1.WorkStatus `json:"status"`
+}
+
+// StreamSummary is the response of the hub when an agent closes its stream.
+type StreamSummary struct {
+	Received int64 `json:"received"`
+}
+
+const (
+	serviceName = "work.multicluster.x-k8s.io.v1alpha1.StatusService"
+	methodName  = "StreamStatus"
+)
+
+// codec encodes the messages as JSON, the types of the work API have no protobuf definitions.
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (codec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (codec) Name() string                               { return "json" }
+
+func init() {
+	encoding.RegisterCodec(codec{})
+}
+
+// streamDesc describes the client streaming method of the service.
+var streamDesc = grpc.StreamDesc{
+	StreamName:    methodName,
+	ClientStreams: true,
+}
+
+// LoadTLSConfig returns the TLS config of a server or a client from PEM files. The certificates of
+// the peers are verified against the CA if set, for a server this requires client certificates.
+func LoadTLSConfig(certFile, keyFile, caFile string, server bool) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if caFile == "" {
+		return config, nil
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate found in %s", caFile)
+	}
+	if server {
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		config.RootCAs = pool
+	}
+	return config, nil
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// caValidity and servingCertValidity are how long the self-signed CA and serving
+	// certificate are valid. They are rotated when less than a third of it remains.
+	caValidity          = 10 * 365 * 24 * time.Hour
+	servingCertValidity = 365 * 24 * time.Hour
+
+	// certCheckInterval is how often the certificates are checked for a rotation.
+	certCheckInterval = time.Hour
+
+	// The keys of the certificates in the Secret, the serving certificate and key are also the
+	// file names the webhook server reads.
+	caCertKey      = "ca.crt"
+	caKeyKey       = "ca.key"
+	servingCertKey = "tls.crt"
+	servingKeyKey  = "tls.key"
+)
+
+// CertOptions configures the self-signed certificates of the webhook server.
+type CertOptions struct {
+	// SecretNamespace and SecretName are the Secret the CA and the serving certificate are kept
+	// in, so all the replicas of the hub controller serve the same certificate.
+	SecretNamespace string
+	SecretName      string
+	// ServiceName is the Service of the webhook server, in SecretNamespace.
+	ServiceName string
+	// ValidatingWebhookConfigurations are the configurations whose CA bundle is patched.
+	ValidatingWebhookConfigurations []string
+}
+
+// certProvisioner provisions a self-signed CA and the serving certificate of the webhook server
+// it issues, writes them to the cert dir of the server and patches the CA bundle of the webhook
+// configurations. The certificates are rotated before they expire, the previous CA is kept in the
+// CA bundle until it expires so the certificates it issued are still trusted meanwhile.
+type certProvisioner struct {
+	reader  client.Reader
+	client  client.Client
+	log     logr.Logger
+	opts    CertOptions
+	certDir string
+}
+
+// SetupCertsWithManager provisions the certificates of the webhook server of the manager before
+// it starts, and rotates them while the manager runs.
+func SetupCertsWithManager(ctx context.Context, mgr ctrl.Manager, opts CertOptions) error {
+	server := mgr.GetWebhookServer()
+	if server.CertDir == "" {
+		server.CertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	p := &certProvisioner{
+		reader:  mgr.GetAPIReader(),
+		client:  mgr.GetClient(),
+		log:     ctrl.Log.WithName("webhooks").WithName("certs"),
+		opts:    opts,
+		certDir: server.CertDir,
+	}
+	// the webhook server reads the certificates when it starts
+	if err := p.sync(ctx); err != nil {
+		return err
+	}
+	return mgr.Add(p)
+}
+
+// Start rotates the certificates until the context is done.
+func (p *certProvisioner) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := p.sync(ctx); err != nil {
+			p.log.Error(err, "unable to sync the webhook certificates")
+		}
+	}, certCheckInterval)
+	return nil
+}
+
+// NeedLeaderElection is false, every replica writes the certificates it serves.
+func (p *certProvisioner) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *certProvisioner) sync(ctx context.Context) error {
+	key := types.NamespacedName{Namespace: p.opts.SecretNamespace, Name: p.opts.SecretName}
+	secret := &corev1.Secret{}
+	err := p.reader.Get(ctx, key, secret)
+	switch {
+	case errors.IsNotFound(err):
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: p.opts.SecretNamespace, Name: p.opts.SecretName},
+			Type:       corev1.SecretTypeTLS,
+		}
+		if err := p.rotate(secret, time.Now()); err != nil {
+			return err
+		}
+		err := p.client.Create(ctx, secret)
+		if errors.IsAlreadyExists(err) {
+			// another replica created the secret first, its certificates are used
+			secret = &corev1.Secret{}
+			err = p.reader.Get(ctx, key, secret)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create secret %s: %w", p.opts.SecretName, err)
+		}
+	case err != nil:
+		return err
+	case needsRotation(secret.Data[caCertKey], caValidity) || needsRotation(secret.Data[servingCertKey], servingCertValidity):
+		if err := p.rotate(secret, time.Now()); err != nil {
+			return err
+		}
+		// another replica rotating at the same time conflicts, its certificates are used next time
+		if err := p.client.Update(ctx, secret); err != nil {
+			return fmt.Errorf("failed to update secret %s: %w", p.opts.SecretName, err)
+		}
+	}
+
+	// the new CA is trusted before the certificate it issued is served
+	if err := p.patchCABundle(ctx, secret.Data[caCertKey]); err != nil {
+		return err
+	}
+	return p.writeCerts(secret)
+}
+
+// rotate issues a new serving certificate into the secret, and a new CA if it expires soon.
+func (p *certProvisioner) rotate(secret *corev1.Secret, now time.Time) error {
+	caCert, caKey, err := parseCA(secret.Data[caCertKey], secret.Data[caKeyKey])
+	if err != nil || needsRotation(secret.Data[caCertKey], caValidity) {
+		p.log.Info("issuing a new webhook CA")
+		caPEM, caKeyPEM, err := newCertificate(pkix.Name{CommonName: "work-webhook-ca"}, nil, true, now, caValidity, nil, nil)
+		if err != nil {
+			return err
+		}
+		if caCert, caKey, err = parseCA(caPEM, caKeyPEM); err != nil {
+			return err
+		}
+		secret.Data = map[string][]byte{
+			caCertKey: append(caPEM, validCerts(secret.Data[caCertKey], now)...),
+			caKeyKey:  caKeyPEM,
+		}
+	}
+
+	service := fmt.Sprintf("%s.%s", p.opts.ServiceName, p.opts.SecretNamespace)
+	dnsNames := []string{p.opts.ServiceName, service, service + ".svc", service + ".svc.cluster.local"}
+	p.log.Info("issuing a new webhook serving certificate", "dnsNames", dnsNames)
+	certPEM, keyPEM, err := newCertificate(pkix.Name{CommonName: service + ".svc"}, dnsNames, false, now, servingCertValidity, caCert, caKey)
+	if err != nil {
+		return err
+	}
+	secret.Data[servingCertKey] = certPEM
+	secret.Data[servingKeyKey] = keyPEM
+	return nil
+}
+
+// patchCABundle sets the CA bundle of the webhooks of the configurations.
+func (p *certProvisioner) patchCABundle(ctx context.Context, caBundle []byte) error {
+	for _, name := range p.opts.ValidatingWebhookConfigurations {
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := p.reader.Get(ctx, types.NamespacedName{Name: name}, config); err != nil {
+			return fmt.Errorf("failed to get validatingwebhookconfiguration %s: %w", name, err)
+		}
+		patch := client.MergeFrom(config.DeepCopy())
+		changed := false
+		for i := range config.Webhooks {
+			if !bytes.Equal(config.Webhooks[i].ClientConfig.CABundle, caBundle) {
+				config.Webhooks[i].ClientConfig.CABundle = caBundle
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := p.client.Patch(ctx, config, patch); err != nil {
+			return fmt.Errorf("failed to patch validatingwebhookconfiguration %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// writeCerts writes the serving certificate of the secret to the cert dir, the webhook server
+// reloads it when it changes.
+func (p *certProvisioner) writeCerts(secret *corev1.Secret) error {
+	if err := os.MkdirAll(p.certDir, 0700); err != nil {
+		return err
+	}
+	for _, key := range []string{servingKeyKey, servingCertKey} {
+		path := filepath.Join(p.certDir, key)
+		if current, err := os.ReadFile(path); err == nil && bytes.Equal(current, secret.Data[key]) {
+			continue
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, secret.Data[key], 0600); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// needsRotation returns whether the first certificate of the PEM is missing or has less than a
+// third of its validity left.
+func needsRotation(certPEM []byte, validity time.Duration) bool {
+	certs := parseCerts(certPEM)
+	return len(certs) == 0 || time.Until(certs[0].NotAfter) < validity/3
+}
+
+// validCerts returns the PEM of the certificates which have not expired.
+func validCerts(certPEM []byte, now time.Time) []byte {
+	valid := []byte{}
+	for _, cert := range parseCerts(certPEM) {
+		if now.Before(cert.NotAfter) {
+			valid = append(valid, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+		}
+	}
+	return valid
+}
+
+func parseCerts(certPEM []byte) []*x509.Certificate {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			return certs
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+// parseCA returns the current CA of a bundle, the first certificate, and its key.
+func parseCA(certPEM, keyPEM []byte) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certs := parseCerts(certPEM)
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no CA certificate")
+	}
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("no CA key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certs[0], key, nil
+}
+
+// newCertificate returns the PEM of a new certificate and its key, issued by the parent or
+// self-signed if the parent is nil.
+func newCertificate(
+	subject pkix.Name,
+	dnsNames []string,
+	isCA bool,
+	now time.Time,
+	validity time.Duration,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               subject,
+		DNSNames:              dnsNames,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if isCA {
+		template.IsCA = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = nil
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestProvisioner(t *testing.T, objects ...client.Object) (*certProvisioner, client.Client) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	config := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "validating-webhook-configuration"},
+		Webhooks:   []admissionregistrationv1.ValidatingWebhook{{Name: "vwork.multicluster.x-k8s.io"}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, config)...).Build()
+	return &certProvisioner{
+		reader: c,
+		client: c,
+		log:    ctrl.Log,
+		opts: CertOptions{
+			SecretNamespace:                 "work",
+			SecretName:                      "webhook-server-cert",
+			ServiceName:                     "webhook-service",
+			ValidatingWebhookConfigurations: []string{"validating-webhook-configuration"},
+		},
+		certDir: t.TempDir(),
+	}, c
+}
+
+// verifyServingCert verifies the served certificate against the CA bundle of the webhook
+// configuration and returns the CA bundle.
+func verifyServingCert(t *testing.T, p *certProvisioner, c client.Client) []byte {
+	config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "validating-webhook-configuration"}, config); err != nil {
+		t.Fatal(err)
+	}
+	caBundle := config.Webhooks[0].ClientConfig.CABundle
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		t.Fatal("expected the CA bundle to be patched")
+	}
+
+	certPEM, err := os.ReadFile(filepath.Join(p.certDir, servingCertKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(p.certDir, servingKeyKey)); err != nil {
+		t.Fatal(err)
+	}
+	certs := parseCerts(certPEM)
+	if len(certs) != 1 {
+		t.Fatalf("expected a serving certificate, got %d", len(certs))
+	}
+	if _, err := certs[0].Verify(x509.VerifyOptions{DNSName: "webhook-service.work.svc", Roots: roots}); err != nil {
+		t.Fatalf("expected the serving certificate to be trusted: %v", err)
+	}
+	return caBundle
+}
+
+func TestCertProvisionerProvision(t *testing.T) {
+	p, c := newTestProvisioner(t)
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	caBundle := verifyServingCert(t, p, c)
+
+	secret := &corev1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Namespace: "work", Name: "webhook-server-cert"}, secret); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(secret.Data[caCertKey], caBundle) {
+		t.Error("expected the CA bundle to be the CA of the secret")
+	}
+
+	// another replica serves the same certificates
+	other, _ := newTestProvisioner(t, secret)
+	if err := other.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	served, _ := os.ReadFile(filepath.Join(p.certDir, servingCertKey))
+	otherServed, _ := os.ReadFile(filepath.Join(other.certDir, servingCertKey))
+	if !bytes.Equal(served, otherServed) {
+		t.Error("expected the replicas to serve the same certificate")
+	}
+}
+
+// staleReader misses the objects on its first reads, as if another replica created them meanwhile.
+type staleReader struct {
+	client.Reader
+	misses int
+}
+
+func (r *staleReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if r.misses > 0 {
+		r.misses--
+		return apierrors.NewNotFound(corev1.Resource("secrets"), key.Name)
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+func TestCertProvisionerCreateRace(t *testing.T) {
+	p, c := newTestProvisioner(t)
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the other replica missed the secret and loses the race to create it
+	other, _ := newTestProvisioner(t)
+	other.reader, other.client = &staleReader{Reader: c, misses: 1}, c
+	if err := other.sync(context.Background()); err != nil {
+		t.Fatalf("expected the secret of the other replica to be used, got %v", err)
+	}
+	served, _ := os.ReadFile(filepath.Join(p.certDir, servingCertKey))
+	otherServed, _ := os.ReadFile(filepath.Join(other.certDir, servingCertKey))
+	if !bytes.Equal(served, otherServed) {
+		t.Error("expected the replicas to serve the same certificate")
+	}
+}
+
+func TestCertProvisionerRotateCA(t *testing.T) {
+	issued := time.Now().Add(-caValidity * 9 / 10)
+	caPEM, caKeyPEM, err := newCertificate(pkix.Name{CommonName: "work-webhook-ca"}, nil, true, issued, caValidity, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "work", Name: "webhook-server-cert"},
+		Data:       map[string][]byte{caCertKey: caPEM, caKeyKey: caKeyPEM},
+	}
+	p, c := newTestProvisioner(t, secret)
+	if err := p.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	caBundle := verifyServingCert(t, p, c)
+	cas := parseCerts(caBundle)
+	if len(cas) != 2 {
+		t.Fatalf("expected the new and the previous CA in the bundle, got %d", len(cas))
+	}
+	if !bytes.Equal(cas[1].Raw, parseCerts(caPEM)[0].Raw) {
+		t.Error("expected the previous CA to be kept in the bundle")
+	}
+	if needsRotation(caBundle, caValidity) {
+		t.Error("expected the new CA to be first in the bundle")
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// SecretPolicy is how the webhook treats the Works whose manifests include Secrets with plaintext data.
+type SecretPolicy string
+
+const (
+	// SecretPolicyAllow admits the Works with plaintext Secrets.
+	SecretPolicyAllow SecretPolicy = "Allow"
+	// SecretPolicyWarn admits the Works with plaintext Secrets with a warning to the client.
+	SecretPolicyWarn SecretPolicy = "Warn"
+	// SecretPolicyReject rejects the Works with plaintext Secrets, the credentials must be delivered
+	// as e.g. SealedSecrets or ExternalSecrets which are only resolved on the spoke cluster.
+	SecretPolicyReject SecretPolicy = "Reject"
+)
+
+// ParseSecretPolicy returns the secret policy of the given name, Allow if empty.
+func ParseSecretPolicy(name string) (SecretPolicy, error) {
+	switch policy := SecretPolicy(name); policy {
+	case "":
+		return SecretPolicyAllow, nil
+	case SecretPolicyAllow, SecretPolicyWarn, SecretPolicyReject:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown secret policy %q, expected one of %s, %s or %s", name, SecretPolicyAllow, SecretPolicyWarn, SecretPolicyReject)
+	}
+}
+
+// findPlaintextSecrets returns the manifests of a work which are Secrets with data or stringData.
+// The manifests which cannot be decoded are left to the agent to report.
+func findPlaintextSecrets(work *workv1alpha1.Work) field.ErrorList {
+	var errs field.ErrorList
+	manifestsPath := field.NewPath("spec", "workload", "manifests")
+	for index, manifest := range work.Spec.Workload.Manifests {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(manifest.Raw); err != nil {
+			continue
+		}
+		if obj.GetAPIVersion() != "v1" || obj.GetKind() != "Secret" {
+			continue
+		}
+		data, _, _ := unstructured.NestedMap(obj.Object, "data")
+		stringData, _, _ := unstructured.NestedMap(obj.Object, "stringData")
+		if len(data) == 0 && len(stringData) == 0 {
+			continue
+		}
+		errs = append(errs, field.Forbidden(manifestsPath.Index(index),
+			fmt.Sprintf("the Secret %q holds plaintext data, deliver it as e.g. a SealedSecret or an ExternalSecret instead", secretName(obj))))
+	}
+	return errs
+}
+
+func secretName(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains the admission webhooks served on the hub.
+package webhook
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	// ValidateWorkPath is the path the work validating webhook is served at.
+	ValidateWorkPath = "/validate-multicluster-x-k8s-io-v1alpha1-work"
+)
+
+// SetupWithManager registers the webhooks with the webhook server of the manager. The works whose
+// manifests include plaintext Secrets are treated according to secretPolicy.
+func SetupWithManager(mgr ctrl.Manager, secretPolicy SecretPolicy) {
+	server := mgr.GetWebhookServer()
+	server.Register(ValidateWorkPath, &webhook.Admission{
+		Handler: &WorkValidator{log: ctrl.Log.WithName("webhooks").WithName("Work"), secretPolicy: secretPolicy},
+	})
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// +kubebuilder:webhook:path=/validate-multicluster-x-k8s-io-v1alpha1-work,mutating=false,failurePolicy=fail,sideEffects=None,groups=multicluster.x-k8s.io,resources=works,verbs=create;update,versions=v1alpha1,name=vwork.multicluster.x-k8s.io,admissionReviewVersions=v1
+
+// WorkValidator validates the works created and updated on the hub
+type WorkValidator struct {
+	log     logr.Logger
+	decoder *admission.Decoder
+	// secretPolicy is how the works whose manifests include plaintext Secrets are treated.
+	secretPolicy SecretPolicy
+}
+
+var _ admission.DecoderInjector = &WorkValidator{}
+
+// InjectDecoder injects the decoder into the validator.
+func (v *WorkValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle rejects the works which are not valid, and warns about or rejects the works with plaintext
+// Secrets as configured by the secret policy.
+func (v *WorkValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	work := &workv1alpha1.Work{}
+	if err := v.decoder.Decode(req, work); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var errs field.ErrorList
+	// the secrets are only checked when the workload changes, so the works created before the
+	// policy can still be updated, e.g. to remove their finalizer
+	checkSecrets := v.secretPolicy == SecretPolicyWarn || v.secretPolicy == SecretPolicyReject
+	if req.Operation == admissionv1.Update {
+		oldWork := &workv1alpha1.Work{}
+		if err := v.decoder.DecodeRaw(req.OldObject, oldWork); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		errs = append(errs, validateWorkUpdate(work, oldWork)...)
+		checkSecrets = checkSecrets && !equality.Semantic.DeepEqual(work.Spec.Workload, oldWork.Spec.Workload)
+	}
+
+	var warnings []string
+	if checkSecrets {
+		secretErrs := findPlaintextSecrets(work)
+		if v.secretPolicy == SecretPolicyReject {
+			errs = append(errs, secretErrs...)
+		} else {
+			for _, err := range secretErrs {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+
+	workKey := types.NamespacedName{Namespace: req.Namespace, Name: req.Name}
+	if len(errs) > 0 {
+		v.log.V(2).Info("rejecting work", "work", workKey, "errors", errs.ToAggregate().Error())
+		return admission.Denied(errs.ToAggregate().Error())
+	}
+	if len(warnings) > 0 {
+		v.log.V(2).Info("admitting work with warnings", "work", workKey, "warnings", warnings)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// validateWorkUpdate forbids changing the workload of an immutable work, or making it mutable again.
+func validateWorkUpdate(work, oldWork *workv1alpha1.Work) field.ErrorList {
+	if !oldWork.Spec.Immutable {
+		return nil
+	}
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	if !work.Spec.Immutable {
+		errs = append(errs, field.Forbidden(specPath.Child("immutable"), "an immutable work cannot be made mutable"))
+	}
+	if !equality.Semantic.DeepEqual(work.Spec.Workload, oldWork.Spec.Workload) {
+		errs = append(errs, field.Forbidden(specPath.Child("workload"), "the workload of an immutable work cannot be changed, replace the work instead"))
+	}
+	return errs
+}
@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func newTestValidator(t *testing.T) *WorkValidator {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(workv1alpha1.AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v := &WorkValidator{log: ctrl.Log}
+	if err := v.InjectDecoder(decoder); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+func newTestWork(immutable bool, configMapName string) *workv1alpha1.Work {
+	return &workv1alpha1.Work{
+		TypeMeta:   metav1.TypeMeta{APIVersion: workv1alpha1.GroupVersion.String(), Kind: workv1alpha1.WorkKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "work", Namespace: "cluster1"},
+		Spec: workv1alpha1.WorkSpec{
+			Immutable: immutable,
+			Workload: workv1alpha1.WorkloadTemplate{
+				Manifests: []workv1alpha1.Manifest{
+					{RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"` + configMapName + `","namespace":"default"}}`)}},
+				},
+			},
+		},
+	}
+}
+
+func newUpdateRequest(t *testing.T, work, oldWork *workv1alpha1.Work) admission.Request {
+	raw, err := json.Marshal(work)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	oldRaw, err := json.Marshal(oldWork)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Update,
+		Name:      work.Name,
+		Namespace: work.Namespace,
+		Object:    runtime.RawExtension{Raw: raw},
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+	}}
+}
+
+func TestWorkValidatorImmutable(t *testing.T) {
+	cases := []struct {
+		name            string
+		oldWork         *workv1alpha1.Work
+		work            *workv1alpha1.Work
+		expectedAllowed bool
+	}{
+		{
+			name:            "mutable work changed",
+			oldWork:         newTestWork(false, "cm1"),
+			work:            newTestWork(false, "cm2"),
+			expectedAllowed: true,
+		},
+		{
+			name:            "mutable work made immutable",
+			oldWork:         newTestWork(false, "cm1"),
+			work:            newTestWork(true, "cm1"),
+			expectedAllowed: true,
+		},
+		{
+			name:            "immutable work unchanged",
+			oldWork:         newTestWork(true, "cm1"),
+			work:            newTestWork(true, "cm1"),
+			expectedAllowed: true,
+		},
+		{
+			name:    "immutable work changed",
+			oldWork: newTestWork(true, "cm1"),
+			work:    newTestWork(true, "cm2"),
+		},
+		{
+			name:    "immutable work made mutable",
+			oldWork: newTestWork(true, "cm1"),
+			work:    newTestWork(false, "cm1"),
+		},
+	}
+
+	v := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := v.Handle(context.Background(), newUpdateRequest(t, c.work, c.oldWork))
+			if resp.Allowed != c.expectedAllowed {
+				t.Errorf("expected allowed %v, got %v: %v", c.expectedAllowed, resp.Allowed, resp.Result)
+			}
+		})
+	}
+}
+
+func newSecretWork(secret string) *workv1alpha1.Work {
+	work := newTestWork(false, "cm1")
+	work.Spec.Workload.Manifests = append(work.Spec.Workload.Manifests, workv1alpha1.Manifest{
+		RawExtension: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"secret","namespace":"default"}` + secret + `}`)},
+	})
+	return work
+}
+
+func TestWorkValidatorSecretPolicy(t *testing.T) {
+	cases := []struct {
+		name             string
+		policy           SecretPolicy
+		oldWork          *workv1alpha1.Work
+		work             *workv1alpha1.Work
+		expectedAllowed  bool
+		expectedWarnings int
+	}{
+		{
+			name:            "secret allowed",
+			policy:          SecretPolicyAllow,
+			work:            newSecretWork(`,"data":{"password":"c2VjcmV0"}`),
+			expectedAllowed: true,
+		},
+		{
+			name:             "secret warned",
+			policy:           SecretPolicyWarn,
+			work:             newSecretWork(`,"stringData":{"password":"secret"}`),
+			expectedAllowed:  true,
+			expectedWarnings: 1,
+		},
+		{
+			name:   "secret rejected",
+			policy: SecretPolicyReject,
+			work:   newSecretWork(`,"data":{"password":"c2VjcmV0"}`),
+		},
+		{
+			name:            "secret without data",
+			policy:          SecretPolicyReject,
+			work:            newSecretWork(`,"type":"kubernetes.io/service-account-token"`),
+			expectedAllowed: true,
+		},
+		{
+			name:            "workload unchanged",
+			policy:          SecretPolicyReject,
+			oldWork:         newSecretWork(`,"data":{"password":"c2VjcmV0"}`),
+			work:            newSecretWork(`,"data":{"password":"c2VjcmV0"}`),
+			expectedAllowed: true,
+		},
+		{
+			name:    "workload changed",
+			policy:  SecretPolicyReject,
+			oldWork: newTestWork(false, "cm1"),
+			work:    newSecretWork(`,"data":{"password":"c2VjcmV0"}`),
+		},
+	}
+
+	v := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v.secretPolicy = c.policy
+			var req admission.Request
+			if c.oldWork != nil {
+				req = newUpdateRequest(t, c.work, c.oldWork)
+			} else {
+				raw, err := json.Marshal(c.work)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				req = admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Name:      c.work.Name,
+					Namespace: c.work.Namespace,
+					Object:    runtime.RawExtension{Raw: raw},
+				}}
+			}
+			resp := v.Handle(context.Background(), req)
+			if resp.Allowed != c.expectedAllowed {
+				t.Errorf("expected allowed %v, got %v: %v", c.expectedAllowed, resp.Allowed, resp.Result)
+			}
+			if len(resp.Warnings) != c.expectedWarnings {
+				t.Errorf("expected %d warnings, got %v", c.expectedWarnings, resp.Warnings)
+			}
+		})
+	}
+}
+
+func TestParseSecretPolicy(t *testing.T) {
+	if policy, err := ParseSecretPolicy(""); err != nil || policy != SecretPolicyAllow {
+		t.Errorf("expected the Allow policy by default, got %q: %v", policy, err)
+	}
+	if policy, err := ParseSecretPolicy("Reject"); err != nil || policy != SecretPolicyReject {
+		t.Errorf("expected the Reject policy, got %q: %v", policy, err)
+	}
+	if _, err := ParseSecretPolicy("Deny"); err == nil {
+		t.Error("expected an unknown policy to fail")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package worktest sets up the tests of the work API consumers and controllers: the scheme, an
+// envtest environment installing the work CRDs, and a fake dynamic client of the spoke cluster.
+package worktest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/work-api/config/crd"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// NewScheme returns a scheme holding the built-in types and the work API.
+func NewScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := workv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+// CRDs returns the CustomResourceDefinitions of the work API.
+func CRDs() ([]apiextensionsv1.CustomResourceDefinition, error) {
+	files, err := fs.Glob(crd.FS, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	crds := []apiextensionsv1.CustomResourceDefinition{}
+	for _, file := range files {
+		content, err := crd.FS.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(content), 4096)
+		for {
+			definition := apiextensionsv1.CustomResourceDefinition{}
+			err := decoder.Decode(&definition)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %s: %w", file, err)
+			}
+			if definition.Name != "" {
+				crds = append(crds, definition)
+			}
+		}
+	}
+	return crds, nil
+}
+
+// NewEnvironment returns an envtest environment installing the work CRDs on start. Like any
+// envtest environment, it needs the control plane binaries, see envtest.Environment.
+func NewEnvironment() (*envtest.Environment, error) {
+	crds, err := CRDs()
+	if err != nil {
+		return nil, err
+	}
+	return &envtest.Environment{CRDs: crds}, nil
+}
+
+// NewFakeSpokeDynamicClient returns a fake dynamic client of the spoke cluster holding the given
+// objects. The objects must be typed objects of the scheme, or unstructured objects of its kinds.
+func NewFakeSpokeDynamicClient(scheme *runtime.Scheme, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+}
+
+// FailOn makes the fake client fail the given verb on a resource with err, "*" matches any verb
+// or resource. The failure takes precedence over the reactors added before.
+func FailOn(client *dynamicfake.FakeDynamicClient, verb, resource string, err error) {
+	client.PrependReactor(verb, resource, func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, err
+	})
+}
+
+// FailOnce is like FailOn, but the verb fails only the first time it is called.
+func FailOnce(client *dynamicfake.FakeDynamicClient, verb, resource string, err error) {
+	failed := false
+	client.PrependReactor(verb, resource, func(clienttesting.Action) (bool, runtime.Object, error) {
+		if failed {
+			return false, nil, nil
+		}
+		failed = true
+		return true, nil, err
+	})
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worktest
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	workv1alpha1 "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+func TestCRDs(t *testing.T) {
+	crds, err := CRDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]bool{}
+	for _, crd := range crds {
+		if crd.Spec.Group != workv1alpha1.GroupName {
+			t.Errorf("unexpected group %s of crd %s", crd.Spec.Group, crd.Name)
+		}
+		kinds[crd.Spec.Names.Kind] = true
+	}
+	for _, kind := range []string{"Work", "AppliedWork", "WorkSet"} {
+		if !kinds[kind] {
+			t.Errorf("expected a crd of kind %s, got %v", kind, kinds)
+		}
+	}
+}
+
+func TestFakeSpokeDynamicClient(t *testing.T) {
+	scheme, err := NewScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "default"}}
+	client := NewFakeSpokeDynamicClient(scheme, configMap)
+	configMaps := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("default")
+
+	FailOnce(client, "get", "configmaps", errors.NewServiceUnavailable("unavailable"))
+	if _, err := configMaps.Get(context.TODO(), "config", metav1.GetOptions{}); !errors.IsServiceUnavailable(err) {
+		t.Errorf("expected the first get to fail, got %v", err)
+	}
+	if _, err := configMaps.Get(context.TODO(), "config", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the second get to succeed, got %v", err)
+	}
+
+	FailOn(client, "delete", "*", errors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "config", nil))
+	for i := 0; i < 2; i++ {
+		if err := configMaps.Delete(context.TODO(), "config", metav1.DeleteOptions{}); !errors.IsForbidden(err) {
+			t.Errorf("expected the delete to fail, got %v", err)
+		}
+	}
+}
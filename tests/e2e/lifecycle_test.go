@@ -0,0 +1,163 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	workapi "sigs.k8s.io/work-api/pkg/apis/v1alpha1"
+)
+
+// newConfigMapWork returns a work in the default namespace applying a configmap of the same name
+// to the default namespace of the spoke cluster.
+func newConfigMapWork(name string, data map[string]string) *workapi.Work {
+	return &workapi.Work{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: workapi.WorkSpec{
+			Workload: workapi.WorkloadTemplate{
+				Manifests: []workapi.Manifest{newConfigMapManifest(name, data)},
+			},
+		},
+	}
+}
+
+func newConfigMapManifest(name string, data map[string]string) workapi.Manifest {
+	return workapi.Manifest{RawExtension: runtime.RawExtension{Object: &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       data,
+	}}}
+}
+
+// spokeConfigMapData returns the data of a configmap on the spoke cluster.
+func spokeConfigMapData(name string) func() (map[string]string, error) {
+	return func() (map[string]string, error) {
+		cm, err := spokeKubeClient.CoreV1().ConfigMaps("default").Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return cm.Data, nil
+	}
+}
+
+var _ = ginkgo.Describe("Work lifecycle", func() {
+	var work *workapi.Work
+
+	ginkgo.BeforeEach(func() {
+		work = newConfigMapWork("e2e-"+utilrand.String(5), map[string]string{"key": "v1"})
+		_, err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Create(context.Background(), work, metav1.CreateOptions{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Eventually(spokeConfigMapData(work.Name), eventuallyTimeout, eventuallyInterval).Should(gomega.HaveKeyWithValue("key", "v1"))
+	})
+
+	ginkgo.AfterEach(func() {
+		err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Delete(context.Background(), work.Name, metav1.DeleteOptions{})
+		if !errors.IsNotFound(err) {
+			gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		}
+	})
+
+	ginkgo.It("Should report the status of the manifests", func() {
+		gomega.Eventually(func() error {
+			current, err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if len(current.Status.ManifestConditions) != 1 {
+				return fmt.Errorf("expected 1 manifest condition, got %d", len(current.Status.ManifestConditions))
+			}
+			manifestCondition := current.Status.ManifestConditions[0]
+			if manifestCondition.Identifier.Resource != "configmaps" || manifestCondition.Identifier.Name != work.Name {
+				return fmt.Errorf("unexpected manifest identifier %v", manifestCondition.Identifier)
+			}
+			if !meta.IsStatusConditionTrue(manifestCondition.Conditions, "Applied") || manifestCondition.LastAppliedTime == nil {
+				return fmt.Errorf("expected the manifest to be applied")
+			}
+			if !meta.IsStatusConditionTrue(current.Status.Conditions, "Applied") {
+				return fmt.Errorf("expected the work to be applied")
+			}
+			return nil
+		}, eventuallyTimeout, eventuallyInterval).ShouldNot(gomega.HaveOccurred())
+	})
+
+	ginkgo.It("Should update the resources when the work is updated", func() {
+		gomega.Eventually(func() error {
+			current, err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			current.Spec.Workload.Manifests = []workapi.Manifest{newConfigMapManifest(work.Name, map[string]string{"key": "v2"})}
+			_, err = hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Update(context.Background(), current, metav1.UpdateOptions{})
+			return err
+		}, eventuallyTimeout, eventuallyInterval).ShouldNot(gomega.HaveOccurred())
+
+		gomega.Eventually(spokeConfigMapData(work.Name), eventuallyTimeout, eventuallyInterval).Should(gomega.HaveKeyWithValue("key", "v2"))
+	})
+
+	ginkgo.It("Should correct the drift of the resources when the work is resynced", func() {
+		cm, err := spokeKubeClient.CoreV1().ConfigMaps("default").Get(context.Background(), work.Name, metav1.GetOptions{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+		cm.Data["key"] = "drifted"
+		_, err = spokeKubeClient.CoreV1().ConfigMaps("default").Update(context.Background(), cm, metav1.UpdateOptions{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Eventually(func() error {
+			current, err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			metav1.SetMetaDataAnnotation(&current.ObjectMeta, workapi.ResyncAnnotation, "1")
+			_, err = hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Update(context.Background(), current, metav1.UpdateOptions{})
+			return err
+		}, eventuallyTimeout, eventuallyInterval).ShouldNot(gomega.HaveOccurred())
+
+		gomega.Eventually(spokeConfigMapData(work.Name), eventuallyTimeout, eventuallyInterval).Should(gomega.HaveKeyWithValue("key", "v1"))
+	})
+
+	ginkgo.It("Should delete the resources when the work is deleted", func() {
+		err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Delete(context.Background(), work.Name, metav1.DeleteOptions{})
+		gomega.Expect(err).ToNot(gomega.HaveOccurred())
+
+		gomega.Eventually(func() bool {
+			_, err := spokeKubeClient.CoreV1().ConfigMaps("default").Get(context.Background(), work.Name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, eventuallyTimeout, eventuallyInterval).Should(gomega.BeTrue())
+
+		gomega.Eventually(func() bool {
+			_, err := hubWorkClient.MulticlusterV1alpha1().AppliedWorks().Get(context.Background(), workapi.AppliedWorkName(work.Namespace, work.Name), metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, eventuallyTimeout, eventuallyInterval).Should(gomega.BeTrue())
+
+		gomega.Eventually(func() bool {
+			_, err := hubWorkClient.MulticlusterV1alpha1().Works(work.Namespace).Get(context.Background(), work.Name, metav1.GetOptions{})
+			return errors.IsNotFound(err)
+		}, eventuallyTimeout, eventuallyInterval).Should(gomega.BeTrue())
+	})
+})